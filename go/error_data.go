@@ -0,0 +1,100 @@
+package x402
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// VerifyErrorData is the data object a facilitator returned with a failed verify
+// Fields the facilitator sends beyond these are kept in Raw; read them with Field.
+type VerifyErrorData struct {
+	InvalidReason  string  `json:"invalidReason,omitempty"`
+	InvalidMessage string  `json:"invalidMessage,omitempty"` // Human-readable explanation, when provided
+	Payer          string  `json:"payer,omitempty"`
+	Network        Network `json:"network,omitempty"`
+
+	// Raw is the complete data object as received
+	Raw json.RawMessage `json:"-"`
+}
+
+// Field decodes the named field of the data object into out
+// Returns false when the field is absent, and an error when it does not decode into out.
+func (d *VerifyErrorData) Field(name string, out interface{}) (bool, error) {
+	return rawField(d.Raw, name, out)
+}
+
+// SettleErrorData is the data object a facilitator returned with a failed settle
+// Fields the facilitator sends beyond these are kept in Raw; read them with Field.
+type SettleErrorData struct {
+	ErrorReason  string  `json:"errorReason,omitempty"`
+	ErrorMessage string  `json:"errorMessage,omitempty"` // Human-readable explanation, when provided
+	Payer        string  `json:"payer,omitempty"`
+	Network      Network `json:"network,omitempty"`
+	Transaction  string  `json:"transaction,omitempty"`  // Hash of the reverted or failed transaction
+	RevertReason string  `json:"revertReason,omitempty"` // Decoded revert reason of the transaction
+	GasUsed      string  `json:"-"`                      // Gas used by the transaction, as a decimal string
+
+	// Raw is the complete data object as received
+	Raw json.RawMessage `json:"-"`
+}
+
+// GasUsedUint64 returns GasUsed as a number; false when the facilitator did not report it
+func (d *SettleErrorData) GasUsedUint64() (uint64, bool) {
+	gas, err := strconv.ParseUint(d.GasUsed, 10, 64)
+	return gas, err == nil
+}
+
+// Field decodes the named field of the data object into out
+// Returns false when the field is absent, and an error when it does not decode into out.
+func (d *SettleErrorData) Field(name string, out interface{}) (bool, error) {
+	return rawField(d.Raw, name, out)
+}
+
+// UnmarshalJSON decodes the data object, keeping a copy in Raw. gasUsed may be a JSON
+// number or a decimal string.
+func (d *SettleErrorData) UnmarshalJSON(data []byte) error {
+	type plain SettleErrorData
+	var decoded struct {
+		plain
+		GasUsed json.Number `json:"gasUsed,omitempty"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*d = SettleErrorData(decoded.plain)
+	d.GasUsed = decoded.GasUsed.String()
+	d.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// UnmarshalJSON decodes the data object, keeping a copy in Raw
+func (d *VerifyErrorData) UnmarshalJSON(data []byte) error {
+	type plain VerifyErrorData
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*d = VerifyErrorData(decoded)
+	d.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// rawField decodes one field of a JSON object
+func rawField(raw json.RawMessage, name string, out interface{}) (bool, error) {
+	if len(raw) == 0 {
+		return false, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return false, err
+	}
+	value, ok := fields[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(value, out); err != nil {
+		return true, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return true, nil
+}
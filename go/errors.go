@@ -53,6 +53,9 @@ type VerifyError struct {
 	Payer   string  // Payer address (if known)
 	Network Network // Network identifier (if known)
 	Err     error   // Optional underlying error (for wrapping system errors)
+
+	// Data is the error detail a remote facilitator returned (nil for local failures)
+	Data *VerifyErrorData
 }
 
 // Error implements the error interface
@@ -97,6 +100,9 @@ type SettleError struct {
 	Network     Network // Network identifier
 	Transaction string  // Transaction hash (if settlement was attempted)
 	Err         error   // Optional underlying error (for wrapping system errors)
+
+	// Data is the error detail a remote facilitator returned (nil for local failures)
+	Data *SettleErrorData
 }
 
 // Error implements the error interface
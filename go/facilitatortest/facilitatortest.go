@@ -0,0 +1,196 @@
+// Package facilitatortest provides an in-memory x402.FacilitatorClient for tests of
+// code that depends on a facilitator, in the spirit of net/http/httptest.
+package facilitatortest
+
+import (
+	"context"
+	"sync"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// Method names recorded in Call.Method
+const (
+	MethodVerify    = "Verify"
+	MethodSettle    = "Settle"
+	MethodSupported = "GetSupported"
+)
+
+// DefaultPayer is the payer reported by the responses of AlwaysValid
+const DefaultPayer = "0xmockpayer"
+
+// DefaultTransaction is the transaction reported by successful settlements of AlwaysValid
+const DefaultTransaction = "0xmocktransaction"
+
+// Call is one recorded call to a MockFacilitatorClient
+type Call struct {
+	Method            string
+	PayloadBytes      []byte // nil for GetSupported
+	RequirementsBytes []byte // nil for GetSupported
+}
+
+// MockFacilitatorClient is a programmable x402.FacilitatorClient that records every call.
+//
+// Each method returns the result of its Func field when set, otherwise the response and
+// error configured with SetVerifyResult, SetSettleResult and SetSupported. It is safe
+// for concurrent use.
+type MockFacilitatorClient struct {
+	// VerifyFunc, when set, handles Verify calls
+	VerifyFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error)
+
+	// SettleFunc, when set, handles Settle calls
+	SettleFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error)
+
+	// SupportedFunc, when set, handles GetSupported calls
+	SupportedFunc func(ctx context.Context) (x402.SupportedResponse, error)
+
+	mu           sync.Mutex
+	verifyResp   *x402.VerifyResponse
+	verifyErr    error
+	settleResp   *x402.SettleResponse
+	settleErr    error
+	supported    x402.SupportedResponse
+	supportedErr error
+	calls        []Call
+}
+
+// NewMockFacilitatorClient creates a mock that advertises kinds from GetSupported and
+// returns nil responses from Verify and Settle until results are configured
+func NewMockFacilitatorClient(kinds ...x402.SupportedKind) *MockFacilitatorClient {
+	return &MockFacilitatorClient{
+		supported: x402.SupportedResponse{
+			Kinds:      kinds,
+			Extensions: []string{},
+			Signers:    make(map[string][]string),
+		},
+	}
+}
+
+// AlwaysValid returns a mock that accepts every payment and settles it successfully.
+// It advertises the given kinds, or exact on eip155:1 when none are given.
+func AlwaysValid(kinds ...x402.SupportedKind) *MockFacilitatorClient {
+	m := NewMockFacilitatorClient(defaultKinds(kinds)...)
+	m.SetVerifyResult(&x402.VerifyResponse{IsValid: true, Payer: DefaultPayer}, nil)
+	m.SetSettleResult(&x402.SettleResponse{Success: true, Payer: DefaultPayer, Transaction: DefaultTransaction}, nil)
+	return m
+}
+
+// AlwaysInvalid returns a mock that rejects every payment with reason, as a *x402.VerifyError
+// from Verify and a *x402.SettleError from Settle.
+// It advertises the given kinds, or exact on eip155:1 when none are given.
+func AlwaysInvalid(reason string, kinds ...x402.SupportedKind) *MockFacilitatorClient {
+	m := NewMockFacilitatorClient(defaultKinds(kinds)...)
+	m.SetVerifyResult(nil, x402.NewVerifyError(reason, "", "", nil))
+	m.SetSettleResult(nil, x402.NewSettleError(reason, "", "", "", nil))
+	return m
+}
+
+// SetVerifyResult sets what Verify returns when VerifyFunc is nil
+func (m *MockFacilitatorClient) SetVerifyResult(resp *x402.VerifyResponse, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifyResp, m.verifyErr = resp, err
+}
+
+// SetSettleResult sets what Settle returns when SettleFunc is nil
+func (m *MockFacilitatorClient) SetSettleResult(resp *x402.SettleResponse, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settleResp, m.settleErr = resp, err
+}
+
+// SetSupported sets what GetSupported returns when SupportedFunc is nil
+func (m *MockFacilitatorClient) SetSupported(supported x402.SupportedResponse, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.supported, m.supportedErr = supported, err
+}
+
+// Verify implements x402.FacilitatorClient
+func (m *MockFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	m.record(MethodVerify, payloadBytes, requirementsBytes)
+	if m.VerifyFunc != nil {
+		return m.VerifyFunc(ctx, payloadBytes, requirementsBytes)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.verifyResp == nil {
+		return nil, m.verifyErr
+	}
+	resp := *m.verifyResp
+	return &resp, m.verifyErr
+}
+
+// Settle implements x402.FacilitatorClient
+func (m *MockFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	m.record(MethodSettle, payloadBytes, requirementsBytes)
+	if m.SettleFunc != nil {
+		return m.SettleFunc(ctx, payloadBytes, requirementsBytes)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.settleResp == nil {
+		return nil, m.settleErr
+	}
+	resp := *m.settleResp
+	return &resp, m.settleErr
+}
+
+// GetSupported implements x402.FacilitatorClient
+func (m *MockFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	m.record(MethodSupported, nil, nil)
+	if m.SupportedFunc != nil {
+		return m.SupportedFunc(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.supported, m.supportedErr
+}
+
+// Calls returns a copy of the recorded calls in order
+func (m *MockFacilitatorClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns the number of recorded calls to method
+func (m *MockFacilitatorClient) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, call := range m.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset clears the recorded calls
+func (m *MockFacilitatorClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *MockFacilitatorClient) record(method string, payloadBytes, requirementsBytes []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{
+		Method:            method,
+		PayloadBytes:      append([]byte(nil), payloadBytes...),
+		RequirementsBytes: append([]byte(nil), requirementsBytes...),
+	})
+}
+
+// defaultKinds returns kinds, or exact on eip155:1 when empty
+func defaultKinds(kinds []x402.SupportedKind) []x402.SupportedKind {
+	if len(kinds) > 0 {
+		return kinds
+	}
+	return []x402.SupportedKind{{X402Version: x402.ProtocolVersion, Scheme: "exact", Network: "eip155:1"}}
+}
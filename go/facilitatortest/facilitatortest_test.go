@@ -0,0 +1,114 @@
+package facilitatortest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestAlwaysValid(t *testing.T) {
+	mock := AlwaysValid()
+
+	verifyResp, err := mock.Verify(context.Background(), []byte(`{"p":1}`), []byte(`{"r":1}`))
+	if err != nil || !verifyResp.IsValid || verifyResp.Payer != DefaultPayer {
+		t.Errorf("Expected valid verify, got %+v, %v", verifyResp, err)
+	}
+
+	settleResp, err := mock.Settle(context.Background(), []byte(`{"p":2}`), []byte(`{"r":2}`))
+	if err != nil || !settleResp.Success || settleResp.Transaction != DefaultTransaction {
+		t.Errorf("Expected successful settle, got %+v, %v", settleResp, err)
+	}
+
+	supported, err := mock.GetSupported(context.Background())
+	if err != nil || len(supported.Kinds) != 1 || supported.Kinds[0].Network != "eip155:1" {
+		t.Errorf("Expected default kind, got %+v, %v", supported, err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected 3 calls, got %d", len(calls))
+	}
+	if calls[0].Method != MethodVerify || string(calls[0].PayloadBytes) != `{"p":1}` || string(calls[0].RequirementsBytes) != `{"r":1}` {
+		t.Errorf("Unexpected verify call: %+v", calls[0])
+	}
+	if calls[1].Method != MethodSettle || calls[2].Method != MethodSupported || calls[2].PayloadBytes != nil {
+		t.Errorf("Unexpected calls: %+v", calls)
+	}
+
+	// Responses are copies, so callers cannot change the programmed result
+	verifyResp.Payer = "changed"
+	if again, _ := mock.Verify(context.Background(), nil, nil); again.Payer != DefaultPayer {
+		t.Errorf("Expected programmed response to be unchanged, got %s", again.Payer)
+	}
+}
+
+func TestAlwaysInvalid(t *testing.T) {
+	mock := AlwaysInvalid("insufficient_funds", x402.SupportedKind{X402Version: 2, Scheme: "exact", Network: "eip155:8453"})
+
+	_, err := mock.Verify(context.Background(), nil, nil)
+	var verifyErr *x402.VerifyError
+	if !errors.As(err, &verifyErr) || verifyErr.Reason != "insufficient_funds" {
+		t.Errorf("Expected VerifyError, got %v", err)
+	}
+
+	_, err = mock.Settle(context.Background(), nil, nil)
+	var settleErr *x402.SettleError
+	if !errors.As(err, &settleErr) || settleErr.Reason != "insufficient_funds" {
+		t.Errorf("Expected SettleError, got %v", err)
+	}
+
+	supported, _ := mock.GetSupported(context.Background())
+	if len(supported.Kinds) != 1 || supported.Kinds[0].Network != "eip155:8453" {
+		t.Errorf("Expected the given kind, got %+v", supported.Kinds)
+	}
+}
+
+func TestMockFacilitatorClientProgrammable(t *testing.T) {
+	mock := NewMockFacilitatorClient()
+
+	if resp, err := mock.Verify(context.Background(), nil, nil); resp != nil || err != nil {
+		t.Errorf("Expected nil results before programming, got %+v, %v", resp, err)
+	}
+
+	unavailable := errors.New("facilitator down")
+	mock.SetSupported(x402.SupportedResponse{}, unavailable)
+	if _, err := mock.GetSupported(context.Background()); !errors.Is(err, unavailable) {
+		t.Errorf("Expected programmed error, got %v", err)
+	}
+
+	mock.SettleFunc = func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+		return &x402.SettleResponse{Success: true, Transaction: string(payloadBytes)}, nil
+	}
+	mock.SetSettleResult(nil, unavailable)
+	if resp, err := mock.Settle(context.Background(), []byte("0xfunc"), nil); err != nil || resp.Transaction != "0xfunc" {
+		t.Errorf("Expected SettleFunc to take precedence, got %+v, %v", resp, err)
+	}
+
+	if mock.CallCount(MethodVerify) != 1 || mock.CallCount(MethodSettle) != 1 || mock.CallCount(MethodSupported) != 1 {
+		t.Errorf("Unexpected call counts: %+v", mock.Calls())
+	}
+	mock.Reset()
+	if len(mock.Calls()) != 0 {
+		t.Errorf("Expected no calls after Reset")
+	}
+}
+
+func TestMockFacilitatorClientConcurrent(t *testing.T) {
+	mock := AlwaysValid()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = mock.Verify(context.Background(), nil, nil)
+			mock.SetSettleResult(&x402.SettleResponse{Success: true}, nil)
+		}()
+	}
+	wg.Wait()
+	if got := mock.CallCount(MethodVerify); got != 20 {
+		t.Errorf("Expected 20 verify calls, got %d", got)
+	}
+}
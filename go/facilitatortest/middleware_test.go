@@ -0,0 +1,104 @@
+package facilitatortest_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/facilitatortest"
+	x402http "github.com/gatechain/x402/go/http"
+	ginmw "github.com/gatechain/x402/go/http/gin"
+	"github.com/gatechain/x402/go/types"
+	"github.com/gin-gonic/gin"
+)
+
+// fixedPriceScheme is a minimal exact scheme server that prices everything at 1 USDC
+type fixedPriceScheme struct{}
+
+func (fixedPriceScheme) Scheme() string { return "exact" }
+
+func (fixedPriceScheme) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	return x402.AssetAmount{Asset: "USDC", Amount: "1000000"}, nil
+}
+
+func (fixedPriceScheme) EnhancePaymentRequirements(ctx context.Context, base types.PaymentRequirements, supported types.SupportedKind, extensions []string) (types.PaymentRequirements, error) {
+	return base, nil
+}
+
+// TestMockInGinMiddleware substitutes a mock facilitator into the Gin payment middleware
+func TestMockInGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	facilitator := facilitatortest.AlwaysValid()
+
+	routes := x402http.RoutesConfig{
+		"GET /paid": x402http.RouteConfig{
+			Accepts: x402http.PaymentOptions{
+				{Scheme: "exact", PayTo: "0xmerchant", Price: "$1.00", Network: "eip155:1"},
+			},
+		},
+	}
+
+	router := gin.New()
+	router.Use(ginmw.PaymentMiddlewareFromConfig(routes,
+		ginmw.WithFacilitatorClient(facilitator),
+		ginmw.WithScheme("eip155:1", fixedPriceScheme{}),
+	))
+	router.GET("/paid", func(c *gin.Context) {
+		c.String(http.StatusOK, "paid content")
+	})
+
+	payload, _ := json.Marshal(x402.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"signature": "0x"},
+		Accepted: x402.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:1",
+			Asset:             "USDC",
+			Amount:            "1000000",
+			PayTo:             "0xmerchant",
+			MaxTimeoutSeconds: 300,
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/paid", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", base64.StdEncoding.EncodeToString(payload))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "paid content" {
+		t.Fatalf("Expected paid content, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected PAYMENT-RESPONSE header")
+	}
+	if got := facilitator.CallCount(facilitatortest.MethodVerify); got != 1 {
+		t.Errorf("Expected 1 verify call, got %d", got)
+	}
+	if got := facilitator.CallCount(facilitatortest.MethodSettle); got != 1 {
+		t.Errorf("Expected 1 settle call, got %d", got)
+	}
+
+	// The same route rejects payments when the facilitator does
+	rejecting := facilitatortest.AlwaysInvalid("invalid_signature")
+	router = gin.New()
+	router.Use(ginmw.PaymentMiddlewareFromConfig(routes,
+		ginmw.WithFacilitatorClient(rejecting),
+		ginmw.WithScheme("eip155:1", fixedPriceScheme{}),
+	))
+	router.GET("/paid", func(c *gin.Context) {
+		c.String(http.StatusOK, "paid content")
+	})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected 402, got %d", w.Code)
+	}
+	if got := rejecting.CallCount(facilitatortest.MethodSettle); got != 0 {
+		t.Errorf("Expected no settle for a rejected payment, got %d", got)
+	}
+}
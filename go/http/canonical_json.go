@@ -0,0 +1,52 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ============================================================================
+// Canonical JSON
+// ============================================================================
+
+// CanonicalJSON marshals v with object keys sorted at every level, no insignificant
+// whitespace and numbers kept as written (never round-tripped through float64).
+//
+// The bodies of the built-in codecs are canonical, so re-marshaling one (e.g. after a
+// proxy or a test decodes and re-encodes it) reproduces the bytes that were signed.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Struct fields marshal in declaration order and json.RawMessage verbatim; decoding
+	// into generic values and marshaling again sorts and compacts everything
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// decodeJSONObject decodes a single object keeping numbers as json.Number, so CanonicalJSON
+// sends them unchanged. Trailing data, null and non-object values are rejected.
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	if object == nil {
+		return nil, errors.New("expected a JSON object, got null")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON object")
+	}
+	return object, nil
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	type inner struct {
+		Zeta  string `json:"zeta"`
+		Alpha int    `json:"alpha"`
+	}
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "struct fields sorted", value: inner{Zeta: "z", Alpha: 1}, want: `{"alpha":1,"zeta":"z"}`},
+		{name: "nested maps sorted", value: map[string]interface{}{"b": map[string]interface{}{"y": 1, "x": []interface{}{inner{}}}, "a": nil}, want: `{"a":null,"b":{"x":[{"alpha":0,"zeta":""}],"y":1}}`},
+		{name: "raw message compacted", value: json.RawMessage(`{ "b" : 2, "a" : 1 }`), want: `{"a":1,"b":2}`},
+		{name: "numbers kept", value: json.RawMessage(`{"big":123456789012345678901234567890,"exp":1e3,"frac":1.50}`), want: `{"big":123456789012345678901234567890,"exp":1e3,"frac":1.50}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(tt.value)
+			if err != nil {
+				t.Fatalf("CanonicalJSON failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONObject(t *testing.T) {
+	object, err := decodeJSONObject([]byte(` {"amount":1000000000000000000000} `))
+	if err != nil {
+		t.Fatalf("decodeJSONObject failed: %v", err)
+	}
+	if object["amount"] != json.Number("1000000000000000000000") {
+		t.Errorf("Expected the number to be kept, got %v", object["amount"])
+	}
+
+	for _, data := range []string{``, `null`, `[]`, `"object"`, `42`, `{"a":1} {"b":2}`, `{"a":1}]`, `{"a":1} x`} {
+		if _, err := decodeJSONObject([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
+
+func TestSignedBodySurvivesRemarshaling(t *testing.T) {
+	type received struct {
+		body      []byte
+		timestamp string
+		signature string
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{body: body, timestamp: r.Header.Get("X-Timestamp"), signature: r.Header.Get("X-Signature")}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"data":{"isValid":true}}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+
+	// Unsorted keys, whitespace and an integer beyond float64 precision
+	payloadBytes := []byte(`{ "x402Version": 2, "payload": {"nonce": 18446744073709551617, "authorization": {"validBefore": 99999999999, "from": "0xpayer"}},
+		"accepted": {"scheme": "exact", "network": "eip155:1"} }`)
+	requirementsBytes := []byte(`{"payTo":"0xrecipient","amount":"1000","scheme":"exact","network":"eip155:1","asset":"USDC"}`)
+	if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	request := <-requests
+
+	// A receiver that decodes and re-encodes the body gets the bytes that were signed
+	decoder := json.NewDecoder(bytes.NewReader(request.body))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		t.Fatalf("Invalid request body %s: %v", request.body, err)
+	}
+	remarshaled, err := json.Marshal(generic)
+	if err != nil {
+		t.Fatalf("Failed to re-marshal body: %v", err)
+	}
+	if !bytes.Equal(remarshaled, request.body) {
+		t.Fatalf("Expected the body to be canonical\n sent: %s\n again: %s", request.body, remarshaled)
+	}
+	if !bytes.Contains(request.body, []byte(`"nonce":18446744073709551617`)) {
+		t.Errorf("Expected the nonce to be sent as written, got %s", request.body)
+	}
+
+	mac := hmac.New(sha256.New, []byte("sk"))
+	_, _ = mac.Write([]byte(request.timestamp + gateWeb3SigningPath + string(remarshaled)))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); request.signature != want {
+		t.Errorf("Expected the signature to cover the re-marshaled body, got %s want %s", request.signature, want)
+	}
+}
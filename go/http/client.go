@@ -403,24 +403,18 @@ func decodePaymentRequiredHeader(header string) (x402.PaymentRequired, error) {
 
 // encodePaymentResponseHeader encodes a settlement response as base64
 func encodePaymentResponseHeader(response x402.SettleResponse) string {
-	data, err := json.Marshal(response)
+	header, err := x402.EncodeSettlementHeader(response)
 	if err != nil {
-		panic(fmt.Sprintf("failed to marshal settle response: %v", err))
+		panic(err.Error())
 	}
-	return base64.StdEncoding.EncodeToString(data)
+	return header
 }
 
 // decodePaymentResponseHeader decodes a base64 payment response header
 func decodePaymentResponseHeader(header string) (*x402.SettleResponse, error) {
-	data, err := base64.StdEncoding.DecodeString(header)
+	response, err := x402.DecodeSettlementHeader(header)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base64 encoding: %w", err)
-	}
-
-	var response x402.SettleResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("invalid settle response JSON: %w", err)
+		return nil, err
 	}
-
 	return &response, nil
 }
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// Network and Asset Allowlist
+// ============================================================================
+
+// PaymentNotAllowedError is returned, wrapped in a FacilitatorError of kind
+// FacilitatorErrorInvalidRequest, when FacilitatorConfig.AllowedNetworks or AllowedAssets
+// refuse the requirements of a payment before it is sent
+type PaymentNotAllowedError struct {
+	Network string
+	Asset   string // Empty when the network itself is refused
+}
+
+// Error implements the error interface
+func (e *PaymentNotAllowedError) Error() string {
+	if e.Asset == "" {
+		return fmt.Sprintf("payment not allowed: network %q is not allowed", e.Network)
+	}
+	return fmt.Sprintf("payment not allowed: asset %q is not in AllowedAssets for network %q", e.Asset, e.Network)
+}
+
+// paymentAllowlist holds the configured networks and assets, lowercased
+type paymentAllowlist struct {
+	networks map[string]bool            // nil when any network is allowed
+	assets   map[string]map[string]bool // network -> assets; nil when any asset is allowed
+}
+
+// newPaymentAllowlist copies the configured lists, or returns nil when both are empty
+func newPaymentAllowlist(networks []string, assets map[string][]string) *paymentAllowlist {
+	if len(networks) == 0 && len(assets) == 0 {
+		return nil
+	}
+	allowlist := &paymentAllowlist{}
+	if len(networks) > 0 {
+		allowlist.networks = make(map[string]bool, len(networks))
+		for _, network := range networks {
+			allowlist.networks[allowlistKey(network)] = true
+		}
+	}
+	if len(assets) > 0 {
+		allowlist.assets = make(map[string]map[string]bool, len(assets))
+		for network, addresses := range assets {
+			key := allowlistKey(network)
+			if allowlist.assets[key] == nil {
+				allowlist.assets[key] = make(map[string]bool, len(addresses))
+			}
+			for _, address := range addresses {
+				allowlist.assets[key][allowlistKey(address)] = true
+			}
+		}
+	}
+	return allowlist
+}
+
+// check returns a *PaymentNotAllowedError unless the network and asset of the requirements
+// (V1 or V2, both carry them at the top level) are allowed
+func (a *paymentAllowlist) check(requirementsBytes []byte) error {
+	var requirements struct {
+		Network string `json:"network"`
+		Asset   string `json:"asset"`
+	}
+	// Undecodable requirements are reported by paymentRequestParams; an empty network is never allowed
+	_ = json.Unmarshal(requirementsBytes, &requirements)
+
+	network := allowlistKey(requirements.Network)
+	if a.networks != nil && !a.networks[network] {
+		return &PaymentNotAllowedError{Network: requirements.Network}
+	}
+	if a.assets != nil && !a.assets[network][allowlistKey(requirements.Asset)] {
+		if a.networks == nil && a.assets[network] == nil {
+			return &PaymentNotAllowedError{Network: requirements.Network}
+		}
+		return &PaymentNotAllowedError{Network: requirements.Network, Asset: requirements.Asset}
+	}
+	return nil
+}
+
+// allowlistKey normalizes a network or asset for comparison; addresses and CAIP-2
+// prefixes are case-insensitive
+func allowlistKey(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
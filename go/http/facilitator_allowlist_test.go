@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestHTTPFacilitatorClientAllowlist(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/settle") {
+			_ = json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtx"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+	}))
+	defer server.Close()
+
+	const usdc = "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF"
+	requirements := func(network, asset string) []byte {
+		return []byte(`{"scheme":"exact","network":"` + network + `","asset":"` + asset + `","amount":"1000","payTo":"0xpayto","maxTimeoutSeconds":60}`)
+	}
+	payload := []byte(`{"x402Version":2,"payload":{"signature":"0xsig"}}`)
+
+	tests := []struct {
+		name         string
+		networks     []string
+		assets       map[string][]string
+		requirements []byte
+		wantDenied   bool
+		wantNetwork  string
+		wantAsset    string
+	}{
+		{name: "no allowlist", requirements: requirements("eip155:1", "0xother")},
+		{name: "allowed network", networks: []string{"eip155:10087"}, requirements: requirements("eip155:10087", usdc)},
+		{name: "network case-insensitive", networks: []string{"EIP155:10087"}, requirements: requirements("eip155:10087", usdc)},
+		{name: "disallowed network", networks: []string{"eip155:10087"}, requirements: requirements("eip155:1", usdc), wantDenied: true, wantNetwork: "eip155:1"},
+		{name: "missing network", networks: []string{"eip155:10087"}, requirements: []byte(`{"scheme":"exact"}`), wantDenied: true},
+		{
+			name:         "allowed asset",
+			assets:       map[string][]string{"eip155:10087": {usdc}},
+			requirements: requirements("eip155:10087", strings.ToLower(usdc)),
+		},
+		{
+			name:         "disallowed asset",
+			assets:       map[string][]string{"eip155:10087": {usdc}},
+			requirements: requirements("eip155:10087", "0xother"),
+			wantDenied:   true,
+			wantNetwork:  "eip155:10087",
+			wantAsset:    "0xother",
+		},
+		{
+			name:         "network without assets",
+			assets:       map[string][]string{"eip155:10087": {usdc}},
+			requirements: requirements("eip155:1", usdc),
+			wantDenied:   true,
+			wantNetwork:  "eip155:1",
+		},
+		{
+			name:         "allowed network, disallowed asset",
+			networks:     []string{"eip155:10087", "eip155:1"},
+			assets:       map[string][]string{"eip155:10087": {usdc}},
+			requirements: requirements("eip155:1", usdc),
+			wantDenied:   true,
+			wantNetwork:  "eip155:1",
+			wantAsset:    usdc,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+				URL:             server.URL,
+				Codec:           RESTEnvelopeCodec{},
+				AllowedNetworks: tt.networks,
+				AllowedAssets:   tt.assets,
+			})
+			requests.Store(0)
+			_, verifyErr := client.Verify(context.Background(), payload, tt.requirements)
+			_, settleErr := client.Settle(context.Background(), payload, tt.requirements)
+
+			for op, err := range map[string]error{"verify": verifyErr, "settle": settleErr} {
+				if !tt.wantDenied {
+					if err != nil {
+						t.Errorf("%s: unexpected error: %v", op, err)
+					}
+					continue
+				}
+
+				var notAllowed *PaymentNotAllowedError
+				if !errors.As(err, &notAllowed) {
+					t.Fatalf("%s: expected *PaymentNotAllowedError, got %v", op, err)
+				}
+				if notAllowed.Network != tt.wantNetwork || notAllowed.Asset != tt.wantAsset {
+					t.Errorf("%s: expected denial of %q/%q, got %+v", op, tt.wantNetwork, tt.wantAsset, notAllowed)
+				}
+				var facilitatorErr *FacilitatorError
+				if !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorInvalidRequest || facilitatorErr.Operation != op {
+					t.Errorf("%s: expected an invalid_request FacilitatorError, got %v", op, err)
+				}
+			}
+
+			wantRequests := int32(2)
+			if tt.wantDenied {
+				wantRequests = 0
+			}
+			if requests.Load() != wantRequests {
+				t.Errorf("Expected %d requests, got %d", wantRequests, requests.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// Batch Verify
+// ============================================================================
+
+// defaultVerifyBatchConcurrency bounds fallback Verify calls when VerifyBatchConcurrency is unset
+const defaultVerifyBatchConcurrency = 8
+
+// VerifyItem is one payment in a VerifyBatch call
+type VerifyItem struct {
+	PayloadBytes      []byte
+	RequirementsBytes []byte
+}
+
+// VerifyBatchError reports the items of a VerifyBatch call that could not be verified
+type VerifyBatchError struct {
+	// Errors has one entry per item, in item order; nil for items that were verified
+	Errors []error
+}
+
+// Error implements the error interface
+func (e *VerifyBatchError) Error() string {
+	var failed []string
+	for i, err := range e.Errors {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("item %d: %v", i, err))
+		}
+	}
+	return fmt.Sprintf("verify batch: %d of %d items failed: %s", len(failed), len(e.Errors), strings.Join(failed, "; "))
+}
+
+// VerifyBatch verifies several payments, preferably in a single x402.verify request whose
+// params is an array of {x402Version, paymentPayload, paymentRequirements}.
+//
+// The returned responses are in item order. If any item fails, the error is a
+// *VerifyBatchError and the responses of the failed items are nil.
+//
+// When the facilitator rejects the batched request, VerifyBatch falls back to concurrent
+// Verify calls bounded by FacilitatorConfig.VerifyBatchConcurrency. Only a definitive "not
+// supported" answer (HTTP 404, 405 or 501, or a single result for the batched request) keeps
+// the fallback for the lifetime of the client; other rejections, such as a batch refused
+// over one bad item, fall back for that call only. Auth, throttling and server errors are
+// returned as they are. Verify has no side effects, so falling back after a rejected batch
+// is safe.
+func (c *HTTPFacilitatorClient) VerifyBatch(ctx context.Context, items []VerifyItem) ([]*x402.VerifyResponse, error) {
+	responses := make([]*x402.VerifyResponse, len(items))
+	errs := make([]error, len(items))
+	if len(items) == 0 {
+		return responses, nil
+	}
+
+	outcome := batchNotSupported
+	if !c.batchUnsupported.Load() {
+		var err error
+		if outcome, err = c.verifyBatchHTTP(ctx, items, responses, errs); err != nil {
+			return nil, err
+		}
+		if outcome == batchNotSupported {
+			c.batchUnsupported.Store(true)
+		}
+	}
+
+	if outcome != batchVerified {
+		c.verifyConcurrently(ctx, items, responses, errs)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return responses, &VerifyBatchError{Errors: errs}
+		}
+	}
+	return responses, nil
+}
+
+// batchOutcome is how a batched verify request was answered
+type batchOutcome int
+
+const (
+	// batchVerified means every encoded item has a result
+	batchVerified batchOutcome = iota
+	// batchRetrySingly means this batch was rejected and its items are verified one by one
+	batchRetrySingly
+	// batchNotSupported means the facilitator does not accept batched requests at all
+	batchNotSupported
+)
+
+// verifyBatchHTTP sends the items as one batched verify request, filling responses and errs
+func (c *HTTPFacilitatorClient) verifyBatchHTTP(
+	ctx context.Context,
+	items []VerifyItem,
+	responses []*x402.VerifyResponse,
+	errs []error,
+) (batchOutcome, error) {
+	// Items that cannot be encoded fail on their own and are left out of the request
+	params := make([]map[string]interface{}, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		version, err := types.DetectVersion(item.PayloadBytes)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to detect version: %w", err)
+			continue
+		}
+		itemParams, err := c.paymentRequestParams(operationVerify, version, item.PayloadBytes, item.RequirementsBytes)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		params = append(params, itemParams)
+		indexes = append(indexes, i)
+	}
+	if len(params) == 0 {
+		return batchVerified, nil
+	}
+
+	request, err := c.codec.EncodeRequest(envelopeRequest(operationVerify, params))
+	if err != nil {
+		return batchVerified, err
+	}
+
+	statusCode, responseBody, err := c.doRequest(ctx, operationVerify, request)
+	if err != nil {
+		return batchVerified, err
+	}
+	switch statusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return batchNotSupported, nil
+	}
+
+	var results []x402.VerifyResponse
+	envelope, err := c.codec.DecodeResponse(operationVerify.name, responseBody, &results)
+	if err != nil {
+		// A facilitator that only understands single requests answers with one result
+		var single x402.VerifyResponse
+		if _, singleErr := c.codec.DecodeResponse(operationVerify.name, responseBody, &single); singleErr == nil && statusCode == http.StatusOK {
+			return batchNotSupported, nil
+		}
+		if failure := newMalformedResponseError(operationVerify, statusCode, responseBody); failure.Kind != FacilitatorErrorMalformed {
+			return batchVerified, failure
+		}
+		return batchRetrySingly, nil
+	}
+	if !responseSucceeded(statusCode, envelope) {
+		if failure := newResponseError(operationVerify, statusCode, envelope); failure.Kind != FacilitatorErrorOther {
+			return batchVerified, failure
+		}
+		// e.g. a batch refused over one bad item; the single requests tell which
+		return batchRetrySingly, nil
+	}
+	if len(results) != len(params) {
+		return batchRetrySingly, nil
+	}
+
+	for j, i := range indexes {
+		response := results[j]
+		response.Envelope = envelope
+		responses[i] = &response
+	}
+	return batchVerified, nil
+}
+
+// verifyConcurrently verifies every item that has not failed yet with individual Verify calls
+func (c *HTTPFacilitatorClient) verifyConcurrently(
+	ctx context.Context,
+	items []VerifyItem,
+	responses []*x402.VerifyResponse,
+	errs []error,
+) {
+	concurrency := c.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultVerifyBatchConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if errs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, item VerifyItem) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			responses[i], errs[i] = c.Verify(ctx, item.PayloadBytes, item.RequirementsBytes)
+		}(i, item)
+	}
+	wg.Wait()
+}
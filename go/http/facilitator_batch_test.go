@@ -0,0 +1,245 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// batchTestItems returns n verify items whose payTo encodes the item index
+func batchTestItems(t *testing.T, n int) []VerifyItem {
+	t.Helper()
+	items := make([]VerifyItem, n)
+	for i := range items {
+		requirements := x402.PaymentRequirements{
+			Scheme:  "exact",
+			Network: "eip155:1",
+			Asset:   "USDC",
+			Amount:  "1000000",
+			PayTo:   fmt.Sprintf("0xpayee%d", i),
+		}
+		payload := x402.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+		payloadBytes, _ := json.Marshal(payload)
+		requirementsBytes, _ := json.Marshal(requirements)
+		items[i] = VerifyItem{PayloadBytes: payloadBytes, RequirementsBytes: requirementsBytes}
+	}
+	return items
+}
+
+// batchParams is one entry of a batched verify request
+type batchParams struct {
+	PaymentRequirements x402.PaymentRequirements `json:"paymentRequirements"`
+}
+
+func TestHTTPFacilitatorClientVerifyBatchNative(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var req struct {
+			Action string        `json:"action"`
+			Params []batchParams `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Action != "x402.verify" {
+			t.Errorf("unexpected request: %v", err)
+		}
+
+		// Payer echoes payTo so ordering can be checked; odd items are invalid
+		data := make([]x402.VerifyResponse, len(req.Params))
+		for i, p := range req.Params {
+			data[i] = x402.VerifyResponse{IsValid: i%2 == 0, Payer: p.PaymentRequirements.PayTo}
+			if i%2 == 1 {
+				data[i].InvalidReason = "insufficient_funds"
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+	items := batchTestItems(t, 5)
+	items[2].PayloadBytes = []byte(`not json`)
+
+	responses, err := client.VerifyBatch(context.Background(), items)
+
+	var batchErr *VerifyBatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected *VerifyBatchError for the malformed item, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 batched request, got %d", requests)
+	}
+	if len(responses) != len(items) || len(batchErr.Errors) != len(items) {
+		t.Fatalf("Expected %d results, got %d responses and %d errors", len(items), len(responses), len(batchErr.Errors))
+	}
+
+	for i := range items {
+		if i == 2 {
+			if responses[i] != nil || batchErr.Errors[i] == nil {
+				t.Errorf("Item 2: expected error only, got %v, %v", responses[i], batchErr.Errors[i])
+			}
+			continue
+		}
+		if batchErr.Errors[i] != nil {
+			t.Errorf("Item %d: unexpected error %v", i, batchErr.Errors[i])
+			continue
+		}
+		if want := fmt.Sprintf("0xpayee%d", i); responses[i].Payer != want {
+			t.Errorf("Item %d: expected payer %s, got %s", i, want, responses[i].Payer)
+		}
+		if responses[i].Envelope == nil {
+			t.Errorf("Item %d: expected envelope", i)
+		}
+	}
+}
+
+func TestHTTPFacilitatorClientVerifyBatchFallback(t *testing.T) {
+	var inFlight, maxInFlight, requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Params json.RawMessage `json:"params"`
+		}
+		_ = json.Unmarshal(body, &req)
+
+		// Single-request facilitator: batched params are rejected
+		var single batchParams
+		if err := json.Unmarshal(req.Params, &single); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 400, "msg": "invalid params"})
+			return
+		}
+
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+
+		data := x402.VerifyResponse{IsValid: true, Payer: single.PaymentRequirements.PayTo}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                    server.URL,
+		Signer:                 &recordingSigner{},
+		VerifyBatchConcurrency: 2,
+	})
+	items := batchTestItems(t, 6)
+
+	responses, err := client.VerifyBatch(context.Background(), items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, resp := range responses {
+		if want := fmt.Sprintf("0xpayee%d", i); resp == nil || resp.Payer != want {
+			t.Errorf("Item %d: expected payer %s, got %+v", i, want, resp)
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("Expected at most 2 concurrent verifies, got %d", got)
+	}
+	if got := atomic.LoadInt32(&requests); got != 7 {
+		t.Errorf("Expected 1 rejected batch and 6 single requests, got %d", got)
+	}
+
+	// A rejected batch is not proof that batching is unsupported, so the next call batches again
+	atomic.StoreInt32(&requests, 0)
+	if _, err := client.VerifyBatch(context.Background(), items[:2]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected 1 rejected batch and 2 single requests, got %d", got)
+	}
+}
+
+func TestHTTPFacilitatorClientVerifyBatchUnsupported(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int  // answer to batched requests
+		wantErr      bool // the call fails instead of falling back
+		wantRequests int32
+	}{
+		// 1 batch + 2 singles, then 2 singles
+		{name: "not implemented", status: http.StatusNotImplemented, wantRequests: 5},
+		{name: "not found", status: http.StatusNotFound, wantRequests: 5},
+		// 1 batch + 2 singles, twice
+		{name: "bad request", status: http.StatusBadRequest, wantRequests: 6},
+		// 1 failed batch, twice
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: true, wantRequests: 2},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantErr: true, wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				var req struct {
+					Params json.RawMessage `json:"params"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				if len(req.Params) > 0 && req.Params[0] == '[' {
+					w.WriteHeader(tt.status)
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": tt.status, "msg": "rejected"})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": x402.VerifyResponse{IsValid: true}})
+			}))
+			defer server.Close()
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+
+			for i := 0; i < 2; i++ {
+				_, err := client.VerifyBatch(context.Background(), batchTestItems(t, 2))
+				if gotErr := err != nil; gotErr != tt.wantErr {
+					t.Fatalf("Call %d: expected error %v, got %v", i, tt.wantErr, err)
+				}
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, got)
+			}
+		})
+	}
+}
+
+func TestHTTPFacilitatorClientVerifyBatchFallbackErrors(t *testing.T) {
+	// The echo server answers batched params with a single object, forcing the fallback
+	server := envelopeEchoServer(t, nil)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+
+	items := batchTestItems(t, 3)
+	items[1].RequirementsBytes = []byte(`[]`)
+
+	responses, err := client.VerifyBatch(context.Background(), items)
+	var batchErr *VerifyBatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected *VerifyBatchError, got %v", err)
+	}
+	if batchErr.Errors[0] != nil || batchErr.Errors[2] != nil || batchErr.Errors[1] == nil {
+		t.Errorf("Expected only item 1 to fail, got %v", batchErr.Errors)
+	}
+	if responses[0] == nil || !responses[0].IsValid || responses[2] == nil || responses[1] != nil {
+		t.Errorf("Unexpected responses: %+v", responses)
+	}
+}
+
+func TestHTTPFacilitatorClientVerifyBatchEmpty(t *testing.T) {
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "http://127.0.0.1:0"})
+	responses, err := client.VerifyBatch(context.Background(), nil)
+	if err != nil || len(responses) != 0 {
+		t.Errorf("Expected empty result, got %v, %v", responses, err)
+	}
+}
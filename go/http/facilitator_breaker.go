@@ -0,0 +1,223 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Circuit Breaker
+// ============================================================================
+
+// ErrCircuitOpen is returned without contacting the facilitator while the circuit breaker
+// for the operation is open. Check for it with errors.Is.
+var ErrCircuitOpen = errors.New("facilitator circuit breaker is open")
+
+// Default breaker policies used for zero-valued CircuitBreakerConfig fields.
+// Settle trips sooner and waits longer before probing: a facilitator that is flapping
+// mid-settlement leaves payments in an uncertain state, which is costlier than a failed verify.
+var (
+	DefaultVerifyCircuitBreakerPolicy = CircuitBreakerPolicy{
+		FailureThreshold: 5,
+		Window:           30 * time.Second,
+		Cooldown:         10 * time.Second,
+	}
+	DefaultSettleCircuitBreakerPolicy = CircuitBreakerPolicy{
+		FailureThreshold: 3,
+		Window:           60 * time.Second,
+		Cooldown:         30 * time.Second,
+	}
+)
+
+// CircuitBreakerPolicy configures when a breaker trips and how long it stays open.
+//
+// A failure is a transport error or a 5xx response after retries; facilitator business
+// errors (4xx, non-zero codes) and calls canceled by the caller do not count.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+
+	// Window is how long a run of consecutive failures may span; a failure after the
+	// window starts a new run (zero means no limit)
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before a single probe request is let through
+	Cooldown time.Duration
+}
+
+// CircuitBreakerConfig enables per-operation circuit breakers on HTTPFacilitatorClient.
+// Zero-valued policies fall back to DefaultVerifyCircuitBreakerPolicy and
+// DefaultSettleCircuitBreakerPolicy. GetSupported uses the Verify policy with its own breaker.
+type CircuitBreakerConfig struct {
+	Verify CircuitBreakerPolicy
+	Settle CircuitBreakerPolicy
+}
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast until the cooldown has passed
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through to decide whether to close again
+	CircuitHalfOpen
+)
+
+// String returns the state name
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreakers holds one breaker per facilitator operation
+type circuitBreakers struct {
+	verify    *circuitBreaker
+	settle    *circuitBreaker
+	supported *circuitBreaker
+}
+
+// newCircuitBreakers builds the breakers for a config; nil config disables them
+func newCircuitBreakers(config *CircuitBreakerConfig) *circuitBreakers {
+	if config == nil {
+		return nil
+	}
+	verify := policyOrDefault(config.Verify, DefaultVerifyCircuitBreakerPolicy)
+	return &circuitBreakers{
+		verify:    newCircuitBreaker(verify),
+		settle:    newCircuitBreaker(policyOrDefault(config.Settle, DefaultSettleCircuitBreakerPolicy)),
+		supported: newCircuitBreaker(verify),
+	}
+}
+
+// forOperation returns the breaker for an operation, or nil when breakers are disabled
+func (b *circuitBreakers) forOperation(op facilitatorOperation) *circuitBreaker {
+	if b == nil {
+		return nil
+	}
+	switch op {
+	case operationVerify:
+		return b.verify
+	case operationSettle:
+		return b.settle
+	default:
+		return b.supported
+	}
+}
+
+// policyOrDefault returns p, or fallback when p is the zero policy
+func policyOrDefault(p, fallback CircuitBreakerPolicy) CircuitBreakerPolicy {
+	if p == (CircuitBreakerPolicy{}) {
+		return fallback
+	}
+	return p
+}
+
+// circuitBreaker tracks consecutive failures of one operation
+type circuitBreaker struct {
+	policy CircuitBreakerPolicy
+	now    func() time.Time
+
+	mu           sync.Mutex
+	state        CircuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+func newCircuitBreaker(policy CircuitBreakerPolicy) *circuitBreaker {
+	return &circuitBreaker{policy: policy, now: time.Now}
+}
+
+// currentState returns the state, moving an open breaker whose cooldown has passed to half-open
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advanceLocked()
+	return b.state
+}
+
+// allow reports whether a request may be sent. A nil error in the half-open state
+// makes the caller the probe; it must report the outcome with record or release.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advanceLocked()
+	switch b.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record reports the outcome of an allowed request
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !failed {
+		b.state = CircuitClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	if b.state == CircuitHalfOpen {
+		b.tripLocked(now)
+		return
+	}
+
+	if b.failures == 0 || (b.policy.Window > 0 && now.Sub(b.firstFailure) > b.policy.Window) {
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+	if b.failures >= b.policy.FailureThreshold {
+		b.tripLocked(now)
+	}
+}
+
+// release ends an allowed request that produced no outcome (e.g. canceled by the caller)
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// advanceLocked moves an open breaker to half-open once the cooldown has passed. b.mu must be held.
+func (b *circuitBreaker) advanceLocked() {
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.policy.Cooldown)) {
+		b.state = CircuitHalfOpen
+		b.probing = false
+	}
+}
+
+// tripLocked opens the breaker. b.mu must be held.
+func (b *circuitBreaker) tripLocked(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.failures = 0
+	b.probing = false
+}
+
+// isBreakerFailure reports whether a request outcome counts against the breaker
+func isBreakerFailure(statusCode int, err error) bool {
+	return err != nil || statusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// breakerTestServer answers every request with the status held in status
+func breakerTestServer(t *testing.T, status *int32, requests *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		code := int(atomic.LoadInt32(status))
+		w.WriteHeader(code)
+		if code == http.StatusOK {
+			_, _ = w.Write([]byte(`{"code":0,"msg":"","data":{"isValid":true,"success":true}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":1,"msg":"unavailable"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newBreakerTestClient builds a client whose breakers share a fake clock
+func newBreakerTestClient(t *testing.T, url string, config *CircuitBreakerConfig) (*HTTPFacilitatorClient, *time.Time) {
+	t.Helper()
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: url, Signer: &recordingSigner{}, CircuitBreaker: config})
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+	client.breakers.verify.now = clock
+	client.breakers.settle.now = clock
+	client.breakers.supported.now = clock
+	return client, &now
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	status, requests := int32(http.StatusServiceUnavailable), int32(0)
+	server := breakerTestServer(t, &status, &requests)
+	client, now := newBreakerTestClient(t, server.URL, &CircuitBreakerConfig{
+		Verify: CircuitBreakerPolicy{FailureThreshold: 2, Window: time.Minute, Cooldown: 10 * time.Second},
+	})
+	payload, requirements := retryTestPayload(t)
+	breaker := client.breakers.verify
+
+	// closed -> open after FailureThreshold consecutive failures
+	for i := 0; i < 2; i++ {
+		if _, err := client.Verify(context.Background(), payload, requirements); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Attempt %d: expected facilitator error, got %v", i, err)
+		}
+	}
+	if state := breaker.currentState(); state != CircuitOpen {
+		t.Fatalf("Expected open after 2 failures, got %s", state)
+	}
+
+	// open: fail fast without contacting the facilitator
+	_, err := client.Verify(context.Background(), payload, requirements)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected no request while open, got %d requests", requests)
+	}
+
+	// open -> half-open after the cooldown; a failed probe reopens
+	*now = now.Add(10 * time.Second)
+	if state := breaker.currentState(); state != CircuitHalfOpen {
+		t.Fatalf("Expected half-open after cooldown, got %s", state)
+	}
+	if _, err := client.Verify(context.Background(), payload, requirements); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected probe to reach the facilitator, got %v", err)
+	}
+	if state := breaker.currentState(); state != CircuitOpen {
+		t.Fatalf("Expected failed probe to reopen, got %s", state)
+	}
+
+	// half-open -> closed after a successful probe
+	*now = now.Add(10 * time.Second)
+	atomic.StoreInt32(&status, http.StatusOK)
+	if _, err := client.Verify(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Expected successful probe, got %v", err)
+	}
+	if state := breaker.currentState(); state != CircuitClosed {
+		t.Fatalf("Expected closed after successful probe, got %s", state)
+	}
+	if requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", requests)
+	}
+
+	// Breakers are per operation
+	if state := client.breakers.settle.currentState(); state != CircuitClosed {
+		t.Errorf("Expected settle breaker untouched, got %s", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsSingleProbe(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 1, Cooldown: time.Second})
+	now := time.Unix(1700000000, 0)
+	breaker.now = func() time.Time { return now }
+
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	breaker.record(true)
+
+	now = now.Add(time.Second)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("Expected probe to be allowed, got %v", err)
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected second call during probe to fail fast, got %v", err)
+	}
+
+	// A probe that ends without an outcome lets the next call probe
+	breaker.release()
+	if err := breaker.allow(); err != nil {
+		t.Errorf("Expected new probe after release, got %v", err)
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 3, Window: time.Minute, Cooldown: time.Second})
+	now := time.Unix(1700000000, 0)
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(true)
+	breaker.record(true)
+	now = now.Add(2 * time.Minute)
+	breaker.record(true)
+	if state := breaker.currentState(); state != CircuitClosed {
+		t.Fatalf("Expected failures outside the window not to trip, got %s", state)
+	}
+
+	breaker.record(true)
+	breaker.record(false)
+	breaker.record(true)
+	breaker.record(true)
+	if state := breaker.currentState(); state != CircuitClosed {
+		t.Fatalf("Expected a success to reset the run, got %s", state)
+	}
+	breaker.record(true)
+	if state := breaker.currentState(); state != CircuitOpen {
+		t.Errorf("Expected open after 3 consecutive failures, got %s", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	status, requests := int32(http.StatusBadRequest), int32(0)
+	server := breakerTestServer(t, &status, &requests)
+	client, _ := newBreakerTestClient(t, server.URL, &CircuitBreakerConfig{
+		Settle: CircuitBreakerPolicy{FailureThreshold: 1, Cooldown: time.Minute},
+	})
+	payload, requirements := retryTestPayload(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Settle(context.Background(), payload, requirements); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Attempt %d: 4xx responses must not trip the breaker", i)
+		}
+	}
+
+	// A canceled call does not count either
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = client.Settle(ctx, payload, requirements)
+	if state := client.breakers.settle.currentState(); state != CircuitClosed {
+		t.Errorf("Expected closed, got %s", state)
+	}
+}
+
+func TestCircuitBreakerDefaults(t *testing.T) {
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "http://127.0.0.1:0"})
+	if client.breakers != nil {
+		t.Errorf("Expected breakers to be disabled by default")
+	}
+
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "http://127.0.0.1:0", CircuitBreaker: &CircuitBreakerConfig{}})
+	if client.breakers.verify.policy != DefaultVerifyCircuitBreakerPolicy {
+		t.Errorf("Expected default verify policy, got %+v", client.breakers.verify.policy)
+	}
+	if client.breakers.settle.policy != DefaultSettleCircuitBreakerPolicy {
+		t.Errorf("Expected default settle policy, got %+v", client.breakers.settle.policy)
+	}
+
+	verify, settle := DefaultVerifyCircuitBreakerPolicy, DefaultSettleCircuitBreakerPolicy
+	if settle.FailureThreshold >= verify.FailureThreshold || settle.Cooldown <= verify.Cooldown {
+		t.Errorf("Expected settle defaults to be more conservative than verify: %+v vs %+v", settle, verify)
+	}
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// GetSupported Caching
+// ============================================================================
+
+// CachingFacilitatorClient memoizes GetSupported responses of an inner facilitator client
+// Verify and Settle pass straight through. Concurrent GetSupported calls that miss the
+// cache share a single upstream request; failed responses are never cached.
+type CachingFacilitatorClient struct {
+	inner x402.FacilitatorClient
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	supported *x402.SupportedResponse
+	expiresAt time.Time
+	inflight  *supportedCall
+}
+
+// supportedCall is a GetSupported request shared by every caller waiting on it
+type supportedCall struct {
+	done     chan struct{}
+	response x402.SupportedResponse
+	err      error
+}
+
+// NewCachingFacilitatorClient wraps inner so GetSupported responses are reused for ttl.
+// A ttl of zero or less disables caching but still collapses concurrent calls into one.
+func NewCachingFacilitatorClient(inner x402.FacilitatorClient, ttl time.Duration) *CachingFacilitatorClient {
+	return &CachingFacilitatorClient{
+		inner: inner,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Verify passes through to the inner client
+func (c *CachingFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	return c.inner.Verify(ctx, payloadBytes, requirementsBytes)
+}
+
+// Settle passes through to the inner client
+func (c *CachingFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	return c.inner.Settle(ctx, payloadBytes, requirementsBytes)
+}
+
+// GetSupported returns the cached response while it is fresh, otherwise fetches it from the inner client
+func (c *CachingFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	c.mu.Lock()
+	if c.supported != nil && c.now().Before(c.expiresAt) {
+		supported := copySupportedResponse(*c.supported)
+		c.mu.Unlock()
+		return supported, nil
+	}
+	call := c.startFetchLocked(ctx)
+	c.mu.Unlock()
+
+	return waitForSupported(ctx, call)
+}
+
+// Refresh discards the cached response and fetches a new one from the inner client.
+// If a fetch is already in flight, Refresh waits for it instead of starting another.
+func (c *CachingFacilitatorClient) Refresh(ctx context.Context) (x402.SupportedResponse, error) {
+	c.mu.Lock()
+	c.supported = nil
+	call := c.startFetchLocked(ctx)
+	c.mu.Unlock()
+
+	return waitForSupported(ctx, call)
+}
+
+// Invalidate discards the cached response so the next GetSupported call fetches a new one
+func (c *CachingFacilitatorClient) Invalidate() {
+	c.mu.Lock()
+	c.supported = nil
+	c.mu.Unlock()
+}
+
+// startFetchLocked joins the in-flight fetch or starts a new one. c.mu must be held.
+func (c *CachingFacilitatorClient) startFetchLocked(ctx context.Context) *supportedCall {
+	if c.inflight != nil {
+		return c.inflight
+	}
+
+	call := &supportedCall{done: make(chan struct{})}
+	c.inflight = call
+
+	// The fetch is shared, so it must not be aborted when the caller that started it gives up.
+	fetchCtx := context.WithoutCancel(ctx)
+	go func() {
+		response, err := c.inner.GetSupported(fetchCtx)
+
+		c.mu.Lock()
+		call.response, call.err = response, err
+		if err == nil && c.ttl > 0 {
+			c.supported = &response
+			c.expiresAt = c.now().Add(c.ttl)
+		}
+		c.inflight = nil
+		c.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	return call
+}
+
+// waitForSupported blocks until call completes or ctx is done
+func waitForSupported(ctx context.Context, call *supportedCall) (x402.SupportedResponse, error) {
+	select {
+	case <-call.done:
+		return copySupportedResponse(call.response), call.err
+	case <-ctx.Done():
+		return x402.SupportedResponse{}, ctx.Err()
+	}
+}
+
+// copySupportedResponse deep-copies response, so callers that modify their result do not
+// change the cached one
+func copySupportedResponse(response x402.SupportedResponse) x402.SupportedResponse {
+	if response.Kinds != nil {
+		kinds := make([]x402.SupportedKind, len(response.Kinds))
+		for i, kind := range response.Kinds {
+			if kind.Extra != nil {
+				kind.Extra = copyJSONValue(kind.Extra).(map[string]interface{})
+			}
+			kinds[i] = kind
+		}
+		response.Kinds = kinds
+	}
+	if response.Extensions != nil {
+		response.Extensions = append([]string(nil), response.Extensions...)
+	}
+	if response.Signers != nil {
+		signers := make(map[string][]string, len(response.Signers))
+		for family, addresses := range response.Signers {
+			signers[family] = append([]string(nil), addresses...)
+		}
+		response.Signers = signers
+	}
+	return response
+}
+
+// copyJSONValue deep-copies the objects and arrays of a decoded JSON value
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = copyJSONValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = copyJSONValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
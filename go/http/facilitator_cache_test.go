@@ -0,0 +1,220 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// countingSupportedClient counts GetSupported calls and blocks each one until release is closed
+func countingSupportedClient(calls *int32, release <-chan struct{}, err error) *mockMultiFacilitatorClient {
+	return &mockMultiFacilitatorClient{
+		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
+			n := atomic.AddInt32(calls, 1)
+			if release != nil {
+				<-release
+			}
+			if err != nil {
+				return x402.SupportedResponse{}, err
+			}
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{string(rune('a' + n - 1))},
+			}, nil
+		},
+		verifyFunc: func(ctx context.Context, p, r []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true}, nil
+		},
+		settleFunc: func(ctx context.Context, p, r []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: true}, nil
+		},
+	}
+}
+
+func TestCachingFacilitatorClientSingleFlight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	client := NewCachingFacilitatorClient(countingSupportedClient(&calls, release, nil), time.Minute)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			supported, err := client.GetSupported(context.Background())
+			if err == nil && len(supported.Kinds) != 1 {
+				err = errors.New("unexpected supported response")
+			}
+			errs <- err
+		}()
+	}
+
+	// Give every caller a chance to queue behind the in-flight request
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", got)
+	}
+
+	// Served from cache within the TTL
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected cached response, got %d upstream calls", got)
+	}
+}
+
+func TestCachingFacilitatorClientExpiry(t *testing.T) {
+	var calls int32
+	client := NewCachingFacilitatorClient(countingSupportedClient(&calls, nil, nil), time.Minute)
+
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	first, _ := client.GetSupported(context.Background())
+	now = now.Add(30 * time.Second)
+	second, _ := client.GetSupported(context.Background())
+	if atomic.LoadInt32(&calls) != 1 || second.Extensions[0] != first.Extensions[0] {
+		t.Fatalf("Expected cached response before TTL, got %d calls", calls)
+	}
+
+	now = now.Add(31 * time.Second)
+	third, _ := client.GetSupported(context.Background())
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected refetch after TTL, got %d calls", calls)
+	}
+	if third.Extensions[0] == first.Extensions[0] {
+		t.Error("Expected fresh response after TTL")
+	}
+}
+
+func TestCachingFacilitatorClientRefresh(t *testing.T) {
+	var calls int32
+	client := NewCachingFacilitatorClient(countingSupportedClient(&calls, nil, nil), time.Hour)
+	ctx := context.Background()
+
+	_, _ = client.GetSupported(ctx)
+	refreshed, err := client.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected Refresh to bypass cache, got %d calls", calls)
+	}
+
+	cached, _ := client.GetSupported(ctx)
+	if cached.Extensions[0] != refreshed.Extensions[0] {
+		t.Error("Expected refreshed response to be cached")
+	}
+
+	client.Invalidate()
+	_, _ = client.GetSupported(ctx)
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("Expected Invalidate to force a refetch, got %d calls", calls)
+	}
+}
+
+func TestCachingFacilitatorClientDoesNotCacheErrors(t *testing.T) {
+	var calls int32
+	upstreamErr := errors.New("facilitator down")
+	client := NewCachingFacilitatorClient(countingSupportedClient(&calls, nil, upstreamErr), time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetSupported(context.Background()); !errors.Is(err, upstreamErr) {
+			t.Fatalf("Expected upstream error, got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected errors not to be cached, got %d calls", got)
+	}
+}
+
+func TestCachingFacilitatorClientCallerCancellation(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	client := NewCachingFacilitatorClient(countingSupportedClient(&calls, release, nil), time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetSupported(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+
+	// The shared fetch keeps running and its result is cached for later callers
+	close(release)
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", got)
+	}
+}
+
+func TestCachingFacilitatorClientPassThrough(t *testing.T) {
+	var calls int32
+	client := NewCachingFacilitatorClient(countingSupportedClient(&calls, nil, nil), time.Hour)
+
+	verify, err := client.Verify(context.Background(), nil, nil)
+	if err != nil || !verify.IsValid {
+		t.Errorf("Expected Verify to pass through, got %v, %v", verify, err)
+	}
+	settle, err := client.Settle(context.Background(), nil, nil)
+	if err != nil || !settle.Success {
+		t.Errorf("Expected Settle to pass through, got %v, %v", settle, err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no GetSupported calls, got %d", calls)
+	}
+}
+
+func TestCachingFacilitatorClientReturnsCopies(t *testing.T) {
+	inner := &mockMultiFacilitatorClient{
+		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds: []x402.SupportedKind{{
+					X402Version: 2, Scheme: "exact", Network: "eip155:1",
+					Extra: map[string]interface{}{"tokens": []interface{}{map[string]interface{}{"name": "USDC"}}},
+				}},
+				Extensions: []string{"bazaar"},
+				Signers:    map[string][]string{"eip155:*": {"0xsigner"}},
+			}, nil
+		},
+	}
+	client := NewCachingFacilitatorClient(inner, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		supported, err := client.GetSupported(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		kind := supported.Kinds[0]
+		token := kind.Extra["tokens"].([]interface{})[0].(map[string]interface{})
+		if kind.Network != "eip155:1" || token["name"] != "USDC" || supported.Extensions[0] != "bazaar" || supported.Signers["eip155:*"][0] != "0xsigner" {
+			t.Fatalf("Call %d: expected the original response, got %+v", i, supported)
+		}
+
+		// Modify every part of the fetched (then the cached) response
+		supported.Kinds[0].Network = "eip155:8453"
+		token["name"] = "DAI"
+		kind.Extra["added"] = true
+		supported.Extensions[0] = "other"
+		supported.Signers["eip155:*"][0] = "0xother"
+		supported.Signers["solana:*"] = []string{"signer"}
+	}
+}
@@ -3,21 +3,19 @@ package http
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"strconv"
+	neturl "net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	x402 "github.com/gatechain/x402/go"
 	"github.com/gatechain/x402/go/types"
-	"github.com/google/uuid"
 )
 
 // ============================================================================
@@ -31,6 +29,82 @@ type HTTPFacilitatorClient struct {
 	httpClient   *http.Client
 	authProvider AuthProvider
 	identifier   string
+	signer       RequestSigner
+	verifier     ResponseVerifier
+	credentials  *GateWeb3Credentials
+	gatePaths    GateWeb3Paths
+	retryPolicy  RetryPolicy
+	timeouts     operationTimeouts
+	onRequest    RequestHook
+	onResponse   ResponseHook
+	injectHeader HeaderInjector
+	breakers     *circuitBreakers
+	codec        EnvelopeCodec
+
+	// idempotencyHeader carries the settle idempotency key
+	idempotencyHeader string
+
+	// validatePayments checks payloads and requirements locally before sending
+	validatePayments bool
+
+	// allowlist refuses payments on other networks and assets (nil when unrestricted)
+	allowlist *paymentAllowlist
+
+	// verifies collapses concurrent identical verifies (nil when DedupeVerify is off)
+	verifies *verifyGroup
+
+	// journal records settles by idempotency key (nil when unset)
+	journal SettleJournal
+
+	// limiter paces every attempt of every operation (nil when unlimited)
+	limiter *rate.Limiter
+
+	metrics MetricsCollector
+
+	// Clock offset applied to Gate Web3 signing timestamps
+	clock facilitatorClock
+
+	// Response body limit per attempt (negative when unlimited)
+	maxResponseBytes int64
+
+	// Structured logging (never nil); verboseLogging adds bodies and redacted headers
+	logger         Logger
+	verboseLogging bool
+
+	// Headers set on every request before signing (reserved signing headers removed)
+	defaultHeaders http.Header
+
+	// Ping deadline and the latency above which a successful ping is degraded
+	pingTimeout         time.Duration
+	pingDegradedLatency time.Duration
+
+	// Batch verify state
+	batchConcurrency int
+	batchUnsupported atomic.Bool
+
+	// Set once the facilitator rejected GetSupportedFiltered params
+	supportedFilterUnsupported atomic.Bool
+}
+
+// RequestHook is called with the facilitator action and the request body before it is sent
+type RequestHook func(action string, body []byte)
+
+// HeaderInjector adds headers to each outbound facilitator request, e.g. W3C trace context:
+//
+//	func(ctx context.Context, req *http.Request) {
+//		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+//	}
+type HeaderInjector func(ctx context.Context, req *http.Request)
+
+// ResponseHook is called with the facilitator action and the outcome of the HTTP exchange.
+// statusCode is 0 and body is empty when no response was received; err is the transport error, if any.
+type ResponseHook func(action string, statusCode int, body []byte, err error)
+
+// operationTimeouts holds the resolved per-operation deadlines (zero means no extra deadline)
+type operationTimeouts struct {
+	verify    time.Duration
+	settle    time.Duration
+	supported time.Duration
 }
 
 // AuthProvider generates authentication headers for facilitator requests
@@ -51,120 +125,191 @@ type FacilitatorConfig struct {
 	// URL is the base URL of the facilitator service
 	URL string
 
-	// HTTPClient is the HTTP client to use (optional)
+	// HTTPClient is the HTTP client to use (optional).
+	// When set, Transport, Proxy and PinnedCertSHA256 are ignored and its own Timeout applies.
 	HTTPClient *http.Client
 
-	// AuthProvider provides authentication headers (optional)
-	AuthProvider AuthProvider
+	// Transport is the round tripper of the client built when HTTPClient is nil
+	// (optional, defaults to http.DefaultTransport). Use it for mutual TLS.
+	Transport http.RoundTripper
 
-	// Timeout for requests (optional, defaults to 30s)
-	Timeout time.Duration
+	// Proxy selects the proxy for each request of the client built when HTTPClient is nil
+	// (optional, defaults to the environment as in http.ProxyFromEnvironment).
+	// Applied to a clone of Transport; ignored when Transport is not an *http.Transport.
+	Proxy func(*http.Request) (*neturl.URL, error)
 
-	// Identifier for this facilitator (optional)
-	Identifier string
-}
+	// PinnedCertSHA256 lists the SHA-256 fingerprints (hex, colons allowed) of the facilitator
+	// leaf certificates the client built when HTTPClient is nil accepts (optional, empty trusts
+	// any valid certificate). Other certificates fail the handshake with ErrCertificateNotPinned,
+	// so signing secrets are not sent through a MITM proxy. Applied to a clone of Transport;
+	// when Transport is not an *http.Transport, every request fails instead.
+	PinnedCertSHA256 []string
 
-// DefaultFacilitatorURL is the default public facilitator (Gate Web3 OpenAPI Testnet)
-// Matches the documentation in querydoc: https://openapi-test.gateweb3.cc/api/v1/x402
-const DefaultFacilitatorURL = "https://openapi-test.gateweb3.cc/api/v1/x402"
-
-// Gate Web3 signing path and logical target URIs (used for x-target-uri)
-const (
-	gateWeb3SigningPath          = "/api/v1/x402"
-	gateWeb3TargetURISupported   = "/v1/x402/supported"
-	gateWeb3TargetURIVerify      = "/v1/x402/verify"
-	gateWeb3TargetURISettle      = "/v1/x402/settle"
-	envGateWeb3APIKey            = "GATE_WEB3_API_KEY"
-	envGateWeb3APISecret         = "GATE_WEB3_API_SECRET"
-	envGateWeb3Passphrase        = "GATE_WEB3_PASSPHRASE"
-	envGateWeb3RealIP            = "GATE_WEB3_REAL_IP"
-	defaultGateWeb3ForwardedFor  = "127.0.0.1"
-	defaultGateWeb3Passphrase    = ""
-	defaultGateWeb3RequestIDPref = "req-"
-)
-
-type gateWeb3Credentials struct {
-	APIKey     string
-	APISecret  string
-	Passphrase string
-	RealIP     string
-}
-
-// loadGateWeb3Credentials loads AK/SK and related configuration for the default signing logic.
-// If both AK and SK are present, the Gate Web3 default signing is enabled.
-func loadGateWeb3Credentials() (*gateWeb3Credentials, bool) {
-	ak := strings.TrimSpace(os.Getenv(envGateWeb3APIKey))
-	sk := strings.TrimSpace(os.Getenv(envGateWeb3APISecret))
-	if ak == "" || sk == "" {
-		return nil, false
-	}
-
-	pass := os.Getenv(envGateWeb3Passphrase)
-	if pass == "" {
-		pass = defaultGateWeb3Passphrase
-	}
+	// AuthProvider provides authentication headers (optional)
+	AuthProvider AuthProvider
 
-	realIP := os.Getenv(envGateWeb3RealIP)
-	if realIP == "" {
-		realIP = defaultGateWeb3ForwardedFor
-	}
+	// Signer signs each outbound request (optional).
+	// When nil, requests are signed with GateWeb3Signer if Gate Web3 credentials
+	// are present in GateWeb3Credentials or the environment, and left unsigned otherwise.
+	Signer RequestSigner
 
-	return &gateWeb3Credentials{
-		APIKey:     ak,
-		APISecret:  sk,
-		Passphrase: pass,
-		RealIP:     realIP,
-	}, true
-}
+	// GateWeb3Credentials supplies Gate Web3 OpenAPI credentials (optional).
+	// Set fields take precedence over the GATE_WEB3_* environment variables;
+	// empty fields fall back to the environment. Ignored when Signer is set.
+	GateWeb3Credentials *GateWeb3Credentials
 
-// applyGateWeb3Signature signs the request using the same logic as web3api.sh and sets HTTP headers.
-// PREHASH = <timestamp><gateWeb3SigningPath><rawBody>
-// Signature = Base64(HMAC_SHA256(SK, PREHASH))
-// Additional headers: X-Api-Key, X-Timestamp, X-Signature, X-Passphrase, X-Request-Id, X-Forwarded-For, x-target-uri
-func applyGateWeb3Signature(req *http.Request, body []byte, targetURI string) {
-	creds, ok := loadGateWeb3Credentials()
-	if !ok {
-		// If credentials are not configured, fall back to any custom AuthProvider
-		return
-	}
+	// GateWeb3Paths overrides the Gate Web3 signing path and target URIs (optional).
+	// The target URIs are passed to any Signer; the signing path only applies to the
+	// GateWeb3Signer built from credentials (set GateWeb3Signer.SigningPath for a custom one).
+	GateWeb3Paths *GateWeb3Paths
 
-	timestamp := time.Now().UnixMilli()
-	prehash := fmt.Sprintf("%d%s%s", timestamp, gateWeb3SigningPath, string(body))
+	// Timeout for requests (optional, defaults to 30s)
+	// Used for any operation whose specific timeout below is zero.
+	Timeout time.Duration
 
-	mac := hmac.New(sha256.New, []byte(creds.APISecret))
-	_, _ = mac.Write([]byte(prehash))
-	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	// VerifyTimeout bounds each Verify call, including retries (optional, defaults to Timeout)
+	VerifyTimeout time.Duration
 
-	req.Header.Set("X-Api-Key", creds.APIKey)
-	req.Header.Set("X-Timestamp", strconv.FormatInt(timestamp, 10))
-	req.Header.Set("X-Signature", signature)
+	// SettleTimeout bounds each Settle call, including retries (optional, defaults to Timeout).
+	// Settlement usually waits for on-chain inclusion, so this is typically longer than VerifyTimeout.
+	SettleTimeout time.Duration
 
-	if creds.Passphrase != "" {
-		req.Header.Set("X-Passphrase", creds.Passphrase)
-	}
-	if creds.RealIP != "" {
-		req.Header.Set("X-Forwarded-For", creds.RealIP)
-	}
+	// SupportedTimeout bounds each GetSupported call, including retries (optional, defaults to Timeout)
+	SupportedTimeout time.Duration
 
-	// Request ID
-	req.Header.Set("X-Request-Id", uuid.NewString())
+	// Identifier for this facilitator (optional)
+	Identifier string
 
-	// x-target-uri: remove leading slash per gateway expectation
-	req.Header.Set("x-target-uri", strings.TrimPrefix(targetURI, "/"))
+	// RetryPolicy controls retries of transient failures (optional, defaults to no retries)
+	RetryPolicy RetryPolicy
+
+	// OnRequest is called once per Verify, Settle and GetSupported call before the request is sent (optional)
+	OnRequest RequestHook
+
+	// HeaderInjector is called for every attempt with the call's context and the outbound request
+	// (optional), so tracing propagators can add traceparent/tracestate. It runs before the request
+	// is signed: the Gate Web3 signature covers only the timestamp, signing path and body, so
+	// injected headers never invalidate it, and a custom Signer can sign them if it needs to.
+	HeaderInjector HeaderInjector
+
+	// OnResponse is called once per Verify, Settle and GetSupported call after the final attempt,
+	// including when the request failed (optional)
+	OnResponse ResponseHook
+
+	// VerifyBatchConcurrency bounds the concurrent Verify calls VerifyBatch makes when the
+	// facilitator does not accept batched verify requests (optional, defaults to 8)
+	VerifyBatchConcurrency int
+
+	// CircuitBreaker fails calls fast with ErrCircuitOpen after repeated facilitator
+	// failures (optional, nil disables the breakers)
+	CircuitBreaker *CircuitBreakerConfig
+
+	// Codec controls the request and response wire format (optional, defaults to GateEnvelopeCodec).
+	// Use RESTEnvelopeCodec for facilitators that expose plain /verify, /settle and /supported endpoints.
+	Codec EnvelopeCodec
+
+	// IdempotencyKeyHeader is the header that carries the settle idempotency key set with
+	// WithIdempotencyKey or requirements.Extra["idempotencyKey"] (optional, defaults to X-Idempotency-Key).
+	// The key is identical on every retry, whereas X-Request-Id changes per attempt.
+	IdempotencyKeyHeader string
+
+	// ValidatePayments checks the structure of payloads and requirements against the x402
+	// V1/V2 schemas before Verify, Settle and VerifyBatch send them (optional, defaults to off);
+	// requirements must also pass types.PaymentRequirements.ValidateAllowZero. Invalid input
+	// fails without a network call with a FacilitatorError of kind FacilitatorErrorInvalidRequest
+	// wrapping a *PaymentValidationError.
+	ValidatePayments bool
+
+	// AllowedNetworks lists the networks whose payments Verify, Settle and VerifyBatch relay
+	// (optional, empty allows any network). Networks are compared case-insensitively with the
+	// requirements' network, so list every form the merchants use (e.g. "eip155:8453").
+	AllowedNetworks []string
+
+	// AllowedAssets lists, per network, the token addresses whose payments are relayed
+	// (optional, empty allows any asset). Once set, networks without an entry are refused as
+	// well. Addresses are compared case-insensitively.
+	//
+	// Refused payments fail without a network call with a FacilitatorError of kind
+	// FacilitatorErrorInvalidRequest wrapping a *PaymentNotAllowedError, so a merchant
+	// cannot steer payments to an unexpected chain or token.
+	AllowedAssets map[string][]string
+
+	// DedupeVerify makes concurrent Verify calls with identical payload and requirements bytes
+	// share one upstream request and its result (optional, defaults to off), e.g. when a client
+	// retries the same payment header in parallel. Verifies sent to different URLs (see
+	// WithFacilitatorURL) are not shared. The shared request runs under the context of the first
+	// caller, without its cancellation but bounded by VerifyTimeout. Settle is never deduplicated.
+	DedupeVerify bool
+
+	// SettleJournal records each settle that has an idempotency key (optional, nil disables it).
+	// Settle returns the result completed under the key, without a request, so a caller that
+	// restarts between the facilitator settling and its own bookkeeping does not pay twice.
+	// Journal failures before sending fail the call with a FacilitatorError of kind
+	// FacilitatorErrorInvalidRequest. See MemorySettleJournal.
+	SettleJournal SettleJournal
+
+	// RequestsPerSecond caps outbound requests with a token bucket shared by Verify, Settle
+	// and GetSupported (optional, zero disables the limiter). Every attempt takes a token,
+	// retries included; a call waits for its token until its context or operation timeout expires.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests that may be sent at once before RequestsPerSecond
+	// pacing applies (optional, defaults to 1)
+	Burst int
+
+	// Metrics records the duration, final status and error class of every Verify, Settle
+	// and GetSupported call (optional)
+	Metrics MetricsCollector
+
+	// SyncClock learns the facilitator's clock offset from the Date header of each response and
+	// applies it to Gate Web3 signing timestamps, so a drifting local clock does not get
+	// signatures rejected as stale (optional, defaults to off). See SetClockOffset.
+	SyncClock bool
+
+	// MaxResponseBytes bounds the response body read for each attempt (optional, defaults to
+	// DefaultMaxResponseBytes; negative disables the limit). Larger bodies fail the call with
+	// a FacilitatorError wrapping ErrResponseTooLarge.
+	MaxResponseBytes int64
+
+	// Logger receives request start, retry and outcome logs tagged with the operation and
+	// Identifier (optional, defaults to discarding them). *slog.Logger can be used directly.
+	Logger Logger
+
+	// VerboseLogging adds request and response bodies and the request headers to the logs
+	// (optional, defaults to off). Signatures and passphrases (RedactedHeaders) are masked
+	// even then; bodies carry signed payment payloads, so enable it only for debugging.
+	VerboseLogging bool
+
+	// UserAgent is sent as the User-Agent of every request (optional, defaults to Go's).
+	// It takes precedence over a User-Agent in DefaultHeaders.
+	UserAgent string
+
+	// DefaultHeaders are set on every request (optional). They are applied before
+	// HeaderInjector and signing; the Gate Web3 signature covers only the timestamp,
+	// signing path and body, so they are not signed. Content-Type, the Gate Web3 signing
+	// headers (X-Api-Key, X-Timestamp, X-Signature, X-Passphrase, X-Request-Id, x-target-uri)
+	// and the idempotency key header are reserved and ignored here.
+	DefaultHeaders map[string]string
+
+	// ResponseVerifier authenticates response bodies before they are decoded (optional,
+	// defaults to trusting them), e.g. HMACResponseVerifier or Ed25519ResponseVerifier for
+	// facilitators that sign responses in X-Response-Signature. Successful responses must be
+	// signed; a missing or mismatched signature fails the call with a FacilitatorError
+	// wrapping ErrInvalidResponseSignature, so a tampering proxy cannot forge results.
+	ResponseVerifier ResponseVerifier
+
+	// PingTimeout bounds each Ping and CheckHealth call, independently of SupportedTimeout
+	// (optional, defaults to DefaultPingTimeout)
+	PingTimeout time.Duration
+
+	// PingDegradedLatency is the round trip above which a successful ping reports
+	// HealthDegraded (optional, defaults to DefaultPingDegradedLatency)
+	PingDegradedLatency time.Duration
 }
 
-// facilitatorAPIResponse is the standard envelope used by the facilitator API
-//
-//	{
-//	  "code": 0,
-//	  "msg":  "",
-//	  "data": { ... }
-//	}
-type facilitatorAPIResponse[T any] struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
-	Data T      `json:"data"`
-}
+// DefaultFacilitatorURL is the default public facilitator (Gate Web3 OpenAPI Testnet)
+// Matches the documentation in querydoc: https://openapi-test.gateweb3.cc/api/v1/x402
+const DefaultFacilitatorURL = "https://openapi-test.gateweb3.cc/api/v1/x402"
 
 // NewHTTPFacilitatorClient creates a new HTTP facilitator client
 func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient {
@@ -177,15 +322,16 @@ func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient
 		url = DefaultFacilitatorURL
 	}
 
+	// Deadlines are applied per operation through the request context, so a client
+	// built here carries no overall timeout of its own (it would cap SettleTimeout).
+	// A caller-supplied HTTPClient keeps its own Timeout; Timeout only applies when set.
+	timeout := config.Timeout
 	httpClient := config.HTTPClient
 	if httpClient == nil {
-		timeout := config.Timeout
 		if timeout == 0 {
 			timeout = 30 * time.Second
 		}
-		httpClient = &http.Client{
-			Timeout: timeout,
-		}
+		httpClient = &http.Client{Transport: buildTransport(config.Transport, config.Proxy, normalizeCertPins(config.PinnedCertSHA256))}
 	}
 
 	identifier := config.Identifier
@@ -193,11 +339,75 @@ func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient
 		identifier = url
 	}
 
+	var credentials *GateWeb3Credentials
+	if config.GateWeb3Credentials != nil {
+		creds := *config.GateWeb3Credentials
+		credentials = &creds
+	}
+
+	var gatePaths GateWeb3Paths
+	if config.GateWeb3Paths != nil {
+		gatePaths = *config.GateWeb3Paths
+	}
+
+	codec := config.Codec
+	if codec == nil {
+		codec = GateEnvelopeCodec{}
+	}
+
+	idempotencyHeader := config.IdempotencyKeyHeader
+	if idempotencyHeader == "" {
+		idempotencyHeader = DefaultIdempotencyKeyHeader
+	}
+
+	defaultHeaders := buildDefaultHeaders(config.DefaultHeaders, config.UserAgent, idempotencyHeader)
+
+	logger := config.Logger
+	if logger == nil {
+		logger = nopLogger{}
+	}
+
+	maxResponseBytes := config.MaxResponseBytes
+	if maxResponseBytes == 0 {
+		maxResponseBytes = DefaultMaxResponseBytes
+	}
+
 	return &HTTPFacilitatorClient{
 		url:          url,
 		httpClient:   httpClient,
 		authProvider: config.AuthProvider,
 		identifier:   identifier,
+		signer:       config.Signer,
+		verifier:     config.ResponseVerifier,
+		credentials:  credentials,
+		gatePaths:    gatePaths,
+		retryPolicy:  config.RetryPolicy,
+		timeouts: operationTimeouts{
+			verify:    durationOrDefault(config.VerifyTimeout, timeout),
+			settle:    durationOrDefault(config.SettleTimeout, timeout),
+			supported: durationOrDefault(config.SupportedTimeout, timeout),
+		},
+		onRequest:         config.OnRequest,
+		onResponse:        config.OnResponse,
+		injectHeader:      config.HeaderInjector,
+		breakers:          newCircuitBreakers(config.CircuitBreaker),
+		codec:             codec,
+		idempotencyHeader: idempotencyHeader,
+		batchConcurrency:  config.VerifyBatchConcurrency,
+		validatePayments:  config.ValidatePayments,
+		allowlist:         newPaymentAllowlist(config.AllowedNetworks, config.AllowedAssets),
+		verifies:          newVerifyGroup(config.DedupeVerify),
+		journal:           config.SettleJournal,
+		limiter:           newRateLimiter(config.RequestsPerSecond, config.Burst),
+		metrics:           config.Metrics,
+		clock:             facilitatorClock{fromDate: config.SyncClock},
+		maxResponseBytes:  maxResponseBytes,
+		defaultHeaders:    defaultHeaders,
+		logger:            logger,
+		verboseLogging:    config.VerboseLogging,
+
+		pingTimeout:         durationOrDefault(config.PingTimeout, DefaultPingTimeout),
+		pingDegradedLatency: durationOrDefault(config.PingDegradedLatency, DefaultPingDegradedLatency),
 	}
 }
 
@@ -206,18 +416,30 @@ func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient
 // ============================================================================
 
 // Verify checks if a payment is valid (supports both V1 and V2)
-func (c *HTTPFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+func (c *HTTPFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (_ *x402.VerifyResponse, err error) {
+	start := time.Now()
+	defer func() { c.recordCall(operationVerify, start, err) }()
+
 	// Detect version from bytes
 	version, err := types.DetectVersion(payloadBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect version: %w", err)
 	}
 
+	if c.verifies != nil {
+		url, timeout := c.baseURL(ctx), c.timeouts.forOperation(operationVerify)
+		return c.verifies.do(ctx, url, timeout, payloadBytes, requirementsBytes, func(ctx context.Context) (*x402.VerifyResponse, error) {
+			return c.verifyHTTP(ctx, version, payloadBytes, requirementsBytes)
+		})
+	}
 	return c.verifyHTTP(ctx, version, payloadBytes, requirementsBytes)
 }
 
 // Settle executes a payment (supports both V1 and V2)
-func (c *HTTPFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+func (c *HTTPFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (_ *x402.SettleResponse, err error) {
+	start := time.Now()
+	defer func() { c.recordCall(operationSettle, start, err) }()
+
 	// Detect version from bytes
 	version, err := types.DetectVersion(payloadBytes)
 	if err != nil {
@@ -228,228 +450,477 @@ func (c *HTTPFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte,
 }
 
 // GetSupported gets supported payment kinds (shared by both V1 and V2)
-func (c *HTTPFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
-	// OpenAPI style: POST to a single endpoint with action wrapper
-	requestBody := map[string]interface{}{
-		"action": "x402.supported",
-		"params": map[string]interface{}{},
-	}
+func (c *HTTPFacilitatorClient) GetSupported(ctx context.Context) (_ x402.SupportedResponse, err error) {
+	start := time.Now()
+	defer func() { c.recordCall(operationSupported, start, err) }()
 
-	body, err := json.Marshal(requestBody)
+	request, err := c.codec.EncodeRequest(envelopeRequest(operationSupported, nil))
 	if err != nil {
-		return x402.SupportedResponse{}, fmt.Errorf("failed to marshal supported request: %w", err)
+		return x402.SupportedResponse{}, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	statusCode, responseBody, err := c.doRequest(ctx, operationSupported, request)
 	if err != nil {
-		return x402.SupportedResponse{}, fmt.Errorf("failed to create supported request: %w", err)
+		return x402.SupportedResponse{}, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	return decodeSupportedResponse(c.codec, statusCode, responseBody)
+}
 
-	// Apply default web3api.sh-style signing
-	applyGateWeb3Signature(req, body, gateWeb3TargetURISupported)
+// ============================================================================
+// Internal HTTP Methods (shared by V1 and V2)
+// ============================================================================
 
-	// Apply additional custom auth headers (if provided), overriding defaults if needed
-	if c.authProvider != nil {
-		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
-		if err != nil {
-			return x402.SupportedResponse{}, fmt.Errorf("failed to get auth headers: %w", err)
-		}
-		for k, v := range authHeaders.Supported {
-			req.Header.Set(k, v)
-		}
+func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	request, err := c.encodePaymentRequest(operationVerify, version, payloadBytes, requirementsBytes)
+	if err != nil {
+		return nil, err
 	}
 
-	// Make request
-	resp, err := c.httpClient.Do(req)
+	statusCode, responseBody, err := c.doRequest(ctx, operationVerify, request)
 	if err != nil {
-		return x402.SupportedResponse{}, fmt.Errorf("supported request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return x402.SupportedResponse{}, fmt.Errorf("failed to read supported response body: %w", err)
+	return decodeVerifyResponse(c.codec, statusCode, responseBody)
+}
+
+func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	// The key rides on the context so every retry attempt sends the same value
+	key := settleIdempotencyKey(ctx, requirementsBytes)
+	if key != "" {
+		ctx = WithIdempotencyKey(ctx, key)
 	}
 
-	var apiResp facilitatorAPIResponse[x402.SupportedResponse]
-	if err := json.Unmarshal(responseBody, &apiResp); err != nil {
-		return x402.SupportedResponse{}, fmt.Errorf("failed to decode supported response (%d): %s", resp.StatusCode, string(responseBody))
+	// A settle completed before a restart is resumed even once its authorization has
+	// expired, so the journal is read before the request is prechecked
+	if recorded, ok, err := c.lookupJournaledSettle(ctx, key); err != nil || ok {
+		return recorded, err
 	}
 
-	// For non-200 or non-zero business code, return an error
-	if resp.StatusCode != http.StatusOK || apiResp.Code != 0 {
-		return x402.SupportedResponse{}, fmt.Errorf("facilitator supported failed (http=%d, code=%d, msg=%s)", resp.StatusCode, apiResp.Code, apiResp.Msg)
+	request, err := c.encodePaymentRequest(operationSettle, version, payloadBytes, requirementsBytes)
+	if err != nil {
+		return nil, err
 	}
 
-	return apiResp.Data, nil
-}
+	return c.journaledSettle(ctx, key, func() (*x402.SettleResponse, error) {
+		statusCode, responseBody, err := c.doRequest(ctx, operationSettle, request)
+		if err != nil {
+			return nil, err
+		}
 
-// ============================================================================
-// Internal HTTP Methods (shared by V1 and V2)
-// ============================================================================
+		response, err := decodeSettleResponse(c.codec, statusCode, responseBody)
+		if err != nil {
+			return nil, err
+		}
+		// A failed settle charged nothing, so only successful ones default to the requirements
+		if response.Success && response.SettledAmount == "" {
+			response.SettledAmount = requiredAmount(requirementsBytes)
+		}
+		return response, nil
+	})
+}
 
-func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.VerifyResponse, error) {
-	// Build request body
-	var payloadMap, requirementsMap map[string]interface{}
-	if err := json.Unmarshal(payloadBytes, &payloadMap); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+// requestSigner returns the configured signer, falling back to Gate Web3 credentials from config and environment.
+// The environment is read on every request so credentials can be rotated without rebuilding the client.
+func (c *HTTPFacilitatorClient) requestSigner() RequestSigner {
+	if c.signer != nil {
+		return c.signer
 	}
-	if err := json.Unmarshal(requirementsBytes, &requirementsMap); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal requirements: %w", err)
+	if signer, ok := NewGateWeb3Signer(c.credentials); ok {
+		signer.ClockOffset = c.ClockOffset()
+		signer.SigningPath = c.gatePaths.SigningPath
+		return signer
 	}
+	return nil
+}
 
-	params := map[string]interface{}{
-		"x402Version":         version,
-		"paymentPayload":      payloadMap,
-		"paymentRequirements": requirementsMap,
+// facilitatorOperation describes a single facilitator API action
+type facilitatorOperation struct {
+	name      string // Short name used in error messages ("verify", "settle", "supported")
+	action    string // OpenAPI action name placed in the request envelope
+	targetURI string // Logical target URI used for x-target-uri
+}
+
+var (
+	operationVerify    = facilitatorOperation{name: "verify", action: "x402.verify", targetURI: gateWeb3TargetURIVerify}
+	operationSettle    = facilitatorOperation{name: "settle", action: "x402.settle", targetURI: gateWeb3TargetURISettle}
+	operationSupported = facilitatorOperation{name: "supported", action: "x402.supported", targetURI: gateWeb3TargetURISupported}
+)
+
+// authHeadersFor returns the custom auth headers configured for an operation
+func (h AuthHeaders) authHeadersFor(op facilitatorOperation) map[string]string {
+	switch op {
+	case operationVerify:
+		return h.Verify
+	case operationSettle:
+		return h.Settle
+	default:
+		return h.Supported
 	}
+}
+
+// targetURIFor returns the configured target URI of an operation, or its default
+func (p GateWeb3Paths) targetURIFor(op facilitatorOperation) string {
+	var targetURI string
+	switch op {
+	case operationVerify:
+		targetURI = p.VerifyTargetURI
+	case operationSettle:
+		targetURI = p.SettleTargetURI
+	default:
+		targetURI = p.SupportedTargetURI
+	}
+	if targetURI == "" {
+		return op.targetURI
+	}
+	return targetURI
+}
 
-	// OpenAPI style: wrap in action/params envelope
-	requestBody := map[string]interface{}{
-		"action": "x402.verify",
-		"params": params,
+// forOperation returns the deadline configured for an operation
+func (t operationTimeouts) forOperation(op facilitatorOperation) time.Duration {
+	switch op {
+	case operationVerify:
+		return t.verify
+	case operationSettle:
+		return t.settle
+	default:
+		return t.supported
 	}
+}
 
-	body, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal verify request: %w", err)
+// buildTransport returns the round tripper for a client built from config: transport, or a
+// clone of it (or of http.DefaultTransport) with the proxy and certificate pins applied.
+// Nil keeps http.DefaultTransport. Pins on a transport that is not an *http.Transport fail
+// every request rather than connect unpinned.
+func buildTransport(transport http.RoundTripper, proxy func(*http.Request) (*neturl.URL, error), pins map[string]bool) http.RoundTripper {
+	if proxy == nil && len(pins) == 0 {
+		return transport
 	}
 
-	// Create request (single endpoint, action determines operation)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create verify request: %w", err)
+	base, ok := transport.(*http.Transport)
+	if transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		if len(pins) > 0 {
+			return failingTransport{err: errPinningUnsupported}
+		}
+		return transport
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	cloned := base.Clone()
+	if proxy != nil {
+		cloned.Proxy = proxy
+	}
+	if len(pins) > 0 {
+		pinCertificates(cloned, pins)
+	}
+	return cloned
+}
 
-	// Apply default web3api.sh-style signing
-	applyGateWeb3Signature(req, body, gateWeb3TargetURIVerify)
+// reservedRequestHeaders are set by the client and signer and cannot be supplied as DefaultHeaders
+var reservedRequestHeaders = []string{
+	"Content-Type", "X-Api-Key", "X-Timestamp", "X-Signature", "X-Passphrase", "X-Request-Id", "x-target-uri",
+}
 
-	// Apply additional custom auth headers (if provided), overriding defaults if needed
-	if c.authProvider != nil {
-		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get auth headers: %w", err)
-		}
-		for k, v := range authHeaders.Verify {
-			req.Header.Set(k, v)
+// buildDefaultHeaders canonicalizes DefaultHeaders and UserAgent, dropping reserved headers
+func buildDefaultHeaders(headers map[string]string, userAgent, idempotencyHeader string) http.Header {
+	reserved := map[string]bool{http.CanonicalHeaderKey(idempotencyHeader): true}
+	for _, name := range reservedRequestHeaders {
+		reserved[http.CanonicalHeaderKey(name)] = true
+	}
+
+	result := http.Header{}
+	for name, value := range headers {
+		if key := http.CanonicalHeaderKey(name); !reserved[key] {
+			result.Set(key, value)
 		}
 	}
+	if userAgent != "" {
+		result.Set("User-Agent", userAgent)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
 
-	// Make request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("verify request failed: %w", err)
+// durationOrDefault returns d, or fallback when d is zero
+func durationOrDefault(d, fallback time.Duration) time.Duration {
+	if d == 0 {
+		return fallback
 	}
-	defer resp.Body.Close()
+	return d
+}
 
-	responseBody, err := io.ReadAll(resp.Body)
+// encodePaymentRequest encodes a verify or settle call with the configured codec
+func (c *HTTPFacilitatorClient) encodePaymentRequest(op facilitatorOperation, version int, payloadBytes, requirementsBytes []byte) (EncodedRequest, error) {
+	params, err := c.paymentRequestParams(op, version, payloadBytes, requirementsBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return EncodedRequest{}, err
 	}
+	return c.codec.EncodeRequest(envelopeRequest(op, params))
+}
 
-	var apiResp facilitatorAPIResponse[x402.VerifyResponse]
-	if err := json.Unmarshal(responseBody, &apiResp); err != nil {
-		return nil, fmt.Errorf("facilitator verify failed (%d): %s", resp.StatusCode, string(responseBody))
+// paymentRequestParams checks the authorization's validity window and the allowlist, validates
+// the payment when ValidatePayments is set, then builds its params
+func (c *HTTPFacilitatorClient) paymentRequestParams(op facilitatorOperation, version int, payloadBytes, requirementsBytes []byte) (map[string]interface{}, error) {
+	if err := checkPayloadValidity(payloadBytes, time.Now().Add(c.ClockOffset())); err != nil {
+		return nil, newInvalidRequestError(op, err)
 	}
-
-	// For non-200 or non-zero business code, return an error with details
-	if resp.StatusCode != http.StatusOK || apiResp.Code != 0 {
-		if apiResp.Data.InvalidReason != "" {
-			return nil, x402.NewVerifyError(
-				apiResp.Data.InvalidReason,
-				apiResp.Data.Payer,
-				"",
-				fmt.Errorf("facilitator returned http=%d code=%d msg=%s", resp.StatusCode, apiResp.Code, apiResp.Msg),
-			)
+	if c.allowlist != nil {
+		if err := c.allowlist.check(requirementsBytes); err != nil {
+			return nil, newInvalidRequestError(op, err)
+		}
+	}
+	if c.validatePayments {
+		if err := validatePayment(version, payloadBytes, requirementsBytes); err != nil {
+			return nil, newInvalidRequestError(op, err)
 		}
-		return nil, fmt.Errorf("facilitator verify failed (http=%d, code=%d, msg=%s)", resp.StatusCode, apiResp.Code, apiResp.Msg)
 	}
+	return paymentRequestParams(version, payloadBytes, requirementsBytes)
+}
 
-	return &apiResp.Data, nil
+// checkPayloadValidity rejects an authorization that expired or is not valid yet at now, which
+// the facilitator would refuse anyway. Payloads it cannot read are left to the facilitator.
+func checkPayloadValidity(payloadBytes []byte, now time.Time) error {
+	var payload types.PaymentPayload
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return nil
+	}
+	return payload.CheckValidity(now)
 }
 
-func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.SettleResponse, error) {
-	// Build request body
-	var payloadMap, requirementsMap map[string]interface{}
-	if err := json.Unmarshal(payloadBytes, &payloadMap); err != nil {
+// envelopeRequest describes an operation call for the codec
+func envelopeRequest(op facilitatorOperation, params interface{}) EnvelopeRequest {
+	return EnvelopeRequest{Operation: op.name, Action: op.action, Params: params}
+}
+
+// requiredAmount returns the amount of V2 requirements, or maxAmountRequired of V1 ones
+func requiredAmount(requirementsBytes []byte) string {
+	var requirements struct {
+		Amount            string `json:"amount"`
+		MaxAmountRequired string `json:"maxAmountRequired"`
+	}
+	if err := json.Unmarshal(requirementsBytes, &requirements); err != nil {
+		return ""
+	}
+	if requirements.Amount != "" {
+		return requirements.Amount
+	}
+	return requirements.MaxAmountRequired
+}
+
+// paymentRequestParams builds the params object shared by verify and settle requests
+func paymentRequestParams(version int, payloadBytes, requirementsBytes []byte) (map[string]interface{}, error) {
+	payloadMap, err := decodeJSONObject(payloadBytes)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
-	if err := json.Unmarshal(requirementsBytes, &requirementsMap); err != nil {
+	requirementsMap, err := decodeJSONObject(requirementsBytes)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal requirements: %w", err)
 	}
 
-	params := map[string]interface{}{
+	return map[string]interface{}{
 		"x402Version":         version,
 		"paymentPayload":      payloadMap,
 		"paymentRequirements": requirementsMap,
+	}, nil
+}
+
+// doRequest sends a signed request for the operation and reports it to the configured hooks.
+// Returns the HTTP status code and the raw response body.
+func (c *HTTPFacilitatorClient) doRequest(ctx context.Context, op facilitatorOperation, request EncodedRequest) (int, []byte, error) {
+	breaker := c.breakers.forOperation(op)
+	if breaker != nil {
+		if err := breaker.allow(); err != nil {
+			return 0, nil, newUnavailableError(op, fmt.Errorf("%s request not sent: %w", op.name, err))
+		}
 	}
-	// OpenAPI style: wrap in action/params envelope
-	requestBody := map[string]interface{}{
-		"action": "x402.settle",
-		"params": params,
+
+	if c.onRequest != nil {
+		c.onRequest(op.action, request.Body)
 	}
+	c.logRequestStart(op, request.Body)
 
-	body, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal settle request: %w", err)
+	statusCode, responseBody, err := c.doRequestWithRetry(ctx, op, request)
+	c.logResponse(op, statusCode, responseBody)
+
+	if c.onResponse != nil {
+		hookBody := responseBody
+		if hookBody == nil {
+			hookBody = []byte{}
+		}
+		c.onResponse(op.action, statusCode, hookBody, err)
 	}
 
-	// Create request (single endpoint, action determines operation)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create settle request: %w", err)
+	if breaker != nil {
+		// A call the caller gave up on says nothing about facilitator health
+		if err != nil && ctx.Err() != nil {
+			breaker.release()
+		} else {
+			breaker.record(isBreakerFailure(statusCode, err))
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	return statusCode, responseBody, err
+}
 
-	// Apply default web3api.sh-style signing
-	applyGateWeb3Signature(req, body, gateWeb3TargetURISettle)
+// doRequestWithRetry sends the request, retrying according to the retry policy
+func (c *HTTPFacilitatorClient) doRequestWithRetry(ctx context.Context, op facilitatorOperation, request EncodedRequest) (int, []byte, error) {
+	// Derive the operation deadline from the caller's context; an earlier caller deadline still wins
+	if timeout := c.timeouts.forOperation(op); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	// Apply additional custom auth headers (if provided), overriding defaults if needed
-	if c.authProvider != nil {
-		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get auth headers: %w", err)
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForToken(ctx); err != nil {
+			return 0, nil, fmt.Errorf("%s request not sent: rate limit wait: %w", op.name, err)
 		}
-		for k, v := range authHeaders.Settle {
-			req.Header.Set(k, v)
+
+		statusCode, responseBody, err := c.sendRequest(ctx, op, request)
+		if !c.retryPolicy.shouldRetry(ctx, op, attempt, statusCode, err) {
+			return statusCode, responseBody, err
+		}
+
+		backoff := c.retryPolicy.backoff(attempt)
+		c.logRetry(op, attempt, statusCode, err, backoff)
+		if waitErr := waitForRetry(ctx, backoff); waitErr != nil {
+			if err == nil {
+				err = fmt.Errorf("facilitator %s returned http=%d", op.name, statusCode)
+			}
+			return 0, nil, fmt.Errorf("%s request aborted while retrying: %w (last error: %v)", op.name, waitErr, err)
 		}
 	}
+}
+
+// newRateLimiter returns the token bucket for RequestsPerSecond and Burst, or nil when unlimited
+func newRateLimiter(requestsPerSecond float64, burst int) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+}
+
+// waitForToken blocks until the rate limiter grants a request or ctx is done.
+// Unlike rate.Limiter.Wait it waits out the context instead of failing early when the
+// token would arrive after the deadline, so the error is always the context's.
+func (c *HTTPFacilitatorClient) waitForToken(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	reservation := c.limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if err := waitForRetry(ctx, delay); err != nil {
+		reservation.Cancel()
+		return err
+	}
+	return nil
+}
+
+// requestURL joins the facilitator URL for the call and a codec path
+func (c *HTTPFacilitatorClient) requestURL(ctx context.Context, path string) string {
+	url := c.baseURL(ctx)
+	if path == "" {
+		return url
+	}
+	return strings.TrimSuffix(url, "/") + path
+}
+
+// sendRequest performs a single attempt: builds, signs and sends the request, then reads the response body
+func (c *HTTPFacilitatorClient) sendRequest(ctx context.Context, op facilitatorOperation, request EncodedRequest) (int, []byte, error) {
+	req, err := c.newRequest(ctx, op, request)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c.logAttempt(op, req)
 
 	// Make request
+	sentAt := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("settle request failed: %w", err)
+		err = fmt.Errorf("%s request failed: %w", op.name, err)
+		if errors.Is(err, context.Canceled) {
+			return 0, nil, err
+		}
+		return 0, nil, newUnavailableError(op, err)
 	}
 	defer resp.Body.Close()
+	c.clock.observe(resp.Header.Get("Date"), sentAt, time.Now())
+
+	responseBody, err := readResponseBody(op, resp.StatusCode, resp.Body, c.maxResponseBytes)
+	if err != nil {
+		var facilitatorErr *FacilitatorError
+		if errors.As(err, &facilitatorErr) {
+			return resp.StatusCode, nil, err
+		}
+		return resp.StatusCode, nil, fmt.Errorf("failed to read %s response body: %w", op.name, err)
+	}
+	captureRawResponse(ctx, resp, responseBody)
+
+	if err := c.verifyResponse(op, resp, responseBody); err != nil {
+		return resp.StatusCode, nil, err
+	}
 
-	responseBody, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, responseBody, nil
+}
+
+// newRequest builds the HTTP request for one attempt, with the propagation, signing,
+// idempotency and auth headers applied. The signer is given exactly the body bytes that are
+// sent; nothing re-encodes the body after signing.
+func (c *HTTPFacilitatorClient) newRequest(ctx context.Context, op facilitatorOperation, request EncodedRequest) (*http.Request, error) {
+	body := request.Body
+	req, err := http.NewRequestWithContext(ctx, request.Method, c.requestURL(ctx, request.Path), bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to create %s request: %w", op.name, err)
+	}
+
+	for name, values := range c.defaultHeaders {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Inject propagation headers first so a signer may cover them
+	if c.injectHeader != nil {
+		c.injectHeader(ctx, req)
 	}
 
-	var apiResp facilitatorAPIResponse[x402.SettleResponse]
-	if err := json.Unmarshal(responseBody, &apiResp); err != nil {
-		return nil, fmt.Errorf("facilitator settle failed (%d): %s", resp.StatusCode, string(responseBody))
+	// Sign the request (defaults to web3api.sh-style signing when credentials are configured)
+	if signer := c.requestSigner(); signer != nil {
+		if err := signer.Sign(req, body, c.gatePaths.targetURIFor(op)); err != nil {
+			return nil, fmt.Errorf("failed to sign %s request: %w", op.name, err)
+		}
+	}
+
+	if op == operationSettle {
+		if key, ok := IdempotencyKeyFromContext(ctx); ok {
+			req.Header.Set(c.idempotencyHeader, key)
+		}
 	}
 
-	// For non-200 or non-zero business code, return an error with the details from the response
-	if resp.StatusCode != http.StatusOK || apiResp.Code != 0 {
-		if apiResp.Data.ErrorReason != "" {
-			return nil, x402.NewSettleError(
-				apiResp.Data.ErrorReason,
-				apiResp.Data.Payer,
-				apiResp.Data.Network,
-				apiResp.Data.Transaction,
-				fmt.Errorf("facilitator returned http=%d code=%d msg=%s", resp.StatusCode, apiResp.Code, apiResp.Msg),
-			)
+	// Apply additional custom auth headers (if provided), overriding defaults if needed
+	if c.authProvider != nil {
+		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get auth headers: %w", err)
+		}
+		for k, v := range authHeaders.authHeadersFor(op) {
+			req.Header.Set(k, v)
 		}
-		return nil, fmt.Errorf("facilitator settle failed (http=%d, code=%d, msg=%s)", resp.StatusCode, apiResp.Code, apiResp.Msg)
 	}
 
-	return &apiResp.Data, nil
+	return req, nil
 }
@@ -7,9 +7,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
 )
 
 // Test helper functions
@@ -179,7 +185,8 @@ func TestHTTPFacilitatorClientVerify(t *testing.T) {
 	defer server.Close()
 
 	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
-		URL: server.URL,
+		URL:   server.URL,
+		Codec: RESTEnvelopeCodec{},
 	})
 
 	requirements := x402.PaymentRequirements{
@@ -239,7 +246,8 @@ func TestHTTPFacilitatorClientSettle(t *testing.T) {
 	defer server.Close()
 
 	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
-		URL: server.URL,
+		URL:   server.URL,
+		Codec: RESTEnvelopeCodec{},
 	})
 
 	requirements := x402.PaymentRequirements{
@@ -276,6 +284,51 @@ func TestHTTPFacilitatorClientSettle(t *testing.T) {
 	}
 }
 
+func TestHTTPFacilitatorClientSettledAmount(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "reduced settled amount",
+			data: `{"success":true,"transaction":"0xsettledtx","network":"eip155:1","settledAmount":"250000"}`,
+			want: "250000",
+		},
+		{
+			name: "defaults to the required amount",
+			data: `{"success":true,"transaction":"0xsettledtx","network":"eip155:1"}`,
+			want: "1000000",
+		},
+		{
+			name: "failed settle charges nothing",
+			data: `{"success":false,"errorReason":"transaction_reverted","network":"eip155:1"}`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"code":0,"msg":"","data":` + tt.data + `}`))
+			}))
+			defer server.Close()
+
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+			payloadBytes, requirementsBytes := retryTestPayload(t)
+
+			response, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.SettledAmount != tt.want {
+				t.Errorf("Expected settled amount %s, got %s", tt.want, response.SettledAmount)
+			}
+		})
+	}
+}
+
 func TestHTTPFacilitatorClientGetSupported(t *testing.T) {
 	ctx := context.Background()
 
@@ -312,7 +365,8 @@ func TestHTTPFacilitatorClientGetSupported(t *testing.T) {
 	defer server.Close()
 
 	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
-		URL: server.URL,
+		URL:   server.URL,
+		Codec: RESTEnvelopeCodec{},
 	})
 
 	response, err := client.GetSupported(ctx)
@@ -356,6 +410,7 @@ func TestHTTPFacilitatorClientWithAuth(t *testing.T) {
 
 	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
 		URL:          server.URL,
+		Codec:        RESTEnvelopeCodec{},
 		AuthProvider: NewStaticAuthProvider("test-key"),
 	})
 
@@ -477,7 +532,8 @@ func TestHTTPFacilitatorClient400WithValidResponse(t *testing.T) {
 	defer server.Close()
 
 	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
-		URL: server.URL,
+		URL:   server.URL,
+		Codec: RESTEnvelopeCodec{},
 	})
 
 	requirements := x402.PaymentRequirements{
@@ -719,3 +775,487 @@ func (m *mockMultiFacilitatorClient) GetSupported(ctx context.Context) (x402.Sup
 func (m *mockMultiFacilitatorClient) Identifier() string {
 	return m.id
 }
+
+// slowFacilitatorServer delays every response before returning data in the OpenAPI envelope
+func slowFacilitatorServer(t *testing.T, delay time.Duration, data interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPFacilitatorClientPerOperationTimeouts(t *testing.T) {
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	t.Run("verify timeout expires", func(t *testing.T) {
+		server := slowFacilitatorServer(t, 200*time.Millisecond, x402.VerifyResponse{IsValid: true})
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:           server.URL,
+			VerifyTimeout: 20 * time.Millisecond,
+			SettleTimeout: 5 * time.Second,
+		})
+
+		_, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected verify to hit its deadline, got %v", err)
+		}
+	})
+
+	t.Run("settle timeout allows longer calls", func(t *testing.T) {
+		server := slowFacilitatorServer(t, 50*time.Millisecond, x402.SettleResponse{Success: true, Transaction: "0xtx"})
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:           server.URL,
+			Timeout:       10 * time.Millisecond,
+			SettleTimeout: 2 * time.Second,
+		})
+
+		resp, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+		if err != nil {
+			t.Fatalf("Expected settle to succeed within SettleTimeout, got %v", err)
+		}
+		if resp.Transaction != "0xtx" {
+			t.Errorf("Expected transaction 0xtx, got %s", resp.Transaction)
+		}
+
+		// GetSupported falls back to the shorter Timeout
+		if _, err := client.GetSupported(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected supported to fall back to Timeout, got %v", err)
+		}
+	})
+
+	t.Run("earlier caller deadline wins", func(t *testing.T) {
+		server := slowFacilitatorServer(t, 200*time.Millisecond, x402.SettleResponse{Success: true})
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:           server.URL,
+			SettleTimeout: 5 * time.Second,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.Settle(ctx, payloadBytes, requirementsBytes)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected caller deadline to be honored, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Errorf("Expected caller deadline to win, took %v", elapsed)
+		}
+	})
+}
+
+func TestHTTPFacilitatorClientTimeoutDefaults(t *testing.T) {
+	client := NewHTTPFacilitatorClient(nil)
+	want := operationTimeouts{verify: 30 * time.Second, settle: 30 * time.Second, supported: 30 * time.Second}
+	if client.timeouts != want {
+		t.Errorf("Expected default timeouts %+v, got %+v", want, client.timeouts)
+	}
+
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{Timeout: 5 * time.Second, SettleTimeout: time.Minute})
+	want = operationTimeouts{verify: 5 * time.Second, settle: time.Minute, supported: 5 * time.Second}
+	if client.timeouts != want {
+		t.Errorf("Expected timeouts %+v, got %+v", want, client.timeouts)
+	}
+
+	// A caller-supplied HTTP client keeps its own timeout unless one is configured explicitly
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{HTTPClient: &http.Client{Timeout: time.Second}})
+	if client.timeouts != (operationTimeouts{}) {
+		t.Errorf("Expected no extra deadlines with a custom HTTP client, got %+v", client.timeouts)
+	}
+}
+
+func TestHTTPFacilitatorClientHooks(t *testing.T) {
+	type hookCall struct {
+		action     string
+		statusCode int
+		body       []byte
+		err        error
+	}
+
+	newClient := func(url string, requests, responses *[]hookCall) *HTTPFacilitatorClient {
+		return NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL: url,
+			OnRequest: func(action string, body []byte) {
+				*requests = append(*requests, hookCall{action: action, body: body})
+			},
+			OnResponse: func(action string, statusCode int, body []byte, err error) {
+				*responses = append(*responses, hookCall{action: action, statusCode: statusCode, body: body, err: err})
+			},
+		})
+	}
+
+	t.Run("fires for each operation", func(t *testing.T) {
+		server := envelopeEchoServer(t, nil)
+		var requests, responses []hookCall
+		client := newClient(server.URL, &requests, &responses)
+
+		payloadBytes, requirementsBytes := retryTestPayload(t)
+		ctx := context.Background()
+		if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Settle failed: %v", err)
+		}
+		if _, err := client.GetSupported(ctx); err != nil {
+			t.Fatalf("GetSupported failed: %v", err)
+		}
+
+		expected := []string{"x402.verify", "x402.settle", "x402.supported"}
+		if len(requests) != len(expected) || len(responses) != len(expected) {
+			t.Fatalf("Expected %d request and response hooks, got %d and %d", len(expected), len(requests), len(responses))
+		}
+		for i, action := range expected {
+			if requests[i].action != action {
+				t.Errorf("OnRequest %d: expected action %s, got %s", i, action, requests[i].action)
+			}
+			if !json.Valid(requests[i].body) {
+				t.Errorf("OnRequest %d: expected JSON request body, got %s", i, requests[i].body)
+			}
+			if responses[i].action != action {
+				t.Errorf("OnResponse %d: expected action %s, got %s", i, action, responses[i].action)
+			}
+			if responses[i].statusCode != http.StatusOK || responses[i].err != nil {
+				t.Errorf("OnResponse %d: expected 200 without error, got %d, %v", i, responses[i].statusCode, responses[i].err)
+			}
+			if len(responses[i].body) == 0 {
+				t.Errorf("OnResponse %d: expected response body", i)
+			}
+		}
+	})
+
+	t.Run("fires when the request fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		var requests, responses []hookCall
+		client := newClient(url, &requests, &responses)
+
+		if _, err := client.GetSupported(context.Background()); err == nil {
+			t.Fatal("Expected error from closed server")
+		}
+		if len(requests) != 1 || len(responses) != 1 {
+			t.Fatalf("Expected one request and one response hook, got %d and %d", len(requests), len(responses))
+		}
+		if responses[0].action != "x402.supported" || responses[0].err == nil {
+			t.Errorf("Expected supported failure to be reported, got %+v", responses[0])
+		}
+		if responses[0].body == nil {
+			t.Error("Expected non-nil response body on failure")
+		}
+	})
+}
+
+func TestHTTPFacilitatorClientExposesEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var envelope struct {
+			Action string `json:"action"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&envelope)
+
+		var data interface{} = x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}
+		if envelope.Action == "x402.settle" {
+			data = x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "quota at 90%", "data": data})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	verifyResp, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if verifyResp.Envelope == nil || verifyResp.Envelope.Code != 0 || verifyResp.Envelope.Msg != "quota at 90%" {
+		t.Errorf("Expected verify envelope with facilitator message, got %+v", verifyResp.Envelope)
+	}
+
+	settleResp, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if settleResp.Envelope == nil || settleResp.Envelope.Msg != "quota at 90%" {
+		t.Errorf("Expected settle envelope with facilitator message, got %+v", settleResp.Envelope)
+	}
+
+	// The envelope must not leak into the PAYMENT-RESPONSE header encoding
+	encoded, _ := json.Marshal(settleResp)
+	if strings.Contains(string(encoded), "quota") {
+		t.Errorf("Expected envelope to be excluded from JSON, got %s", encoded)
+	}
+}
+
+// roundTripRecorder records each outbound request as the transport sees it, then delegates
+type roundTripRecorder struct {
+	requests  []*http.Request
+	deadlines []bool
+	next      http.RoundTripper
+}
+
+func (r *roundTripRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req.Clone(req.Context()))
+	_, hasDeadline := req.Context().Deadline()
+	r.deadlines = append(r.deadlines, hasDeadline)
+	return r.next.RoundTrip(req)
+}
+
+func TestHTTPFacilitatorClientCustomTransport(t *testing.T) {
+	server := envelopeEchoServer(t, nil)
+	recorder := &roundTripRecorder{next: http.DefaultTransport}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		Transport:           recorder,
+		Timeout:             5 * time.Second,
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if len(recorder.requests) != 1 {
+		t.Fatalf("Expected 1 request through the transport, got %d", len(recorder.requests))
+	}
+	// Signing happens before the request reaches the transport
+	req := recorder.requests[0]
+	if req.Header.Get("X-Api-Key") != "ak" || req.Header.Get("X-Signature") == "" {
+		t.Errorf("Expected signed request at the transport, got headers %v", req.Header)
+	}
+	// Timeout still applies with a custom transport
+	if !recorder.deadlines[0] {
+		t.Error("Expected the request context to carry the Timeout deadline")
+	}
+}
+
+func TestHTTPFacilitatorClientProxy(t *testing.T) {
+	server := envelopeEchoServer(t, nil)
+	var proxied []string
+	proxy := func(req *http.Request) (*neturl.URL, error) {
+		proxied = append(proxied, req.URL.String())
+		return nil, nil
+	}
+
+	// Applied to the default transport
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, Proxy: proxy})
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("GetSupported failed: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != server.URL {
+		t.Errorf("Expected proxy to be consulted for %s, got %v", server.URL, proxied)
+	}
+
+	// Applied to a clone of a custom *http.Transport, leaving the original untouched
+	transport := &http.Transport{}
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, Transport: transport, Proxy: proxy})
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("GetSupported failed: %v", err)
+	}
+	if len(proxied) != 2 {
+		t.Errorf("Expected proxy to be consulted with a custom transport, got %v", proxied)
+	}
+	if transport.Proxy != nil {
+		t.Error("Expected the caller's transport not to be modified")
+	}
+
+	// Ignored when a full HTTP client is supplied
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, HTTPClient: &http.Client{}, Proxy: proxy})
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("GetSupported failed: %v", err)
+	}
+	if len(proxied) != 2 {
+		t.Errorf("Expected Proxy to be ignored with HTTPClient, got %v", proxied)
+	}
+}
+
+type traceContextKey struct{}
+
+// headerCheckingSigner records the traceparent header present when Sign is called
+type headerCheckingSigner struct {
+	traceparents []string
+}
+
+func (s *headerCheckingSigner) Sign(req *http.Request, body []byte, targetURI string) error {
+	s.traceparents = append(s.traceparents, req.Header.Get("traceparent"))
+	return nil
+}
+
+func TestHTTPFacilitatorClientHeaderInjector(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var received []http.Header
+	server := envelopeEchoServer(t, func(r *http.Request, body []byte) {
+		received = append(received, r.Header.Clone())
+	})
+
+	inject := func(ctx context.Context, req *http.Request) {
+		if tp, ok := ctx.Value(traceContextKey{}).(string); ok {
+			req.Header.Set("traceparent", tp)
+			req.Header.Set("tracestate", "vendor=x402")
+		}
+	}
+	signer := &headerCheckingSigner{}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:            server.URL,
+		Signer:         signer,
+		HeaderInjector: inject,
+	})
+
+	ctx := context.WithValue(context.Background(), traceContextKey{}, traceparent)
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(received))
+	}
+	if got := received[0].Get("traceparent"); got != traceparent {
+		t.Errorf("Expected traceparent %s, got %q", traceparent, got)
+	}
+	if got := received[0].Get("tracestate"); got != "vendor=x402" {
+		t.Errorf("Expected tracestate vendor=x402, got %q", got)
+	}
+	if got := received[1].Get("traceparent"); got != "" {
+		t.Errorf("Expected no traceparent without trace context, got %q", got)
+	}
+
+	// Headers are injected before signing
+	if len(signer.traceparents) != 2 || signer.traceparents[0] != traceparent {
+		t.Errorf("Expected the signer to see the injected traceparent, got %v", signer.traceparents)
+	}
+}
+
+func TestHTTPFacilitatorClientUserAgentAndDefaultHeaders(t *testing.T) {
+	var mu sync.Mutex
+	headers := map[string]http.Header{}
+	server := envelopeEchoServer(t, func(r *http.Request, body []byte) {
+		var envelope struct {
+			Action string `json:"action"`
+		}
+		_ = json.Unmarshal(body, &envelope)
+		mu.Lock()
+		headers[envelope.Action] = r.Header.Clone()
+		mu.Unlock()
+	})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		UserAgent:           "merchant-app/1.2",
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+		DefaultHeaders: map[string]string{
+			"x-merchant-id": "m-42",
+			"User-Agent":    "overridden",
+			"X-Signature":   "forged",
+			"X-Api-Key":     "forged",
+			"Content-Type":  "text/plain",
+		},
+	})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	ctx := context.Background()
+
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if _, err := client.GetSupported(ctx); err != nil {
+		t.Fatalf("GetSupported failed: %v", err)
+	}
+
+	for _, action := range []string{"x402.verify", "x402.settle", "x402.supported"} {
+		h, ok := headers[action]
+		if !ok {
+			t.Errorf("%s: no request received", action)
+			continue
+		}
+		if got := h.Get("User-Agent"); got != "merchant-app/1.2" {
+			t.Errorf("%s: expected User-Agent merchant-app/1.2, got %q", action, got)
+		}
+		if got := h.Get("X-Merchant-Id"); got != "m-42" {
+			t.Errorf("%s: expected the default header, got %q", action, got)
+		}
+		if h.Get("X-Api-Key") != "ak" || h.Get("X-Signature") == "forged" {
+			t.Errorf("%s: expected default headers not to clobber signing headers, got %v", action, h)
+		}
+		if got := h.Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: expected JSON content type, got %q", action, got)
+		}
+	}
+}
+
+func TestHTTPFacilitatorClientRejectsOutOfWindowPayloads(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/settle") {
+			_ = json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtx"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Codec: RESTEnvelopeCodec{}})
+	requirements := []byte(`{"scheme":"exact","network":"eip155:10087","amount":"1000","payTo":"0xpayto"}`)
+	payload := func(validAfter, validBefore int64) []byte {
+		return []byte(fmt.Sprintf(`{"x402Version":2,"payload":{"authorization":{"validAfter":"%d","validBefore":"%d"}}}`, validAfter, validBefore))
+	}
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name    string
+		payload []byte
+		wantErr error
+	}{
+		{name: "in window", payload: payload(now-60, now+600)},
+		{name: "expired", payload: payload(now-600, now-60), wantErr: types.ErrAuthorizationExpired},
+		{name: "not yet valid", payload: payload(now+600, now+1200), wantErr: types.ErrAuthorizationNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			_, verifyErr := client.Verify(context.Background(), tt.payload, requirements)
+			_, settleErr := client.Settle(context.Background(), tt.payload, requirements)
+
+			for op, err := range map[string]error{"verify": verifyErr, "settle": settleErr} {
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Errorf("%s: unexpected error: %v", op, err)
+					}
+					continue
+				}
+				var facilitatorErr *FacilitatorError
+				if !errors.Is(err, tt.wantErr) || !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorInvalidRequest {
+					t.Errorf("%s: expected an invalid_request error wrapping %v, got %v", op, tt.wantErr, err)
+				}
+			}
+
+			wantRequests := int32(2)
+			if tt.wantErr != nil {
+				wantRequests = 0
+			}
+			if requests.Load() != wantRequests {
+				t.Errorf("Expected %d requests, got %d", wantRequests, requests.Load())
+			}
+		})
+	}
+}
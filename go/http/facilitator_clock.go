@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Clock Offset
+// ============================================================================
+
+// dateHeaderResolution is the precision of the HTTP Date header; smaller offsets are ignored
+const dateHeaderResolution = time.Second
+
+// facilitatorClock holds the offset between the facilitator's clock and the local clock
+type facilitatorClock struct {
+	fromDate bool // Learn the offset from response Date headers
+
+	mu     sync.Mutex
+	offset time.Duration // Added to the local clock
+	manual bool          // Set by SetClockOffset; stops Date header learning
+}
+
+// SetClockOffset sets the offset added to the local clock when the client signs requests
+// with the Gate Web3 signer it builds from credentials, and stops FacilitatorConfig.SyncClock
+// from changing it. A Signer set in FacilitatorConfig keeps its own timestamps.
+// An offset of zero restores signing with the local clock.
+func (c *HTTPFacilitatorClient) SetClockOffset(d time.Duration) {
+	c.clock.mu.Lock()
+	defer c.clock.mu.Unlock()
+	c.clock.manual = true
+	c.clock.offset = d
+}
+
+// ClockOffset returns the offset currently applied to Gate Web3 signing timestamps
+func (c *HTTPFacilitatorClient) ClockOffset() time.Duration {
+	c.clock.mu.Lock()
+	defer c.clock.mu.Unlock()
+	return c.clock.offset
+}
+
+// observe updates the offset from a response Date header when SyncClock is enabled
+//
+// The server time is taken as the middle of the second the header names, compared with
+// the middle of the request round trip. Offsets within the header's one-second resolution
+// are treated as no skew.
+func (k *facilitatorClock) observe(date string, sentAt, receivedAt time.Time) {
+	if !k.fromDate || date == "" {
+		return
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+
+	localTime := sentAt.Add(receivedAt.Sub(sentAt) / 2)
+	offset := serverTime.Add(dateHeaderResolution / 2).Sub(localTime)
+	if offset > -dateHeaderResolution && offset < dateHeaderResolution {
+		offset = 0
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.manual {
+		k.offset = offset
+	}
+}
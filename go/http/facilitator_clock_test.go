@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// signedTimestamp returns the X-Timestamp of req after checking that X-Signature covers it
+func signedTimestamp(t *testing.T, req *http.Request, secret string, body []byte) time.Time {
+	t.Helper()
+	timestamp := req.Header.Get("X-Timestamp")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp + gateWeb3SigningPath + string(body)))
+	if got, want := req.Header.Get("X-Signature"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("Expected signature over timestamp %s, got %s", timestamp, got)
+	}
+
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("Invalid X-Timestamp %q: %v", timestamp, err)
+	}
+	return time.UnixMilli(ms)
+}
+
+// assertNear fails unless got is within a second of want
+func assertNear(t *testing.T, what string, got, want time.Time) {
+	t.Helper()
+	if d := got.Sub(want); d < -time.Second || d > time.Second {
+		t.Errorf("Expected %s near %v, got %v", what, want, got)
+	}
+}
+
+func TestGateWeb3SignerClockOffset(t *testing.T) {
+	body := []byte(`{"action":"x402.verify"}`)
+	for _, offset := range []time.Duration{0, 90 * time.Second, -time.Hour} {
+		signer := &GateWeb3Signer{APIKey: "ak", APISecret: "sk", ClockOffset: offset}
+		req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+		if err := signer.Sign(req, body, gateWeb3TargetURIVerify); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertNear(t, "signing timestamp", signedTimestamp(t, req, "sk", body), time.Now().Add(offset))
+	}
+}
+
+func TestHTTPFacilitatorClientSetClockOffset(t *testing.T) {
+	var mu sync.Mutex
+	var timestamps []time.Time
+	server := envelopeEchoServer(t, func(r *http.Request, body []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		timestamps = append(timestamps, signedTimestamp(t, r, "sk", body))
+	})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+	client.SetClockOffset(-2 * time.Minute)
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.SetClockOffset(0)
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(timestamps) != 2 {
+		t.Fatalf("Expected 2 signed requests, got %d", len(timestamps))
+	}
+	assertNear(t, "offset timestamp", timestamps[0], time.Now().Add(-2*time.Minute))
+	assertNear(t, "local timestamp", timestamps[1], time.Now())
+}
+
+func TestHTTPFacilitatorClientSyncClock(t *testing.T) {
+	serverAhead := time.Hour
+	var mu sync.Mutex
+	var timestamps []time.Time
+	server := envelopeEchoServer(t, nil)
+	echo := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms, _ := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		mu.Lock()
+		timestamps = append(timestamps, time.UnixMilli(ms))
+		mu.Unlock()
+		w.Header().Set("Date", time.Now().Add(serverAhead).UTC().Format(http.TimeFormat))
+		echo.ServeHTTP(w, r)
+	})
+
+	newClient := func(sync bool) *HTTPFacilitatorClient {
+		return NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:                 server.URL,
+			GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+			SyncClock:           sync,
+		})
+	}
+
+	t.Run("learns offset from Date header", func(t *testing.T) {
+		client := newClient(true)
+		for i := 0; i < 2; i++ {
+			if _, err := client.GetSupported(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if d := client.ClockOffset() - serverAhead; d < -time.Second || d > time.Second {
+			t.Errorf("Expected clock offset near %v, got %v", serverAhead, client.ClockOffset())
+		}
+		// The first request is signed before any response has been seen
+		assertNear(t, "first timestamp", timestamps[0], time.Now())
+		assertNear(t, "synced timestamp", timestamps[1], time.Now().Add(serverAhead))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := newClient(false)
+		if _, err := client.GetSupported(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if client.ClockOffset() != 0 {
+			t.Errorf("Expected no clock offset, got %v", client.ClockOffset())
+		}
+	})
+
+	t.Run("manual offset takes precedence", func(t *testing.T) {
+		client := newClient(true)
+		client.SetClockOffset(5 * time.Second)
+		if _, err := client.GetSupported(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if client.ClockOffset() != 5*time.Second {
+			t.Errorf("Expected manual clock offset 5s, got %v", client.ClockOffset())
+		}
+	})
+}
+
+func TestFacilitatorClockObserve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		date string
+		want time.Duration
+	}{
+		{name: "server ahead", date: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30*time.Second + 500*time.Millisecond},
+		{name: "server behind", date: now.Add(-10 * time.Second).Format(http.TimeFormat), want: -10*time.Second + 500*time.Millisecond},
+		{name: "within header resolution", date: now.Format(http.TimeFormat), want: 0},
+		{name: "unparseable date keeps offset", date: "yesterday", want: time.Minute},
+		{name: "missing date keeps offset", date: "", want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &facilitatorClock{fromDate: true, offset: time.Minute}
+			clock.observe(tt.date, now.Add(-100*time.Millisecond), now.Add(100*time.Millisecond))
+			if clock.offset != tt.want {
+				t.Errorf("Expected offset %v, got %v", tt.want, clock.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Envelope Codecs
+// ============================================================================
+
+// EnvelopeRequest is a facilitator call for an EnvelopeCodec to encode
+type EnvelopeRequest struct {
+	// Operation is "verify", "settle" or "supported"
+	Operation string
+
+	// Action is the Gate OpenAPI action name ("x402.verify", "x402.settle", "x402.supported")
+	Action string
+
+	// Params is the request payload: {x402Version, paymentPayload, paymentRequirements} for
+	// verify and settle (an array of them for batched verify), nil for supported
+	Params interface{}
+}
+
+// EncodedRequest is the HTTP request an EnvelopeCodec produced
+type EncodedRequest struct {
+	// Method is the HTTP method
+	Method string
+
+	// Path is appended to FacilitatorConfig.URL (empty to post to the URL itself)
+	Path string
+
+	// Body is the request body (may be empty)
+	Body []byte
+}
+
+// EnvelopeCodec controls how facilitator requests are encoded and responses decoded,
+// so the client can talk to facilitators with different wire formats
+type EnvelopeCodec interface {
+	// EncodeRequest builds the HTTP request for a facilitator call
+	EncodeRequest(req EnvelopeRequest) (EncodedRequest, error)
+
+	// DecodeResponse decodes the result of an operation into out and returns the business
+	// envelope, or nil for formats without one. A non-nil error means body could not be decoded.
+	DecodeResponse(operation string, body []byte, out interface{}) (*x402.FacilitatorEnvelope, error)
+}
+
+// GateEnvelopeCodec is the Gate Web3 OpenAPI format and the default codec: every call is a
+// POST of {"action", "params"} to the facilitator URL, answered with {"code", "msg", "data"}.
+// Request bodies are CanonicalJSON, the bytes the Gate Web3 signature covers.
+type GateEnvelopeCodec struct{}
+
+// EncodeRequest wraps the params in the action/params envelope
+func (GateEnvelopeCodec) EncodeRequest(req EnvelopeRequest) (EncodedRequest, error) {
+	params := req.Params
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	body, err := CanonicalJSON(map[string]interface{}{
+		"action": req.Action,
+		"params": params,
+	})
+	if err != nil {
+		return EncodedRequest{}, fmt.Errorf("failed to marshal %s request: %w", req.Operation, err)
+	}
+	return EncodedRequest{Method: http.MethodPost, Body: body}, nil
+}
+
+// DecodeResponse unwraps the code/msg/data envelope, decoding data into out
+func (GateEnvelopeCodec) DecodeResponse(operation string, body []byte, out interface{}) (*x402.FacilitatorEnvelope, error) {
+	var apiResp facilitatorAPIResponse[json.RawMessage]
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, err
+	}
+	if len(apiResp.Data) > 0 && !bytes.Equal(apiResp.Data, []byte("null")) {
+		if err := json.Unmarshal(apiResp.Data, out); err != nil {
+			return nil, err
+		}
+	}
+	return apiResp.envelope(), nil
+}
+
+// RESTEnvelopeCodec is the plain REST format of the reference x402 facilitator:
+// POST {URL}/verify and {URL}/settle with the params as a bare JSON body, GET {URL}/supported,
+// and bare JSON results. Success is signalled by the HTTP status alone.
+type RESTEnvelopeCodec struct{}
+
+// EncodeRequest sends the params as the request body on the operation's path
+func (RESTEnvelopeCodec) EncodeRequest(req EnvelopeRequest) (EncodedRequest, error) {
+	if req.Params == nil {
+		return EncodedRequest{Method: http.MethodGet, Path: "/" + req.Operation}, nil
+	}
+
+	body, err := CanonicalJSON(req.Params)
+	if err != nil {
+		return EncodedRequest{}, fmt.Errorf("failed to marshal %s request: %w", req.Operation, err)
+	}
+	return EncodedRequest{Method: http.MethodPost, Path: "/" + req.Operation, Body: body}, nil
+}
+
+// DecodeResponse decodes the bare JSON body into out
+func (RESTEnvelopeCodec) DecodeResponse(operation string, body []byte, out interface{}) (*x402.FacilitatorEnvelope, error) {
+	return nil, json.Unmarshal(body, out)
+}
+
+// facilitatorAPIResponse is the standard envelope used by the facilitator API
+//
+//	{
+//	  "code": 0,
+//	  "msg":  "",
+//	  "data": { ... }
+//	}
+type facilitatorAPIResponse[T any] struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data T      `json:"data"`
+}
+
+// envelope returns the business code and message of the response
+func (r facilitatorAPIResponse[T]) envelope() *x402.FacilitatorEnvelope {
+	return &x402.FacilitatorEnvelope{Code: r.Code, Msg: r.Msg}
+}
+
+// responseSucceeded reports whether a decoded response is a success: HTTP 200 and,
+// for enveloped formats, a zero business code
+func responseSucceeded(statusCode int, envelope *x402.FacilitatorEnvelope) bool {
+	return statusCode == http.StatusOK && (envelope == nil || envelope.Code == 0)
+}
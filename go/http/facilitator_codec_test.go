@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestGateEnvelopeCodecAgainstServer(t *testing.T) {
+	type seenRequest struct {
+		method, path, action string
+		params               json.RawMessage
+	}
+	var seen []seenRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var envelope struct {
+			Action string          `json:"action"`
+			Params json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&envelope)
+		seen = append(seen, seenRequest{r.Method, r.URL.Path, envelope.Action, envelope.Params})
+
+		var data interface{}
+		switch envelope.Action {
+		case "x402.verify":
+			data = x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}
+		case "x402.settle":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 4001,
+				"msg":  "settle rejected",
+				"data": x402.SettleResponse{ErrorReason: "insufficient_funds", Network: "eip155:1"},
+			})
+			return
+		default:
+			data = x402.SupportedResponse{Kinds: []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL + "/api/v1/x402", Signer: &recordingSigner{}})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	verifyResp, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !verifyResp.IsValid || verifyResp.Envelope == nil {
+		t.Errorf("Expected valid response with envelope, got %+v", verifyResp)
+	}
+
+	_, err = client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	var settleErr *x402.SettleError
+	if !errors.As(err, &settleErr) || settleErr.Reason != "insufficient_funds" {
+		t.Errorf("Expected SettleError from non-zero code, got %v", err)
+	}
+
+	supported, err := client.GetSupported(context.Background())
+	if err != nil || len(supported.Kinds) != 1 {
+		t.Fatalf("Expected 1 supported kind, got %+v, %v", supported, err)
+	}
+
+	if len(seen) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(seen))
+	}
+	for i, action := range []string{"x402.verify", "x402.settle", "x402.supported"} {
+		if seen[i].method != http.MethodPost || seen[i].path != "/api/v1/x402" || seen[i].action != action {
+			t.Errorf("Request %d: expected POST /api/v1/x402 %s, got %+v", i, action, seen[i])
+		}
+	}
+	if string(seen[2].params) != "{}" {
+		t.Errorf("Expected empty params for supported, got %s", seen[2].params)
+	}
+}
+
+func TestRESTEnvelopeCodecAgainstServer(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/facilitator/verify":
+			var params map[string]interface{}
+			_ = json.Unmarshal(body, &params)
+			if _, ok := params["action"]; ok {
+				t.Errorf("Expected bare REST body, got %s", body)
+			}
+			if params["paymentPayload"] == nil || params["paymentRequirements"] == nil {
+				t.Errorf("Expected payload and requirements in body, got %s", body)
+			}
+			_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true, Payer: "0xpayer"})
+		case "/facilitator/settle":
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(x402.SettleResponse{ErrorReason: "invalid_signature", Network: "eip155:1"})
+		case "/facilitator/supported":
+			if len(body) != 0 {
+				t.Errorf("Expected empty GET body, got %s", body)
+			}
+			_ = json.NewEncoder(w).Encode(x402.SupportedResponse{Kinds: []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:    server.URL + "/facilitator/",
+		Signer: &recordingSigner{},
+		Codec:  RESTEnvelopeCodec{},
+	})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	verifyResp, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !verifyResp.IsValid || verifyResp.Payer != "0xpayer" {
+		t.Errorf("Unexpected verify response: %+v", verifyResp)
+	}
+	if verifyResp.Envelope != nil {
+		t.Errorf("Expected no envelope for REST responses, got %+v", verifyResp.Envelope)
+	}
+
+	_, err = client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	var settleErr *x402.SettleError
+	if !errors.As(err, &settleErr) || settleErr.Reason != "invalid_signature" {
+		t.Errorf("Expected SettleError from 400 response, got %v", err)
+	}
+
+	supported, err := client.GetSupported(context.Background())
+	if err != nil || len(supported.Kinds) != 1 || supported.Kinds[0].Network != "eip155:8453" {
+		t.Errorf("Unexpected supported response: %+v, %v", supported, err)
+	}
+
+	want := []string{"POST /facilitator/verify", "POST /facilitator/settle", "GET /facilitator/supported"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("Request %d: expected %s, got %s", i, want[i], paths[i])
+		}
+	}
+}
+
+func TestRESTEnvelopeCodecVerifyBatchFallsBack(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var params map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"expected an object"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, Codec: RESTEnvelopeCodec{}})
+	responses, err := client.VerifyBatch(context.Background(), batchTestItems(t, 2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(responses) != 2 || !responses[0].IsValid || !responses[1].IsValid {
+		t.Errorf("Unexpected responses: %+v", responses)
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 3 {
+		t.Errorf("Expected 1 rejected batch and 2 single requests, got %d", requests)
+	}
+}
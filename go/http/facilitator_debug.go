@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// Request Builders (debugging)
+// ============================================================================
+
+// RedactedHeaders are the headers CurlCommand replaces with "REDACTED"
+var RedactedHeaders = []string{"Authorization", "X-Signature", "X-Passphrase"}
+
+// BuildVerifyRequest returns the signed request and body Verify would send for the payment,
+// without sending it. The request is built exactly as for the first attempt: same codec
+// envelope, URL (including WithFacilitatorURL), signing, propagation and auth headers.
+// Signatures carry a timestamp, so a dumped request is only accepted while it is fresh.
+func (c *HTTPFacilitatorClient) BuildVerifyRequest(ctx context.Context, payloadBytes, requirementsBytes []byte) (*http.Request, []byte, error) {
+	return c.buildPaymentRequest(ctx, operationVerify, payloadBytes, requirementsBytes)
+}
+
+// BuildSettleRequest is BuildVerifyRequest for Settle, including the idempotency key header
+func (c *HTTPFacilitatorClient) BuildSettleRequest(ctx context.Context, payloadBytes, requirementsBytes []byte) (*http.Request, []byte, error) {
+	if key := settleIdempotencyKey(ctx, requirementsBytes); key != "" {
+		ctx = WithIdempotencyKey(ctx, key)
+	}
+	return c.buildPaymentRequest(ctx, operationSettle, payloadBytes, requirementsBytes)
+}
+
+func (c *HTTPFacilitatorClient) buildPaymentRequest(ctx context.Context, op facilitatorOperation, payloadBytes, requirementsBytes []byte) (*http.Request, []byte, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect version: %w", err)
+	}
+
+	request, err := c.encodePaymentRequest(op, version, payloadBytes, requirementsBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := c.newRequest(ctx, op, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, request.Body, nil
+}
+
+// CurlCommand renders req and body as a curl command line, with RedactedHeaders masked.
+// Headers are sorted so the output is stable.
+func CurlCommand(req *http.Request, body []byte) string {
+	parts := []string{"curl", "-X", req.Method, shellQuote(req.URL.String())}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if isRedactedHeader(name) {
+				value = "REDACTED"
+			}
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		parts = append(parts, "--data-raw", shellQuote(string(body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func isRedactedHeader(name string) bool {
+	for _, redacted := range RedactedHeaders {
+		if strings.EqualFold(name, redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHTTPFacilitatorClientBuildVerifyRequest(t *testing.T) {
+	facilitator := newRecordingFacilitator(t)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 "https://facilitator.example",
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+	payload, requirements := retryTestPayload(t)
+
+	req, body, err := client.BuildVerifyRequest(context.Background(), payload, requirements)
+	if err != nil {
+		t.Fatalf("BuildVerifyRequest failed: %v", err)
+	}
+	if req.Method != http.MethodPost {
+		t.Errorf("Expected method POST, got %s", req.Method)
+	}
+	if got := req.URL.String(); got != "https://facilitator.example" {
+		t.Errorf("Expected the configured URL, got %s", got)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", got)
+	}
+	if req.Header.Get("X-Api-Key") != "ak" || req.Header.Get("X-Timestamp") == "" {
+		t.Errorf("Expected signing headers, got %v", req.Header)
+	}
+	if got := req.Header.Get("x-target-uri"); got != strings.TrimPrefix(gateWeb3TargetURIVerify, "/") {
+		t.Errorf("Expected verify target URI, got %q", got)
+	}
+	mac := hmac.New(sha256.New, []byte("sk"))
+	_, _ = mac.Write([]byte(req.Header.Get("X-Timestamp") + gateWeb3SigningPath + string(body)))
+	if got, want := req.Header.Get("X-Signature"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+		t.Error("Expected the signature to cover the returned body")
+	}
+	if sent, _ := io.ReadAll(req.Body); string(sent) != string(body) {
+		t.Error("Expected the request body to match the returned body")
+	}
+	if !strings.Contains(string(body), `"paymentPayload"`) {
+		t.Errorf("Expected the verify envelope, got %s", body)
+	}
+
+	// The built request is ready to send and honours the per-call URL
+	req, _, err = client.BuildVerifyRequest(WithFacilitatorURL(context.Background(), facilitator.URL), payload, requirements)
+	if err != nil {
+		t.Fatalf("BuildVerifyRequest with URL override failed: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Sending the built request failed: %v", err)
+	}
+	resp.Body.Close()
+	if got := len(facilitator.received()); got != 1 {
+		t.Errorf("Expected the built request to reach the override URL, got %d requests", got)
+	}
+
+	if _, _, err := client.BuildVerifyRequest(context.Background(), []byte("not json"), requirements); err == nil {
+		t.Error("Expected an error for an invalid payload")
+	}
+}
+
+func TestHTTPFacilitatorClientBuildSettleRequest(t *testing.T) {
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "https://facilitator.example"})
+	payload, requirements := retryTestPayload(t)
+
+	req, _, err := client.BuildSettleRequest(WithIdempotencyKey(context.Background(), "key-1"), payload, requirements)
+	if err != nil {
+		t.Fatalf("BuildSettleRequest failed: %v", err)
+	}
+	if got := req.Header.Get(DefaultIdempotencyKeyHeader); got != "key-1" {
+		t.Errorf("Expected the idempotency key header, got %q", got)
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://facilitator.example/verify", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", "ak")
+	req.Header.Set("X-Signature", "secret-signature")
+	req.Header.Set("X-Passphrase", "secret-pass")
+
+	got := CurlCommand(req, []byte(`{"note":"it's"}`))
+	want := `curl -X POST 'https://facilitator.example/verify' -H 'Content-Type: application/json' -H 'X-Api-Key: ak' ` +
+		`-H 'X-Passphrase: REDACTED' -H 'X-Signature: REDACTED' --data-raw '{"note":"it'\''s"}'`
+	if got != want {
+		t.Errorf("Unexpected curl command:\n got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Verify Deduplication
+// ============================================================================
+
+// verifyGroup collapses concurrent verifies of identical payload and requirements bytes,
+// sent to the same facilitator URL, into one upstream call, like CachingFacilitatorClient
+// does for GetSupported
+type verifyGroup struct {
+	mu    sync.Mutex
+	calls map[[sha256.Size]byte]*verifyCall
+}
+
+// verifyCall is a verify request shared by every caller waiting on it
+type verifyCall struct {
+	done     chan struct{}
+	response *x402.VerifyResponse
+	err      error
+}
+
+// newVerifyGroup returns a group, or nil when deduplication is disabled
+func newVerifyGroup(enabled bool) *verifyGroup {
+	if !enabled {
+		return nil
+	}
+	return &verifyGroup{calls: map[[sha256.Size]byte]*verifyCall{}}
+}
+
+// do joins the in-flight verify of the same payment at url or starts one with fn. The call
+// is forgotten once it completes, so later verifies of the same payment reach the facilitator.
+//
+// The call is shared, so it does not end when the caller that started it gives up; it keeps
+// that caller's context values and is bounded by timeout instead (zero for no deadline).
+func (g *verifyGroup) do(
+	ctx context.Context,
+	url string,
+	timeout time.Duration,
+	payloadBytes, requirementsBytes []byte,
+	fn func(ctx context.Context) (*x402.VerifyResponse, error),
+) (*x402.VerifyResponse, error) {
+	key := verifyKey(url, payloadBytes, requirementsBytes)
+
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		call = &verifyCall{done: make(chan struct{})}
+		g.calls[key] = call
+
+		callCtx, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+		if timeout > 0 {
+			callCtx, cancel = context.WithTimeout(callCtx, timeout)
+		}
+		go func() {
+			defer cancel()
+			response, err := fn(callCtx)
+
+			g.mu.Lock()
+			call.response, call.err = response, err
+			delete(g.calls, key)
+			g.mu.Unlock()
+
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.response == nil {
+			return nil, call.err
+		}
+		// Each caller gets its own copy to modify
+		response := *call.response
+		return &response, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// verifyKey hashes the URL, payload and requirements, length-prefixing the URL and payload
+// so the boundaries between them are unambiguous
+func verifyKey(url string, payloadBytes, requirementsBytes []byte) [sha256.Size]byte {
+	h := sha256.New()
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(url)))
+	h.Write(length[:])
+	h.Write([]byte(url))
+	binary.BigEndian.PutUint64(length[:], uint64(len(payloadBytes)))
+	h.Write(length[:])
+	h.Write(payloadBytes)
+	h.Write(requirementsBytes)
+
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestHTTPFacilitatorClientDedupeVerify(t *testing.T) {
+	var verifies, settles atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/settle") {
+			settles.Add(1)
+			_ = json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtx"})
+			return
+		}
+		verifies.Add(1)
+		<-release
+		_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true, Payer: "0xpayer"})
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"x402Version":2,"payload":{"signature":"0xsig"}}`)
+	requirements := []byte(`{"scheme":"exact","network":"eip155:1","amount":"1000","payTo":"0xpayto"}`)
+
+	// run calls fn from n goroutines at once, releasing the facilitator once all have started
+	run := func(n int, fn func() error) []error {
+		errs := make([]error, n)
+		var started, done sync.WaitGroup
+		started.Add(n)
+		done.Add(n)
+		for i := 0; i < n; i++ {
+			go func(i int) {
+				defer done.Done()
+				started.Done()
+				errs[i] = fn()
+			}(i)
+		}
+		started.Wait()
+		time.Sleep(50 * time.Millisecond)
+		release <- struct{}{}
+		done.Wait()
+		return errs
+	}
+
+	const n = 16
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Codec: RESTEnvelopeCodec{}, DedupeVerify: true})
+	responses := make([]*x402.VerifyResponse, n)
+	var next atomic.Int32
+	errs := run(n, func() error {
+		response, err := client.Verify(context.Background(), payload, requirements)
+		responses[next.Add(1)-1] = response
+		return err
+	})
+	for i, err := range errs {
+		if err != nil || responses[i] == nil || !responses[i].IsValid || responses[i].Payer != "0xpayer" {
+			t.Fatalf("Verify %d: unexpected result %+v, %v", i, responses[i], err)
+		}
+	}
+	if verifies.Load() != 1 {
+		t.Errorf("Expected 1 upstream verify, got %d", verifies.Load())
+	}
+	if responses[0] == responses[1] {
+		t.Error("Expected each caller to get its own response")
+	}
+
+	// The shared call is forgotten once it completes
+	go func() { release <- struct{}{} }()
+	if _, err := client.Verify(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if verifies.Load() != 2 {
+		t.Errorf("Expected a later verify to reach the facilitator, got %d requests", verifies.Load())
+	}
+
+	// Settles are never shared
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Settle(context.Background(), payload, requirements); err != nil {
+				t.Errorf("Settle failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if settles.Load() != n {
+		t.Errorf("Expected %d upstream settles, got %d", n, settles.Load())
+	}
+
+	// Distinct payments are verified separately
+	verifies.Store(0)
+	other := []byte(`{"scheme":"exact","network":"eip155:1","amount":"2000","payTo":"0xpayto"}`)
+	go func() { release <- struct{}{}; release <- struct{}{} }()
+	var pair sync.WaitGroup
+	for _, r := range [][]byte{requirements, other} {
+		pair.Add(1)
+		go func(r []byte) {
+			defer pair.Done()
+			_, _ = client.Verify(context.Background(), payload, r)
+		}(r)
+	}
+	pair.Wait()
+	if verifies.Load() != 2 {
+		t.Errorf("Expected 2 upstream verifies for distinct requirements, got %d", verifies.Load())
+	}
+}
+
+func TestHTTPFacilitatorClientDedupeVerifyCanceledWaiter(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Codec: RESTEnvelopeCodec{}, DedupeVerify: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Verify(ctx, []byte(`{"x402Version":2,"payload":{}}`), []byte(`{"scheme":"exact"}`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the waiter to give up with its context, got %v", err)
+	}
+}
+
+func TestHTTPFacilitatorClientDedupeVerifyPerURL(t *testing.T) {
+	release := make(chan struct{})
+	var requests atomic.Int32
+	newServer := func(payer string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			<-release
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true, Payer: payer})
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	primary, replica := newServer("0xprimary"), newServer("0xreplica")
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: primary.URL, Codec: RESTEnvelopeCodec{}, DedupeVerify: true})
+
+	payload := []byte(`{"x402Version":2,"payload":{"signature":"0xsig"}}`)
+	requirements := []byte(`{"scheme":"exact","network":"eip155:1","amount":"1000","payTo":"0xpayto"}`)
+
+	contexts := map[string]context.Context{
+		"0xprimary": context.Background(),
+		"0xreplica": WithFacilitatorURL(context.Background(), replica.URL),
+	}
+	var wg sync.WaitGroup
+	for want, ctx := range contexts {
+		wg.Add(1)
+		go func(want string, ctx context.Context) {
+			defer wg.Done()
+			response, err := client.Verify(ctx, payload, requirements)
+			if err != nil || response.Payer != want {
+				t.Errorf("Expected the answer of %s, got %+v, %v", want, response, err)
+			}
+		}(want, ctx)
+	}
+
+	// Both verifies reach their own facilitator before either is answered
+	deadline := time.Now().Add(5 * time.Second)
+	for requests.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 1 verify per facilitator, got %d requests", got)
+	}
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// Envelope Mapping (transport independent)
+// ============================================================================
+
+// The functions below map x402 calls to the Gate facilitator envelope, {"action", "params"}
+// requests answered with {"code", "msg", "data"}, without any HTTP specifics, so clients over
+// other transports (gRPC, message queues) can share the mapping with HTTPFacilitatorClient.
+// Signing, headers and retries remain the transport's concern.
+
+// BuildVerifyEnvelope returns the request envelope of a verify call for the payment
+func BuildVerifyEnvelope(payloadBytes, requirementsBytes []byte) ([]byte, error) {
+	return buildPaymentEnvelope(operationVerify, payloadBytes, requirementsBytes)
+}
+
+// BuildSettleEnvelope returns the request envelope of a settle call for the payment
+func BuildSettleEnvelope(payloadBytes, requirementsBytes []byte) ([]byte, error) {
+	return buildPaymentEnvelope(operationSettle, payloadBytes, requirementsBytes)
+}
+
+// BuildSupportedEnvelope returns the request envelope of a supported call
+func BuildSupportedEnvelope() ([]byte, error) {
+	request, err := GateEnvelopeCodec{}.EncodeRequest(envelopeRequest(operationSupported, nil))
+	if err != nil {
+		return nil, err
+	}
+	return request.Body, nil
+}
+
+// ParseVerifyEnvelope decodes a verify response envelope.
+//
+// Failures are reported as by HTTPFacilitatorClient.Verify: a *FacilitatorError for a non-zero
+// code or an undecodable body, wrapped in an *x402.VerifyError when the data carries an
+// invalid reason. The VerifyError's Data holds the decoded error data, including fields
+// beyond the response's. HTTPStatus is 200, since the transport delivered the response.
+func ParseVerifyEnvelope(body []byte) (*x402.VerifyResponse, error) {
+	return decodeVerifyResponse(GateEnvelopeCodec{}, http.StatusOK, body)
+}
+
+// ParseSettleEnvelope decodes a settle response envelope, reporting failures as ParseVerifyEnvelope does
+func ParseSettleEnvelope(body []byte) (*x402.SettleResponse, error) {
+	return decodeSettleResponse(GateEnvelopeCodec{}, http.StatusOK, body)
+}
+
+// ParseSupportedEnvelope decodes a supported response envelope, reporting failures as ParseVerifyEnvelope does
+func ParseSupportedEnvelope(body []byte) (x402.SupportedResponse, error) {
+	return decodeSupportedResponse(GateEnvelopeCodec{}, http.StatusOK, body)
+}
+
+func buildPaymentEnvelope(op facilitatorOperation, payloadBytes, requirementsBytes []byte) ([]byte, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect version: %w", err)
+	}
+	params, err := paymentRequestParams(version, payloadBytes, requirementsBytes)
+	if err != nil {
+		return nil, err
+	}
+	request, err := GateEnvelopeCodec{}.EncodeRequest(envelopeRequest(op, params))
+	if err != nil {
+		return nil, err
+	}
+	return request.Body, nil
+}
+
+// decodeVerifyResponse decodes a verify response with codec, returning a FacilitatorError for failures
+func decodeVerifyResponse(codec EnvelopeCodec, statusCode int, responseBody []byte) (*x402.VerifyResponse, error) {
+	var response x402.VerifyResponse
+	decoded := verifyResponseData{response: &response}
+	envelope, err := codec.DecodeResponse(operationVerify.name, responseBody, &decoded)
+	if err != nil {
+		return nil, newMalformedResponseError(operationVerify, statusCode, responseBody)
+	}
+
+	// For non-200 or non-zero business code, return an error with details
+	if !responseSucceeded(statusCode, envelope) {
+		if response.InvalidReason != "" {
+			verifyErr := x402.NewVerifyError(
+				response.InvalidReason,
+				response.Payer,
+				decoded.data.Network,
+				newResponseError(operationVerify, statusCode, envelope),
+			)
+			verifyErr.Data = decoded.data
+			return nil, verifyErr
+		}
+		return nil, newResponseError(operationVerify, statusCode, envelope)
+	}
+
+	response.Envelope = envelope
+	return &response, nil
+}
+
+// decodeSettleResponse decodes a settle response with codec, returning a FacilitatorError for failures
+func decodeSettleResponse(codec EnvelopeCodec, statusCode int, responseBody []byte) (*x402.SettleResponse, error) {
+	var response x402.SettleResponse
+	decoded := settleResponseData{response: &response}
+	envelope, err := codec.DecodeResponse(operationSettle.name, responseBody, &decoded)
+	if err != nil {
+		return nil, newMalformedResponseError(operationSettle, statusCode, responseBody)
+	}
+
+	// For non-200 or non-zero business code, return an error with the details from the response
+	if !responseSucceeded(statusCode, envelope) {
+		if response.ErrorReason != "" {
+			settleErr := x402.NewSettleError(
+				response.ErrorReason,
+				response.Payer,
+				response.Network,
+				response.Transaction,
+				newResponseError(operationSettle, statusCode, envelope),
+			)
+			settleErr.Data = decoded.data
+			return nil, settleErr
+		}
+		return nil, newResponseError(operationSettle, statusCode, envelope)
+	}
+
+	response.Envelope = envelope
+	return &response, nil
+}
+
+// verifyResponseData decodes verify data into response, keeping the error data alongside
+type verifyResponseData struct {
+	response *x402.VerifyResponse
+	data     *x402.VerifyErrorData
+}
+
+func (d *verifyResponseData) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, d.response); err != nil {
+		return err
+	}
+	// The error data only adds detail, so data that does not fit its types is not a malformed response
+	d.data = &x402.VerifyErrorData{}
+	if err := json.Unmarshal(data, d.data); err != nil {
+		d.data = &x402.VerifyErrorData{Raw: append(json.RawMessage(nil), data...)}
+	}
+	d.data.InvalidReason, d.data.Payer = d.response.InvalidReason, d.response.Payer
+	return nil
+}
+
+// settleResponseData decodes settle data into response, keeping the error data alongside
+type settleResponseData struct {
+	response *x402.SettleResponse
+	data     *x402.SettleErrorData
+}
+
+func (d *settleResponseData) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, d.response); err != nil {
+		return err
+	}
+	d.data = &x402.SettleErrorData{}
+	if err := json.Unmarshal(data, d.data); err != nil {
+		d.data = &x402.SettleErrorData{Raw: append(json.RawMessage(nil), data...)}
+	}
+	d.data.ErrorReason, d.data.Payer = d.response.ErrorReason, d.response.Payer
+	d.data.Network, d.data.Transaction = d.response.Network, d.response.Transaction
+	return nil
+}
+
+// decodeSupportedResponse decodes a supported response with codec, returning a FacilitatorError for failures
+func decodeSupportedResponse(codec EnvelopeCodec, statusCode int, responseBody []byte) (x402.SupportedResponse, error) {
+	var supported x402.SupportedResponse
+	envelope, err := codec.DecodeResponse(operationSupported.name, responseBody, &supported)
+	if err != nil {
+		return x402.SupportedResponse{}, newMalformedResponseError(operationSupported, statusCode, responseBody)
+	}
+
+	// For non-200 or non-zero business code, return an error
+	if !responseSucceeded(statusCode, envelope) {
+		return x402.SupportedResponse{}, newResponseError(operationSupported, statusCode, envelope)
+	}
+
+	return supported, nil
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// envelopeTransport stands in for a non-HTTP transport: it hands request envelopes to handle
+// and returns the response envelope it produces
+type envelopeTransport func(action string, params json.RawMessage) interface{}
+
+func (handle envelopeTransport) roundTrip(t *testing.T, request []byte) []byte {
+	t.Helper()
+	var envelope struct {
+		Action string          `json:"action"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(request, &envelope); err != nil {
+		t.Fatalf("Invalid request envelope %s: %v", request, err)
+	}
+	response, err := json.Marshal(handle(envelope.Action, envelope.Params))
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	return response
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	var seen []string
+	transport := envelopeTransport(func(action string, params json.RawMessage) interface{} {
+		seen = append(seen, action)
+		var data interface{}
+		switch action {
+		case "x402.verify", "x402.settle":
+			var p struct {
+				X402Version         int             `json:"x402Version"`
+				PaymentPayload      json.RawMessage `json:"paymentPayload"`
+				PaymentRequirements json.RawMessage `json:"paymentRequirements"`
+			}
+			if err := json.Unmarshal(params, &p); err != nil || p.X402Version != 2 || len(p.PaymentPayload) == 0 || len(p.PaymentRequirements) == 0 {
+				t.Errorf("Unexpected %s params: %s", action, params)
+			}
+			if action == "x402.verify" {
+				data = x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}
+			} else {
+				data = x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}
+			}
+		default:
+			if string(params) != "{}" {
+				t.Errorf("Expected empty supported params, got %s", params)
+			}
+			data = x402.SupportedResponse{Kinds: []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}}
+		}
+		return map[string]interface{}{"code": 0, "msg": "ok", "data": data}
+	})
+
+	request, err := BuildVerifyEnvelope(payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("BuildVerifyEnvelope failed: %v", err)
+	}
+	verifyResp, err := ParseVerifyEnvelope(transport.roundTrip(t, request))
+	if err != nil {
+		t.Fatalf("ParseVerifyEnvelope failed: %v", err)
+	}
+	if !verifyResp.IsValid || verifyResp.Payer != "0xpayer" || verifyResp.Envelope == nil || verifyResp.Envelope.Msg != "ok" {
+		t.Errorf("Unexpected verify response: %+v", verifyResp)
+	}
+
+	request, err = BuildSettleEnvelope(payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("BuildSettleEnvelope failed: %v", err)
+	}
+	settleResp, err := ParseSettleEnvelope(transport.roundTrip(t, request))
+	if err != nil {
+		t.Fatalf("ParseSettleEnvelope failed: %v", err)
+	}
+	if !settleResp.Success || settleResp.Transaction != "0xtx" || settleResp.Network != "eip155:1" {
+		t.Errorf("Unexpected settle response: %+v", settleResp)
+	}
+
+	request, err = BuildSupportedEnvelope()
+	if err != nil {
+		t.Fatalf("BuildSupportedEnvelope failed: %v", err)
+	}
+	supported, err := ParseSupportedEnvelope(transport.roundTrip(t, request))
+	if err != nil || len(supported.Kinds) != 1 || supported.Kinds[0].Scheme != "exact" {
+		t.Errorf("Unexpected supported response: %+v, %v", supported, err)
+	}
+
+	want := []string{"x402.verify", "x402.settle", "x402.supported"}
+	if len(seen) != len(want) {
+		t.Fatalf("Expected actions %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("Expected actions %v, got %v", want, seen)
+		}
+	}
+}
+
+func TestEnvelopeMatchesHTTPClient(t *testing.T) {
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "https://facilitator.example/api/v1/x402", Signer: &recordingSigner{}})
+
+	_, httpBody, err := client.BuildVerifyRequest(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("BuildVerifyRequest failed: %v", err)
+	}
+	envelope, err := BuildVerifyEnvelope(payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("BuildVerifyEnvelope failed: %v", err)
+	}
+	if string(envelope) != string(httpBody) {
+		t.Errorf("Expected the HTTP body %s, got %s", httpBody, envelope)
+	}
+}
+
+func TestParseEnvelopeErrors(t *testing.T) {
+	t.Run("verify rejection", func(t *testing.T) {
+		body := []byte(`{"code":4001,"msg":"invalid","data":{"isValid":false,"invalidReason":"invalid_signature","payer":"0xpayer"}}`)
+		_, err := ParseVerifyEnvelope(body)
+		var verifyErr *x402.VerifyError
+		if !errors.As(err, &verifyErr) || verifyErr.Reason != "invalid_signature" {
+			t.Errorf("Expected VerifyError, got %v", err)
+		}
+		var facilitatorErr *FacilitatorError
+		if !errors.As(err, &facilitatorErr) || facilitatorErr.BusinessCode != 4001 {
+			t.Errorf("Expected wrapped FacilitatorError with code 4001, got %v", err)
+		}
+	})
+
+	t.Run("settle rejection", func(t *testing.T) {
+		body := []byte(`{"code":4002,"msg":"rejected","data":{"success":false,"errorReason":"insufficient_funds","network":"eip155:1"}}`)
+		_, err := ParseSettleEnvelope(body)
+		var settleErr *x402.SettleError
+		if !errors.As(err, &settleErr) || settleErr.Reason != "insufficient_funds" {
+			t.Errorf("Expected SettleError, got %v", err)
+		}
+	})
+
+	t.Run("business error without data", func(t *testing.T) {
+		_, err := ParseSupportedEnvelope([]byte(`{"code":5000,"msg":"internal error"}`))
+		var facilitatorErr *FacilitatorError
+		if !errors.As(err, &facilitatorErr) || facilitatorErr.BusinessCode != 5000 || facilitatorErr.Msg != "internal error" {
+			t.Errorf("Expected FacilitatorError with code 5000, got %v", err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ParseVerifyEnvelope([]byte("not json"))
+		var facilitatorErr *FacilitatorError
+		if !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorMalformed {
+			t.Errorf("Expected malformed FacilitatorError, got %v", err)
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		if _, err := BuildSettleEnvelope([]byte("{"), []byte("{}")); err == nil {
+			t.Error("Expected error for an invalid payload")
+		}
+	})
+}
+
+func TestParseEnvelopeErrorData(t *testing.T) {
+	t.Run("verify", func(t *testing.T) {
+		body := []byte(`{"code":4001,"msg":"invalid","data":{"isValid":false,"invalidReason":"invalid_signature","invalidMessage":"signer does not match from","payer":"0xpayer","network":"eip155:10087","recovered":"0xother"}}`)
+		_, err := ParseVerifyEnvelope(body)
+		var verifyErr *x402.VerifyError
+		if !errors.As(err, &verifyErr) || verifyErr.Data == nil {
+			t.Fatalf("Expected VerifyError with data, got %v", err)
+		}
+		data := verifyErr.Data
+		if data.InvalidReason != "invalid_signature" || data.InvalidMessage != "signer does not match from" || data.Payer != "0xpayer" {
+			t.Errorf("Unexpected error data %+v", data)
+		}
+		if verifyErr.Network != "eip155:10087" {
+			t.Errorf("Expected network eip155:10087, got %q", verifyErr.Network)
+		}
+		var recovered string
+		if ok, err := data.Field("recovered", &recovered); !ok || err != nil || recovered != "0xother" {
+			t.Errorf("Expected recovered 0xother, got %q (%v, %v)", recovered, ok, err)
+		}
+		if ok, _ := data.Field("missing", &recovered); ok {
+			t.Error("Expected no missing field")
+		}
+	})
+
+	t.Run("settle", func(t *testing.T) {
+		body := []byte(`{"code":4002,"msg":"reverted","data":{"success":false,"errorReason":"transaction_failed","errorMessage":"execution reverted","payer":"0xpayer","transaction":"0xabc","network":"eip155:1","revertReason":"FiatTokenV2: invalid signature","gasUsed":51234}}`)
+		_, err := ParseSettleEnvelope(body)
+		var settleErr *x402.SettleError
+		if !errors.As(err, &settleErr) || settleErr.Data == nil {
+			t.Fatalf("Expected SettleError with data, got %v", err)
+		}
+		data := settleErr.Data
+		if data.ErrorReason != "transaction_failed" || data.ErrorMessage != "execution reverted" || data.Transaction != "0xabc" ||
+			data.Network != "eip155:1" || data.RevertReason != "FiatTokenV2: invalid signature" {
+			t.Errorf("Unexpected error data %+v", data)
+		}
+		if gas, ok := data.GasUsedUint64(); !ok || gas != 51234 {
+			t.Errorf("Expected gasUsed 51234, got %d (%v)", gas, ok)
+		}
+	})
+
+	t.Run("ill-typed detail", func(t *testing.T) {
+		// Detail fields of unexpected types leave the typed data empty, not the error malformed
+		body := []byte(`{"code":4002,"msg":"reverted","data":{"success":false,"errorReason":"transaction_failed","revertReason":{"selector":"0x08c379a0"}}}`)
+		_, err := ParseSettleEnvelope(body)
+		var settleErr *x402.SettleError
+		if !errors.As(err, &settleErr) || settleErr.Data == nil {
+			t.Fatalf("Expected SettleError with data, got %v", err)
+		}
+		if settleErr.Data.ErrorReason != "transaction_failed" || settleErr.Data.RevertReason != "" {
+			t.Errorf("Unexpected error data %+v", settleErr.Data)
+		}
+		var revert map[string]string
+		if ok, err := settleErr.Data.Field("revertReason", &revert); !ok || err != nil || revert["selector"] != "0x08c379a0" {
+			t.Errorf("Expected raw revertReason, got %v (%v, %v)", revert, ok, err)
+		}
+		if _, ok := settleErr.Data.GasUsedUint64(); ok {
+			t.Error("Expected no gasUsed")
+		}
+	})
+}
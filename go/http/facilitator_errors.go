@@ -0,0 +1,168 @@
+package http
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Facilitator Errors
+// ============================================================================
+
+// FacilitatorErrorKind classifies why a facilitator call failed
+type FacilitatorErrorKind int
+
+const (
+	// FacilitatorErrorOther is a failure that fits no other kind (e.g. a rejected request)
+	FacilitatorErrorOther FacilitatorErrorKind = iota
+	// FacilitatorErrorAuth means the facilitator rejected the credentials (bad AK/SK, expired signature)
+	FacilitatorErrorAuth
+	// FacilitatorErrorRateLimited means the facilitator is throttling this client
+	FacilitatorErrorRateLimited
+	// FacilitatorErrorUnavailable means the facilitator could not be reached or failed internally
+	FacilitatorErrorUnavailable
+	// FacilitatorErrorMalformed means the response could not be decoded
+	FacilitatorErrorMalformed
+	// FacilitatorErrorInvalidRequest means the request was rejected locally and never sent
+	FacilitatorErrorInvalidRequest
+)
+
+// String returns the kind name
+func (k FacilitatorErrorKind) String() string {
+	switch k {
+	case FacilitatorErrorAuth:
+		return "auth"
+	case FacilitatorErrorRateLimited:
+		return "rate_limited"
+	case FacilitatorErrorUnavailable:
+		return "unavailable"
+	case FacilitatorErrorMalformed:
+		return "malformed"
+	case FacilitatorErrorInvalidRequest:
+		return "invalid_request"
+	default:
+		return "other"
+	}
+}
+
+// FacilitatorError is returned by HTTPFacilitatorClient when a facilitator call fails.
+// Verify and settle rejections that carry a reason are returned as *x402.VerifyError and
+// *x402.SettleError wrapping a FacilitatorError, so errors.As finds either.
+type FacilitatorError struct {
+	Operation    string               // "verify", "settle" or "supported"
+	HTTPStatus   int                  // HTTP status code (0 when no response was received, 400 when rejected locally)
+	BusinessCode int                  // Envelope code (0 for formats without an envelope)
+	Msg          string               // Envelope message, or a preview of the body when it could not be decoded
+	Kind         FacilitatorErrorKind // Classification of the failure
+	Err          error                // Underlying error (transport failure, ErrCircuitOpen), if any
+}
+
+// Error implements the error interface
+func (e *FacilitatorError) Error() string {
+	switch {
+	case e.Err != nil:
+		return e.Err.Error()
+	case e.Kind == FacilitatorErrorMalformed:
+		return fmt.Sprintf("facilitator %s failed (%d): %s", e.Operation, e.HTTPStatus, e.Msg)
+	default:
+		return fmt.Sprintf("facilitator %s failed (http=%d, code=%d, msg=%s)", e.Operation, e.HTTPStatus, e.BusinessCode, e.Msg)
+	}
+}
+
+// Unwrap returns the underlying error (for errors.Is/As)
+func (e *FacilitatorError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the same call may succeed later without changes
+func (e *FacilitatorError) Retryable() bool {
+	return e.Kind == FacilitatorErrorRateLimited || e.Kind == FacilitatorErrorUnavailable
+}
+
+// newResponseError builds the error for a response that was not a success
+func newResponseError(op facilitatorOperation, statusCode int, envelope *x402.FacilitatorEnvelope) *FacilitatorError {
+	err := &FacilitatorError{Operation: op.name, HTTPStatus: statusCode}
+	if envelope != nil {
+		err.BusinessCode = envelope.Code
+		err.Msg = envelope.Msg
+	}
+	err.Kind = classifyFailure(statusCode, err.BusinessCode)
+	return err
+}
+
+// newMalformedResponseError builds the error for a response body that could not be decoded
+func newMalformedResponseError(op facilitatorOperation, statusCode int, body []byte) *FacilitatorError {
+	kind := FacilitatorErrorMalformed
+	// Gateways answer auth, throttling and outages with their own (often non-JSON) bodies
+	if statusCode != http.StatusOK {
+		if k := classifyFailure(statusCode, 0); k != FacilitatorErrorOther {
+			kind = k
+		}
+	}
+	return &FacilitatorError{Operation: op.name, HTTPStatus: statusCode, Msg: bodyPreview(body), Kind: kind}
+}
+
+// maxBodyPreview bounds the part of an undecodable response body kept in FacilitatorError.Msg
+const maxBodyPreview = 200
+
+var (
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// bodyPreview summarizes an undecodable response body: the title of an HTML error page (or
+// its text without markup), otherwise the body, with whitespace collapsed and truncated
+func bodyPreview(body []byte) string {
+	text := string(body)
+	if strings.HasPrefix(http.DetectContentType(body), "text/html") {
+		if match := htmlTitlePattern.FindStringSubmatch(text); match != nil && strings.TrimSpace(match[1]) != "" {
+			text = match[1]
+		} else {
+			text = htmlTagPattern.ReplaceAllString(text, " ")
+		}
+		text = html.UnescapeString(text)
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxBodyPreview {
+		cut := maxBodyPreview
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
+
+// newUnavailableError wraps a failure to exchange a request with the facilitator
+func newUnavailableError(op facilitatorOperation, err error) *FacilitatorError {
+	return &FacilitatorError{Operation: op.name, Kind: FacilitatorErrorUnavailable, Err: err}
+}
+
+// newInvalidRequestError wraps a request that failed local validation; HTTPStatus is 400
+// as the facilitator would have answered, although nothing was sent
+func newInvalidRequestError(op facilitatorOperation, err error) *FacilitatorError {
+	return &FacilitatorError{Operation: op.name, HTTPStatus: http.StatusBadRequest, Kind: FacilitatorErrorInvalidRequest, Err: err}
+}
+
+// classifyFailure maps an HTTP status and business code to a kind. The business code is
+// checked as well, for facilitators that report gateway failures with an HTTP-style code.
+func classifyFailure(statusCode, businessCode int) FacilitatorErrorKind {
+	for _, code := range []int{statusCode, businessCode} {
+		switch {
+		case code == http.StatusUnauthorized || code == http.StatusForbidden:
+			return FacilitatorErrorAuth
+		case code == http.StatusTooManyRequests:
+			return FacilitatorErrorRateLimited
+		case code >= http.StatusInternalServerError && code <= 599:
+			return FacilitatorErrorUnavailable
+		}
+	}
+	return FacilitatorErrorOther
+}
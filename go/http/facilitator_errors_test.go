@@ -0,0 +1,219 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestGetSupportedFacilitatorErrorClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantKind     FacilitatorErrorKind
+		wantCode     int
+		wantRetrying bool
+	}{
+		{"http 401", http.StatusUnauthorized, `{"code":10001,"msg":"invalid signature"}`, FacilitatorErrorAuth, 10001, false},
+		{"http 403 plain body", http.StatusForbidden, `Forbidden`, FacilitatorErrorAuth, 0, false},
+		{"business code 401", http.StatusOK, `{"code":401,"msg":"invalid api key"}`, FacilitatorErrorAuth, 401, false},
+		{"http 429", http.StatusTooManyRequests, `{"code":429,"msg":"slow down"}`, FacilitatorErrorRateLimited, 429, true},
+		{"http 503", http.StatusServiceUnavailable, `<html>down</html>`, FacilitatorErrorUnavailable, 0, true},
+		{"business code 502", http.StatusOK, `{"code":502,"msg":"upstream"}`, FacilitatorErrorUnavailable, 502, true},
+		{"malformed 200", http.StatusOK, `not json`, FacilitatorErrorMalformed, 0, false},
+		{"malformed 400", http.StatusBadRequest, `Bad request`, FacilitatorErrorMalformed, 0, false},
+		{"rejected", http.StatusBadRequest, `{"code":4000,"msg":"invalid params"}`, FacilitatorErrorOther, 4000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+			_, err := client.GetSupported(context.Background())
+
+			var facErr *FacilitatorError
+			if !errors.As(err, &facErr) {
+				t.Fatalf("Expected *FacilitatorError, got %T (%v)", err, err)
+			}
+			if facErr.Kind != tt.wantKind {
+				t.Errorf("Expected kind %s, got %s", tt.wantKind, facErr.Kind)
+			}
+			if facErr.HTTPStatus != tt.status || facErr.BusinessCode != tt.wantCode {
+				t.Errorf("Expected http=%d code=%d, got http=%d code=%d", tt.status, tt.wantCode, facErr.HTTPStatus, facErr.BusinessCode)
+			}
+			if facErr.Operation != "supported" {
+				t.Errorf("Expected operation supported, got %s", facErr.Operation)
+			}
+			if facErr.Retryable() != tt.wantRetrying {
+				t.Errorf("Expected Retryable()=%v", tt.wantRetrying)
+			}
+		})
+	}
+}
+
+func TestFacilitatorErrorTransportFailures(t *testing.T) {
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	// Nothing listening: the facilitator is unreachable
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: url, Signer: &recordingSigner{}})
+	_, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	var facErr *FacilitatorError
+	if !errors.As(err, &facErr) || facErr.Kind != FacilitatorErrorUnavailable || facErr.HTTPStatus != 0 {
+		t.Errorf("Expected unavailable FacilitatorError, got %v", err)
+	}
+
+	// An open circuit is reported as unavailable and still matches ErrCircuitOpen
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:            url,
+		Signer:         &recordingSigner{},
+		CircuitBreaker: &CircuitBreakerConfig{Settle: CircuitBreakerPolicy{FailureThreshold: 1, Cooldown: time.Hour}},
+	})
+	_, _ = client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	_, err = client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &facErr) || facErr.Kind != FacilitatorErrorUnavailable {
+		t.Errorf("Expected unavailable FacilitatorError wrapping ErrCircuitOpen, got %v", err)
+	}
+
+	// A call the caller canceled is not a facilitator failure
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{URL: url, Signer: &recordingSigner{}})
+	_, err = client.Verify(ctx, payloadBytes, requirementsBytes)
+	if err == nil || errors.As(err, &facErr) {
+		t.Errorf("Expected plain context error, got %v", err)
+	}
+}
+
+func TestVerifyAndSettleErrorsWrapFacilitatorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var envelope struct {
+			Action string `json:"action"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&envelope)
+
+		var data interface{} = x402.VerifyResponse{InvalidReason: "invalid_signature", Payer: "0xpayer"}
+		if envelope.Action == "x402.settle" {
+			data = x402.SettleResponse{ErrorReason: "insufficient_funds", Network: "eip155:1"}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 4001, "msg": "rejected", "data": data})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	_, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	var verifyErr *x402.VerifyError
+	var facErr *FacilitatorError
+	if !errors.As(err, &verifyErr) || verifyErr.Reason != "invalid_signature" {
+		t.Errorf("Expected VerifyError, got %v", err)
+	}
+	if !errors.As(err, &facErr) || facErr.Operation != "verify" || facErr.BusinessCode != 4001 || facErr.Msg != "rejected" {
+		t.Errorf("Expected wrapped FacilitatorError, got %+v", facErr)
+	}
+
+	_, err = client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	var settleErr *x402.SettleError
+	if !errors.As(err, &settleErr) || settleErr.Reason != "insufficient_funds" {
+		t.Errorf("Expected SettleError, got %v", err)
+	}
+	if !errors.As(err, &facErr) || facErr.Operation != "settle" || facErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("Expected wrapped FacilitatorError, got %+v", facErr)
+	}
+}
+
+func TestFacilitatorErrorHTMLErrorPage(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html>
+<head><title>502 Bad Gateway</title></head>
+<body>
+<center><h1>502 Bad Gateway</h1></center>
+<hr><center>nginx</center>
+` + strings.Repeat("<!-- padding to defeat error pages in IE -->\n", 20) + `</body>
+</html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	calls := map[string]func() error{
+		"verify": func() error {
+			_, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+			return err
+		},
+		"settle": func() error {
+			_, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+			return err
+		},
+		"supported": func() error {
+			_, err := client.GetSupported(context.Background())
+			return err
+		},
+	}
+
+	for operation, call := range calls {
+		t.Run(operation, func(t *testing.T) {
+			err := call()
+			var facErr *FacilitatorError
+			if !errors.As(err, &facErr) {
+				t.Fatalf("Expected *FacilitatorError, got %T (%v)", err, err)
+			}
+			if facErr.HTTPStatus != http.StatusBadGateway || facErr.Kind != FacilitatorErrorUnavailable {
+				t.Errorf("Expected unavailable 502, got http=%d kind=%s", facErr.HTTPStatus, facErr.Kind)
+			}
+			if facErr.Msg != "502 Bad Gateway" {
+				t.Errorf("Expected the page title as message, got %q", facErr.Msg)
+			}
+			if want := "facilitator " + operation + " failed (http=502, code=0, msg=502 Bad Gateway)"; err.Error() != want {
+				t.Errorf("Expected error %q, got %q", want, err.Error())
+			}
+		})
+	}
+}
+
+func TestBodyPreview(t *testing.T) {
+	long := strings.Repeat("a", maxBodyPreview+50)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "plain text", body: "Bad request", want: "Bad request"},
+		{name: "whitespace collapsed", body: "upstream\n\n  timed   out\n", want: "upstream timed out"},
+		{name: "html title", body: "<html><head><title>Service &amp; Gateway Down</title></head><body>...</body></html>", want: "Service & Gateway Down"},
+		{name: "html without title", body: "<html><body><h1>Forbidden</h1><p>nope</p></body></html>", want: "Forbidden nope"},
+		{name: "truncated", body: long, want: long[:maxBodyPreview] + "..."},
+		{name: "truncated on rune boundary", body: strings.Repeat("a", maxBodyPreview-1) + "é", want: strings.Repeat("a", maxBodyPreview-1) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bodyPreview([]byte(tt.body)); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ============================================================================
+// Health Checks
+// ============================================================================
+
+// Defaults for Ping and CheckHealth
+const (
+	DefaultPingTimeout         = 5 * time.Second
+	DefaultPingDegradedLatency = time.Second
+)
+
+// HealthStatus is the outcome of a facilitator health check
+type HealthStatus string
+
+const (
+	// HealthHealthy means the facilitator answered a signed supported request in time
+	HealthHealthy HealthStatus = "healthy"
+	// HealthDegraded means the facilitator accepted the request but answered slowly or throttled it
+	HealthDegraded HealthStatus = "degraded"
+	// HealthUnhealthy means the facilitator was unreachable, failing or rejected the credentials
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// HealthReport describes one health check
+type HealthReport struct {
+	Status  HealthStatus
+	Latency time.Duration // Round trip of the probe, including a timed-out one
+	Err     error         // The failure for degraded and unhealthy reports (nil when merely slow)
+}
+
+// CheckHealth probes the facilitator with a single supported request and classifies the result.
+//
+// The probe is sent once, without retries, and bounded by PingTimeout rather than the
+// operation timeouts. It is signed and rate limited like any other call but bypasses the
+// circuit breakers, hooks and metrics, so probing does not distort them. Auth failures and
+// unavailable or malformed responses are unhealthy; throttling and answers slower than
+// PingDegradedLatency are degraded.
+func (c *HTTPFacilitatorClient) CheckHealth(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, c.pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.probe(ctx)
+	report := HealthReport{Latency: time.Since(start), Err: err}
+
+	var facilitatorErr *FacilitatorError
+	switch {
+	case err == nil && report.Latency > c.pingDegradedLatency:
+		report.Status = HealthDegraded
+	case err == nil:
+		report.Status = HealthHealthy
+	case errors.As(err, &facilitatorErr) && facilitatorErr.Kind == FacilitatorErrorRateLimited:
+		report.Status = HealthDegraded
+	default:
+		report.Status = HealthUnhealthy
+	}
+	return report
+}
+
+// Ping is CheckHealth for liveness probes: it returns nil unless the facilitator is unhealthy
+func (c *HTTPFacilitatorClient) Ping(ctx context.Context) error {
+	report := c.CheckHealth(ctx)
+	if report.Status != HealthUnhealthy {
+		return nil
+	}
+	return fmt.Errorf("facilitator unhealthy after %s: %w", report.Latency.Round(time.Millisecond), report.Err)
+}
+
+// probe sends one supported request and decodes the response
+func (c *HTTPFacilitatorClient) probe(ctx context.Context) error {
+	request, err := c.codec.EncodeRequest(envelopeRequest(operationSupported, nil))
+	if err != nil {
+		return err
+	}
+	if err := c.waitForToken(ctx); err != nil {
+		return fmt.Errorf("%s request not sent: rate limit wait: %w", operationSupported.name, err)
+	}
+
+	statusCode, responseBody, err := c.sendRequest(ctx, operationSupported, request)
+	if err != nil {
+		return err
+	}
+	_, err = decodeSupportedResponse(c.codec, statusCode, responseBody)
+	return err
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPFacilitatorClientCheckHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		delay      time.Duration
+		status     int
+		body       string
+		wantStatus HealthStatus
+		wantKind   FacilitatorErrorKind
+	}{
+		{"healthy", 0, http.StatusOK, `{"code":0,"msg":"","data":{"kinds":[]}}`, HealthHealthy, 0},
+		{"slow", 60 * time.Millisecond, http.StatusOK, `{"code":0,"msg":"","data":{"kinds":[]}}`, HealthDegraded, 0},
+		{"throttled", 0, http.StatusTooManyRequests, `{"code":429,"msg":"slow down"}`, HealthDegraded, FacilitatorErrorRateLimited},
+		{"unavailable", 0, http.StatusServiceUnavailable, `<html><title>503 Service Unavailable</title></html>`, HealthUnhealthy, FacilitatorErrorUnavailable},
+		{"auth", 0, http.StatusUnauthorized, `{"code":401,"msg":"invalid signature"}`, HealthUnhealthy, FacilitatorErrorAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				time.Sleep(tt.delay)
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+				URL:                 server.URL,
+				PingDegradedLatency: 30 * time.Millisecond,
+				RetryPolicy:         RetryPolicy{MaxRetries: 3},
+			})
+			report := client.CheckHealth(context.Background())
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("Expected %s, got %s (err %v)", tt.wantStatus, report.Status, report.Err)
+			}
+			if got := requests.Load(); got != 1 {
+				t.Errorf("Expected a single probe without retries, got %d requests", got)
+			}
+			if tt.wantKind == 0 {
+				if report.Err != nil {
+					t.Errorf("Expected no error, got %v", report.Err)
+				}
+			} else {
+				var facilitatorErr *FacilitatorError
+				if !errors.As(report.Err, &facilitatorErr) || facilitatorErr.Kind != tt.wantKind {
+					t.Errorf("Expected a %s FacilitatorError, got %v", tt.wantKind, report.Err)
+				}
+			}
+
+			err := client.Ping(context.Background())
+			if (err != nil) != (tt.wantStatus == HealthUnhealthy) {
+				t.Errorf("Expected Ping to fail only when unhealthy, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPFacilitatorClientPingTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:              server.URL,
+		SupportedTimeout: time.Minute,
+		PingTimeout:      50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	report := client.CheckHealth(context.Background())
+	if report.Status != HealthUnhealthy {
+		t.Errorf("Expected a timed-out probe to be unhealthy, got %s", report.Status)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected PingTimeout to bound the probe instead of SupportedTimeout, took %s", elapsed)
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// ============================================================================
+// Settle Idempotency Keys
+// ============================================================================
+
+// DefaultIdempotencyKeyHeader is the header that carries the settle idempotency key
+// when FacilitatorConfig.IdempotencyKeyHeader is unset
+const DefaultIdempotencyKeyHeader = "X-Idempotency-Key"
+
+// IdempotencyKeyExtraField is the requirements.Extra field read as the settle idempotency key
+// when none was set on the context
+const IdempotencyKeyExtraField = "idempotencyKey"
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context whose Settle calls carry key in the idempotency key header.
+//
+// The key is sent verbatim on every attempt, including retries made by RetryPolicy, so a
+// facilitator can dedupe a settlement that is retried after a timeout or a process restart.
+// Unlike X-Request-Id, which the Gate Web3 signer randomizes per attempt for tracing, the
+// key must be derived from the payment itself (e.g. the authorization nonce) to stay stable.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set with WithIdempotencyKey
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// settleIdempotencyKey returns the key for a settle call: the context key, else requirements.Extra
+func settleIdempotencyKey(ctx context.Context, requirementsBytes []byte) string {
+	if key, ok := IdempotencyKeyFromContext(ctx); ok {
+		return key
+	}
+
+	var requirements struct {
+		Extra map[string]interface{} `json:"extra"`
+	}
+	if err := json.Unmarshal(requirementsBytes, &requirements); err != nil {
+		return ""
+	}
+	key, _ := requirements.Extra[IdempotencyKeyExtraField].(string)
+	return strings.TrimSpace(key)
+}
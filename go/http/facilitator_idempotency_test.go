@@ -0,0 +1,106 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// headerRecordingTransport records the headers of every attempt before delegating
+type headerRecordingTransport struct {
+	mu      sync.Mutex
+	headers []http.Header
+	next    http.RoundTripper
+}
+
+func (h *headerRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	h.headers = append(h.headers, req.Header.Clone())
+	h.mu.Unlock()
+	return h.next.RoundTrip(req)
+}
+
+func TestSettleIdempotencyKeyStableAcrossRetries(t *testing.T) {
+	server, _ := flakyFacilitatorServer(t, 0, http.StatusOK, x402.SettleResponse{Success: true, Transaction: "0xtx"})
+
+	recorder := &headerRecordingTransport{next: &dialFailingTransport{failures: 2, next: http.DefaultTransport}}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		HTTPClient:          &http.Client{Transport: recorder},
+		RetryPolicy:         fastRetryPolicy(3),
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	ctx := WithIdempotencyKey(context.Background(), "settle-0xnonce")
+	if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+
+	if len(recorder.headers) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(recorder.headers))
+	}
+	requestIDs := make(map[string]bool)
+	for i, header := range recorder.headers {
+		if got := header.Get(DefaultIdempotencyKeyHeader); got != "settle-0xnonce" {
+			t.Errorf("Attempt %d: expected idempotency key settle-0xnonce, got %q", i, got)
+		}
+		requestIDs[header.Get("X-Request-Id")] = true
+	}
+	if len(requestIDs) != 3 {
+		t.Errorf("Expected X-Request-Id to change per attempt, got %v", requestIDs)
+	}
+}
+
+func TestSettleIdempotencyKeyFromExtra(t *testing.T) {
+	var mu sync.Mutex
+	var headers []http.Header
+	server := envelopeEchoServer(t, func(r *http.Request, body []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		headers = append(headers, r.Header.Clone())
+	})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                  server.URL,
+		Signer:               &recordingSigner{},
+		IdempotencyKeyHeader: "Idempotency-Key",
+	})
+
+	payloadBytes, _ := retryTestPayload(t)
+	requirements := x402.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+		Extra:   map[string]interface{}{IdempotencyKeyExtraField: "order-42"},
+	}
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	if _, err := client.Settle(context.Background(), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	// The context key wins over requirements.Extra
+	if _, err := client.Settle(WithIdempotencyKey(context.Background(), "ctx-key"), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	// Verify never carries the key
+	if _, err := client.Verify(WithIdempotencyKey(context.Background(), "ctx-key"), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	want := []string{"order-42", "ctx-key", ""}
+	for i, header := range headers {
+		if got := header.Get("Idempotency-Key"); got != want[i] {
+			t.Errorf("Request %d: expected idempotency key %q, got %q", i, want[i], got)
+		}
+		if header.Get(DefaultIdempotencyKeyHeader) != "" {
+			t.Errorf("Request %d: expected default header to be unused", i)
+		}
+	}
+}
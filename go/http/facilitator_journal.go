@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Settle Journal
+// ============================================================================
+
+// SettleJournal durably records settlements by idempotency key, so a process that restarts
+// after the facilitator settled, but before the result was stored, returns the recorded
+// result instead of settling again. Implementations must be safe for concurrent use.
+//
+// A database-backed journal keeps one row per key, e.g.
+//
+//	CREATE TABLE settle_journal (
+//	    key        TEXT PRIMARY KEY,
+//	    response   JSONB,        -- NULL until Complete
+//	    started_at TIMESTAMPTZ NOT NULL,
+//	    settled_at TIMESTAMPTZ
+//	);
+//
+// Begin inserts the row (ON CONFLICT DO NOTHING), Complete sets response and settled_at,
+// and Lookup returns the response of a completed row. Rows begun but never completed mark
+// settles interrupted mid-request or reported as failed; they are re-sent with the same
+// idempotency key, so the facilitator's own deduplication decides whether they settle again.
+//
+// Lookup runs before the request is prechecked, so a completed settle is returned even after
+// its authorization has expired.
+type SettleJournal interface {
+	// Begin records that a settle for key is about to be sent
+	Begin(ctx context.Context, key string) error
+
+	// Complete records the successful result of the settle for key
+	Complete(ctx context.Context, key string, response x402.SettleResponse) error
+
+	// Lookup returns the result recorded by Complete for key, and false when there is none
+	Lookup(ctx context.Context, key string) (*x402.SettleResponse, bool, error)
+}
+
+// MemorySettleJournal is a SettleJournal held in memory. It survives client rebuilds within
+// a process but not restarts; use a durable store to resume after a crash.
+type MemorySettleJournal struct {
+	mu        sync.Mutex
+	pending   map[string]struct{}
+	completed map[string]x402.SettleResponse
+}
+
+// NewMemorySettleJournal creates an empty in-memory journal
+func NewMemorySettleJournal() *MemorySettleJournal {
+	return &MemorySettleJournal{
+		pending:   make(map[string]struct{}),
+		completed: make(map[string]x402.SettleResponse),
+	}
+}
+
+// Begin marks key as in progress
+func (j *MemorySettleJournal) Begin(ctx context.Context, key string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.completed[key]; !ok {
+		j.pending[key] = struct{}{}
+	}
+	return nil
+}
+
+// Complete stores a copy of response under key
+func (j *MemorySettleJournal) Complete(ctx context.Context, key string, response x402.SettleResponse) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.pending, key)
+	j.completed[key] = copySettleResponse(response)
+	return nil
+}
+
+// Lookup returns a copy of the response completed under key
+func (j *MemorySettleJournal) Lookup(ctx context.Context, key string) (*x402.SettleResponse, bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	response, ok := j.completed[key]
+	if !ok {
+		return nil, false, nil
+	}
+	response = copySettleResponse(response)
+	return &response, true, nil
+}
+
+// Pending returns the keys begun but not completed, i.e. settles whose outcome is unknown
+func (j *MemorySettleJournal) Pending() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	keys := make([]string, 0, len(j.pending))
+	for key := range j.pending {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func copySettleResponse(response x402.SettleResponse) x402.SettleResponse {
+	if response.Envelope != nil {
+		envelope := *response.Envelope
+		response.Envelope = &envelope
+	}
+	return response
+}
+
+// lookupJournaledSettle returns the result journaled for key. Settles without a key, or
+// without a journal, are never found.
+func (c *HTTPFacilitatorClient) lookupJournaledSettle(ctx context.Context, key string) (*x402.SettleResponse, bool, error) {
+	if c.journal == nil || key == "" {
+		return nil, false, nil
+	}
+
+	recorded, ok, err := c.journal.Lookup(ctx, key)
+	if err != nil {
+		return nil, false, newInvalidRequestError(operationSettle, fmt.Errorf("settle journal lookup failed: %w", err))
+	}
+	if ok {
+		c.logger.Info("settle resumed from journal", c.logFields(operationSettle, "idempotencyKey", key)...)
+	}
+	return recorded, ok, nil
+}
+
+// journaledSettle runs settle and journals its success, once lookupJournaledSettle found no
+// result for key. Settles without a key are not journaled.
+func (c *HTTPFacilitatorClient) journaledSettle(ctx context.Context, key string, settle func() (*x402.SettleResponse, error)) (*x402.SettleResponse, error) {
+	if c.journal == nil || key == "" {
+		return settle()
+	}
+
+	if err := c.journal.Begin(ctx, key); err != nil {
+		return nil, newInvalidRequestError(operationSettle, fmt.Errorf("settle journal begin failed: %w", err))
+	}
+
+	response, err := settle()
+	if err != nil {
+		return nil, err
+	}
+	// A settle reported as failed (e.g. HTTP 200 with success:false) stays pending, so a
+	// retry under the same key is sent again instead of replaying the failure
+	if !response.Success {
+		return response, nil
+	}
+	// The settlement happened; failing the call now would invite the caller to settle again
+	if err := c.journal.Complete(ctx, key, *response); err != nil {
+		c.logger.Error("settle journal complete failed", c.logFields(operationSettle, "idempotencyKey", key, "error", err)...)
+	}
+	return response, nil
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+// failingJournal fails every call
+type failingJournal struct{}
+
+func (failingJournal) Begin(ctx context.Context, key string) error { return errors.New("db down") }
+func (failingJournal) Complete(ctx context.Context, key string, response x402.SettleResponse) error {
+	return errors.New("db down")
+}
+func (failingJournal) Lookup(ctx context.Context, key string) (*x402.SettleResponse, bool, error) {
+	return nil, false, errors.New("db down")
+}
+
+func TestSettleJournalResumesAfterCompletion(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 0, http.StatusOK, x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1"})
+	journal := NewMemorySettleJournal()
+	newClient := func() *HTTPFacilitatorClient {
+		return NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, SettleJournal: journal})
+	}
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	ctx := WithIdempotencyKey(context.Background(), "settle-0xnonce")
+
+	first, err := newClient().Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if len(journal.Pending()) != 0 {
+		t.Errorf("Expected no pending settles, got %v", journal.Pending())
+	}
+
+	// A rebuilt client, as after a restart, returns the journaled result without a request
+	resumed, err := newClient().Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Resumed settle failed: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("Expected 1 upstream settle, got %d", got)
+	}
+	if resumed.Transaction != first.Transaction || !resumed.Success || resumed.SettledAmount != "1000000" {
+		t.Errorf("Expected the journaled response %+v, got %+v", first, resumed)
+	}
+	resumed.Transaction = "0xchanged"
+	if again, _ := newClient().Settle(ctx, payloadBytes, requirementsBytes); again.Transaction != "0xtx" {
+		t.Errorf("Expected the journal to hand out copies, got %s", again.Transaction)
+	}
+
+	// Settles without a key, and under other keys, are sent
+	if _, err := newClient().Settle(context.Background(), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle without a key failed: %v", err)
+	}
+	if _, err := newClient().Settle(WithIdempotencyKey(context.Background(), "other"), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle under another key failed: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("Expected 3 upstream settles, got %d", got)
+	}
+}
+
+func TestSettleJournalResumesExpiredAuthorization(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 0, http.StatusOK, x402.SettleResponse{Success: true, Transaction: "0xnew"})
+	journal := NewMemorySettleJournal()
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, SettleJournal: journal})
+	ctx := WithIdempotencyKey(context.Background(), "settle-0xnonce")
+
+	// The settle completed before a restart, and its authorization has since expired
+	requirements := x402.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(x402.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload: map[string]interface{}{
+			"authorization": map[string]interface{}{"validAfter": "0", "validBefore": "1"},
+		},
+	})
+	requirementsBytes, _ := json.Marshal(requirements)
+	if err := journal.Complete(ctx, "settle-0xnonce", x402.SettleResponse{Success: true, Transaction: "0xtx"}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	resumed, err := client.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Expected the journaled settle, got %v", err)
+	}
+	if resumed.Transaction != "0xtx" || atomic.LoadInt32(calls) != 0 {
+		t.Errorf("Expected the journaled response without a request, got %+v after %d requests", resumed, atomic.LoadInt32(calls))
+	}
+
+	// Without a journaled result the expired authorization is still refused locally
+	_, err = client.Settle(WithIdempotencyKey(context.Background(), "other"), payloadBytes, requirementsBytes)
+	if !errors.Is(err, types.ErrAuthorizationExpired) {
+		t.Errorf("Expected an expiry error, got %v", err)
+	}
+	if pending := journal.Pending(); len(pending) != 0 {
+		t.Errorf("Expected a refused settle not to be journaled, got %v", pending)
+	}
+}
+
+func TestSettleJournalInterruptedSettle(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 1, http.StatusBadGateway, x402.SettleResponse{Success: true, Transaction: "0xtx"})
+	journal := NewMemorySettleJournal()
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, SettleJournal: journal})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	ctx := WithIdempotencyKey(context.Background(), "settle-0xnonce")
+
+	// A failed settle stays pending and is sent again
+	if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected the first settle to fail")
+	}
+	if pending := journal.Pending(); len(pending) != 1 || pending[0] != "settle-0xnonce" {
+		t.Errorf("Expected settle-0xnonce pending, got %v", pending)
+	}
+	if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 || len(journal.Pending()) != 0 {
+		t.Errorf("Expected 2 upstream settles and nothing pending, got %d and %v", got, journal.Pending())
+	}
+}
+
+func TestSettleJournalFailedSettleIsRetried(t *testing.T) {
+	// The first settle is answered with HTTP 200 and success:false, the second succeeds
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1"}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			response = x402.SettleResponse{Success: false, ErrorReason: "transaction_reverted", Network: "eip155:1"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": response})
+	}))
+	defer server.Close()
+
+	journal := NewMemorySettleJournal()
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, SettleJournal: journal})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	ctx := WithIdempotencyKey(context.Background(), "settle-0xnonce")
+
+	failed, err := client.Settle(ctx, payloadBytes, requirementsBytes)
+	if err == nil && failed.Success {
+		t.Fatal("Expected the first settle to fail")
+	}
+	if pending := journal.Pending(); len(pending) != 1 || pending[0] != "settle-0xnonce" {
+		t.Errorf("Expected settle-0xnonce pending, got %v", pending)
+	}
+
+	retried, err := client.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected the retry to reach the facilitator, got %d upstream settles", got)
+	}
+	if !retried.Success || retried.Transaction != "0xtx" || len(journal.Pending()) != 0 {
+		t.Errorf("Expected the retry to settle and complete the key, got %+v and %v", retried, journal.Pending())
+	}
+}
+
+func TestSettleJournalFailure(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 0, http.StatusOK, x402.SettleResponse{Success: true})
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{}, SettleJournal: failingJournal{}})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	_, err := client.Settle(WithIdempotencyKey(context.Background(), "key"), payloadBytes, requirementsBytes)
+	var facilitatorErr *FacilitatorError
+	if !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorInvalidRequest {
+		t.Errorf("Expected an invalid request error, got %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("Expected no upstream settle, got %d", got)
+	}
+}
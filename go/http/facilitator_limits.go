@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ============================================================================
+// Response Size Limit
+// ============================================================================
+
+// DefaultMaxResponseBytes bounds facilitator response bodies when FacilitatorConfig.MaxResponseBytes is zero
+const DefaultMaxResponseBytes = 1 << 20
+
+// ErrResponseTooLarge is wrapped by the FacilitatorError returned when a response body
+// exceeds FacilitatorConfig.MaxResponseBytes. The call is not retried.
+var ErrResponseTooLarge = errors.New("facilitator response body exceeds the size limit")
+
+// readResponseBody reads at most limit bytes of body; a negative limit reads it all
+// Only limit+1 bytes are ever buffered, so an oversized body fails without being read in full.
+func readResponseBody(op facilitatorOperation, statusCode int, body io.Reader, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &FacilitatorError{
+			Operation:  op.name,
+			HTTPStatus: statusCode,
+			Kind:       FacilitatorErrorMalformed,
+			Err:        fmt.Errorf("%s response: %w (%d bytes)", op.name, ErrResponseTooLarge, limit),
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPFacilitatorClientMaxResponseBytes(t *testing.T) {
+	const limit = 1024
+	const total = 64 << 20
+
+	var written, requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		chunk := bytes.Repeat([]byte("x"), 32<<10)
+		for atomic.LoadInt64(&written) < total {
+			n, err := w.Write(chunk)
+			atomic.AddInt64(&written, int64(n))
+			if err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:              server.URL,
+		MaxResponseBytes: limit,
+		RetryPolicy:      RetryPolicy{MaxRetries: 2},
+	})
+
+	payload, requirements := retryTestPayload(t)
+	_, err := client.Verify(context.Background(), payload, requirements)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	var facilitatorErr *FacilitatorError
+	if !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorMalformed || facilitatorErr.Operation != "verify" {
+		t.Errorf("Expected a malformed verify FacilitatorError, got %#v", facilitatorErr)
+	}
+	if facilitatorErr != nil && facilitatorErr.Retryable() {
+		t.Error("Expected an oversized response not to be retryable")
+	}
+	if n := atomic.LoadInt64(&requests); n != 1 {
+		t.Errorf("Expected no retries, got %d requests", n)
+	}
+
+	// The client stops reading at the limit, so the server cannot deliver the whole body
+	server.CloseClientConnections()
+	if n := atomic.LoadInt64(&written); n >= total {
+		t.Errorf("Expected the body to be cut off, server wrote %d bytes", n)
+	}
+}
+
+func TestHTTPFacilitatorClientMaxResponseBytesBoundary(t *testing.T) {
+	body := `{"code":0,"msg":"","data":{"isValid":true,"payer":"0xpayer"}}`
+	server := rawBodyServer(t, http.StatusOK, body)
+	payload, requirements := retryTestPayload(t)
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "default limit", limit: 0},
+		{name: "exactly at the limit", limit: int64(len(body))},
+		{name: "one byte over", limit: int64(len(body) - 1), wantErr: true},
+		{name: "unlimited", limit: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, MaxResponseBytes: tt.limit})
+			response, err := client.Verify(context.Background(), payload, requirements)
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+				}
+				return
+			}
+			if err != nil || !response.IsValid {
+				t.Errorf("Expected a valid response, got %+v %v", response, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ============================================================================
+// Facilitator Logging
+// ============================================================================
+
+// Logger receives structured logs from HTTPFacilitatorClient. keysAndValues alternate
+// string keys and values, so *slog.Logger satisfies it directly. Implementations must be
+// safe for concurrent use.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// nopLogger discards everything; it is the default Logger
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// logFields returns the fields shared by every log line of an operation
+func (c *HTTPFacilitatorClient) logFields(op facilitatorOperation, keysAndValues ...interface{}) []interface{} {
+	return append([]interface{}{"operation", op.name, "facilitator", c.identifier}, keysAndValues...)
+}
+
+// logRequestStart logs a call about to be sent; the body only in verbose mode
+func (c *HTTPFacilitatorClient) logRequestStart(op facilitatorOperation, body []byte) {
+	fields := c.logFields(op, "action", op.action)
+	if c.verboseLogging {
+		fields = append(fields, "body", string(body))
+	}
+	c.logger.Debug("facilitator request", fields...)
+}
+
+// logAttempt logs the headers of one attempt in verbose mode, with RedactedHeaders masked
+func (c *HTTPFacilitatorClient) logAttempt(op facilitatorOperation, req *http.Request) {
+	if !c.verboseLogging {
+		return
+	}
+	headers := make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		value := req.Header.Get(name)
+		if isRedactedHeader(name) {
+			value = "REDACTED"
+		}
+		headers[name] = value
+	}
+	c.logger.Debug("facilitator attempt", c.logFields(op, "url", req.URL.String(), "headers", headers)...)
+}
+
+// logRetry logs a failed attempt that will be retried after backoff
+func (c *HTTPFacilitatorClient) logRetry(op facilitatorOperation, attempt, statusCode int, err error, backoff time.Duration) {
+	fields := c.logFields(op, "attempt", attempt+1, "status", statusCode, "backoff", backoff)
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+	c.logger.Warn("facilitator request retrying", fields...)
+}
+
+// logResponse logs the raw response of a call in verbose mode
+func (c *HTTPFacilitatorClient) logResponse(op facilitatorOperation, statusCode int, body []byte) {
+	if !c.verboseLogging || body == nil {
+		return
+	}
+	c.logger.Debug("facilitator response body", c.logFields(op, "status", statusCode, "body", string(body))...)
+}
+
+// logOutcome logs a finished Verify, Settle or GetSupported call
+func (c *HTTPFacilitatorClient) logOutcome(op facilitatorOperation, duration time.Duration, err error) {
+	if err == nil {
+		c.logger.Info("facilitator request succeeded", c.logFields(op, "duration", duration)...)
+		return
+	}
+
+	fields := c.logFields(op, "duration", duration, "error", err.Error(), "class", errorClass(err))
+	var facilitatorErr *FacilitatorError
+	if errors.As(err, &facilitatorErr) {
+		fields = append(fields, "status", facilitatorErr.HTTPStatus, "code", facilitatorErr.BusinessCode)
+	}
+	c.logger.Error("facilitator request failed", fields...)
+}
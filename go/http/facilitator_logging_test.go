@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// *slog.Logger can be passed as a Logger as is
+var _ Logger = (*slog.Logger)(nil)
+
+// logEntry is one captured log line
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// testLogger captures log lines
+type testLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *testLogger) log(level, msg string, keysAndValues []interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *testLogger) Debug(msg string, kv ...interface{}) { l.log("debug", msg, kv) }
+func (l *testLogger) Info(msg string, kv ...interface{})  { l.log("info", msg, kv) }
+func (l *testLogger) Warn(msg string, kv ...interface{})  { l.log("warn", msg, kv) }
+func (l *testLogger) Error(msg string, kv ...interface{}) { l.log("error", msg, kv) }
+
+// find returns the entries logged with msg
+func (l *testLogger) find(msg string) []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var found []logEntry
+	for _, entry := range l.entries {
+		if entry.msg == msg {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+func TestHTTPFacilitatorClientLogging(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			_, _ = io.WriteString(w, `{"code":0,"msg":"","data":{"isValid":true,"payer":"0xpayer"}}`)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"code":401,"msg":"invalid signature"}`)
+		}
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		Identifier:          "primary",
+		Logger:              logger,
+		RetryPolicy:         RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond},
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+	payload, requirements := retryTestPayload(t)
+
+	if _, err := client.Verify(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	starts := logger.find("facilitator request")
+	if len(starts) != 1 || starts[0].level != "debug" || starts[0].fields["operation"] != "verify" || starts[0].fields["facilitator"] != "primary" {
+		t.Errorf("Expected one request start log with operation and facilitator, got %+v", starts)
+	}
+	if _, ok := starts[0].fields["body"]; ok {
+		t.Error("Expected the body to be left out of the logs by default")
+	}
+	retries := logger.find("facilitator request retrying")
+	if len(retries) != 1 || retries[0].level != "warn" || retries[0].fields["attempt"] != 1 || retries[0].fields["status"] != http.StatusServiceUnavailable {
+		t.Errorf("Expected one retry log for the 503, got %+v", retries)
+	}
+	if successes := logger.find("facilitator request succeeded"); len(successes) != 1 || successes[0].level != "info" {
+		t.Errorf("Expected one success log, got %+v", successes)
+	}
+	if attempts := logger.find("facilitator attempt"); len(attempts) != 0 {
+		t.Errorf("Expected no attempt logs outside verbose mode, got %+v", attempts)
+	}
+
+	if _, err := client.GetSupported(context.Background()); err == nil {
+		t.Fatal("Expected GetSupported to fail")
+	}
+	failures := logger.find("facilitator request failed")
+	if len(failures) != 1 || failures[0].level != "error" || failures[0].fields["operation"] != "supported" ||
+		failures[0].fields["class"] != "auth" || failures[0].fields["status"] != http.StatusUnauthorized {
+		t.Errorf("Expected one auth failure log, got %+v", failures)
+	}
+}
+
+func TestHTTPFacilitatorClientVerboseLogging(t *testing.T) {
+	server := newRecordingFacilitator(t)
+	logger := &testLogger{}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		Logger:              logger,
+		VerboseLogging:      true,
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk", Passphrase: "pass"},
+	})
+	payload, requirements := retryTestPayload(t)
+
+	if _, err := client.Verify(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	starts := logger.find("facilitator request")
+	if len(starts) != 1 || !strings.Contains(starts[0].fields["body"].(string), "paymentPayload") {
+		t.Errorf("Expected the request body in verbose mode, got %+v", starts)
+	}
+	if responses := logger.find("facilitator response body"); len(responses) != 1 || !strings.Contains(responses[0].fields["body"].(string), "isValid") {
+		t.Errorf("Expected the response body in verbose mode, got %+v", responses)
+	}
+
+	attempts := logger.find("facilitator attempt")
+	if len(attempts) != 1 {
+		t.Fatalf("Expected one attempt log, got %d", len(attempts))
+	}
+	headers := attempts[0].fields["headers"].(map[string]string)
+	if headers["X-Signature"] != "REDACTED" || headers["X-Passphrase"] != "REDACTED" || headers["X-Api-Key"] != "ak" {
+		t.Errorf("Expected signing secrets to be redacted, got %v", headers)
+	}
+}
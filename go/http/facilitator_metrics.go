@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ============================================================================
+// Facilitator Metrics
+// ============================================================================
+
+// Error classes reported in FacilitatorCall.ErrorClass besides FacilitatorErrorKind names
+const (
+	// ErrorClassCanceled is a call whose context was canceled or timed out
+	ErrorClassCanceled = "canceled"
+	// ErrorClassCircuitOpen is a call rejected by an open circuit breaker
+	ErrorClassCircuitOpen = "circuit_open"
+)
+
+// FacilitatorCall describes one finished Verify, Settle or GetSupported call
+type FacilitatorCall struct {
+	// Facilitator is the FacilitatorConfig.Identifier (the URL by default)
+	Facilitator string
+
+	// Duration covers the whole call, including retries and rate limit waits
+	Duration time.Duration
+
+	// StatusCode is the final HTTP status (0 when no response was received)
+	StatusCode int
+
+	// ErrorClass is empty on success, otherwise a FacilitatorErrorKind name ("auth",
+	// "rate_limited", "unavailable", "malformed", "invalid_request", "other"),
+	// ErrorClassCanceled or ErrorClassCircuitOpen
+	ErrorClass string
+}
+
+// MetricsCollector receives a record of every Verify, Settle and GetSupported call made by
+// HTTPFacilitatorClient. Implementations must be safe for concurrent use and should not block.
+// See the http/prometheus package for a Prometheus implementation.
+type MetricsCollector interface {
+	RecordVerify(call FacilitatorCall)
+	RecordSettle(call FacilitatorCall)
+	RecordSupported(call FacilitatorCall)
+}
+
+// recordCall reports a finished call to the logger and the configured MetricsCollector
+func (c *HTTPFacilitatorClient) recordCall(op facilitatorOperation, start time.Time, err error) {
+	c.logOutcome(op, time.Since(start), err)
+	if c.metrics == nil {
+		return
+	}
+
+	call := FacilitatorCall{
+		Facilitator: c.identifier,
+		Duration:    time.Since(start),
+		ErrorClass:  errorClass(err),
+	}
+	var facilitatorErr *FacilitatorError
+	switch {
+	case err == nil:
+		call.StatusCode = http.StatusOK
+	case errors.As(err, &facilitatorErr):
+		call.StatusCode = facilitatorErr.HTTPStatus
+	}
+
+	switch op {
+	case operationVerify:
+		c.metrics.RecordVerify(call)
+	case operationSettle:
+		c.metrics.RecordSettle(call)
+	default:
+		c.metrics.RecordSupported(call)
+	}
+}
+
+// errorClass classifies a call error for metrics
+func errorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return ErrorClassCircuitOpen
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassCanceled
+	}
+	var facilitatorErr *FacilitatorError
+	if errors.As(err, &facilitatorErr) {
+		return facilitatorErr.Kind.String()
+	}
+	return FacilitatorErrorOther.String()
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestHTTPFacilitatorClientRateLimit(t *testing.T) {
+	t.Run("paces requests", func(t *testing.T) {
+		server, calls := flakyFacilitatorServer(t, 0, 0, x402.SupportedResponse{})
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:               server.URL,
+			RequestsPerSecond: 20,
+			Burst:             2,
+		})
+
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			if _, err := client.GetSupported(context.Background()); err != nil {
+				t.Fatalf("GetSupported failed: %v", err)
+			}
+		}
+		// Two requests use the burst; the other three wait 50ms each
+		if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+			t.Errorf("Expected requests to be paced to 20/s, 5 requests took %v", elapsed)
+		}
+		if atomic.LoadInt32(calls) != 5 {
+			t.Errorf("Expected 5 requests, got %d", atomic.LoadInt32(calls))
+		}
+	})
+
+	t.Run("retries take tokens", func(t *testing.T) {
+		server, calls := flakyFacilitatorServer(t, 2, http.StatusServiceUnavailable, x402.VerifyResponse{IsValid: true})
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:               server.URL,
+			RequestsPerSecond: 20,
+			RetryPolicy:       RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+		})
+		payloadBytes, requirementsBytes := retryTestPayload(t)
+
+		start := time.Now()
+		if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		// Three attempts: the first uses the burst, each retry waits 50ms for a token
+		if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+			t.Errorf("Expected retries to wait for tokens, 3 attempts took %v", elapsed)
+		}
+		if atomic.LoadInt32(calls) != 3 {
+			t.Errorf("Expected 3 attempts, got %d", atomic.LoadInt32(calls))
+		}
+	})
+
+	t.Run("respects context cancellation", func(t *testing.T) {
+		server, calls := flakyFacilitatorServer(t, 0, 0, x402.SupportedResponse{})
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:               server.URL,
+			RequestsPerSecond: 0.1,
+		})
+
+		if _, err := client.GetSupported(context.Background()); err != nil {
+			t.Fatalf("GetSupported failed: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := client.GetSupported(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected the wait to end with the context, took %v", elapsed)
+		}
+		if atomic.LoadInt32(calls) != 1 {
+			t.Errorf("Expected the second request not to be sent, got %d requests", atomic.LoadInt32(calls))
+		}
+
+		// The canceled wait returns its token, so the limiter is not pushed further back
+		if tokens := client.limiter.Tokens(); tokens < -0.1 {
+			t.Errorf("Expected the reservation to be canceled, limiter has %.2f tokens", tokens)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "http://localhost"})
+		if client.limiter != nil {
+			t.Error("Expected no limiter without RequestsPerSecond")
+		}
+	})
+}
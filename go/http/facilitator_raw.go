@@ -0,0 +1,65 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Raw Responses
+// ============================================================================
+
+type rawResponseContextKey struct{}
+
+// rawResponse receives the last HTTP response of a Raw call
+type rawResponse struct {
+	resp *http.Response
+	body []byte
+}
+
+// withRawResponse returns a context whose requests record their responses in the returned slot
+func withRawResponse(ctx context.Context) (context.Context, *rawResponse) {
+	raw := &rawResponse{}
+	return context.WithValue(ctx, rawResponseContextKey{}, raw), raw
+}
+
+// captureRawResponse records resp and its drained body for a Raw call; later attempts replace earlier ones
+func captureRawResponse(ctx context.Context, resp *http.Response, body []byte) {
+	raw, ok := ctx.Value(rawResponseContextKey{}).(*rawResponse)
+	if !ok {
+		return
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	raw.resp = resp
+	raw.body = body
+}
+
+// VerifyRaw is Verify, also returning the facilitator's HTTP response and body
+//
+// The response is that of the last attempt, with its body already drained into the returned
+// bytes (resp.Body replays them). Both are nil when no response was received, and are
+// returned alongside the error when the facilitator answered with a failure, so callers can
+// inspect rate limit headers, request IDs and the exact status.
+func (c *HTTPFacilitatorClient) VerifyRaw(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, *http.Response, []byte, error) {
+	ctx, raw := withRawResponse(ctx)
+	response, err := c.Verify(ctx, payloadBytes, requirementsBytes)
+	return response, raw.resp, raw.body, err
+}
+
+// SettleRaw is Settle, also returning the facilitator's HTTP response and body (see VerifyRaw)
+func (c *HTTPFacilitatorClient) SettleRaw(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, *http.Response, []byte, error) {
+	ctx, raw := withRawResponse(ctx)
+	response, err := c.Settle(ctx, payloadBytes, requirementsBytes)
+	return response, raw.resp, raw.body, err
+}
+
+// GetSupportedRaw is GetSupported, also returning the facilitator's HTTP response and body (see VerifyRaw)
+func (c *HTTPFacilitatorClient) GetSupportedRaw(ctx context.Context) (x402.SupportedResponse, *http.Response, []byte, error) {
+	ctx, raw := withRawResponse(ctx)
+	response, err := c.GetSupported(ctx)
+	return response, raw.resp, raw.body, err
+}
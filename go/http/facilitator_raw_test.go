@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// rawBodyServer answers every request with status, body and an X-RateLimit-Remaining header
+func rawBodyServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "41")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPFacilitatorClientVerifyRaw(t *testing.T) {
+	body := `{"code":0,"msg":"","data":{"isValid":true,"payer":"0xpayer"}}`
+	server := rawBodyServer(t, http.StatusOK, body)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+
+	payload, requirements := retryTestPayload(t)
+	response, resp, raw, err := client.VerifyRaw(context.Background(), payload, requirements)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsValid || response.Payer != "0xpayer" {
+		t.Errorf("Expected decoded valid response, got %+v", response)
+	}
+	if string(raw) != body {
+		t.Errorf("Expected raw body %s, got %s", body, raw)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-RateLimit-Remaining") != "41" {
+		t.Errorf("Expected status 200 with rate limit header, got %d %v", resp.StatusCode, resp.Header)
+	}
+	replayed, _ := io.ReadAll(resp.Body)
+	if string(replayed) != body {
+		t.Errorf("Expected resp.Body to replay the raw body, got %s", replayed)
+	}
+}
+
+func TestHTTPFacilitatorClientRawOnFailure(t *testing.T) {
+	body := `{"code":429,"msg":"slow down"}`
+	server := rawBodyServer(t, http.StatusTooManyRequests, body)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+
+	payload, requirements := retryTestPayload(t)
+	_, resp, raw, err := client.SettleRaw(context.Background(), payload, requirements)
+	if err == nil {
+		t.Fatal("Expected an error for a 429 response")
+	}
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests || string(raw) != body {
+		t.Errorf("Expected the 429 response and its body alongside the error, got %v %s", resp, raw)
+	}
+
+	_, resp, raw, err = client.GetSupportedRaw(context.Background())
+	if err == nil || resp == nil || string(raw) != body {
+		t.Errorf("Expected GetSupportedRaw to return the failed response, got %v %v %s", err, resp, raw)
+	}
+}
+
+func TestHTTPFacilitatorClientRawWithoutResponse(t *testing.T) {
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "http://127.0.0.1:1"})
+
+	_, resp, raw, err := client.GetSupportedRaw(context.Background())
+	if err == nil {
+		t.Fatal("Expected a connection error")
+	}
+	if resp != nil || raw != nil {
+		t.Errorf("Expected no response when none was received, got %v %s", resp, raw)
+	}
+}
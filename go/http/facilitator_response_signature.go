@@ -0,0 +1,115 @@
+package http
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ============================================================================
+// Response Signature Verification
+// ============================================================================
+
+// ResponseSignatureHeader carries the facilitator's signature over the response body
+const ResponseSignatureHeader = "X-Response-Signature"
+
+// ErrInvalidResponseSignature is wrapped by the FacilitatorError returned when a response
+// signature is missing or does not match the body. The call is not retried.
+var ErrInvalidResponseSignature = errors.New("invalid facilitator response signature")
+
+// ResponseVerifier authenticates facilitator responses before their data is trusted.
+// Implement this for facilitators that sign responses with another scheme.
+type ResponseVerifier interface {
+	// Verify returns an error unless header carries a valid signature over body, the exact
+	// response body received
+	Verify(header http.Header, body []byte) error
+}
+
+// HMACResponseVerifier checks X-Response-Signature against a secret shared with the facilitator
+//
+//	Signature = Base64(HMAC_SHA256(Secret, rawBody))
+type HMACResponseVerifier struct {
+	Secret string
+}
+
+// Verify implements ResponseVerifier
+func (v HMACResponseVerifier) Verify(header http.Header, body []byte) error {
+	if v.Secret == "" {
+		return fmt.Errorf("hmac response verifier requires a secret")
+	}
+	signature, err := responseSignature(header)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(v.Secret))
+	_, _ = mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ErrInvalidResponseSignature
+	}
+	return nil
+}
+
+// Ed25519ResponseVerifier checks X-Response-Signature against the facilitator's public key
+//
+//	Signature = Base64(Ed25519_Sign(PrivateKey, rawBody))
+type Ed25519ResponseVerifier struct {
+	PublicKey ed25519.PublicKey
+}
+
+// Verify implements ResponseVerifier
+func (v Ed25519ResponseVerifier) Verify(header http.Header, body []byte) error {
+	if len(v.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("ed25519 response verifier requires a %d-byte public key", ed25519.PublicKeySize)
+	}
+	signature, err := responseSignature(header)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(v.PublicKey, body, signature) {
+		return ErrInvalidResponseSignature
+	}
+	return nil
+}
+
+// responseSignature decodes the X-Response-Signature header
+func responseSignature(header http.Header) ([]byte, error) {
+	value := header.Get(ResponseSignatureHeader)
+	if value == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrInvalidResponseSignature, ResponseSignatureHeader)
+	}
+	signature, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not base64", ErrInvalidResponseSignature, ResponseSignatureHeader)
+	}
+	return signature, nil
+}
+
+// verifyResponse applies the configured ResponseVerifier. Successful responses must be signed;
+// unsigned failures pass through, since gateways and proxies answer throttling and outages
+// themselves and those must still be classified and retried.
+func (c *HTTPFacilitatorClient) verifyResponse(op facilitatorOperation, resp *http.Response, body []byte) error {
+	if c.verifier == nil {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK && resp.Header.Get(ResponseSignatureHeader) == "" {
+		return nil
+	}
+
+	if err := c.verifier.Verify(resp.Header, body); err != nil {
+		if !errors.Is(err, ErrInvalidResponseSignature) {
+			err = fmt.Errorf("%w: %w", ErrInvalidResponseSignature, err)
+		}
+		return &FacilitatorError{
+			Operation:  op.name,
+			HTTPStatus: resp.StatusCode,
+			Kind:       FacilitatorErrorMalformed,
+			Err:        fmt.Errorf("%s response: %w", op.name, err),
+		}
+	}
+	return nil
+}
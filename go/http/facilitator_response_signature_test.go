@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const signedSettleBody = `{"code":0,"msg":"","data":{"success":true,"transaction":"0xsettledtx","network":"eip155:1"}}`
+
+func hmacSignature(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signingServer answers every call with body, signed by sign over signedBody
+func signingServer(t *testing.T, status int, body, signedBody string, sign func(string) string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sign != nil {
+			w.Header().Set(ResponseSignatureHeader, sign(signedBody))
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPFacilitatorClientResponseSignature(t *testing.T) {
+	const secret = "facilitator-secret"
+	hmacSign := func(body string) string { return hmacSignature(secret, body) }
+	tampered := strings.Replace(signedSettleBody, "0xsettledtx", "0xforgedtx", 1)
+
+	tests := []struct {
+		name       string
+		body       string
+		signedBody string
+		sign       func(string) string
+		wantErr    bool
+	}{
+		{name: "valid", body: signedSettleBody, signedBody: signedSettleBody, sign: hmacSign},
+		{name: "tampered body", body: tampered, signedBody: signedSettleBody, sign: hmacSign, wantErr: true},
+		{name: "wrong secret", body: signedSettleBody, signedBody: signedSettleBody, sign: func(body string) string { return hmacSignature("other", body) }, wantErr: true},
+		{name: "not base64", body: signedSettleBody, sign: func(string) string { return "%%%" }, wantErr: true},
+		{name: "missing", body: signedSettleBody, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := signingServer(t, http.StatusOK, tt.body, tt.signedBody, tt.sign)
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+				URL:              server.URL,
+				Signer:           &recordingSigner{},
+				ResponseVerifier: HMACResponseVerifier{Secret: secret},
+			})
+			payloadBytes, requirementsBytes := retryTestPayload(t)
+
+			response, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if response.Transaction != "0xsettledtx" {
+					t.Errorf("Expected transaction 0xsettledtx, got %s", response.Transaction)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidResponseSignature) {
+				t.Fatalf("Expected ErrInvalidResponseSignature, got %v (response %+v)", err, response)
+			}
+			var facilitatorErr *FacilitatorError
+			if !errors.As(err, &facilitatorErr) || facilitatorErr.Retryable() {
+				t.Errorf("Expected a non-retryable FacilitatorError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEd25519ResponseVerifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifier := Ed25519ResponseVerifier{PublicKey: publicKey}
+
+	header := http.Header{}
+	header.Set(ResponseSignatureHeader, base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(signedSettleBody))))
+
+	if err := verifier.Verify(header, []byte(signedSettleBody)); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+	if err := verifier.Verify(header, []byte(strings.Replace(signedSettleBody, "true", "false", 1))); !errors.Is(err, ErrInvalidResponseSignature) {
+		t.Errorf("Expected ErrInvalidResponseSignature for a tampered body, got %v", err)
+	}
+	if err := (Ed25519ResponseVerifier{}).Verify(header, []byte(signedSettleBody)); err == nil {
+		t.Error("Expected error without a public key")
+	}
+}
+
+func TestHTTPFacilitatorClientUnsignedFailurePassesThrough(t *testing.T) {
+	server := signingServer(t, http.StatusServiceUnavailable, "upstream unavailable", "", nil)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:              server.URL,
+		Signer:           &recordingSigner{},
+		ResponseVerifier: HMACResponseVerifier{Secret: "facilitator-secret"},
+	})
+
+	_, err := client.GetSupported(context.Background())
+	if errors.Is(err, ErrInvalidResponseSignature) {
+		t.Fatalf("Expected an unsigned 503 not to fail verification, got %v", err)
+	}
+	var facilitatorErr *FacilitatorError
+	if !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorUnavailable {
+		t.Errorf("Expected an unavailable FacilitatorError, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Default retry backoff bounds used when the policy leaves them unset
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 2 * time.Second
+)
+
+// defaultRetryableStatusCodes are retried when RetryPolicy.RetryableStatusCodes is empty
+var defaultRetryableStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures retries with exponential backoff for facilitator requests.
+//
+// Verify and GetSupported are retried on transport errors (connection resets, timeouts)
+// and on any of RetryableStatusCodes. Settle is only retried when the request never
+// reached the facilitator (dial/DNS failures): a settle that was received may already
+// have been executed on-chain, so retrying it could double-spend.
+//
+// Retries stop as soon as the caller's context is done.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt (0 disables retries)
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry, doubled for each subsequent retry (defaults to 100ms)
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries (defaults to 2s)
+	MaxDelay time.Duration
+
+	// RetryableStatusCodes lists HTTP status codes that trigger a retry (defaults to 502, 503, 504)
+	RetryableStatusCodes []int
+}
+
+// shouldRetry reports whether the attempt that just finished should be retried
+func (p RetryPolicy) shouldRetry(ctx context.Context, op facilitatorOperation, attempt int, statusCode int, err error) bool {
+	if attempt >= p.MaxRetries || ctx.Err() != nil {
+		return false
+	}
+
+	// An oversized response would be just as large on the next attempt
+	if errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+
+	// Settle is side-effecting: only retry when the request was never delivered
+	if op == operationSettle {
+		return err != nil && isDialError(err)
+	}
+
+	if err != nil {
+		return true
+	}
+	return p.isRetryableStatus(statusCode)
+}
+
+// isRetryableStatus reports whether an HTTP status code should be retried
+func (p RetryPolicy) isRetryableStatus(statusCode int) bool {
+	codes := p.RetryableStatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryableStatusCodes
+	}
+	for _, code := range codes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the retry following the given attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	delay := base
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// waitForRetry blocks for the backoff delay or until the context is done
+func waitForRetry(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isDialError reports whether err happened before the request reached the server
+// (connection refused, DNS resolution failure, etc.)
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
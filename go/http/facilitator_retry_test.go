@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// flakyFacilitatorServer fails the first `failures` requests with failStatus, then returns data in the envelope
+func flakyFacilitatorServer(t *testing.T, failures int32, failStatus int, data interface{}) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if n <= failures {
+			w.WriteHeader(failStatus)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": failStatus, "msg": "unavailable"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func retryTestPayload(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	requirements := x402.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	payload := x402.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	return payloadBytes, requirementsBytes
+}
+
+// dialFailingTransport returns a dial error for the first `failures` requests, then delegates
+type dialFailingTransport struct {
+	failures int32
+	calls    int32
+	next     http.RoundTripper
+}
+
+func (d *dialFailingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&d.calls, 1) <= d.failures {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return d.next.RoundTrip(req)
+}
+
+func fastRetryPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryVerifySucceedsAfterTransientFailures(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 2, http.StatusServiceUnavailable, x402.VerifyResponse{IsValid: true, Payer: "0xpayer"})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: fastRetryPolicy(3)})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	resp, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if !resp.IsValid {
+		t.Error("Expected valid response")
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryVerifyGivesUpAfterMaxRetries(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 10, http.StatusBadGateway, x402.VerifyResponse{IsValid: true})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: fastRetryPolicy(2)})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("Expected 3 attempts (1 + 2 retries), got %d", got)
+	}
+}
+
+func TestRetryNonRetryableStatus(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 1, http.StatusBadRequest, x402.VerifyResponse{IsValid: true})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: fastRetryPolicy(3)})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected error for non-retryable status")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("Expected a single attempt, got %d", got)
+	}
+}
+
+func TestRetryCustomStatusCodes(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 1, http.StatusTooManyRequests, x402.SupportedResponse{Kinds: []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}})
+
+	policy := fastRetryPolicy(1)
+	policy.RetryableStatusCodes = []int{http.StatusTooManyRequests}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: policy})
+
+	supported, err := client.GetSupported(context.Background())
+	if err != nil {
+		t.Fatalf("Expected success after retry, got %v", err)
+	}
+	if len(supported.Kinds) != 1 {
+		t.Errorf("Expected 1 kind, got %d", len(supported.Kinds))
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestRetrySettleNotRetriedOnStatus(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 1, http.StatusServiceUnavailable, x402.SettleResponse{Success: true, Transaction: "0xtx"})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: fastRetryPolicy(3)})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	if _, err := client.Settle(context.Background(), payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected settle error without retry")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("Expected settle to be attempted once, got %d", got)
+	}
+}
+
+func TestRetrySettleRetriedOnDialError(t *testing.T) {
+	server, calls := flakyFacilitatorServer(t, 0, http.StatusOK, x402.SettleResponse{Success: true, Transaction: "0xtx"})
+
+	transport := &dialFailingTransport{failures: 2, next: http.DefaultTransport}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:         server.URL,
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: fastRetryPolicy(3),
+	})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	resp, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Expected settle to succeed after dial retries, got %v", err)
+	}
+	if resp.Transaction != "0xtx" {
+		t.Errorf("Expected transaction 0xtx, got %s", resp.Transaction)
+	}
+	if got := atomic.LoadInt32(&transport.calls); got != 3 {
+		t.Errorf("Expected 3 transport attempts, got %d", got)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("Expected the facilitator to receive settle once, got %d", got)
+	}
+}
+
+func TestRetryRespectsContextDeadline(t *testing.T) {
+	server, _ := flakyFacilitatorServer(t, 100, http.StatusServiceUnavailable, x402.VerifyResponse{IsValid: true})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:         server.URL,
+		RetryPolicy: RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: time.Second},
+	})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Verify(ctx, payloadBytes, requirementsBytes)
+	if err == nil {
+		t.Fatal("Expected error when context expires during backoff")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected retries to abort promptly, took %v", elapsed)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for attempt, want := range expected {
+		if got := policy.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+
+	var defaults RetryPolicy
+	if got := defaults.backoff(0); got != defaultRetryBaseDelay {
+		t.Errorf("Expected default base delay %v, got %v", defaultRetryBaseDelay, got)
+	}
+}
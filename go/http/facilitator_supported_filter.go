@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Filtered GetSupported
+// ============================================================================
+
+// SupportedFilter selects the payment kinds GetSupportedFiltered returns.
+// Empty fields match every kind.
+type SupportedFilter struct {
+	// Networks keeps kinds on any of these networks, compared case-insensitively
+	Networks []string
+
+	// Schemes keeps kinds of any of these schemes, compared case-insensitively
+	Schemes []string
+
+	// X402Version keeps kinds of this protocol version (zero matches any version)
+	X402Version int
+}
+
+// IsEmpty reports whether the filter matches every kind
+func (f SupportedFilter) IsEmpty() bool {
+	return len(f.Networks) == 0 && len(f.Schemes) == 0 && f.X402Version == 0
+}
+
+// Matches reports whether the kind passes the filter
+func (f SupportedFilter) Matches(kind x402.SupportedKind) bool {
+	if f.X402Version != 0 && kind.X402Version != f.X402Version {
+		return false
+	}
+	return matchesAny(f.Networks, kind.Network) && matchesAny(f.Schemes, kind.Scheme)
+}
+
+// Apply returns a copy of the response holding only the kinds that pass the filter.
+// Extensions and signers are kept as they are.
+func (f SupportedFilter) Apply(response x402.SupportedResponse) x402.SupportedResponse {
+	kinds := make([]x402.SupportedKind, 0, len(response.Kinds))
+	for _, kind := range response.Kinds {
+		if f.Matches(kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	response.Kinds = kinds
+	return response
+}
+
+// params returns the x402.supported params carrying the filter
+func (f SupportedFilter) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if len(f.Networks) > 0 {
+		params["networks"] = f.Networks
+	}
+	if len(f.Schemes) > 0 {
+		params["schemes"] = f.Schemes
+	}
+	if f.X402Version != 0 {
+		params["x402Version"] = f.X402Version
+	}
+	return params
+}
+
+// matchesAny reports whether value is in values (case-insensitively), or values is empty
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSupportedFiltered returns the supported payment kinds that pass the filter.
+//
+// The filter is sent as {networks, schemes, x402Version} params so the facilitator can
+// return only the matching kinds. When the facilitator rejects the params, it falls back to
+// GetSupported and filters the full response locally, and keeps using the fallback for the
+// lifetime of the client. The response is filtered locally in either case, so facilitators
+// that ignore the params still yield only matching kinds.
+func (c *HTTPFacilitatorClient) GetSupportedFiltered(ctx context.Context, filter SupportedFilter) (x402.SupportedResponse, error) {
+	if filter.IsEmpty() || c.supportedFilterUnsupported.Load() {
+		return c.getSupportedAndFilter(ctx, filter)
+	}
+
+	response, supported, err := c.getSupportedWithParams(ctx, filter)
+	if err != nil {
+		return x402.SupportedResponse{}, err
+	}
+	if !supported {
+		c.supportedFilterUnsupported.Store(true)
+		return c.getSupportedAndFilter(ctx, filter)
+	}
+	return filter.Apply(response), nil
+}
+
+// getSupportedAndFilter fetches every supported kind and filters them locally
+func (c *HTTPFacilitatorClient) getSupportedAndFilter(ctx context.Context, filter SupportedFilter) (x402.SupportedResponse, error) {
+	response, err := c.GetSupported(ctx)
+	if err != nil {
+		return x402.SupportedResponse{}, err
+	}
+	return filter.Apply(response), nil
+}
+
+// getSupportedWithParams sends the filter as x402.supported params.
+// Returns false when the facilitator does not accept them.
+func (c *HTTPFacilitatorClient) getSupportedWithParams(ctx context.Context, filter SupportedFilter) (_ x402.SupportedResponse, _ bool, err error) {
+	start := time.Now()
+	defer func() { c.recordCall(operationSupported, start, err) }()
+
+	request, err := c.codec.EncodeRequest(envelopeRequest(operationSupported, filter.params()))
+	if err != nil {
+		return x402.SupportedResponse{}, false, err
+	}
+
+	statusCode, responseBody, err := c.doRequest(ctx, operationSupported, request)
+	if err != nil {
+		return x402.SupportedResponse{}, false, err
+	}
+
+	// Server errors say nothing about the params; anything else that fails means the
+	// facilitator only understands the plain request
+	response, err := decodeSupportedResponse(c.codec, statusCode, responseBody)
+	if err != nil {
+		if statusCode >= http.StatusInternalServerError {
+			return x402.SupportedResponse{}, false, err
+		}
+		return x402.SupportedResponse{}, false, nil
+	}
+	return response, true, nil
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// supportedTestKinds is the full catalog of the filter test facilitators
+var supportedTestKinds = []x402.SupportedKind{
+	{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+	{X402Version: 2, Scheme: "upto", Network: "eip155:1"},
+	{X402Version: 2, Scheme: "exact", Network: "eip155:8453"},
+	{X402Version: 1, Scheme: "exact", Network: "base"},
+}
+
+// supportedFilterParams are the params of an x402.supported request
+type supportedFilterParams struct {
+	Networks    []string `json:"networks"`
+	Schemes     []string `json:"schemes"`
+	X402Version int      `json:"x402Version"`
+}
+
+// supportedFilterServer answers x402.supported requests with the full catalog. Filtered
+// requests are filtered when acceptParams, otherwise rejected with a business error.
+func supportedFilterServer(t *testing.T, acceptParams bool, requests *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		var req struct {
+			Action string                `json:"action"`
+			Params supportedFilterParams `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Action != "x402.supported" {
+			t.Errorf("unexpected request: %v", err)
+		}
+
+		filter := SupportedFilter{Networks: req.Params.Networks, Schemes: req.Params.Schemes, X402Version: req.Params.X402Version}
+		w.Header().Set("Content-Type", "application/json")
+		if !filter.IsEmpty() && !acceptParams {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":40001,"msg":"unknown params"}`))
+			return
+		}
+		response := x402.SupportedResponse{Kinds: supportedTestKinds, Extensions: []string{"bazaar"}}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": filter.Apply(response)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSupportedFilterMatches(t *testing.T) {
+	kind := x402.SupportedKind{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}
+	tests := []struct {
+		name   string
+		filter SupportedFilter
+		want   bool
+	}{
+		{name: "empty", filter: SupportedFilter{}, want: true},
+		{name: "network", filter: SupportedFilter{Networks: []string{"eip155:1", "EIP155:8453"}}, want: true},
+		{name: "other network", filter: SupportedFilter{Networks: []string{"eip155:1"}}, want: false},
+		{name: "scheme", filter: SupportedFilter{Schemes: []string{"Exact"}}, want: true},
+		{name: "other scheme", filter: SupportedFilter{Schemes: []string{"upto"}}, want: false},
+		{name: "version", filter: SupportedFilter{X402Version: 2}, want: true},
+		{name: "other version", filter: SupportedFilter{X402Version: 1}, want: false},
+		{name: "all fields", filter: SupportedFilter{Networks: []string{"eip155:8453"}, Schemes: []string{"exact"}, X402Version: 2}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(kind); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHTTPFacilitatorClientGetSupportedFiltered(t *testing.T) {
+	ctx := context.Background()
+	filter := SupportedFilter{Networks: []string{"eip155:1"}, Schemes: []string{"exact"}}
+	want := []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}
+
+	tests := []struct {
+		name         string
+		acceptParams bool
+		wantRequests int32 // for two calls
+	}{
+		{name: "server filter", acceptParams: true, wantRequests: 2},
+		{name: "client fallback", acceptParams: false, wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := supportedFilterServer(t, tt.acceptParams, &requests)
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+
+			for i := 0; i < 2; i++ {
+				response, err := client.GetSupportedFiltered(ctx, filter)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(response.Kinds, want) {
+					t.Errorf("Expected kinds %+v, got %+v", want, response.Kinds)
+				}
+				if len(response.Extensions) != 1 || response.Extensions[0] != "bazaar" {
+					t.Errorf("Expected the extensions to be kept, got %v", response.Extensions)
+				}
+			}
+
+			// The fallback is remembered, so only the first call sends the params
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, got)
+			}
+		})
+	}
+}
+
+func TestHTTPFacilitatorClientGetSupportedFilteredIgnoredParams(t *testing.T) {
+	// A facilitator that ignores the params returns the full catalog, which is filtered locally
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		data := x402.SupportedResponse{Kinds: supportedTestKinds}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+	response, err := client.GetSupportedFiltered(context.Background(), SupportedFilter{X402Version: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []x402.SupportedKind{{X402Version: 1, Scheme: "exact", Network: "base"}}
+	if !reflect.DeepEqual(response.Kinds, want) {
+		t.Errorf("Expected kinds %+v, got %+v", want, response.Kinds)
+	}
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ============================================================================
+// Certificate Pinning
+// ============================================================================
+
+// ErrCertificateNotPinned is returned when the facilitator's leaf certificate matches none
+// of FacilitatorConfig.PinnedCertSHA256
+var ErrCertificateNotPinned = errors.New("facilitator certificate is not pinned")
+
+// errPinningUnsupported fails every request of a client whose Transport cannot be pinned
+var errPinningUnsupported = errors.New("certificate pinning requires Transport to be an *http.Transport")
+
+// normalizeCertPins returns the pins as lowercase hex without separators, so fingerprints
+// copied from openssl ("AB:CD:...") and plain hex both match
+func normalizeCertPins(pins []string) map[string]bool {
+	if len(pins) == 0 {
+		return nil
+	}
+	normalized := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		pin = strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(pin)))
+		normalized[pin] = true
+	}
+	return normalized
+}
+
+// pinCertificates installs a VerifyConnection check of the leaf certificate on transport's TLS
+// config. It runs after the usual chain verification, so a pinned certificate must still be trusted.
+func pinCertificates(transport *http.Transport, pins map[string]bool) {
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+
+	verify := tlsConfig.VerifyConnection
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if verify != nil {
+			if err := verify(state); err != nil {
+				return err
+			}
+		}
+		if len(state.PeerCertificates) == 0 {
+			return ErrCertificateNotPinned
+		}
+		fingerprint := sha256.Sum256(state.PeerCertificates[0].Raw)
+		if !pins[hex.EncodeToString(fingerprint[:])] {
+			return fmt.Errorf("%w: sha256 %x", ErrCertificateNotPinned, fingerprint)
+		}
+		return nil
+	}
+	transport.TLSClientConfig = tlsConfig
+}
+
+// failingTransport fails every request with err
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return nil, t.err
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPinnedCertSHA256(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "data": map[string]interface{}{"kinds": []interface{}{}}})
+	}))
+	defer server.Close()
+
+	// The transport trusts the self-signed certificate; pinning narrows it further
+	transport := server.Client().Transport
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	pin := hex.EncodeToString(fingerprint[:])
+	var colonPin []string
+	for i := 0; i < len(pin); i += 2 {
+		colonPin = append(colonPin, strings.ToUpper(pin[i:i+2]))
+	}
+
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr bool
+	}{
+		{name: "unpinned", pins: nil},
+		{name: "pinned", pins: []string{pin}},
+		{name: "pinned openssl format", pins: []string{strings.Repeat("00", 32), strings.Join(colonPin, ":")}},
+		{name: "other certificate", pins: []string{strings.Repeat("ab", 32)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+				URL:              server.URL,
+				Transport:        transport,
+				PinnedCertSHA256: tt.pins,
+			})
+			_, err := client.GetSupported(context.Background())
+			if tt.wantErr {
+				if !errors.Is(err, ErrCertificateNotPinned) {
+					t.Errorf("Expected ErrCertificateNotPinned, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected the connection to succeed, got %v", err)
+			}
+		})
+	}
+
+	t.Run("transport cannot be pinned", func(t *testing.T) {
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:              server.URL,
+			Transport:        &headerRecordingTransport{next: transport},
+			PinnedCertSHA256: []string{pin},
+		})
+		if _, err := client.GetSupported(context.Background()); !errors.Is(err, errPinningUnsupported) {
+			t.Errorf("Expected errPinningUnsupported, got %v", err)
+		}
+	})
+}
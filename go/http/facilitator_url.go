@@ -0,0 +1,26 @@
+package http
+
+import "context"
+
+// ============================================================================
+// Per-Call Facilitator URL
+// ============================================================================
+
+type facilitatorURLContextKey struct{}
+
+// WithFacilitatorURL returns a context whose Verify, Settle, VerifyBatch and GetSupported
+// calls are sent to url instead of FacilitatorConfig.URL, e.g. to keep a verify on the
+// replica that will settle it. Codec paths are appended to url as usual, and signing,
+// auth headers, retries and the circuit breakers are those of the client. An empty url
+// keeps the configured one.
+func WithFacilitatorURL(ctx context.Context, url string) context.Context {
+	return context.WithValue(ctx, facilitatorURLContextKey{}, url)
+}
+
+// baseURL returns the facilitator URL for a call: the context override, else the configured URL
+func (c *HTTPFacilitatorClient) baseURL(ctx context.Context) string {
+	if url, ok := ctx.Value(facilitatorURLContextKey{}).(string); ok && url != "" {
+		return url
+	}
+	return c.url
+}
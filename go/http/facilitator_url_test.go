@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recordingFacilitator answers every verify request as valid and records the requests it received
+type recordingFacilitator struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func newRecordingFacilitator(t *testing.T) *recordingFacilitator {
+	t.Helper()
+	f := &recordingFacilitator{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.Clone(context.Background()))
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"code":0,"msg":"","data":{"isValid":true,"payer":"0xpayer"}}`)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *recordingFacilitator) received() []*http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*http.Request(nil), f.requests...)
+}
+
+func TestHTTPFacilitatorClientWithFacilitatorURL(t *testing.T) {
+	primary := newRecordingFacilitator(t)
+	replica := newRecordingFacilitator(t)
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 primary.URL,
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+	})
+	payload, requirements := retryTestPayload(t)
+
+	if _, err := client.Verify(WithFacilitatorURL(context.Background(), replica.URL), payload, requirements); err != nil {
+		t.Fatalf("Verify with URL override failed: %v", err)
+	}
+	if got := len(primary.received()); got != 0 {
+		t.Errorf("Expected no requests to the configured URL, got %d", got)
+	}
+	requests := replica.received()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request to the override URL, got %d", len(requests))
+	}
+	if requests[0].Header.Get("X-Api-Key") != "ak" || requests[0].Header.Get("X-Signature") == "" {
+		t.Errorf("Expected the override request to stay signed, got headers %v", requests[0].Header)
+	}
+
+	// Calls without an override, or with an empty one, use the configured URL
+	for _, ctx := range []context.Context{context.Background(), WithFacilitatorURL(context.Background(), "")} {
+		if _, err := client.Verify(ctx, payload, requirements); err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+	}
+	if got := len(primary.received()); got != 2 {
+		t.Errorf("Expected 2 requests to the configured URL, got %d", got)
+	}
+	if got := len(replica.received()); got != 1 {
+		t.Errorf("Expected the override to apply to one call only, got %d replica requests", got)
+	}
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// Local Payment Validation
+// ============================================================================
+
+// FieldError is one structural problem found by local payment validation
+type FieldError struct {
+	Field   string // JSON path, e.g. "paymentPayload.accepted.amount"
+	Message string
+}
+
+// PaymentValidationError is returned, wrapped in a FacilitatorError of kind
+// FacilitatorErrorInvalidRequest, when FacilitatorConfig.ValidatePayments rejects a
+// payload or requirements before they are sent
+type PaymentValidationError struct {
+	Fields []FieldError
+}
+
+// Error implements the error interface
+func (e *PaymentValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		problems[i] = field.Field + ": " + field.Message
+	}
+	return "invalid payment: " + strings.Join(problems, "; ")
+}
+
+// validatePayment checks the structure of a verify or settle request against the x402
+// schema of its version. Returns nil or a *PaymentValidationError listing every problem.
+//
+// Requirements (and the V2 accepted requirements) are decoded into their types and checked
+// with types.PaymentRequirements.ValidateAllowZero, then for an asset and a positive
+// maxTimeoutSeconds; V1 requirements must also name a resource. V2: paymentPayload has
+// x402Version 2, a payload object and accepted requirements. V1: paymentPayload has
+// x402Version 1, scheme, network and a payload object.
+func validatePayment(version int, payloadBytes, requirementsBytes []byte) error {
+	v := &paymentValidator{}
+
+	payload := v.object("paymentPayload", payloadBytes)
+
+	if payload != nil {
+		if n, ok := payload["x402Version"].(float64); !ok || int(n) != version {
+			v.add("paymentPayload.x402Version", fmt.Sprintf("must be %d", version))
+		}
+		if _, ok := payload["payload"].(map[string]interface{}); !ok {
+			v.add("paymentPayload.payload", "must be an object")
+		}
+	}
+
+	switch version {
+	case 2:
+		if payload != nil {
+			if _, ok := payload["accepted"].(map[string]interface{}); !ok {
+				v.add("paymentPayload.accepted", "must be an object")
+			} else {
+				accepted, _ := json.Marshal(payload["accepted"])
+				v.requirementsV2("paymentPayload.accepted", accepted)
+			}
+			if resource, present := payload["resource"]; present && resource != nil {
+				if resource, ok := resource.(map[string]interface{}); !ok {
+					v.add("paymentPayload.resource", "must be an object")
+				} else {
+					v.optionalString("paymentPayload.resource.url", resource, "url")
+				}
+			}
+			if extensions, present := payload["extensions"]; present && extensions != nil {
+				if _, ok := extensions.(map[string]interface{}); !ok {
+					v.add("paymentPayload.extensions", "must be an object")
+				}
+			}
+		}
+		v.requirementsV2("paymentRequirements", requirementsBytes)
+
+	case 1:
+		if payload != nil {
+			v.nonEmptyString("paymentPayload.scheme", payload, "scheme")
+			v.nonEmptyString("paymentPayload.network", payload, "network")
+		}
+		v.requirementsV1("paymentRequirements", requirementsBytes)
+
+	default:
+		v.add("paymentPayload.x402Version", fmt.Sprintf("unsupported version %d", version))
+	}
+
+	if len(v.fields) > 0 {
+		return &PaymentValidationError{Fields: v.fields}
+	}
+	return nil
+}
+
+// paymentValidator collects field errors
+type paymentValidator struct {
+	fields []FieldError
+}
+
+func (v *paymentValidator) add(field, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+// object decodes data as a JSON object, recording an error and returning nil otherwise
+func (v *paymentValidator) object(field string, data []byte) map[string]interface{} {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		v.add(field, "must be a JSON object")
+		return nil
+	}
+	return m
+}
+
+// decode unmarshals data into dest, recording a field error when it does not fit.
+// Returns false when nothing could be decoded.
+func (v *paymentValidator) decode(prefix string, data []byte, dest interface{}) bool {
+	err := json.Unmarshal(data, dest)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		// The rest of dest is still decoded
+		v.add(prefix+"."+typeErr.Field, "must be "+jsonTypeName(typeErr.Type))
+		return true
+	default:
+		v.add(prefix, "must be a JSON object")
+		return false
+	}
+}
+
+func (v *paymentValidator) requirementsV2(prefix string, data []byte) {
+	var requirements types.PaymentRequirements
+	if !v.decode(prefix, data, &requirements) {
+		return
+	}
+	v.requirements(prefix, requirements, nil)
+}
+
+func (v *paymentValidator) requirementsV1(prefix string, data []byte) {
+	var requirements types.PaymentRequirementsV1
+	if !v.decode(prefix, data, &requirements) {
+		return
+	}
+	v.requirements(prefix, types.PaymentRequirements{
+		Scheme:            requirements.Scheme,
+		Network:           requirements.Network,
+		Asset:             requirements.Asset,
+		Amount:            requirements.MaxAmountRequired,
+		PayTo:             requirements.PayTo,
+		MaxTimeoutSeconds: requirements.MaxTimeoutSeconds,
+	}, map[string]string{"amount": "maxAmountRequired"})
+	if requirements.Resource == "" {
+		v.add(prefix+".resource", "is required")
+	}
+}
+
+// requirements records the problems found by ValidateAllowZero, with the fields renamed
+// by fieldNames, and the fields the schema requires beyond it
+func (v *paymentValidator) requirements(prefix string, requirements types.PaymentRequirements, fieldNames map[string]string) {
+	if joined, ok := requirements.ValidateAllowZero().(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			var requirementsErr *types.RequirementsError
+			if !errors.As(err, &requirementsErr) {
+				continue
+			}
+			field := requirementsErr.Field
+			if name, ok := fieldNames[field]; ok {
+				field = name
+			}
+			v.add(prefix+"."+field, requirementsErr.Message)
+		}
+	}
+	if requirements.Asset == "" {
+		v.add(prefix+".asset", "is required")
+	}
+	if requirements.MaxTimeoutSeconds <= 0 {
+		v.add(prefix+".maxTimeoutSeconds", "must be a positive integer")
+	}
+}
+
+func (v *paymentValidator) nonEmptyString(field string, m map[string]interface{}, key string) {
+	s, ok := m[key].(string)
+	switch {
+	case m[key] == nil:
+		v.add(field, "is required")
+	case !ok:
+		v.add(field, "must be a string")
+	case s == "":
+		v.add(field, "must not be empty")
+	}
+}
+
+func (v *paymentValidator) optionalString(field string, m map[string]interface{}, key string) {
+	if value, present := m[key]; present && value != nil {
+		if _, ok := value.(string); !ok {
+			v.add(field, "must be a string")
+		}
+	}
+}
+
+// jsonTypeName names the JSON type a Go value of type t decodes from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a " + t.String()
+	}
+}
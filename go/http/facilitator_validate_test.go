@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+func TestHTTPFacilitatorClientValidatePayments(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:              server.URL,
+		Codec:            RESTEnvelopeCodec{},
+		ValidatePayments: true,
+	})
+
+	requirements := `{"scheme":"exact","network":"eip155:1","asset":"0xasset","amount":"1000","payTo":"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF","maxTimeoutSeconds":60}`
+	validPayload := `{"x402Version":2,"payload":{"signature":"0xsig"},"accepted":` + requirements + `}`
+
+	t.Run("valid payment is sent", func(t *testing.T) {
+		requests.Store(0)
+		if _, err := client.Verify(context.Background(), []byte(validPayload), []byte(requirements)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected 1 request, got %d", requests.Load())
+		}
+	})
+
+	tests := []struct {
+		name         string
+		payload      string
+		requirements string
+		wantField    string
+	}{
+		{
+			name:         "missing payload object",
+			payload:      `{"x402Version":2,"accepted":` + requirements + `}`,
+			requirements: requirements,
+			wantField:    "paymentPayload.payload",
+		},
+		{
+			name:         "missing accepted",
+			payload:      `{"x402Version":2,"payload":{}}`,
+			requirements: requirements,
+			wantField:    "paymentPayload.accepted",
+		},
+		{
+			name:         "non-decimal amount",
+			payload:      validPayload,
+			requirements: strings.Replace(requirements, `"1000"`, `"1.5"`, 1),
+			wantField:    "paymentRequirements.amount",
+		},
+		{
+			name:         "numeric amount",
+			payload:      strings.Replace(validPayload, `"1000"`, `1000`, 1),
+			requirements: requirements,
+			wantField:    "paymentPayload.accepted.amount",
+		},
+		{
+			name:         "empty payTo",
+			payload:      validPayload,
+			requirements: strings.Replace(requirements, `"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"`, `""`, 1),
+			wantField:    "paymentRequirements.payTo",
+		},
+		{
+			name:         "non-hex payTo",
+			payload:      validPayload,
+			requirements: strings.Replace(requirements, `"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"`, `"0xpayto"`, 1),
+			wantField:    "paymentRequirements.payTo",
+		},
+		{
+			name:         "unknown scheme",
+			payload:      strings.Replace(validPayload, `"exact"`, `"stream"`, 1),
+			requirements: requirements,
+			wantField:    "paymentPayload.accepted.scheme",
+		},
+		{
+			name:         "fractional timeout",
+			payload:      validPayload,
+			requirements: strings.Replace(requirements, `60`, `1.5`, 1),
+			wantField:    "paymentRequirements.maxTimeoutSeconds",
+		},
+		{
+			name:         "requirements not an object",
+			payload:      validPayload,
+			requirements: `[]`,
+			wantField:    "paymentRequirements",
+		},
+		{
+			name:         "v1 missing resource",
+			payload:      `{"x402Version":1,"scheme":"exact","network":"base","payload":{}}`,
+			requirements: `{"scheme":"exact","network":"base","maxAmountRequired":"1000","asset":"0xasset","payTo":"0xpayto","maxTimeoutSeconds":60}`,
+			wantField:    "paymentRequirements.resource",
+		},
+		{
+			name:         "v1 non-decimal amount",
+			payload:      `{"x402Version":1,"scheme":"exact","network":"base","payload":{}}`,
+			requirements: `{"scheme":"exact","network":"base","maxAmountRequired":"0x10","asset":"0xasset","payTo":"0xpayto","resource":"https://api.example.com","maxTimeoutSeconds":60}`,
+			wantField:    "paymentRequirements.maxAmountRequired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+
+			_, err := client.Verify(context.Background(), []byte(tt.payload), []byte(tt.requirements))
+
+			var facilitatorErr *FacilitatorError
+			if !errors.As(err, &facilitatorErr) || facilitatorErr.Kind != FacilitatorErrorInvalidRequest {
+				t.Fatalf("Expected invalid request error, got %v", err)
+			}
+			if facilitatorErr.HTTPStatus != http.StatusBadRequest || facilitatorErr.Retryable() {
+				t.Errorf("Expected non-retryable 400, got %d", facilitatorErr.HTTPStatus)
+			}
+			var validationErr *PaymentValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected *PaymentValidationError, got %v", err)
+			}
+			found := false
+			for _, field := range validationErr.Fields {
+				found = found || field.Field == tt.wantField
+			}
+			if !found {
+				t.Errorf("Expected error for %s, got %v", tt.wantField, validationErr.Fields)
+			}
+			if requests.Load() != 0 {
+				t.Errorf("Expected no request to be sent, got %d", requests.Load())
+			}
+		})
+	}
+
+	t.Run("settle is validated", func(t *testing.T) {
+		requests.Store(0)
+		_, err := client.Settle(context.Background(), []byte(`{"x402Version":2}`), []byte(requirements))
+		var validationErr *PaymentValidationError
+		if !errors.As(err, &validationErr) || requests.Load() != 0 {
+			t.Errorf("Expected local validation error, got %v after %d requests", err, requests.Load())
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		requests.Store(0)
+		unvalidated := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Codec: RESTEnvelopeCodec{}})
+		if _, err := unvalidated.Verify(context.Background(), []byte(`{"x402Version":2}`), []byte(`{}`)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected the request to be sent, got %d", requests.Load())
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// Request Signing
+// ============================================================================
+
+// RequestSigner signs outbound facilitator requests
+// Implement this to use a facilitator auth scheme other than Gate Web3 HMAC,
+// or to load keys from a vault/KMS instead of the environment.
+type RequestSigner interface {
+	// Sign sets authentication headers on req.
+	// body is the exact request body that will be sent; targetURI is the logical
+	// operation path (e.g. "/v1/x402/verify").
+	Sign(req *http.Request, body []byte, targetURI string) error
+}
+
+// Gate Web3 signing path and logical target URIs (used for x-target-uri)
+const (
+	gateWeb3SigningPath          = "/api/v1/x402"
+	gateWeb3TargetURISupported   = "/v1/x402/supported"
+	gateWeb3TargetURIVerify      = "/v1/x402/verify"
+	gateWeb3TargetURISettle      = "/v1/x402/settle"
+	envGateWeb3APIKey            = "GATE_WEB3_API_KEY"
+	envGateWeb3APISecret         = "GATE_WEB3_API_SECRET"
+	envGateWeb3Passphrase        = "GATE_WEB3_PASSPHRASE"
+	envGateWeb3RealIP            = "GATE_WEB3_REAL_IP"
+	defaultGateWeb3ForwardedFor  = "127.0.0.1"
+	defaultGateWeb3Passphrase    = ""
+	defaultGateWeb3RequestIDPref = "req-"
+)
+
+// GateWeb3Algorithm is the HMAC hash of Gate Web3 request signatures
+type GateWeb3Algorithm string
+
+// Supported Gate Web3 signature algorithms
+const (
+	GateWeb3HMACSHA256 GateWeb3Algorithm = "HMAC-SHA256" // Default
+	GateWeb3HMACSHA512 GateWeb3Algorithm = "HMAC-SHA512"
+)
+
+// Placeholders of GateWeb3Signer.PrehashTemplate
+const (
+	GateWeb3PrehashTimestamp = "{timestamp}" // Signing timestamp in unix milliseconds
+	GateWeb3PrehashPath      = "{path}"      // Signing path
+	GateWeb3PrehashBody      = "{body}"      // Raw request body
+
+	defaultGateWeb3PrehashTemplate = GateWeb3PrehashTimestamp + GateWeb3PrehashPath + GateWeb3PrehashBody
+)
+
+// GateWeb3Signer signs requests using the Gate Web3 OpenAPI scheme (same logic as web3api.sh)
+//
+//	PREHASH   = <timestamp><SigningPath><rawBody>
+//	Signature = Base64(HMAC_SHA256(APISecret, PREHASH))
+//
+// Algorithm and PrehashTemplate adapt the signature to other API versions; the defaults
+// produce the signature above.
+//
+// Headers set: X-Api-Key, X-Timestamp, X-Signature, X-Passphrase, X-Request-Id, X-Forwarded-For, x-target-uri
+type GateWeb3Signer struct {
+	APIKey      string
+	APISecret   string
+	Passphrase  string        // Optional; X-Passphrase is omitted when empty
+	RealIP      string        // Optional; X-Forwarded-For is omitted when empty
+	ClockOffset time.Duration // Optional; added to the local clock for the signing timestamp
+	SigningPath string        // Optional; path in the prehash, defaults to /api/v1/x402
+
+	// Algorithm is the HMAC hash (optional, defaults to GateWeb3HMACSHA256)
+	Algorithm GateWeb3Algorithm
+
+	// PrehashTemplate lays out the prehash with the GateWeb3Prehash* placeholders (optional,
+	// defaults to "{timestamp}{path}{body}"). It must contain {timestamp} and {body}.
+	PrehashTemplate string
+}
+
+// GateWeb3Credentials holds Gate Web3 OpenAPI credentials supplied through configuration
+// Empty fields fall back to the corresponding GATE_WEB3_* environment variable.
+type GateWeb3Credentials struct {
+	APIKey     string
+	APISecret  string
+	Passphrase string
+	RealIP     string
+}
+
+// GateWeb3Paths overrides the Gate Web3 signing path and the logical target URIs sent in
+// x-target-uri, for when the gateway moves its API. Empty fields keep the defaults.
+type GateWeb3Paths struct {
+	SigningPath        string // Path in the signature prehash (defaults to /api/v1/x402)
+	VerifyTargetURI    string // Target URI of verify requests (defaults to /v1/x402/verify)
+	SettleTargetURI    string // Target URI of settle requests (defaults to /v1/x402/settle)
+	SupportedTargetURI string // Target URI of supported requests (defaults to /v1/x402/supported)
+}
+
+// NewGateWeb3SignerFromEnv loads AK/SK and related configuration from the environment.
+// Returns false when GATE_WEB3_API_KEY or GATE_WEB3_API_SECRET is missing.
+func NewGateWeb3SignerFromEnv() (*GateWeb3Signer, bool) {
+	return NewGateWeb3Signer(nil)
+}
+
+// NewGateWeb3Signer builds a signer from creds, using the environment for any field left empty.
+// Returns false when no API key or secret is available from either source.
+func NewGateWeb3Signer(creds *GateWeb3Credentials) (*GateWeb3Signer, bool) {
+	if creds == nil {
+		creds = &GateWeb3Credentials{}
+	}
+
+	ak := credentialOrEnv(creds.APIKey, envGateWeb3APIKey)
+	sk := credentialOrEnv(creds.APISecret, envGateWeb3APISecret)
+	if ak == "" || sk == "" {
+		return nil, false
+	}
+
+	pass := creds.Passphrase
+	if pass == "" {
+		pass = os.Getenv(envGateWeb3Passphrase)
+	}
+	if pass == "" {
+		pass = defaultGateWeb3Passphrase
+	}
+
+	realIP := creds.RealIP
+	if realIP == "" {
+		realIP = os.Getenv(envGateWeb3RealIP)
+	}
+	if realIP == "" {
+		realIP = defaultGateWeb3ForwardedFor
+	}
+
+	return &GateWeb3Signer{
+		APIKey:     ak,
+		APISecret:  sk,
+		Passphrase: pass,
+		RealIP:     realIP,
+	}, true
+}
+
+// credentialOrEnv returns the trimmed configured value, or the trimmed env var when it is empty
+func credentialOrEnv(value, envKey string) string {
+	if v := strings.TrimSpace(value); v != "" {
+		return v
+	}
+	return strings.TrimSpace(os.Getenv(envKey))
+}
+
+// Sign implements RequestSigner
+func (s *GateWeb3Signer) Sign(req *http.Request, body []byte, targetURI string) error {
+	if s.APIKey == "" || s.APISecret == "" {
+		return fmt.Errorf("gate web3 signer requires both API key and secret")
+	}
+
+	signingPath := s.SigningPath
+	if signingPath == "" {
+		signingPath = gateWeb3SigningPath
+	}
+
+	newHash, err := s.Algorithm.hash()
+	if err != nil {
+		return err
+	}
+	template := s.PrehashTemplate
+	if template == "" {
+		template = defaultGateWeb3PrehashTemplate
+	}
+	if !strings.Contains(template, GateWeb3PrehashTimestamp) || !strings.Contains(template, GateWeb3PrehashBody) {
+		return fmt.Errorf("gate web3 prehash template %q must contain %s and %s", template, GateWeb3PrehashTimestamp, GateWeb3PrehashBody)
+	}
+
+	timestamp := time.Now().Add(s.ClockOffset).UnixMilli()
+	prehash := strings.NewReplacer(
+		GateWeb3PrehashTimestamp, strconv.FormatInt(timestamp, 10),
+		GateWeb3PrehashPath, signingPath,
+		GateWeb3PrehashBody, string(body),
+	).Replace(template)
+
+	mac := hmac.New(newHash, []byte(s.APISecret))
+	_, _ = mac.Write([]byte(prehash))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	req.Header.Set("X-Api-Key", s.APIKey)
+	req.Header.Set("X-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Signature", signature)
+
+	if s.Passphrase != "" {
+		req.Header.Set("X-Passphrase", s.Passphrase)
+	}
+	if s.RealIP != "" {
+		req.Header.Set("X-Forwarded-For", s.RealIP)
+	}
+
+	// Request ID: random per attempt, for tracing only (see WithIdempotencyKey for a key that is stable across retries)
+	req.Header.Set("X-Request-Id", uuid.NewString())
+
+	// x-target-uri: remove leading slash per gateway expectation
+	req.Header.Set("x-target-uri", strings.TrimPrefix(targetURI, "/"))
+
+	return nil
+}
+
+// hash returns the hash constructor of the algorithm, with the empty algorithm meaning SHA-256
+func (a GateWeb3Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case "", GateWeb3HMACSHA256:
+		return sha256.New, nil
+	case GateWeb3HMACSHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported gate web3 signature algorithm %q", a)
+}
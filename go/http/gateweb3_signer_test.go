@@ -0,0 +1,454 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// recordingSigner records every Sign call and sets a marker header
+type recordingSigner struct {
+	mu         sync.Mutex
+	targetURIs []string
+	bodies     [][]byte
+	err        error
+}
+
+func (s *recordingSigner) Sign(req *http.Request, body []byte, targetURI string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.targetURIs = append(s.targetURIs, targetURI)
+	s.bodies = append(s.bodies, body)
+	req.Header.Set("X-Custom-Signature", "signed:"+targetURI)
+	return nil
+}
+
+// envelopeEchoServer returns data for each action and records the headers of every request
+func envelopeEchoServer(t *testing.T, onRequest func(r *http.Request, body []byte)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if onRequest != nil {
+			onRequest(r, body)
+		}
+
+		var envelope struct {
+			Action string `json:"action"`
+		}
+		_ = json.Unmarshal(body, &envelope)
+
+		var data interface{}
+		switch envelope.Action {
+		case "x402.verify":
+			data = x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}
+		case "x402.settle":
+			data = x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1"}
+		default:
+			data = x402.SupportedResponse{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "", "data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGateWeb3SignerSign(t *testing.T) {
+	signer := &GateWeb3Signer{APIKey: "ak", APISecret: "sk", Passphrase: "pass", RealIP: "10.0.0.1"}
+	body := []byte(`{"action":"x402.verify","params":{}}`)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	if err := signer.Sign(req, body, gateWeb3TargetURIVerify); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("sk"))
+	_, _ = mac.Write([]byte(req.Header.Get("X-Timestamp") + gateWeb3SigningPath + string(body)))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if got := req.Header.Get("X-Signature"); got != expected {
+		t.Errorf("Expected signature %s, got %s", expected, got)
+	}
+	if got := req.Header.Get("X-Api-Key"); got != "ak" {
+		t.Errorf("Expected X-Api-Key ak, got %s", got)
+	}
+	if got := req.Header.Get("X-Passphrase"); got != "pass" {
+		t.Errorf("Expected X-Passphrase pass, got %s", got)
+	}
+	if got := req.Header.Get("X-Forwarded-For"); got != "10.0.0.1" {
+		t.Errorf("Expected X-Forwarded-For 10.0.0.1, got %s", got)
+	}
+	if got := req.Header.Get("x-target-uri"); got != "v1/x402/verify" {
+		t.Errorf("Expected x-target-uri without leading slash, got %s", got)
+	}
+	if req.Header.Get("X-Request-Id") == "" {
+		t.Error("Expected X-Request-Id to be set")
+	}
+}
+
+func TestGateWeb3SignerRequiresCredentials(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	if err := (&GateWeb3Signer{APIKey: "ak"}).Sign(req, nil, gateWeb3TargetURIVerify); err == nil {
+		t.Error("Expected error when secret is missing")
+	}
+}
+
+func TestNewGateWeb3SignerFromEnv(t *testing.T) {
+	t.Setenv(envGateWeb3APIKey, "")
+	t.Setenv(envGateWeb3APISecret, "")
+	if _, ok := NewGateWeb3SignerFromEnv(); ok {
+		t.Error("Expected no signer without credentials")
+	}
+
+	t.Setenv(envGateWeb3APIKey, " ak ")
+	t.Setenv(envGateWeb3APISecret, "sk")
+	t.Setenv(envGateWeb3RealIP, "")
+	signer, ok := NewGateWeb3SignerFromEnv()
+	if !ok {
+		t.Fatal("Expected signer from environment")
+	}
+	if signer.APIKey != "ak" || signer.APISecret != "sk" {
+		t.Errorf("Unexpected credentials: %+v", signer)
+	}
+	if signer.RealIP != defaultGateWeb3ForwardedFor {
+		t.Errorf("Expected default real IP %s, got %s", defaultGateWeb3ForwardedFor, signer.RealIP)
+	}
+}
+
+func TestHTTPFacilitatorClientDefaultsToEnvSigner(t *testing.T) {
+	t.Setenv(envGateWeb3APIKey, "env-ak")
+	t.Setenv(envGateWeb3APISecret, "env-sk")
+
+	var apiKey string
+	server := envelopeEchoServer(t, func(r *http.Request, _ []byte) {
+		apiKey = r.Header.Get("X-Api-Key")
+	})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if apiKey != "env-ak" {
+		t.Errorf("Expected env credentials to sign the request, got X-Api-Key=%q", apiKey)
+	}
+}
+
+func TestHTTPFacilitatorClientCustomSigner(t *testing.T) {
+	t.Setenv(envGateWeb3APIKey, "env-ak")
+	t.Setenv(envGateWeb3APISecret, "env-sk")
+
+	var mu sync.Mutex
+	headers := map[string]http.Header{}
+	server := envelopeEchoServer(t, func(r *http.Request, body []byte) {
+		var envelope struct {
+			Action string `json:"action"`
+		}
+		_ = json.Unmarshal(body, &envelope)
+		mu.Lock()
+		headers[envelope.Action] = r.Header.Clone()
+		mu.Unlock()
+	})
+
+	signer := &recordingSigner{}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: signer})
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	ctx := context.Background()
+
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if _, err := client.GetSupported(ctx); err != nil {
+		t.Fatalf("GetSupported failed: %v", err)
+	}
+
+	expectedURIs := []string{gateWeb3TargetURIVerify, gateWeb3TargetURISettle, gateWeb3TargetURISupported}
+	if len(signer.targetURIs) != len(expectedURIs) {
+		t.Fatalf("Expected %d sign calls, got %d", len(expectedURIs), len(signer.targetURIs))
+	}
+	for i, uri := range expectedURIs {
+		if signer.targetURIs[i] != uri {
+			t.Errorf("Sign call %d: expected target %s, got %s", i, uri, signer.targetURIs[i])
+		}
+	}
+
+	for action, h := range headers {
+		if h.Get("X-Custom-Signature") == "" {
+			t.Errorf("%s: expected custom signature header", action)
+		}
+		if h.Get("X-Api-Key") != "" {
+			t.Errorf("%s: expected env signer to be bypassed, got X-Api-Key=%s", action, h.Get("X-Api-Key"))
+		}
+	}
+}
+
+func TestHTTPFacilitatorClientSignerError(t *testing.T) {
+	var calls int
+	server := envelopeEchoServer(t, func(*http.Request, []byte) { calls++ })
+
+	signErr := errors.New("vault unavailable")
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Signer: &recordingSigner{err: signErr}})
+
+	_, err := client.GetSupported(context.Background())
+	if !errors.Is(err, signErr) {
+		t.Fatalf("Expected signer error to be wrapped, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no request to be sent, got %d", calls)
+	}
+}
+
+func TestHTTPFacilitatorClientGateWeb3Credentials(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		creds          *GateWeb3Credentials
+		wantAPIKey     string
+		wantPassphrase string
+		wantRealIP     string
+	}{
+		{
+			name:       "config overrides env",
+			env:        map[string]string{envGateWeb3APIKey: "env-ak", envGateWeb3APISecret: "env-sk", envGateWeb3RealIP: "10.0.0.1"},
+			creds:      &GateWeb3Credentials{APIKey: "cfg-ak", APISecret: "cfg-sk", RealIP: "10.0.0.2"},
+			wantAPIKey: "cfg-ak",
+			wantRealIP: "10.0.0.2",
+		},
+		{
+			name:           "partial config falls back to env",
+			env:            map[string]string{envGateWeb3APIKey: "env-ak", envGateWeb3APISecret: "env-sk", envGateWeb3Passphrase: "env-pass"},
+			creds:          &GateWeb3Credentials{APIKey: "cfg-ak"},
+			wantAPIKey:     "cfg-ak",
+			wantPassphrase: "env-pass",
+			wantRealIP:     defaultGateWeb3ForwardedFor,
+		},
+		{
+			name:       "config only",
+			creds:      &GateWeb3Credentials{APIKey: "cfg-ak", APISecret: "cfg-sk"},
+			wantAPIKey: "cfg-ak",
+			wantRealIP: defaultGateWeb3ForwardedFor,
+		},
+		{
+			name:  "incomplete config and no env leaves request unsigned",
+			creds: &GateWeb3Credentials{APIKey: "cfg-ak"},
+		},
+		{
+			name: "no config and no env leaves request unsigned",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{envGateWeb3APIKey, envGateWeb3APISecret, envGateWeb3Passphrase, envGateWeb3RealIP} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			var header http.Header
+			var body []byte
+			server := envelopeEchoServer(t, func(r *http.Request, b []byte) {
+				header = r.Header.Clone()
+				body = b
+			})
+
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, GateWeb3Credentials: tt.creds})
+			if _, err := client.GetSupported(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := header.Get("X-Api-Key"); got != tt.wantAPIKey {
+				t.Errorf("Expected X-Api-Key %q, got %q", tt.wantAPIKey, got)
+			}
+			if got := header.Get("X-Passphrase"); got != tt.wantPassphrase {
+				t.Errorf("Expected X-Passphrase %q, got %q", tt.wantPassphrase, got)
+			}
+			if got := header.Get("X-Forwarded-For"); got != tt.wantRealIP {
+				t.Errorf("Expected X-Forwarded-For %q, got %q", tt.wantRealIP, got)
+			}
+			if tt.wantAPIKey == "" {
+				if header.Get("X-Signature") != "" {
+					t.Error("Expected no signature")
+				}
+				return
+			}
+
+			secret := tt.creds.APISecret
+			if secret == "" {
+				secret = tt.env[envGateWeb3APISecret]
+			}
+			mac := hmac.New(sha256.New, []byte(secret))
+			_, _ = mac.Write([]byte(header.Get("X-Timestamp") + gateWeb3SigningPath + string(body)))
+			if got, want := header.Get("X-Signature"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+				t.Errorf("Expected signature with secret %q", secret)
+			}
+		})
+	}
+}
+
+func TestGateWeb3SignerSigningPath(t *testing.T) {
+	body := []byte(`{"action":"x402.verify","params":{}}`)
+	sign := func(signingPath string) *http.Request {
+		signer := &GateWeb3Signer{APIKey: "ak", APISecret: "sk", SigningPath: signingPath}
+		req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+		if err := signer.Sign(req, body, gateWeb3TargetURIVerify); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return req
+	}
+
+	for _, tt := range []struct{ signingPath, wantPath string }{
+		{signingPath: "", wantPath: gateWeb3SigningPath},
+		{signingPath: "/api/v2/dex", wantPath: "/api/v2/dex"},
+	} {
+		req := sign(tt.signingPath)
+		mac := hmac.New(sha256.New, []byte("sk"))
+		_, _ = mac.Write([]byte(req.Header.Get("X-Timestamp") + tt.wantPath + string(body)))
+		if got, want := req.Header.Get("X-Signature"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("Expected signature over path %s, got %s", tt.wantPath, got)
+		}
+	}
+}
+
+func TestGateWeb3SignerAlgorithm(t *testing.T) {
+	body := []byte(`{"action":"x402.verify","params":{"note":"{timestamp}"}}`)
+	hmacOf := func(newHash func() hash.Hash, prehash string) string {
+		mac := hmac.New(newHash, []byte("sk"))
+		_, _ = mac.Write([]byte(prehash))
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name     string
+		signer   GateWeb3Signer
+		expected func(timestamp string) string
+	}{
+		{
+			name:     "default",
+			expected: func(ts string) string { return hmacOf(sha256.New, ts+gateWeb3SigningPath+string(body)) },
+		},
+		{
+			name:     "explicit SHA-256",
+			signer:   GateWeb3Signer{Algorithm: GateWeb3HMACSHA256, PrehashTemplate: "{timestamp}{path}{body}"},
+			expected: func(ts string) string { return hmacOf(sha256.New, ts+gateWeb3SigningPath+string(body)) },
+		},
+		{
+			name:     "SHA-512",
+			signer:   GateWeb3Signer{Algorithm: GateWeb3HMACSHA512},
+			expected: func(ts string) string { return hmacOf(sha512.New, ts+gateWeb3SigningPath+string(body)) },
+		},
+		{
+			name:     "custom prehash",
+			signer:   GateWeb3Signer{PrehashTemplate: "{path}\n{timestamp}\n{body}"},
+			expected: func(ts string) string { return hmacOf(sha256.New, gateWeb3SigningPath+"\n"+ts+"\n"+string(body)) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := tt.signer
+			signer.APIKey, signer.APISecret = "ak", "sk"
+			req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+			if err := signer.Sign(req, body, gateWeb3TargetURIVerify); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			timestamp := req.Header.Get("X-Timestamp")
+			if got, want := req.Header.Get("X-Signature"), tt.expected(timestamp); got != want {
+				t.Errorf("Expected signature %s, got %s", want, got)
+			}
+		})
+	}
+
+	// The same inputs give different signatures under SHA-256 and SHA-512
+	prehash := "1700000000000" + gateWeb3SigningPath + string(body)
+	if sha256Signature, sha512Signature := hmacOf(sha256.New, prehash), hmacOf(sha512.New, prehash); sha256Signature == sha512Signature {
+		t.Error("Expected SHA-256 and SHA-512 signatures to differ")
+	} else if decoded, _ := base64.StdEncoding.DecodeString(sha512Signature); len(decoded) != sha512.Size {
+		t.Errorf("Expected a %d-byte SHA-512 signature, got %d bytes", sha512.Size, len(decoded))
+	}
+
+	for name, signer := range map[string]*GateWeb3Signer{
+		"unknown algorithm":          {APIKey: "ak", APISecret: "sk", Algorithm: "HMAC-MD5"},
+		"template without body":      {APIKey: "ak", APISecret: "sk", PrehashTemplate: "{timestamp}{path}"},
+		"template without timestamp": {APIKey: "ak", APISecret: "sk", PrehashTemplate: "{path}{body}"},
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+		if err := signer.Sign(req, body, gateWeb3TargetURIVerify); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if req.Header.Get("X-Signature") != "" {
+			t.Errorf("%s: expected no signature header", name)
+		}
+	}
+}
+
+func TestHTTPFacilitatorClientGateWeb3Paths(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	var bodies [][]byte
+	server := envelopeEchoServer(t, func(r *http.Request, body []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+	})
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:                 server.URL,
+		GateWeb3Credentials: &GateWeb3Credentials{APIKey: "ak", APISecret: "sk"},
+		GateWeb3Paths: &GateWeb3Paths{
+			SigningPath:     "/api/v2/dex",
+			VerifyTargetURI: "/v2/dex/x402/verify",
+		},
+	})
+	payload, requirements := retryTestPayload(t)
+	if _, err := client.Verify(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	for i, wantTarget := range []string{"v2/dex/x402/verify", "v1/x402/supported"} {
+		if got := requests[i].Header.Get("x-target-uri"); got != wantTarget {
+			t.Errorf("Expected x-target-uri %s, got %s", wantTarget, got)
+		}
+		mac := hmac.New(sha256.New, []byte("sk"))
+		_, _ = mac.Write([]byte(requests[i].Header.Get("X-Timestamp") + "/api/v2/dex" + string(bodies[i])))
+		if got, want := requests[i].Header.Get("X-Signature"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("Expected signature over the custom signing path, got %s", got)
+		}
+	}
+
+	// Custom signers receive the configured target URIs too
+	signer := &recordingSigner{}
+	client = NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL:           server.URL,
+		Signer:        signer,
+		GateWeb3Paths: &GateWeb3Paths{SettleTargetURI: "/v2/dex/x402/settle"},
+	})
+	if _, err := client.Settle(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(signer.targetURIs) != 1 || signer.targetURIs[0] != "/v2/dex/x402/settle" {
+		t.Errorf("Expected custom signer target URI /v2/dex/x402/settle, got %v", signer.targetURIs)
+	}
+}
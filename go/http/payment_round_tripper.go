@@ -0,0 +1,207 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// Single-Scheme Payment Round Tripper
+// ============================================================================
+
+// PaymentCapExceededError is returned when every payment requirement the server offers
+// for the scheme asks for more than the spending cap
+type PaymentCapExceededError struct {
+	// MaxAmount is the spending cap, in the asset's smallest unit
+	MaxAmount string
+
+	// Requirements are the offered requirements that exceeded the cap
+	Requirements []x402.PaymentRequirements
+}
+
+func (e *PaymentCapExceededError) Error() string {
+	if len(e.Requirements) == 1 {
+		return fmt.Sprintf("payment of %s exceeds spending cap %s", e.Requirements[0].Amount, e.MaxAmount)
+	}
+	return fmt.Sprintf("all %d payment requirements exceed spending cap %s", len(e.Requirements), e.MaxAmount)
+}
+
+// SchemePaymentRoundTripper is an http.RoundTripper that pays for 402 responses with a
+// single SchemeNetworkClient, never paying more than a fixed cap per request.
+//
+// Unlike PaymentRoundTripper it needs no X402Client: on a 402 it reads the requirements
+// from the PAYMENT-REQUIRED header (or the JSON body), picks the first one for its scheme
+// within the cap, and replays the request once with the payload in X-PAYMENT.
+type SchemePaymentRoundTripper struct {
+	// Transport sends the requests (defaults to http.DefaultTransport)
+	Transport http.RoundTripper
+
+	// Policy is consulted before signing (optional). When it denies every candidate the
+	// original 402 response is returned to the caller unpaid.
+	Policy *SpendingPolicy
+
+	scheme    x402.SchemeNetworkClient
+	maxAmount string
+}
+
+// NewPaymentRoundTripper creates a round tripper that pays with scheme, up to maxAmount
+// (in the asset's smallest unit, like PaymentRequirements.Amount) per request
+func NewPaymentRoundTripper(scheme x402.SchemeNetworkClient, maxAmount string) *SchemePaymentRoundTripper {
+	return &SchemePaymentRoundTripper{
+		Transport: http.DefaultTransport,
+		scheme:    scheme,
+		maxAmount: maxAmount,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *SchemePaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusPaymentRequired {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	paymentRequired, err := parsePaymentRequired(resp.Header.Get("PAYMENT-REQUIRED"), body)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := req.URL.String()
+	if paymentRequired.Resource != nil && paymentRequired.Resource.URL != "" {
+		resource = paymentRequired.Resource.URL
+	}
+
+	requirements, err := t.selectRequirements(paymentRequired.Accepts, resource)
+	var denied *SpendingDeniedError
+	if errors.As(err, &denied) {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paymentReq, err := t.paymentRequest(req, requirements, paymentRequired)
+	if err != nil {
+		// The payment was never sent, so it must not count against the policy
+		if t.Policy != nil {
+			t.Policy.Release(requirements)
+		}
+		return nil, err
+	}
+
+	return transport.RoundTrip(paymentReq)
+}
+
+// paymentRequest signs a payment for requirements and returns req with it in X-PAYMENT
+func (t *SchemePaymentRoundTripper) paymentRequest(req *http.Request, requirements x402.PaymentRequirements, paymentRequired types.PaymentRequired) (*http.Request, error) {
+	payload, err := t.scheme.CreatePaymentPayload(req.Context(), requirements)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+	payload.X402Version = x402.ProtocolVersion
+	payload.Accepted = requirements
+	payload.Resource = paymentRequired.Resource
+	payload.Extensions = paymentRequired.Extensions
+
+	paymentHeader, err := types.EncodePaymentHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentReq := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("cannot replay request body for payment")
+		}
+		if paymentReq.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+	}
+	paymentReq.Header.Set("X-PAYMENT", paymentHeader)
+
+	return paymentReq, nil
+}
+
+// selectRequirements returns the first requirements for the scheme within the cap that the
+// policy allows, reserving them in the policy
+func (t *SchemePaymentRoundTripper) selectRequirements(accepts []x402.PaymentRequirements, resource string) (x402.PaymentRequirements, error) {
+	maxAmount, ok := new(big.Int).SetString(t.maxAmount, 10)
+	if !ok {
+		return x402.PaymentRequirements{}, fmt.Errorf("invalid spending cap: %q", t.maxAmount)
+	}
+
+	var overCap []x402.PaymentRequirements
+	var denied error
+	for _, requirements := range accepts {
+		if requirements.Scheme != t.scheme.Scheme() {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(requirements.Amount, 10)
+		if !ok {
+			continue
+		}
+		if amount.Cmp(maxAmount) > 0 {
+			overCap = append(overCap, requirements)
+			continue
+		}
+		if t.Policy != nil {
+			err := t.Policy.Reserve(resource, requirements)
+			var deniedErr *SpendingDeniedError
+			if errors.As(err, &deniedErr) {
+				if denied == nil {
+					denied = err
+				}
+				continue
+			}
+			if err != nil {
+				return x402.PaymentRequirements{}, err
+			}
+		}
+		return requirements, nil
+	}
+
+	if denied != nil {
+		return x402.PaymentRequirements{}, denied
+	}
+	if len(overCap) > 0 {
+		return x402.PaymentRequirements{}, &PaymentCapExceededError{MaxAmount: t.maxAmount, Requirements: overCap}
+	}
+	return x402.PaymentRequirements{}, fmt.Errorf("no payment requirements for scheme %s", t.scheme.Scheme())
+}
+
+// parsePaymentRequired reads a V2 PaymentRequired from the header, or from the body
+func parsePaymentRequired(header string, body []byte) (types.PaymentRequired, error) {
+	if header != "" {
+		paymentRequired, err := decodePaymentRequiredHeader(header)
+		if err != nil {
+			return types.PaymentRequired{}, fmt.Errorf("failed to decode payment required header: %w", err)
+		}
+		return paymentRequired, nil
+	}
+
+	var paymentRequired types.PaymentRequired
+	if err := json.Unmarshal(body, &paymentRequired); err != nil {
+		return types.PaymentRequired{}, fmt.Errorf("failed to parse payment required: %w", err)
+	}
+	return paymentRequired, nil
+}
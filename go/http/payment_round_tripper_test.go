@@ -0,0 +1,126 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/facilitatortest"
+)
+
+// paywalledServer serves a resource behind RequirePayment with an always-valid facilitator
+func paywalledServer(t *testing.T, amount string) (*httptest.Server, *facilitatortest.MockFacilitatorClient) {
+	t.Helper()
+	facilitator := facilitatortest.AlwaysValid()
+	requirements := x402.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  amount,
+		PayTo:   "0xrecipient",
+	}
+	handler := RequirePayment(requirements, facilitator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte("paid content"), body...))
+	}))
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, facilitator
+}
+
+func TestPaymentRoundTripperPaysAndRetries(t *testing.T) {
+	server, facilitator := paywalledServer(t, "1000")
+	client := &http.Client{Transport: NewPaymentRoundTripper(&mockSchemeClient{scheme: "exact"}, "5000")}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader(" for body"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || string(body) != "paid content for body" {
+		t.Fatalf("Expected paid content, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("X-PAYMENT-RESPONSE") == "" {
+		t.Errorf("Expected X-PAYMENT-RESPONSE header")
+	}
+
+	calls := facilitator.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected verify and settle, got %+v", calls)
+	}
+	var payload x402.PaymentPayload
+	if err := json.Unmarshal(calls[0].PayloadBytes, &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.X402Version != 2 || payload.Accepted.Amount != "1000" || payload.Payload["mock"] != "payload" {
+		t.Errorf("Unexpected payload sent to facilitator: %+v", payload)
+	}
+}
+
+func TestPaymentRoundTripperCapExceeded(t *testing.T) {
+	server, facilitator := paywalledServer(t, "10000")
+	client := &http.Client{Transport: NewPaymentRoundTripper(&mockSchemeClient{scheme: "exact"}, "5000")}
+
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Expected cap error, got status %d", resp.StatusCode)
+	}
+
+	var capErr *PaymentCapExceededError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("Expected *PaymentCapExceededError, got %v", err)
+	}
+	if capErr.MaxAmount != "5000" || len(capErr.Requirements) != 1 || capErr.Requirements[0].Amount != "10000" {
+		t.Errorf("Expected the offered requirements on the error, got %+v", capErr)
+	}
+	if len(facilitator.Calls()) != 0 {
+		t.Errorf("Expected no payment to be attempted")
+	}
+}
+
+func TestPaymentRoundTripperPassesThroughNon402(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("X-PAYMENT") != "" {
+			t.Errorf("Expected no payment header")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewPaymentRoundTripper(&mockSchemeClient{scheme: "exact"}, "5000")}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expected a single 500 response, got %d after %d requests", resp.StatusCode, requests)
+	}
+}
+
+func TestPaymentRoundTripperOtherScheme(t *testing.T) {
+	server, _ := paywalledServer(t, "1000")
+	client := &http.Client{Transport: NewPaymentRoundTripper(&mockSchemeClient{scheme: "upto"}, "5000")}
+
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Expected error for unsupported scheme")
+	}
+	var capErr *PaymentCapExceededError
+	if errors.As(err, &capErr) {
+		t.Errorf("Expected a plain error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Package prometheus provides a Prometheus implementation of x402http.MetricsCollector
+package prometheus
+
+import (
+	"strconv"
+
+	x402http "github.com/gatechain/x402/go/http"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector records facilitator calls as Prometheus metrics, labeled by operation
+// ("verify", "settle", "supported") and facilitator identifier:
+//
+//	x402_facilitator_requests_total{operation, facilitator, status}
+//	x402_facilitator_request_duration_seconds{operation, facilitator}
+//	x402_facilitator_errors_total{operation, facilitator, error_class}
+//
+// status is the final HTTP status code ("0" when no response was received).
+type Collector struct {
+	requests *prom.CounterVec
+	duration *prom.HistogramVec
+	errors   *prom.CounterVec
+}
+
+// NewCollector creates a Collector and registers its metrics with registerer
+// (prometheus.DefaultRegisterer when nil).
+//
+// Example:
+//
+//	metrics, err := prometheus.NewCollector(nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{Metrics: metrics})
+func NewCollector(registerer prom.Registerer) (*Collector, error) {
+	if registerer == nil {
+		registerer = prom.DefaultRegisterer
+	}
+
+	c := &Collector{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "x402",
+			Subsystem: "facilitator",
+			Name:      "requests_total",
+			Help:      "Facilitator calls by operation, facilitator and final HTTP status.",
+		}, []string{"operation", "facilitator", "status"}),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: "x402",
+			Subsystem: "facilitator",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of facilitator calls, including retries.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"operation", "facilitator"}),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "x402",
+			Subsystem: "facilitator",
+			Name:      "errors_total",
+			Help:      "Failed facilitator calls by operation, facilitator and error class.",
+		}, []string{"operation", "facilitator", "error_class"}),
+	}
+
+	for _, collector := range []prom.Collector{c.requests, c.duration, c.errors} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// RecordVerify implements x402http.MetricsCollector
+func (c *Collector) RecordVerify(call x402http.FacilitatorCall) {
+	c.record("verify", call)
+}
+
+// RecordSettle implements x402http.MetricsCollector
+func (c *Collector) RecordSettle(call x402http.FacilitatorCall) {
+	c.record("settle", call)
+}
+
+// RecordSupported implements x402http.MetricsCollector
+func (c *Collector) RecordSupported(call x402http.FacilitatorCall) {
+	c.record("supported", call)
+}
+
+func (c *Collector) record(operation string, call x402http.FacilitatorCall) {
+	c.requests.WithLabelValues(operation, call.Facilitator, strconv.Itoa(call.StatusCode)).Inc()
+	c.duration.WithLabelValues(operation, call.Facilitator).Observe(call.Duration.Seconds())
+	if call.ErrorClass != "" {
+		c.errors.WithLabelValues(operation, call.Facilitator, call.ErrorClass).Inc()
+	}
+}
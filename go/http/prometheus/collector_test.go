@@ -0,0 +1,86 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/gatechain/x402/go"
+	x402http "github.com/gatechain/x402/go/http"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectorRecordsFacilitatorCalls(t *testing.T) {
+	failSettle := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/verify":
+			_ = json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		case "/settle":
+			if failSettle {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"error":"unavailable"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(x402.SettleResponse{Success: true})
+		default:
+			_ = json.NewEncoder(w).Encode(x402.SupportedResponse{})
+		}
+	}))
+	defer server.Close()
+
+	registry := prom.NewRegistry()
+	collector, err := NewCollector(registry)
+	if err != nil {
+		t.Fatalf("NewCollector failed: %v", err)
+	}
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{
+		URL:        server.URL,
+		Codec:      x402http.RESTEnvelopeCodec{},
+		Identifier: "test-facilitator",
+		Metrics:    collector,
+	})
+
+	payloadBytes := []byte(`{"x402Version":2,"payload":{},"accepted":{"scheme":"exact","network":"eip155:1"}}`)
+	requirementsBytes := []byte(`{"scheme":"exact","network":"eip155:1"}`)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+	}
+	if _, err := client.Settle(context.Background(), payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected settle to fail")
+	}
+	if _, err := client.GetSupported(context.Background()); err != nil {
+		t.Fatalf("GetSupported failed: %v", err)
+	}
+
+	if got := testutil.ToFloat64(collector.requests.WithLabelValues("verify", "test-facilitator", "200")); got != 2 {
+		t.Errorf("verify requests = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(collector.requests.WithLabelValues("settle", "test-facilitator", "503")); got != 1 {
+		t.Errorf("settle requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(collector.errors.WithLabelValues("settle", "test-facilitator", "unavailable")); got != 1 {
+		t.Errorf("settle errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(collector.requests.WithLabelValues("supported", "test-facilitator", "200")); got != 1 {
+		t.Errorf("supported requests = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(collector.errors); got != 1 {
+		t.Errorf("expected errors only for settle, got %d series", got)
+	}
+	if got := testutil.CollectAndCount(collector.duration); got != 3 {
+		t.Errorf("expected a duration series per operation, got %d", got)
+	}
+
+	if _, err := NewCollector(registry); err == nil {
+		t.Error("expected an error registering the metrics twice")
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// net/http Payment Middleware
+// ============================================================================
+
+// RequirePayment returns net/http middleware that charges for every request with a single
+// set of payment requirements, for servers that do not need the route configuration of
+// HTTPServer.
+//
+// A request without an X-PAYMENT (or PAYMENT-SIGNATURE) header is answered with 402 and
+// the PaymentRequired JSON in the body (also base64-encoded in PAYMENT-REQUIRED). A header
+// that cannot be decoded is answered with 400. Otherwise the payment is verified and settled
+// through facilitator before the wrapped handler runs, with the settlement result in the
+// X-PAYMENT-RESPONSE header; a payment that fails either step is answered with 402.
+//
+// With WithSettleMode(SettleAsyncAfterResponse), verification still gates the handler, but
+// settlement runs in the background once the handler returns, and X-PAYMENT-RESPONSE is not
+// set. A payment header is then served once: repeats are answered with 402
+// "payment_already_used" while it settles, and until its authorization expires if
+// settlement fails.
+func RequirePayment(requirements x402.PaymentRequirements, facilitator x402.FacilitatorClient, opts ...RequirePaymentOption) func(http.Handler) http.Handler {
+	var config requirePaymentConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	var settler *asyncSettler
+	if config.settleMode == SettleAsyncAfterResponse {
+		settler = newAsyncSettler(facilitator, config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("X-PAYMENT")
+			if header == "" {
+				header = r.Header.Get("PAYMENT-SIGNATURE")
+			}
+			if header == "" {
+				writePaymentRequired(w, requirements, "payment required")
+				return
+			}
+
+			payloadBytes, err := decodeBase64Header(header)
+			if err == nil {
+				_, err = types.DetectVersion(payloadBytes)
+			}
+			if err != nil {
+				http.Error(w, "malformed payment header", http.StatusBadRequest)
+				return
+			}
+
+			requirementsBytes, err := json.Marshal(requirements)
+			if err != nil {
+				http.Error(w, "failed to encode payment requirements", http.StatusInternalServerError)
+				return
+			}
+
+			verifyResp, err := facilitator.Verify(r.Context(), payloadBytes, requirementsBytes)
+			if err != nil {
+				writePaymentRequired(w, requirements, paymentFailureReason(err, "verification_failed"))
+				return
+			}
+			if verifyResp == nil || !verifyResp.IsValid {
+				reason := "invalid_payment"
+				if verifyResp != nil && verifyResp.InvalidReason != "" {
+					reason = verifyResp.InvalidReason
+				}
+				writePaymentRequired(w, requirements, reason)
+				return
+			}
+
+			if settler != nil {
+				if !settler.claim(payloadBytes) {
+					writePaymentRequired(w, requirements, "payment_already_used")
+					return
+				}
+				next.ServeHTTP(w, r)
+				settler.settle(r.Context(), payloadBytes, requirementsBytes)
+				return
+			}
+
+			settleResp, err := facilitator.Settle(r.Context(), payloadBytes, requirementsBytes)
+			if err != nil {
+				writePaymentRequired(w, requirements, paymentFailureReason(err, "settlement_failed"))
+				return
+			}
+			if settleResp == nil || !settleResp.Success {
+				reason := "settlement_failed"
+				if settleResp != nil && settleResp.ErrorReason != "" {
+					reason = settleResp.ErrorReason
+				}
+				writePaymentRequired(w, requirements, reason)
+				return
+			}
+
+			w.Header().Set("X-PAYMENT-RESPONSE", encodePaymentResponseHeader(*settleResp))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writePaymentRequired answers with 402 and the payment requirements
+func writePaymentRequired(w http.ResponseWriter, requirements x402.PaymentRequirements, reason string) {
+	paymentRequired := x402.PaymentRequired{
+		X402Version: x402.ProtocolVersion,
+		Error:       reason,
+		Accepts:     []x402.PaymentRequirements{requirements},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("PAYMENT-REQUIRED", encodePaymentRequiredHeader(paymentRequired))
+	w.WriteHeader(http.StatusPaymentRequired)
+	_ = json.NewEncoder(w).Encode(paymentRequired)
+}
+
+// paymentFailureReason returns the reason code of a verify or settle error, or fallback for
+// other errors so transport details are not exposed to the payer
+func paymentFailureReason(err error, fallback string) string {
+	var verifyErr *x402.VerifyError
+	if errors.As(err, &verifyErr) && verifyErr.Reason != "" {
+		return verifyErr.Reason
+	}
+	var settleErr *x402.SettleError
+	if errors.As(err, &settleErr) && settleErr.Reason != "" {
+		return settleErr.Reason
+	}
+	return fallback
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ============================================================================
+// RequirePayment Options
+// ============================================================================
+
+// SettleMode controls when RequirePayment settles a verified payment
+type SettleMode int
+
+const (
+	// SettleSync settles before the handler runs, so content is only served once paid
+	SettleSync SettleMode = iota
+	// SettleAsyncAfterResponse serves the handler as soon as the payment verifies and settles
+	// in the background after it returns. The payer gets the content before settlement, so a
+	// payment that then fails to settle has been served unpaid; report it via WithSettleCallback.
+	SettleAsyncAfterResponse
+)
+
+// DefaultAsyncSettleWorkers bounds the background settlements of SettleAsyncAfterResponse
+// when WithSettleWorkers is unset
+const DefaultAsyncSettleWorkers = 8
+
+// DefaultAsyncSettleQueueSize bounds the settlements of SettleAsyncAfterResponse waiting for
+// a worker when WithSettleQueueSize is unset
+const DefaultAsyncSettleQueueSize = 256
+
+// AsyncSettleResult is the outcome of one background settlement
+type AsyncSettleResult struct {
+	PayloadBytes      []byte
+	RequirementsBytes []byte
+	Response          *x402.SettleResponse // Nil when the settle call failed
+	Err               error                // Nil when the payment settled
+}
+
+// RequirePaymentOption configures RequirePayment
+type RequirePaymentOption func(*requirePaymentConfig)
+
+type requirePaymentConfig struct {
+	settleMode     SettleMode
+	settleWorkers  int
+	settleQueue    int
+	settleCallback func(ctx context.Context, result AsyncSettleResult)
+}
+
+// WithSettleMode sets when payments are settled (defaults to SettleSync)
+func WithSettleMode(mode SettleMode) RequirePaymentOption {
+	return func(c *requirePaymentConfig) {
+		c.settleMode = mode
+	}
+}
+
+// WithSettleWorkers sets how many background settlements of SettleAsyncAfterResponse run
+// at once (defaults to DefaultAsyncSettleWorkers)
+func WithSettleWorkers(workers int) RequirePaymentOption {
+	return func(c *requirePaymentConfig) {
+		c.settleWorkers = workers
+	}
+}
+
+// WithSettleQueueSize bounds the settlements of SettleAsyncAfterResponse waiting for a free
+// worker (defaults to DefaultAsyncSettleQueueSize). When the queue is full, the request
+// settles on its own goroutine after the handler returns, which slows it down instead of
+// piling up pending settlements.
+func WithSettleQueueSize(size int) RequirePaymentOption {
+	return func(c *requirePaymentConfig) {
+		c.settleQueue = size
+	}
+}
+
+// WithSettleCallback is called with the outcome of every background settlement of
+// SettleAsyncAfterResponse, from the worker that ran it
+func WithSettleCallback(callback func(ctx context.Context, result AsyncSettleResult)) RequirePaymentOption {
+	return func(c *requirePaymentConfig) {
+		c.settleCallback = callback
+	}
+}
+
+// ============================================================================
+// Background Settlement
+// ============================================================================
+
+// failedClaimTTL is how long a payload without a validity window stays claimed after its
+// settlement failed
+const failedClaimTTL = time.Hour
+
+// claimSweepInterval is how often expired claims are evicted
+const claimSweepInterval = time.Minute
+
+// asyncSettler runs the settlements of SettleAsyncAfterResponse on a fixed set of worker
+// goroutines fed by a bounded queue. A payload is claimed when it is served, and stays
+// claimed until it settles, after which the spent authorization makes verify reject it. A
+// payload whose settlement failed was already served once, so it stays claimed until its
+// authorization's validBefore (or failedClaimTTL without one), after which it cannot verify.
+type asyncSettler struct {
+	facilitator x402.FacilitatorClient
+	callback    func(ctx context.Context, result AsyncSettleResult)
+	jobs        chan settleJob
+	now         func() time.Time
+
+	mu        sync.Mutex
+	claimed   map[[32]byte]time.Time // Expiry of failed claims; zero while settling
+	nextSweep time.Time
+}
+
+// settleJob is one queued background settlement
+type settleJob struct {
+	ctx               context.Context
+	payloadBytes      []byte
+	requirementsBytes []byte
+}
+
+func newAsyncSettler(facilitator x402.FacilitatorClient, config requirePaymentConfig) *asyncSettler {
+	workers := config.settleWorkers
+	if workers <= 0 {
+		workers = DefaultAsyncSettleWorkers
+	}
+	queue := config.settleQueue
+	if queue <= 0 {
+		queue = DefaultAsyncSettleQueueSize
+	}
+	s := &asyncSettler{
+		facilitator: facilitator,
+		callback:    config.settleCallback,
+		jobs:        make(chan settleJob, queue),
+		now:         time.Now,
+		claimed:     make(map[[32]byte]time.Time),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range s.jobs {
+				s.run(job)
+			}
+		}()
+	}
+	return s
+}
+
+// claim reserves payloadBytes for one request; false when it was already served
+func (s *asyncSettler) claim(payloadBytes []byte) bool {
+	key := sha256.Sum256(payloadBytes)
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.After(s.nextSweep) {
+		for k, expiry := range s.claimed {
+			if !expiry.IsZero() && !now.Before(expiry) {
+				delete(s.claimed, k)
+			}
+		}
+		s.nextSweep = now.Add(claimSweepInterval)
+	}
+
+	if expiry, ok := s.claimed[key]; ok && (expiry.IsZero() || now.Before(expiry)) {
+		return false
+	}
+	s.claimed[key] = time.Time{}
+	return true
+}
+
+// settle queues the settlement of a claimed payload, or runs it on the caller's goroutine
+// when the queue is full. ctx keeps its values (e.g. the idempotency key) but not the
+// request's cancellation, which ends with the response.
+func (s *asyncSettler) settle(ctx context.Context, payloadBytes, requirementsBytes []byte) {
+	job := settleJob{ctx: context.WithoutCancel(ctx), payloadBytes: payloadBytes, requirementsBytes: requirementsBytes}
+	select {
+	case s.jobs <- job:
+	default:
+		s.run(job)
+	}
+}
+
+// run settles a claimed payload and reports the outcome to the callback
+func (s *asyncSettler) run(job settleJob) {
+	ctx, payloadBytes, requirementsBytes := job.ctx, job.payloadBytes, job.requirementsBytes
+
+	result := AsyncSettleResult{PayloadBytes: payloadBytes, RequirementsBytes: requirementsBytes}
+	result.Response, result.Err = s.facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	if result.Err == nil && (result.Response == nil || !result.Response.Success) {
+		reason := "settlement_failed"
+		var payer, transaction string
+		var network x402.Network
+		if result.Response != nil {
+			if result.Response.ErrorReason != "" {
+				reason = result.Response.ErrorReason
+			}
+			payer, network, transaction = result.Response.Payer, result.Response.Network, result.Response.Transaction
+		}
+		result.Err = x402.NewSettleError(reason, payer, network, transaction, nil)
+	}
+
+	key := sha256.Sum256(payloadBytes)
+	expiry := s.failedClaimExpiry(payloadBytes)
+	s.mu.Lock()
+	if result.Err == nil {
+		delete(s.claimed, key)
+	} else {
+		s.claimed[key] = expiry
+	}
+	s.mu.Unlock()
+
+	if s.callback != nil {
+		s.callback(ctx, result)
+	}
+}
+
+// failedClaimExpiry returns when a payload that failed to settle may be claimed again: once
+// its authorization expired, or after failedClaimTTL for payloads without a validity window
+func (s *asyncSettler) failedClaimExpiry(payloadBytes []byte) time.Time {
+	var payload types.PaymentPayload
+	if err := json.Unmarshal(payloadBytes, &payload); err == nil {
+		if _, validBefore, ok := payload.ValidityWindow(); ok {
+			return time.Unix(validBefore, 0)
+		}
+	}
+	return s.now().Add(failedClaimTTL)
+}
@@ -0,0 +1,401 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/facilitatortest"
+)
+
+func requirePaymentTestSetup(t *testing.T, facilitator x402.FacilitatorClient, opts ...RequirePaymentOption) (http.Handler, *bool, string) {
+	t.Helper()
+	payloadBytes, requirementsBytes := retryTestPayload(t)
+	var requirements x402.PaymentRequirements
+	if err := json.Unmarshal(requirementsBytes, &requirements); err != nil {
+		t.Fatalf("Failed to decode requirements: %v", err)
+	}
+
+	called := false
+	handler := RequirePayment(requirements, facilitator, opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		_, _ = w.Write([]byte("paid content"))
+	}))
+	return handler, &called, base64.StdEncoding.EncodeToString(payloadBytes)
+}
+
+func TestRequirePaymentWithoutPayment(t *testing.T) {
+	mock := facilitatortest.AlwaysValid()
+	handler, called, _ := requirePaymentTestSetup(t, mock)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/resource", nil))
+
+	if rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("Expected 402, got %d", rec.Code)
+	}
+	if *called || len(mock.Calls()) != 0 {
+		t.Errorf("Expected neither the handler nor the facilitator to be called")
+	}
+
+	var body x402.PaymentRequired
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected PaymentRequired JSON body: %v", err)
+	}
+	if len(body.Accepts) != 1 || body.Accepts[0].PayTo != "0xrecipient" || body.Accepts[0].Amount != "1000000" {
+		t.Errorf("Unexpected accepts: %+v", body.Accepts)
+	}
+
+	header, err := decodePaymentRequiredHeader(rec.Header().Get("PAYMENT-REQUIRED"))
+	if err != nil || len(header.Accepts) != 1 {
+		t.Errorf("Expected PAYMENT-REQUIRED header with the requirements, got %+v, %v", header, err)
+	}
+}
+
+func TestRequirePaymentMalformedHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"not base64", "!!!"},
+		{"not json", base64.StdEncoding.EncodeToString([]byte("not json"))},
+		{"missing version", base64.StdEncoding.EncodeToString([]byte(`{"payload":{}}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := facilitatortest.AlwaysValid()
+			handler, called, _ := requirePaymentTestSetup(t, mock)
+
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("X-PAYMENT", tt.header)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", rec.Code)
+			}
+			if *called || len(mock.Calls()) != 0 {
+				t.Errorf("Expected neither the handler nor the facilitator to be called")
+			}
+		})
+	}
+}
+
+func TestRequirePaymentInvalidPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		mock       func() *facilitatortest.MockFacilitatorClient
+		wantReason string
+		wantSettle int
+	}{
+		{
+			name: "verify error",
+			mock: func() *facilitatortest.MockFacilitatorClient {
+				return facilitatortest.AlwaysInvalid("insufficient_funds")
+			},
+			wantReason: "insufficient_funds",
+		},
+		{
+			name: "verify invalid",
+			mock: func() *facilitatortest.MockFacilitatorClient {
+				m := facilitatortest.AlwaysValid()
+				m.SetVerifyResult(&x402.VerifyResponse{IsValid: false, InvalidReason: "invalid_signature"}, nil)
+				return m
+			},
+			wantReason: "invalid_signature",
+		},
+		{
+			name: "settle failed",
+			mock: func() *facilitatortest.MockFacilitatorClient {
+				m := facilitatortest.AlwaysValid()
+				m.SetSettleResult(nil, x402.NewSettleError("transaction_reverted", "", "", "", nil))
+				return m
+			},
+			wantReason: "transaction_reverted",
+			wantSettle: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := tt.mock()
+			handler, called, header := requirePaymentTestSetup(t, mock)
+
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("X-PAYMENT", header)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusPaymentRequired {
+				t.Fatalf("Expected 402, got %d", rec.Code)
+			}
+			if *called {
+				t.Errorf("Expected the handler not to be called")
+			}
+			if got := mock.CallCount(facilitatortest.MethodSettle); got != tt.wantSettle {
+				t.Errorf("Expected %d settle calls, got %d", tt.wantSettle, got)
+			}
+
+			var body x402.PaymentRequired
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error != tt.wantReason {
+				t.Errorf("Expected error %q, got %+v, %v", tt.wantReason, body, err)
+			}
+		})
+	}
+}
+
+func TestRequirePaymentSuccess(t *testing.T) {
+	mock := facilitatortest.AlwaysValid()
+	handler, called, header := requirePaymentTestSetup(t, mock)
+
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("X-PAYMENT", header)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || !*called || rec.Body.String() != "paid content" {
+		t.Fatalf("Expected the handler to serve the content, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	settlement, err := decodePaymentResponseHeader(rec.Header().Get("X-PAYMENT-RESPONSE"))
+	if err != nil || !settlement.Success || settlement.Transaction != facilitatortest.DefaultTransaction {
+		t.Errorf("Expected X-PAYMENT-RESPONSE with the settlement, got %+v, %v", settlement, err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Method != facilitatortest.MethodVerify || calls[1].Method != facilitatortest.MethodSettle {
+		t.Fatalf("Expected verify then settle, got %+v", calls)
+	}
+	var requirements x402.PaymentRequirements
+	if err := json.Unmarshal(calls[0].RequirementsBytes, &requirements); err != nil || requirements.PayTo != "0xrecipient" {
+		t.Errorf("Expected the requirements to be sent to the facilitator, got %s", calls[0].RequirementsBytes)
+	}
+}
+
+// gatedSettleFacilitator holds every settle until release is closed
+type gatedSettleFacilitator struct {
+	*facilitatortest.MockFacilitatorClient
+	release chan struct{}
+}
+
+func (g *gatedSettleFacilitator) Settle(ctx context.Context, payloadBytes, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	<-g.release
+	return g.MockFacilitatorClient.Settle(ctx, payloadBytes, requirementsBytes)
+}
+
+func servePayment(handler http.Handler, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("X-PAYMENT", header)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequirePaymentExplicitSyncMode(t *testing.T) {
+	mock := facilitatortest.AlwaysValid()
+	handler, called, header := requirePaymentTestSetup(t, mock, WithSettleMode(SettleSync))
+
+	rec := servePayment(handler, header)
+	if rec.Code != http.StatusOK || !*called {
+		t.Fatalf("Expected the handler to serve the content, got %d", rec.Code)
+	}
+	if rec.Header().Get("X-PAYMENT-RESPONSE") == "" || mock.CallCount(facilitatortest.MethodSettle) != 1 {
+		t.Errorf("Expected the payment to be settled before responding")
+	}
+}
+
+func TestRequirePaymentAsyncSettle(t *testing.T) {
+	facilitator := &gatedSettleFacilitator{MockFacilitatorClient: facilitatortest.AlwaysValid(), release: make(chan struct{})}
+	results := make(chan AsyncSettleResult, 1)
+	handler, called, header := requirePaymentTestSetup(t, facilitator,
+		WithSettleMode(SettleAsyncAfterResponse),
+		WithSettleCallback(func(ctx context.Context, result AsyncSettleResult) { results <- result }),
+	)
+
+	// The content is served while the settlement is still held
+	rec := servePayment(handler, header)
+	if rec.Code != http.StatusOK || !*called || rec.Body.String() != "paid content" {
+		t.Fatalf("Expected the handler to serve the content, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-PAYMENT-RESPONSE") != "" {
+		t.Error("Expected no X-PAYMENT-RESPONSE before settlement")
+	}
+
+	// The same payment is not served again while it settles
+	*called = false
+	if rec := servePayment(handler, header); rec.Code != http.StatusPaymentRequired || *called {
+		t.Errorf("Expected a replay to be refused with 402, got %d", rec.Code)
+	}
+
+	close(facilitator.release)
+	select {
+	case result := <-results:
+		if result.Err != nil || result.Response == nil || result.Response.Transaction != facilitatortest.DefaultTransaction {
+			t.Errorf("Expected a successful settlement, got %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the settle callback to be called")
+	}
+	if got := facilitator.CallCount(facilitatortest.MethodSettle); got != 1 {
+		t.Errorf("Expected 1 settle call, got %d", got)
+	}
+}
+
+func TestRequirePaymentAsyncSettleFailure(t *testing.T) {
+	mock := facilitatortest.AlwaysValid()
+	mock.SetSettleResult(&x402.SettleResponse{Success: false, ErrorReason: "transaction_reverted"}, nil)
+	results := make(chan AsyncSettleResult, 1)
+	handler, called, header := requirePaymentTestSetup(t, mock,
+		WithSettleMode(SettleAsyncAfterResponse),
+		WithSettleWorkers(1),
+		WithSettleCallback(func(ctx context.Context, result AsyncSettleResult) { results <- result }),
+	)
+
+	if rec := servePayment(handler, header); rec.Code != http.StatusOK || !*called {
+		t.Fatalf("Expected the handler to serve the content, got %d", rec.Code)
+	}
+	select {
+	case result := <-results:
+		var settleErr *x402.SettleError
+		if !errors.As(result.Err, &settleErr) || settleErr.Reason != "transaction_reverted" {
+			t.Errorf("Expected a SettleError with the failure reason, got %v", result.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the settle callback to be called")
+	}
+
+	// A payment that failed to settle is not served again while its authorization may verify
+	*called = false
+	if rec := servePayment(handler, header); rec.Code != http.StatusPaymentRequired || *called {
+		t.Errorf("Expected the unsettled payment to be refused with 402, got %d", rec.Code)
+	}
+}
+
+func TestRequirePaymentAsyncVerifyGates(t *testing.T) {
+	mock := facilitatortest.AlwaysInvalid("insufficient_funds")
+	handler, called, header := requirePaymentTestSetup(t, mock, WithSettleMode(SettleAsyncAfterResponse))
+
+	if rec := servePayment(handler, header); rec.Code != http.StatusPaymentRequired || *called {
+		t.Fatalf("Expected 402 without calling the handler, got %d", rec.Code)
+	}
+	if got := mock.CallCount(facilitatortest.MethodSettle); got != 0 {
+		t.Errorf("Expected no settle calls, got %d", got)
+	}
+}
+
+// signalingSettleFacilitator reports every Settle call on entered and holds it until release is closed
+type signalingSettleFacilitator struct {
+	*facilitatortest.MockFacilitatorClient
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (f *signalingSettleFacilitator) Settle(ctx context.Context, payloadBytes, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	f.entered <- struct{}{}
+	<-f.release
+	return f.MockFacilitatorClient.Settle(ctx, payloadBytes, requirementsBytes)
+}
+
+func TestAsyncSettlerQueueFull(t *testing.T) {
+	facilitator := &signalingSettleFacilitator{
+		MockFacilitatorClient: facilitatortest.AlwaysValid(),
+		entered:               make(chan struct{}, 3),
+		release:               make(chan struct{}),
+	}
+	results := make(chan AsyncSettleResult, 3)
+	settler := newAsyncSettler(facilitator, requirePaymentConfig{
+		settleWorkers:  1,
+		settleQueue:    1,
+		settleCallback: func(ctx context.Context, result AsyncSettleResult) { results <- result },
+	})
+	ctx := context.Background()
+
+	// The only worker is busy and the queue takes the second payment
+	settler.settle(ctx, []byte("payment-1"), nil)
+	<-facilitator.entered
+	settler.settle(ctx, []byte("payment-2"), nil)
+
+	// The third settles on the caller's goroutine
+	done := make(chan struct{})
+	go func() {
+		settler.settle(ctx, []byte("payment-3"), nil)
+		close(done)
+	}()
+	select {
+	case <-facilitator.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the third settlement to run on the caller's goroutine")
+	}
+	select {
+	case <-done:
+		t.Fatal("Expected the caller to wait for its settlement")
+	default:
+	}
+
+	close(facilitator.release)
+	<-done
+	for i := 0; i < 3; i++ {
+		select {
+		case result := <-results:
+			if result.Err != nil {
+				t.Errorf("Unexpected settle error: %v", result.Err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected 3 settlements, got %d", i)
+		}
+	}
+}
+
+func TestAsyncSettlerFailedClaimExpires(t *testing.T) {
+	mock := facilitatortest.AlwaysValid()
+	mock.SetSettleResult(&x402.SettleResponse{Success: false, ErrorReason: "transaction_reverted"}, nil)
+	settler := newAsyncSettler(mock, requirePaymentConfig{})
+	now := time.Unix(1700000000, 0)
+	settler.now = func() time.Time { return now }
+
+	windowed, _ := json.Marshal(x402.PaymentPayload{
+		X402Version: 2,
+		Payload: map[string]interface{}{
+			"authorization": map[string]interface{}{"validAfter": "0", "validBefore": "1700000060"},
+		},
+	})
+	unwindowed := []byte(`{"x402Version":2,"payload":{}}`)
+
+	for _, payloadBytes := range [][]byte{windowed, unwindowed} {
+		if !settler.claim(payloadBytes) {
+			t.Fatal("Expected the first claim to succeed")
+		}
+		settler.run(settleJob{ctx: context.Background(), payloadBytes: payloadBytes})
+		if settler.claim(payloadBytes) {
+			t.Error("Expected a payment that failed to settle to stay claimed")
+		}
+	}
+
+	// Past validBefore the authorization can no longer verify, so its claim is dropped
+	now = now.Add(61 * time.Second)
+	if settler.claim(unwindowed) {
+		t.Error("Expected the payment without a window to stay claimed for failedClaimTTL")
+	}
+	if !settler.claim(windowed) {
+		t.Error("Expected the expired authorization to be claimable again")
+	}
+
+	now = now.Add(failedClaimTTL)
+	if !settler.claim([]byte("other")) {
+		t.Fatal("Expected a new payment to be claimable")
+	}
+	settler.mu.Lock()
+	remaining := len(settler.claimed)
+	settler.mu.Unlock()
+	// windowed and other are pending; the expired unwindowed claim was swept
+	if remaining != 2 {
+		t.Errorf("Expected expired claims to be evicted, got %d claims", remaining)
+	}
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// ============================================================================
+// Spending Policy
+// ============================================================================
+
+// SpendingDenialReason identifies the SpendingPolicy rule that refused a payment
+type SpendingDenialReason string
+
+const (
+	SpendingDeniedAmount      SpendingDenialReason = "amount"       // Above MaxAmount
+	SpendingDeniedResourceCap SpendingDenialReason = "resource_cap" // Above the ResourceCaps entry for the resource
+	SpendingDeniedRecipient   SpendingDenialReason = "recipient"    // PayTo not in AllowedRecipients
+	SpendingDeniedNetwork     SpendingDenialReason = "network"      // Network not in AllowedNetworks
+	SpendingDeniedWindow      SpendingDenialReason = "window"       // Would take the rolling total above WindowLimit
+)
+
+// SpendingDeniedError is returned by SpendingPolicy.Check and Reserve when a rule refuses the payment
+type SpendingDeniedError struct {
+	Reason       SpendingDenialReason
+	Resource     string
+	Requirements x402.PaymentRequirements
+	Limit        string // The exceeded limit, for the amount, resource_cap and window reasons
+}
+
+func (e *SpendingDeniedError) Error() string {
+	switch e.Reason {
+	case SpendingDeniedRecipient:
+		return fmt.Sprintf("spending policy denied payment to %s: recipient not allowed", e.Requirements.PayTo)
+	case SpendingDeniedNetwork:
+		return fmt.Sprintf("spending policy denied payment on %s: network not allowed", e.Requirements.Network)
+	case SpendingDeniedWindow:
+		return fmt.Sprintf("spending policy denied payment of %s: window limit %s reached", e.Requirements.Amount, e.Limit)
+	default:
+		return fmt.Sprintf("spending policy denied payment of %s for %s: limit %s", e.Requirements.Amount, e.Resource, e.Limit)
+	}
+}
+
+// SpendingPolicy decides whether SchemePaymentRoundTripper may pay a 402 automatically.
+// Amounts are in the asset's smallest unit, like PaymentRequirements.Amount; empty fields
+// do not restrict. A policy is safe for concurrent use and may be shared by several
+// round trippers so they draw on one window.
+type SpendingPolicy struct {
+	// MaxAmount caps any single payment
+	MaxAmount string
+
+	// ResourceCaps caps single payments per resource URL (as advertised in PaymentRequired.Resource)
+	ResourceCaps map[string]string
+
+	// AllowedRecipients lists the PayTo addresses that may be paid (compared case-insensitively)
+	AllowedRecipients []string
+
+	// AllowedNetworks lists the networks that may be paid on
+	AllowedNetworks []string
+
+	// WindowLimit caps the total paid per network and asset within Window
+	WindowLimit string
+	Window      time.Duration
+
+	// OnDeny is called with every denial, since the caller only sees the original 402
+	OnDeny func(err *SpendingDeniedError)
+
+	now func() time.Time
+
+	mu     sync.Mutex
+	spends []spendRecord
+}
+
+type spendRecord struct {
+	at      time.Time
+	network string
+	asset   string
+	amount  *big.Int
+}
+
+// Check returns a *SpendingDeniedError if the policy refuses to pay requirements for resource,
+// without recording anything
+func (p *SpendingPolicy) Check(resource string, requirements x402.PaymentRequirements) error {
+	p.mu.Lock()
+	_, err := p.checkLocked(resource, requirements)
+	p.mu.Unlock()
+
+	p.notifyDenied(err)
+	return err
+}
+
+// Reserve is Check followed by recording the payment in the window, atomically.
+// SchemePaymentRoundTripper reserves before signing, since a signed payload may be settled
+// even if the paid request later fails, and releases the reservation when no payment was sent.
+func (p *SpendingPolicy) Reserve(resource string, requirements x402.PaymentRequirements) error {
+	p.mu.Lock()
+	amount, err := p.checkLocked(resource, requirements)
+	if err == nil && p.Window > 0 {
+		p.spends = append(p.spends, spendRecord{
+			at:      p.clock(),
+			network: string(requirements.Network),
+			asset:   strings.ToLower(requirements.Asset),
+			amount:  amount,
+		})
+	}
+	p.mu.Unlock()
+
+	p.notifyDenied(err)
+	return err
+}
+
+// Release undoes a Reserve of requirements whose payment was never sent, e.g. because
+// signing failed. It removes the latest matching spend from the window; releasing
+// requirements that were not reserved, or have aged out of the window, does nothing.
+func (p *SpendingPolicy) Release(requirements x402.PaymentRequirements) {
+	amount, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return
+	}
+	network, asset := string(requirements.Network), strings.ToLower(requirements.Asset)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := len(p.spends) - 1; i >= 0; i-- {
+		spend := p.spends[i]
+		if spend.network == network && spend.asset == asset && spend.amount.Cmp(amount) == 0 {
+			p.spends = append(p.spends[:i], p.spends[i+1:]...)
+			return
+		}
+	}
+}
+
+// Spent returns the total reserved for network and asset within the current window
+func (p *SpendingPolicy) Spent(network, asset string) *big.Int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.spentLocked(network, strings.ToLower(asset))
+}
+
+// notifyDenied passes a denial to OnDeny. It is called without p.mu held, so the callback
+// may use the policy, e.g. to log Spent.
+func (p *SpendingPolicy) notifyDenied(err error) {
+	var denied *SpendingDeniedError
+	if p.OnDeny != nil && errors.As(err, &denied) {
+		p.OnDeny(denied)
+	}
+}
+
+// checkLocked applies the rules and returns the parsed amount. p.mu must be held.
+func (p *SpendingPolicy) checkLocked(resource string, requirements x402.PaymentRequirements) (*big.Int, error) {
+	deny := func(reason SpendingDenialReason, limit string) error {
+		return &SpendingDeniedError{Reason: reason, Resource: resource, Requirements: requirements, Limit: limit}
+	}
+
+	amount, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid payment amount: %q", requirements.Amount)
+	}
+
+	if len(p.AllowedNetworks) > 0 && !containsString(p.AllowedNetworks, string(requirements.Network), false) {
+		return nil, deny(SpendingDeniedNetwork, "")
+	}
+	if len(p.AllowedRecipients) > 0 && !containsString(p.AllowedRecipients, requirements.PayTo, true) {
+		return nil, deny(SpendingDeniedRecipient, "")
+	}
+
+	if exceeded, err := exceedsLimit(amount, p.MaxAmount); err != nil {
+		return nil, err
+	} else if exceeded {
+		return nil, deny(SpendingDeniedAmount, p.MaxAmount)
+	}
+	if limit, ok := p.ResourceCaps[resource]; ok {
+		if exceeded, err := exceedsLimit(amount, limit); err != nil {
+			return nil, err
+		} else if exceeded {
+			return nil, deny(SpendingDeniedResourceCap, limit)
+		}
+	}
+
+	if p.Window > 0 && p.WindowLimit != "" {
+		total := new(big.Int).Add(p.spentLocked(string(requirements.Network), strings.ToLower(requirements.Asset)), amount)
+		if exceeded, err := exceedsLimit(total, p.WindowLimit); err != nil {
+			return nil, err
+		} else if exceeded {
+			return nil, deny(SpendingDeniedWindow, p.WindowLimit)
+		}
+	}
+
+	return amount, nil
+}
+
+// spentLocked drops spends older than the window and sums the rest for network and asset.
+// p.mu must be held.
+func (p *SpendingPolicy) spentLocked(network, asset string) *big.Int {
+	cutoff := p.clock().Add(-p.Window)
+	kept := p.spends[:0]
+	total := new(big.Int)
+	for _, spend := range p.spends {
+		if !spend.at.After(cutoff) {
+			continue
+		}
+		kept = append(kept, spend)
+		if spend.network == network && spend.asset == asset {
+			total.Add(total, spend.amount)
+		}
+	}
+	p.spends = kept
+	return total
+}
+
+func (p *SpendingPolicy) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// exceedsLimit reports whether amount is above limit; an empty limit never is
+func exceedsLimit(amount *big.Int, limit string) (bool, error) {
+	if limit == "" {
+		return false, nil
+	}
+	max, ok := new(big.Int).SetString(limit, 10)
+	if !ok {
+		return false, fmt.Errorf("invalid spending limit: %q", limit)
+	}
+	return amount.Cmp(max) > 0, nil
+}
+
+func containsString(values []string, value string, foldCase bool) bool {
+	for _, v := range values {
+		if v == value || (foldCase && strings.EqualFold(v, value)) {
+			return true
+		}
+	}
+	return false
+}
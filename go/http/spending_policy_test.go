@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/types"
+)
+
+func TestSpendingPolicyCheck(t *testing.T) {
+	requirements := x402.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000", PayTo: "0xRecipient"}
+
+	tests := []struct {
+		name   string
+		policy *SpendingPolicy
+		want   SpendingDenialReason
+	}{
+		{"no rules", &SpendingPolicy{}, ""},
+		{"within max", &SpendingPolicy{MaxAmount: "1000"}, ""},
+		{"above max", &SpendingPolicy{MaxAmount: "999"}, SpendingDeniedAmount},
+		{"above resource cap", &SpendingPolicy{ResourceCaps: map[string]string{"https://api.example/data": "500"}}, SpendingDeniedResourceCap},
+		{"other resource cap", &SpendingPolicy{ResourceCaps: map[string]string{"https://api.example/other": "500"}}, ""},
+		{"recipient allowed", &SpendingPolicy{AllowedRecipients: []string{"0xrecipient"}}, ""},
+		{"recipient not allowed", &SpendingPolicy{AllowedRecipients: []string{"0xsomeoneelse"}}, SpendingDeniedRecipient},
+		{"network not allowed", &SpendingPolicy{AllowedNetworks: []string{"eip155:8453"}}, SpendingDeniedNetwork},
+		{"above window limit", &SpendingPolicy{WindowLimit: "999", Window: time.Hour}, SpendingDeniedWindow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check("https://api.example/data", requirements)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected payment to be allowed, got %v", err)
+				}
+				return
+			}
+			denied, ok := err.(*SpendingDeniedError)
+			if !ok {
+				t.Fatalf("Expected *SpendingDeniedError, got %v", err)
+			}
+			if denied.Reason != tt.want {
+				t.Errorf("Expected reason %s, got %s", tt.want, denied.Reason)
+			}
+		})
+	}
+
+	if err := (&SpendingPolicy{MaxAmount: "lots"}).Check("", requirements); err == nil {
+		t.Error("Expected an error for an invalid limit")
+	}
+}
+
+func TestSpendingPolicyOnDenyUsesPolicy(t *testing.T) {
+	requirements := x402.PaymentRequirements{Network: "eip155:1", Asset: "USDC", Amount: "1000", PayTo: "0xrecipient"}
+	policy := &SpendingPolicy{WindowLimit: "1500", Window: time.Minute}
+	var spentAtDenial *big.Int
+	policy.OnDeny = func(err *SpendingDeniedError) {
+		// The callback runs outside the policy lock, so it may query the policy
+		spentAtDenial = policy.Spent("eip155:1", "USDC")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if err := policy.Reserve("", requirements); err != nil {
+			done <- err
+			return
+		}
+		done <- policy.Reserve("", requirements)
+	}()
+
+	select {
+	case err := <-done:
+		var denied *SpendingDeniedError
+		if !errors.As(err, &denied) || denied.Reason != SpendingDeniedWindow {
+			t.Fatalf("Expected a window denial, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reserve deadlocked calling OnDeny")
+	}
+	if spentAtDenial == nil || spentAtDenial.Int64() != 1000 {
+		t.Errorf("Expected OnDeny to see 1000 spent, got %v", spentAtDenial)
+	}
+}
+
+func TestPaymentRoundTripperPolicyRecipientNotAllowed(t *testing.T) {
+	server, facilitator := paywalledServer(t, "1000")
+	var denials []*SpendingDeniedError
+	transport := NewPaymentRoundTripper(&mockSchemeClient{scheme: "exact"}, "5000")
+	transport.Policy = &SpendingPolicy{
+		AllowedRecipients: []string{"0xtrusted"},
+		OnDeny:            func(err *SpendingDeniedError) { denials = append(denials, err) },
+	}
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the 402 to be returned, got error %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusPaymentRequired {
+		t.Fatalf("Expected status 402, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("PAYMENT-REQUIRED") == "" && len(body) == 0 {
+		t.Error("Expected the original payment requirements on the returned response")
+	}
+	if len(facilitator.Calls()) != 0 {
+		t.Errorf("Expected no payment to be attempted")
+	}
+	if len(denials) != 1 || denials[0].Reason != SpendingDeniedRecipient || denials[0].Requirements.PayTo != "0xrecipient" {
+		t.Errorf("Expected one recipient denial, got %+v", denials)
+	}
+}
+
+func TestPaymentRoundTripperPolicyWindowCap(t *testing.T) {
+	server, facilitator := paywalledServer(t, "1000")
+	now := time.Now()
+	policy := &SpendingPolicy{WindowLimit: "1500", Window: time.Minute}
+	policy.now = func() time.Time { return now }
+	transport := NewPaymentRoundTripper(&mockSchemeClient{scheme: "exact"}, "5000")
+	transport.Policy = policy
+	client := &http.Client{Transport: transport}
+
+	get := func() int {
+		t.Helper()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("Expected the first payment to succeed, got %d", status)
+	}
+	if spent := policy.Spent("eip155:1", "usdc"); spent.Int64() != 1000 {
+		t.Errorf("Expected 1000 spent in the window, got %s", spent)
+	}
+
+	// A second payment would take the window to 2000
+	if status := get(); status != http.StatusPaymentRequired {
+		t.Fatalf("Expected the window cap to return the 402, got %d", status)
+	}
+	if calls := len(facilitator.Calls()); calls != 2 {
+		t.Errorf("Expected only the first payment to reach the facilitator, got %d calls", calls)
+	}
+
+	// Spends age out of the rolling window
+	now = now.Add(time.Minute)
+	if status := get(); status != http.StatusOK {
+		t.Errorf("Expected a payment after the window rolled over, got %d", status)
+	}
+}
+
+// failingSchemeClient fails to sign every payment
+type failingSchemeClient struct {
+	mockSchemeClient
+}
+
+func (f *failingSchemeClient) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error) {
+	return types.PaymentPayload{}, errors.New("signer unavailable")
+}
+
+func TestPaymentRoundTripperPolicySigningFails(t *testing.T) {
+	server, facilitator := paywalledServer(t, "1000")
+	policy := &SpendingPolicy{WindowLimit: "1500", Window: time.Minute}
+	transport := NewPaymentRoundTripper(&failingSchemeClient{mockSchemeClient{scheme: "exact"}}, "5000")
+	transport.Policy = policy
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(server.URL); err == nil || !strings.Contains(err.Error(), "signer unavailable") {
+			t.Fatalf("Expected the signing error, got %v", err)
+		}
+	}
+
+	// Both attempts were reserved and released, so neither is denied by the window
+	if spent := policy.Spent("eip155:1", "usdc"); spent.Sign() != 0 {
+		t.Errorf("Expected unsent payments to be released, got %s spent", spent)
+	}
+	if len(facilitator.Calls()) != 0 {
+		t.Errorf("Expected no payment to reach the facilitator")
+	}
+}
+
+func TestSpendingPolicyRelease(t *testing.T) {
+	policy := &SpendingPolicy{Window: time.Minute}
+	small := x402.PaymentRequirements{Network: "eip155:1", Asset: "USDC", Amount: "100", PayTo: "0xrecipient"}
+	large := small
+	large.Amount = "700"
+
+	for _, requirements := range []x402.PaymentRequirements{small, large, small} {
+		if err := policy.Reserve("", requirements); err != nil {
+			t.Fatalf("Reserve failed: %v", err)
+		}
+	}
+	policy.Release(large)
+	policy.Release(x402.PaymentRequirements{Network: "eip155:1", Asset: "USDC", Amount: "5"})
+	if spent := policy.Spent("eip155:1", "usdc"); spent.Int64() != 200 {
+		t.Errorf("Expected only the released payment to be removed, got %s", spent)
+	}
+}
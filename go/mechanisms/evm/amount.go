@@ -0,0 +1,198 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ToAtomicAmount converts a human-readable amount (e.g., "5.00") to the token's smallest unit
+// (e.g., "5000000" for a 6-decimal token) for use as requirements.Amount.
+//
+// Decimals come from the network's default asset or an asset added with RegisterAsset.
+// Use ToAtomicAmountWithClient to read decimals() from chain for other tokens.
+// Amounts with more fractional digits than the token supports are rejected rather than rounded.
+func ToAtomicAmount(network, asset, human string) (string, error) {
+	return ToAtomicAmountWithClient(context.Background(), nil, network, asset, human)
+}
+
+// ToAtomicAmountWithClient is ToAtomicAmount, falling back to the token's decimals()
+// through ethClient when the asset is not known locally
+func ToAtomicAmountWithClient(ctx context.Context, ethClient ContractCaller, network, asset, human string) (string, error) {
+	decimals, err := assetDecimals(ctx, ethClient, network, asset)
+	if err != nil {
+		return "", err
+	}
+
+	amount, err := parseExactAmount(human, decimals)
+	if err != nil {
+		return "", err
+	}
+	return amount.String(), nil
+}
+
+// FromAtomicAmount converts an amount in the token's smallest unit to a human-readable
+// decimal string without trailing zeros (e.g., "5000000" -> "5" for a 6-decimal token)
+func FromAtomicAmount(network, asset, atomic string) (string, error) {
+	return FromAtomicAmountWithClient(context.Background(), nil, network, asset, atomic)
+}
+
+// FromAtomicAmountWithClient is FromAtomicAmount, falling back to the token's decimals()
+// through ethClient when the asset is not known locally
+func FromAtomicAmountWithClient(ctx context.Context, ethClient ContractCaller, network, asset, atomic string) (string, error) {
+	decimals, err := assetDecimals(ctx, ethClient, network, asset)
+	if err != nil {
+		return "", err
+	}
+
+	amount, ok := new(big.Int).SetString(atomic, 10)
+	if !ok || amount.Sign() < 0 {
+		return "", fmt.Errorf("invalid atomic amount: %s", atomic)
+	}
+	return FormatAmount(amount, decimals), nil
+}
+
+// QueryDecimals reads decimals() from an ERC-20 token contract
+func QueryDecimals(ctx context.Context, ethClient ContractCaller, tokenAddress string) (int, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(DecimalsABI))
+	if err != nil {
+		return 0, err
+	}
+
+	addr := common.HexToAddress(tokenAddress)
+	result, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &addr,
+		Data: contractABI.Methods[FunctionDecimals].ID,
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(result) < 32 {
+		return 0, fmt.Errorf("invalid decimals result length: %d", len(result))
+	}
+
+	decimals := new(big.Int).SetBytes(result[:32])
+	if !decimals.IsInt64() || decimals.Int64() > 255 {
+		return 0, fmt.Errorf("invalid decimals: %s", decimals)
+	}
+	return int(decimals.Int64()), nil
+}
+
+// assetDecimals returns the decimals of a known asset, or reads them from chain when ethClient is set
+func assetDecimals(ctx context.Context, ethClient ContractCaller, network, asset string) (int, error) {
+	if !IsValidAddress(asset) {
+		// Symbols resolve to the network's default asset
+		info, err := GetAssetInfo(network, asset)
+		if err != nil {
+			return 0, err
+		}
+		return info.Decimals, nil
+	}
+
+	normalized := NormalizeAddress(asset)
+	if info, ok := lookupAsset(network, normalized); ok {
+		return info.Decimals, nil
+	}
+	if config, err := GetNetworkConfig(network); err == nil && config.DefaultAsset.Address != "" {
+		if NormalizeAddress(config.DefaultAsset.Address) == normalized {
+			return config.DefaultAsset.Decimals, nil
+		}
+	}
+
+	if ethClient == nil {
+		return 0, fmt.Errorf("unknown decimals for asset %s on %s: register the asset or provide a client", asset, network)
+	}
+	decimals, err := QueryDecimals(ctx, ethClient, normalized)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query decimals for asset %s: %w", asset, err)
+	}
+	return decimals, nil
+}
+
+// MaxAmountToleranceBps is the largest tolerance CheckAuthorizationValue accepts (100%)
+const MaxAmountToleranceBps = 10000
+
+// AmountMismatchError is returned by CheckAuthorizationValue when an authorization's value
+// falls outside [Required, MaxAccepted]
+type AmountMismatchError struct {
+	Value       *big.Int // Authorized value
+	Required    *big.Int // Required amount
+	MaxAccepted *big.Int // Largest accepted value (Required when no tolerance is allowed)
+}
+
+// Error implements the error interface
+func (e *AmountMismatchError) Error() string {
+	if e.Short() {
+		return fmt.Sprintf("authorization value %s is below the required amount %s", e.Value, e.Required)
+	}
+	if e.MaxAccepted.Cmp(e.Required) == 0 {
+		return fmt.Sprintf("authorization value %s does not match the required amount %s", e.Value, e.Required)
+	}
+	return fmt.Sprintf("authorization value %s exceeds the required amount %s plus tolerance (at most %s)", e.Value, e.Required, e.MaxAccepted)
+}
+
+// Short reports whether the value is below the required amount (rather than above the tolerance)
+func (e *AmountMismatchError) Short() bool {
+	return e.Value.Cmp(e.Required) < 0
+}
+
+// CheckAuthorizationValue checks an authorization's value against the required amount, both
+// in the token's smallest unit. The value must equal required, or exceed it by at most
+// toleranceBps basis points of required (rounded down), so clients of fee-on-transfer tokens
+// can sign a little more to cover the fee. Paying less than required is never accepted.
+//
+// Returns an *AmountMismatchError when the value is outside that band.
+func CheckAuthorizationValue(value, required *big.Int, toleranceBps int) error {
+	if value == nil || required == nil {
+		return fmt.Errorf("authorization value and required amount are required")
+	}
+	if toleranceBps < 0 || toleranceBps > MaxAmountToleranceBps {
+		return fmt.Errorf("amount tolerance must be between 0 and %d basis points, got %d", MaxAmountToleranceBps, toleranceBps)
+	}
+
+	maxAccepted := new(big.Int).Mul(required, big.NewInt(int64(toleranceBps)))
+	maxAccepted.Quo(maxAccepted, big.NewInt(MaxAmountToleranceBps))
+	maxAccepted.Add(maxAccepted, required)
+
+	if value.Cmp(required) < 0 || value.Cmp(maxAccepted) > 0 {
+		return &AmountMismatchError{Value: value, Required: required, MaxAccepted: maxAccepted}
+	}
+	return nil
+}
+
+// parseExactAmount converts a non-negative decimal string to the smallest unit without rounding
+func parseExactAmount(human string, decimals int) (*big.Int, error) {
+	trimmed := strings.TrimSpace(human)
+	intPart, fracPart, _ := strings.Cut(trimmed, ".")
+	if (intPart == "" && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return nil, fmt.Errorf("invalid amount: %q", human)
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > decimals {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", human, decimals)
+	}
+
+	digits := intPart + fracPart + strings.Repeat("0", decimals-len(fracPart))
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %q", human)
+	}
+	return amount, nil
+}
+
+// isDigits reports whether s contains only ASCII digits (an empty string qualifies)
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
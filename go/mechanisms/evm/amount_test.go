@@ -0,0 +1,159 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testGateLayerUSDC = "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF"
+
+func TestToAtomicAmount(t *testing.T) {
+	const network = "eip155:999997"
+	const token18 = "0xdddddddddddddddddddddddddddddddddddddddd"
+	if err := RegisterNetwork(network, NetworkConfig{ChainID: big.NewInt(999997)}, WithOverride()); err != nil {
+		t.Fatalf("RegisterNetwork failed: %v", err)
+	}
+	if err := RegisterAsset(network, token18, AssetInfo{Name: "Eighteen", Version: "1", Decimals: 18}, WithOverride()); err != nil {
+		t.Fatalf("RegisterAsset failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		network string
+		asset   string
+		human   string
+		want    string
+		wantErr string
+	}{
+		{name: "6 decimals whole", network: "eip155:10087", asset: testGateLayerUSDC, human: "5", want: "5000000"},
+		{name: "6 decimals fraction", network: "eip155:10087", asset: testGateLayerUSDC, human: "5.25", want: "5250000"},
+		{name: "6 decimals trailing zeros", network: "eip155:10087", asset: testGateLayerUSDC, human: "0.1000000000", want: "100000"},
+		{name: "6 decimals smallest unit", network: "eip155:10087", asset: testGateLayerUSDC, human: ".000001", want: "1"},
+		{name: "default asset symbol", network: "eip155:10087", asset: "USDC", human: "1.5", want: "1500000"},
+		{name: "18 decimals", network: network, asset: token18, human: "1.000000000000000001", want: "1000000000000000001"},
+		{name: "18 decimals large", network: network, asset: token18, human: "123456789", want: "123456789000000000000000000"},
+		{name: "too many decimals", network: "eip155:10087", asset: testGateLayerUSDC, human: "0.0000001", wantErr: "more than 6 decimal places"},
+		{name: "negative", network: "eip155:10087", asset: testGateLayerUSDC, human: "-1", wantErr: "invalid amount"},
+		{name: "not a number", network: "eip155:10087", asset: testGateLayerUSDC, human: "1e6", wantErr: "invalid amount"},
+		{name: "empty", network: "eip155:10087", asset: testGateLayerUSDC, human: ".", wantErr: "invalid amount"},
+		{name: "unknown asset without client", network: "eip155:10087", asset: testPermitToken, human: "1", wantErr: "unknown decimals"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToAtomicAmount(tt.network, tt.asset, tt.human)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+
+			back, err := FromAtomicAmount(tt.network, tt.asset, got)
+			if err != nil {
+				t.Fatalf("FromAtomicAmount failed: %v", err)
+			}
+			if roundTrip, _ := ToAtomicAmount(tt.network, tt.asset, back); roundTrip != got {
+				t.Errorf("Round trip %s -> %s -> %s", got, back, roundTrip)
+			}
+		})
+	}
+}
+
+func TestFromAtomicAmount(t *testing.T) {
+	got, err := FromAtomicAmount("eip155:10087", testGateLayerUSDC, "5250000")
+	if err != nil || got != "5.25" {
+		t.Errorf("Expected 5.25, got %s (%v)", got, err)
+	}
+	if _, err := FromAtomicAmount("eip155:10087", testGateLayerUSDC, "1.5"); err == nil {
+		t.Error("Expected error for non-integer atomic amount")
+	}
+}
+
+func TestToAtomicAmountWithClient(t *testing.T) {
+	ctx := context.Background()
+	caller := &mockContractCaller{decimals: common.LeftPadBytes([]byte{18}, 32)}
+
+	got, err := ToAtomicAmountWithClient(ctx, caller, "eip155:10087", testPermitToken, "2.5")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != "2500000000000000000" {
+		t.Errorf("Expected 2500000000000000000, got %s", got)
+	}
+
+	human, err := FromAtomicAmountWithClient(ctx, caller, "eip155:10087", testPermitToken, got)
+	if err != nil || human != "2.5" {
+		t.Errorf("Expected 2.5, got %s (%v)", human, err)
+	}
+
+	// Known assets do not hit the chain
+	got, err = ToAtomicAmountWithClient(ctx, &mockContractCaller{}, "eip155:10087", testGateLayerUSDC, "1")
+	if err != nil || got != "1000000" {
+		t.Errorf("Expected 1000000 from local decimals, got %s (%v)", got, err)
+	}
+
+	if _, err := ToAtomicAmountWithClient(ctx, &mockContractCaller{}, "eip155:10087", testPermitToken, "1"); err == nil {
+		t.Error("Expected error when decimals() reverts")
+	}
+}
+
+func TestCheckAuthorizationValue(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		required     string
+		toleranceBps int
+		wantErr      bool
+		wantShort    bool
+	}{
+		{name: "exact match", value: "1000000", required: "1000000"},
+		{name: "exact match beyond int64", value: "100000000000000000000000", required: "100000000000000000000000"},
+		{name: "short", value: "999999", required: "1000000", wantErr: true, wantShort: true},
+		{name: "over without tolerance", value: "1000001", required: "1000000", wantErr: true},
+		{name: "within tolerance", value: "1005000", required: "1000000", toleranceBps: 50},
+		{name: "at tolerance edge", value: "1010000", required: "1000000", toleranceBps: 100},
+		{name: "beyond tolerance", value: "1010001", required: "1000000", toleranceBps: 100, wantErr: true},
+		{name: "short with tolerance", value: "999999", required: "1000000", toleranceBps: 100, wantErr: true, wantShort: true},
+		{name: "tolerance rounds down", value: "100", required: "99", toleranceBps: 100, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := new(big.Int).SetString(tt.value, 10)
+			required, _ := new(big.Int).SetString(tt.required, 10)
+
+			err := CheckAuthorizationValue(value, required, tt.toleranceBps)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var mismatch *AmountMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("Expected *AmountMismatchError, got %v", err)
+			}
+			if mismatch.Short() != tt.wantShort || mismatch.Value.Cmp(value) != 0 || mismatch.Required.Cmp(required) != 0 {
+				t.Errorf("Unexpected mismatch: %+v", mismatch)
+			}
+		})
+	}
+
+	for _, bps := range []int{-1, MaxAmountToleranceBps + 1} {
+		if err := CheckAuthorizationValue(big.NewInt(1), big.NewInt(1), bps); err == nil {
+			t.Errorf("Expected error for tolerance %d", bps)
+		}
+	}
+}
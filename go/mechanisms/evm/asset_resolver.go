@@ -0,0 +1,148 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultAssetResolveTimeout bounds the chain calls GetAssetInfo makes through a registered AssetResolver
+const DefaultAssetResolveTimeout = 5 * time.Second
+
+// AssetResolver fills in metadata for tokens that are neither configured nor registered
+type AssetResolver interface {
+	ResolveAsset(ctx context.Context, network, address string) (*AssetInfo, error)
+}
+
+var assetResolvers = map[string]AssetResolver{} // network -> resolver, guarded by registryMu
+
+// RegisterAssetResolver makes GetAssetInfo consult resolver for unknown token addresses on
+// network, instead of returning the "Unknown Token" placeholder. A nil resolver removes it.
+// When the resolver fails, GetAssetInfo still falls back to the placeholder.
+func RegisterAssetResolver(network string, resolver AssetResolver) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	network = normalizeNetworkLocked(network)
+	if resolver == nil {
+		delete(assetResolvers, network)
+		return
+	}
+	assetResolvers[network] = resolver
+}
+
+func lookupAssetResolver(network string) (AssetResolver, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	resolver, ok := assetResolvers[normalizeNetworkLocked(network)]
+	return resolver, ok
+}
+
+// ChainAssetResolver reads AssetInfo from the token contract: name(), version() (or
+// EIP712_VERSION() when version() is missing, and "1" when both are) and decimals().
+// Successful lookups are cached for the life of the resolver; failures are not.
+type ChainAssetResolver struct {
+	ethClient ContractCaller
+
+	mu    sync.Mutex
+	cache map[string]AssetInfo // network + normalized address -> info
+}
+
+// NewChainAssetResolver creates a resolver that queries tokens through ethClient
+func NewChainAssetResolver(ethClient ContractCaller) *ChainAssetResolver {
+	return &ChainAssetResolver{
+		ethClient: ethClient,
+		cache:     make(map[string]AssetInfo),
+	}
+}
+
+// ResolveAsset returns the on-chain metadata of the token at address
+func (r *ChainAssetResolver) ResolveAsset(ctx context.Context, network, address string) (*AssetInfo, error) {
+	if !IsValidAddress(address) {
+		return nil, fmt.Errorf("invalid asset address: %s", address)
+	}
+	normalized := NormalizeAddress(address)
+	key := network + "/" + normalized
+
+	r.mu.Lock()
+	info, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok {
+		return &info, nil
+	}
+
+	info, err := r.query(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[key] = info
+	r.mu.Unlock()
+	return &info, nil
+}
+
+func (r *ChainAssetResolver) query(ctx context.Context, address string) (AssetInfo, error) {
+	metadataABI, err := abi.JSON(bytes.NewReader(TokenMetadataABI))
+	if err != nil {
+		return AssetInfo{}, err
+	}
+	decimalsABI, err := abi.JSON(bytes.NewReader(DecimalsABI))
+	if err != nil {
+		return AssetInfo{}, err
+	}
+
+	info := AssetInfo{Address: address, Version: "1"}
+	if err := r.call(ctx, metadataABI, address, FunctionName, &info.Name); err != nil {
+		return AssetInfo{}, err
+	}
+	for _, method := range []string{FunctionVersion, FunctionEIP712Version} {
+		var version string
+		if err := r.call(ctx, metadataABI, address, method, &version); err == nil {
+			info.Version = version
+			break
+		}
+	}
+
+	var decimals uint8
+	if err := r.call(ctx, decimalsABI, address, FunctionDecimals, &decimals); err != nil {
+		return AssetInfo{}, err
+	}
+	info.Decimals = int(decimals)
+	return info, nil
+}
+
+func (r *ChainAssetResolver) call(ctx context.Context, contractABI abi.ABI, address, method string, out interface{}) error {
+	to := common.HexToAddress(address)
+	result, err := r.ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &to,
+		Data: contractABI.Methods[method].ID,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to query %s(): %w", method, err)
+	}
+	if err := contractABI.UnpackIntoInterface(out, method, result); err != nil {
+		return fmt.Errorf("invalid %s() result: %w", method, err)
+	}
+	return nil
+}
+
+// resolveAsset asks the network's registered resolver for an unknown token
+func resolveAsset(ctx context.Context, network, normalizedAddress string) (*AssetInfo, bool) {
+	resolver, ok := lookupAssetResolver(network)
+	if !ok {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultAssetResolveTimeout)
+	defer cancel()
+	info, err := resolver.ResolveAsset(ctx, network, normalizedAddress)
+	if err != nil || info == nil {
+		return nil, false
+	}
+	return info, true
+}
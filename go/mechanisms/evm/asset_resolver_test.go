@@ -0,0 +1,176 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testResolvedToken = "0x1111111111111111111111111111111111111111"
+
+// mockToken is a token contract answering the given metadata calls; empty fields revert
+type mockToken struct {
+	name, version, eip712Version string
+	decimals                     uint8
+	noDecimals                   bool
+	calls                        int
+}
+
+func (m *mockToken) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockToken) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	m.calls++
+	caller := selectorCaller{}
+	if m.name != "" {
+		caller["name()"] = encodeABIString(m.name)
+	}
+	if m.version != "" {
+		caller["version()"] = encodeABIString(m.version)
+	}
+	if m.eip712Version != "" {
+		caller["EIP712_VERSION()"] = encodeABIString(m.eip712Version)
+	}
+	if !m.noDecimals {
+		caller["decimals()"] = common.LeftPadBytes([]byte{m.decimals}, 32)
+	}
+	return caller.CallContract(ctx, call, blockNumber)
+}
+
+func encodeABIString(s string) []byte {
+	out := common.LeftPadBytes([]byte{0x20}, 32)
+	out = append(out, common.LeftPadBytes(big.NewInt(int64(len(s))).Bytes(), 32)...)
+	padded := make([]byte, (len(s)+31)/32*32)
+	copy(padded, s)
+	return append(out, padded...)
+}
+
+func TestChainAssetResolver(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   *mockToken
+		want    AssetInfo
+		wantErr bool
+	}{
+		{
+			name:  "version",
+			token: &mockToken{name: "USD Coin", version: "2", eip712Version: "9", decimals: 6},
+			want:  AssetInfo{Address: testResolvedToken, Name: "USD Coin", Version: "2", Decimals: 6},
+		},
+		{
+			name:  "EIP712_VERSION",
+			token: &mockToken{name: "Bridged USDC", eip712Version: "3", decimals: 6},
+			want:  AssetInfo{Address: testResolvedToken, Name: "Bridged USDC", Version: "3", Decimals: 6},
+		},
+		{
+			name:  "no version defaults to 1",
+			token: &mockToken{name: "Plain Token", decimals: 18},
+			want:  AssetInfo{Address: testResolvedToken, Name: "Plain Token", Version: "1", Decimals: 18},
+		},
+		{name: "no name", token: &mockToken{version: "1", decimals: 18}, wantErr: true},
+		{name: "no decimals", token: &mockToken{name: "Token", noDecimals: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewChainAssetResolver(tt.token)
+			got, err := resolver.ResolveAsset(context.Background(), "eip155:8453", testResolvedToken)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, *got)
+			}
+		})
+	}
+}
+
+func TestChainAssetResolverCaches(t *testing.T) {
+	token := &mockToken{name: "USD Coin", version: "2", decimals: 6}
+	resolver := NewChainAssetResolver(token)
+	ctx := context.Background()
+
+	if _, err := resolver.ResolveAsset(ctx, "eip155:8453", testResolvedToken); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	calls := token.calls
+	got, err := resolver.ResolveAsset(ctx, "eip155:8453", "0x1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token.calls != calls {
+		t.Errorf("Expected cached lookup, got %d more calls", token.calls-calls)
+	}
+	if got.Name != "USD Coin" {
+		t.Errorf("Expected cached name, got %s", got.Name)
+	}
+
+	// Failures are retried
+	failing := &mockToken{noDecimals: true}
+	resolver = NewChainAssetResolver(failing)
+	resolver.ResolveAsset(ctx, "eip155:8453", testResolvedToken)
+	calls = failing.calls
+	resolver.ResolveAsset(ctx, "eip155:8453", testResolvedToken)
+	if failing.calls == calls {
+		t.Error("Expected failed lookup not to be cached")
+	}
+}
+
+type errResolver struct{}
+
+func (errResolver) ResolveAsset(ctx context.Context, network, address string) (*AssetInfo, error) {
+	return nil, errors.New("rpc unavailable")
+}
+
+func TestGetAssetInfoUsesAssetResolver(t *testing.T) {
+	const network = "eip155:10087"
+	t.Cleanup(func() { RegisterAssetResolver(network, nil) })
+
+	info, err := GetAssetInfo(network, testResolvedToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name != "Unknown Token" {
+		t.Fatalf("Expected placeholder without a resolver, got %s", info.Name)
+	}
+
+	RegisterAssetResolver(network, NewChainAssetResolver(&mockToken{name: "Resolved", version: "2", decimals: 8}))
+	info, err = GetAssetInfo(network, testResolvedToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name != "Resolved" || info.Version != "2" || info.Decimals != 8 {
+		t.Errorf("Expected resolved metadata, got %+v", *info)
+	}
+
+	// Known assets never reach the resolver
+	usdc := NetworkConfigs[network].DefaultAsset.Address
+	info, err = GetAssetInfo(network, usdc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name == "Resolved" {
+		t.Errorf("Expected configured metadata for %s, got the resolver's", usdc)
+	}
+
+	// A failing resolver falls back to the placeholder
+	RegisterAssetResolver(network, errResolver{})
+	info, err = GetAssetInfo(network, testResolvedToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name != "Unknown Token" || info.Decimals != 18 {
+		t.Errorf("Expected placeholder on resolver failure, got %+v", *info)
+	}
+}
@@ -5,8 +5,9 @@ import (
 )
 
 const (
-	// Scheme identifier
+	// Scheme identifiers
 	SchemeExact = "exact"
+	SchemeUpTo  = "upto"
 
 	// Default token decimals for USDC
 	DefaultDecimals = 6
@@ -15,11 +16,30 @@ const (
 	FunctionTransferWithAuthorization = "transferWithAuthorization"
 	FunctionReceiveWithAuthorization  = "receiveWithAuthorization"
 	FunctionAuthorizationState        = "authorizationState"
+	FunctionCancelAuthorization       = "cancelAuthorization"
+
+	// EIP-2612 / ERC-20 function names
+	FunctionPermit          = "permit"
+	FunctionNonces          = "nonces"
+	FunctionTransferFrom    = "transferFrom"
+	FunctionDomainSeparator = "DOMAIN_SEPARATOR"
+	FunctionBalanceOf       = "balanceOf"
+	FunctionDecimals        = "decimals"
+	FunctionName            = "name"
+	FunctionVersion         = "version"
+	FunctionEIP712Version   = "EIP712_VERSION"
 
 	// Transaction status
 	TxStatusSuccess = 1
 	TxStatusFailed  = 0
 
+	// Canonical Uniswap Permit2 deployment (same address on every chain)
+	CanonicalPermit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+	// Permit2 function names (AllowanceTransfer reuses FunctionPermit and FunctionTransferFrom)
+	FunctionPermitTransferFrom = "permitTransferFrom"
+	FunctionAllowance          = "allowance"
+
 	// Default validity period (1 hour)
 	DefaultValidityPeriod = 3600 // seconds
 
@@ -34,22 +54,27 @@ const (
 	ErrInvalidSignature            = "invalid_exact_evm_payload_signature"
 	ErrUndeployedSmartWallet       = "invalid_exact_evm_payload_undeployed_smart_wallet"
 	ErrSmartWalletDeploymentFailed = "smart_wallet_deployment_failed"
+	ErrAuthorizationExpired        = "invalid_exact_evm_payload_authorization_valid_before"
+	ErrAuthorizationNotYetValid    = "invalid_exact_evm_payload_authorization_valid_after"
+	ErrNonceAlreadyUsed            = "invalid_exact_evm_payload_authorization_nonce_used"
 )
 
 var (
 	// Network chain IDs
 	ChainIDGateLayerTestnet = big.NewInt(10087) // Gate Layer Testnet chain ID (0x2767)
 
-	// Network configurations
-	// See DEFAULT_ASSET.md for guidelines on adding new chains
+	// Network configurations (built-in)
+	// See DEFAULT_ASSET.md for guidelines on adding new chains.
+	// Use RegisterNetwork/RegisterAsset to add chains at runtime; do not modify this map directly.
 	//
 	// Default Asset Selection Policy:
 	// - Each chain has the right to determine its own default stablecoin
 	// - If the chain has officially endorsed a stablecoin, that asset should be used
 	// - If no official stance exists, the chain team should make the selection
 	//
-	// NOTE: Currently only EIP-3009 supporting stablecoins can be used.
-	// Generic ERC-20 support via EIP-2612/Permit2 is planned but not yet implemented.
+	// NOTE: Default assets must support EIP-3009. Tokens that only implement EIP-2612
+	// permit can be paid with the client ExactEvmPermitScheme, and tokens approved to Permit2
+	// with ExactEvmPermit2Scheme. Set NetworkConfig.Permit2Address where Permit2 is not canonical.
 	NetworkConfigs = map[string]NetworkConfig{
 		// Gate Layer Testnet
 		"gatelayer_testnet": {
@@ -116,6 +141,23 @@ var (
 	// Legacy: Combined ABI (deprecated, use specific ABIs above)
 	TransferWithAuthorizationABI = TransferWithAuthorizationVRSABI
 
+	// EIP-3009 ABI for cancelAuthorization with v,r,s
+	CancelAuthorizationVRSABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "authorizer", "type": "address"},
+				{"name": "nonce", "type": "bytes32"},
+				{"name": "v", "type": "uint8"},
+				{"name": "r", "type": "bytes32"},
+				{"name": "s", "type": "bytes32"}
+			],
+			"name": "cancelAuthorization",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
 	// ABI for authorizationState check
 	AuthorizationStateABI = []byte(`[
 		{
@@ -129,4 +171,213 @@ var (
 			"type": "function"
 		}
 	]`)
+
+	// EIP-2612 ABI for permit with v,r,s
+	PermitABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "owner", "type": "address"},
+				{"name": "spender", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "deadline", "type": "uint256"},
+				{"name": "v", "type": "uint8"},
+				{"name": "r", "type": "bytes32"},
+				{"name": "s", "type": "bytes32"}
+			],
+			"name": "permit",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-2612 ABI for the nonces(owner) check
+	NoncesABI = []byte(`[
+		{
+			"inputs": [{"name": "owner", "type": "address"}],
+			"name": "nonces",
+			"outputs": [{"name": "", "type": "uint256"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// ERC-20 ABI for transferFrom (submitted by the spender after permit)
+	TransferFromABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "from", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "value", "type": "uint256"}
+			],
+			"name": "transferFrom",
+			"outputs": [{"name": "", "type": "bool"}],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// DomainSeparatorMethods are the view functions QueryDomainSeparator tries, in order,
+	// when reading a token's EIP-712 domain separator
+	DomainSeparatorMethods = []string{FunctionDomainSeparator, "getDomainSeparator", "domainSeparator"}
+
+	// ABI for DOMAIN_SEPARATOR (EIP-2612 and most EIP-3009 tokens)
+	DomainSeparatorABI = []byte(`[
+		{
+			"inputs": [],
+			"name": "DOMAIN_SEPARATOR",
+			"outputs": [{"name": "", "type": "bytes32"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// Permit2 ABI for permitTransferFrom(PermitTransferFrom, SignatureTransferDetails, owner, signature)
+	Permit2PermitTransferFromABI = []byte(`[
+		{
+			"inputs": [
+				{
+					"components": [
+						{
+							"components": [
+								{"name": "token", "type": "address"},
+								{"name": "amount", "type": "uint256"}
+							],
+							"name": "permitted",
+							"type": "tuple"
+						},
+						{"name": "nonce", "type": "uint256"},
+						{"name": "deadline", "type": "uint256"}
+					],
+					"name": "permit",
+					"type": "tuple"
+				},
+				{
+					"components": [
+						{"name": "to", "type": "address"},
+						{"name": "requestedAmount", "type": "uint256"}
+					],
+					"name": "transferDetails",
+					"type": "tuple"
+				},
+				{"name": "owner", "type": "address"},
+				{"name": "signature", "type": "bytes"}
+			],
+			"name": "permitTransferFrom",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// Permit2 ABI for allowance(owner, token, spender), the AllowanceTransfer state of a spender
+	Permit2AllowanceABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "owner", "type": "address"},
+				{"name": "token", "type": "address"},
+				{"name": "spender", "type": "address"}
+			],
+			"name": "allowance",
+			"outputs": [
+				{"name": "amount", "type": "uint160"},
+				{"name": "expiration", "type": "uint48"},
+				{"name": "nonce", "type": "uint48"}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// Permit2 ABI for permit(owner, PermitSingle, signature) and the AllowanceTransfer
+	// transferFrom(from, to, amount, token) the spender calls afterwards
+	Permit2PermitSingleABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "owner", "type": "address"},
+				{
+					"components": [
+						{
+							"components": [
+								{"name": "token", "type": "address"},
+								{"name": "amount", "type": "uint160"},
+								{"name": "expiration", "type": "uint48"},
+								{"name": "nonce", "type": "uint48"}
+							],
+							"name": "details",
+							"type": "tuple"
+						},
+						{"name": "spender", "type": "address"},
+						{"name": "sigDeadline", "type": "uint256"}
+					],
+					"name": "permitSingle",
+					"type": "tuple"
+				},
+				{"name": "signature", "type": "bytes"}
+			],
+			"name": "permit",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		},
+		{
+			"inputs": [
+				{"name": "from", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "amount", "type": "uint160"},
+				{"name": "token", "type": "address"}
+			],
+			"name": "transferFrom",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// ERC-20 ABI for balanceOf
+	BalanceOfABI = []byte(`[
+		{
+			"inputs": [{"name": "account", "type": "address"}],
+			"name": "balanceOf",
+			"outputs": [{"name": "", "type": "uint256"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// ABI for name(), version() and EIP712_VERSION(), the EIP-712 domain fields of most EIP-3009 tokens
+	TokenMetadataABI = []byte(`[
+		{
+			"inputs": [],
+			"name": "name",
+			"outputs": [{"name": "", "type": "string"}],
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"inputs": [],
+			"name": "version",
+			"outputs": [{"name": "", "type": "string"}],
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"inputs": [],
+			"name": "EIP712_VERSION",
+			"outputs": [{"name": "", "type": "string"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// ERC-20 ABI for decimals
+	DecimalsABI = []byte(`[
+		{
+			"inputs": [],
+			"name": "decimals",
+			"outputs": [{"name": "", "type": "uint8"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
 )
@@ -0,0 +1,129 @@
+package evm
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Defaults for DefaultDomainSeparatorCache
+const (
+	DefaultDomainSeparatorCacheTTL        = time.Hour
+	DefaultDomainSeparatorCacheMaxEntries = 1024
+)
+
+// DefaultDomainSeparatorCache is shared by every client scheme that does not set its own cache
+var DefaultDomainSeparatorCache = NewDomainSeparatorCache(DefaultDomainSeparatorCacheTTL, DefaultDomainSeparatorCacheMaxEntries)
+
+// DomainSeparatorCache is a concurrency-safe cache of on-chain DOMAIN_SEPARATOR values
+// keyed by (chainID, verifyingContract). Entries expire after the TTL; when the cache is
+// full, expired entries are dropped first and then the oldest entry is evicted.
+type DomainSeparatorCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[domainCacheKey]domainCacheEntry
+}
+
+type domainCacheKey struct {
+	chainID           string
+	verifyingContract string
+}
+
+type domainCacheEntry struct {
+	domainSeparator []byte
+	storedAt        time.Time
+}
+
+// NewDomainSeparatorCache creates a cache whose entries live for ttl.
+// A ttl of zero or less keeps entries until evicted; maxEntries of zero or less means no size limit.
+func NewDomainSeparatorCache(ttl time.Duration, maxEntries int) *DomainSeparatorCache {
+	return &DomainSeparatorCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[domainCacheKey]domainCacheEntry),
+	}
+}
+
+// Get returns the cached DOMAIN_SEPARATOR for a token contract if present and fresh
+func (c *DomainSeparatorCache) Get(chainID *big.Int, verifyingContract string) ([]byte, bool) {
+	key := newDomainCacheKey(chainID, verifyingContract)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.expired(entry) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return append([]byte(nil), entry.domainSeparator...), true
+}
+
+// Set stores the DOMAIN_SEPARATOR for a token contract
+func (c *DomainSeparatorCache) Set(chainID *big.Int, verifyingContract string, domainSeparator []byte) {
+	key := newDomainCacheKey(chainID, verifyingContract)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evictLocked()
+	}
+	c.entries[key] = domainCacheEntry{
+		domainSeparator: append([]byte(nil), domainSeparator...),
+		storedAt:        c.now(),
+	}
+}
+
+// Len returns the number of cached entries, including ones that have expired but not been evicted
+func (c *DomainSeparatorCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Clear removes every entry
+func (c *DomainSeparatorCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[domainCacheKey]domainCacheEntry)
+}
+
+// evictLocked drops expired entries, or the oldest entry if none expired. c.mu must be held.
+func (c *DomainSeparatorCache) evictLocked() {
+	var oldestKey domainCacheKey
+	var oldest time.Time
+	evicted := false
+	for key, entry := range c.entries {
+		if c.expired(entry) {
+			delete(c.entries, key)
+			evicted = true
+			continue
+		}
+		if oldest.IsZero() || entry.storedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.storedAt
+		}
+	}
+	if !evicted {
+		delete(c.entries, oldestKey)
+	}
+}
+
+func (c *DomainSeparatorCache) expired(entry domainCacheEntry) bool {
+	return c.ttl > 0 && !c.now().Before(entry.storedAt.Add(c.ttl))
+}
+
+func newDomainCacheKey(chainID *big.Int, verifyingContract string) domainCacheKey {
+	key := domainCacheKey{verifyingContract: NormalizeAddress(verifyingContract)}
+	if chainID != nil {
+		key.chainID = chainID.String()
+	}
+	return key
+}
@@ -0,0 +1,84 @@
+package evm
+
+import (
+	"bytes"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDomainSeparatorCache(t *testing.T) {
+	cache := NewDomainSeparatorCache(time.Minute, 2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	sepA := bytes.Repeat([]byte{0xaa}, 32)
+	cache.Set(big.NewInt(1), testPermitToken, sepA)
+
+	// Keys are case-insensitive on the contract and distinct per chain
+	if got, ok := cache.Get(big.NewInt(1), "0xcccccccccccccccccccccccccccccccccccccccc"); !ok || !bytes.Equal(got, sepA) {
+		t.Error("expected hit for the same contract with different casing")
+	}
+	if _, ok := cache.Get(big.NewInt(8453), testPermitToken); ok {
+		t.Error("expected miss for another chain")
+	}
+
+	// Returned values are copies
+	got, _ := cache.Get(big.NewInt(1), testPermitToken)
+	got[0] = 0
+	if again, _ := cache.Get(big.NewInt(1), testPermitToken); again[0] != 0xaa {
+		t.Error("expected cached value to be unaffected by caller mutation")
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get(big.NewInt(1), testPermitToken); ok {
+		t.Error("expected entry to expire")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected expired entry to be removed, got %d entries", cache.Len())
+	}
+}
+
+func TestDomainSeparatorCacheEviction(t *testing.T) {
+	cache := NewDomainSeparatorCache(0, 2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := int64(1); i <= 3; i++ {
+		cache.Set(big.NewInt(i), testPermitToken, bytes.Repeat([]byte{byte(i)}, 32))
+		now = now.Add(time.Second)
+	}
+
+	if cache.Len() != 2 {
+		t.Fatalf("expected size limit of 2, got %d", cache.Len())
+	}
+	if _, ok := cache.Get(big.NewInt(1), testPermitToken); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	for _, chainID := range []int64{2, 3} {
+		if _, ok := cache.Get(big.NewInt(chainID), testPermitToken); !ok {
+			t.Errorf("expected chain %d to stay cached", chainID)
+		}
+	}
+}
+
+func TestDomainSeparatorCacheConcurrent(t *testing.T) {
+	cache := NewDomainSeparatorCache(time.Hour, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			chainID := big.NewInt(int64(i % 16))
+			cache.Set(chainID, testPermitToken, bytes.Repeat([]byte{byte(i)}, 32))
+			cache.Get(chainID, testPermitToken)
+		}(i)
+	}
+	wg.Wait()
+
+	if cache.Len() > 8 {
+		t.Errorf("expected at most 8 entries, got %d", cache.Len())
+	}
+}
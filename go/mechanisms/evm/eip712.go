@@ -1,10 +1,13 @@
 package evm
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
@@ -39,32 +42,41 @@ func HashTypedData(
 		Domain: apitypes.TypedDataDomain{
 			Name:              domain.Name,
 			Version:           domain.Version,
-			ChainId:           (*math.HexOrDecimal256)(domain.ChainID),
 			VerifyingContract: domain.VerifyingContract,
 		},
 		Message: message,
 	}
+	if !domain.OmitChainID {
+		typedData.Domain.ChainId = (*math.HexOrDecimal256)(domain.ChainID)
+	}
+	if domain.HasSalt() {
+		typedData.Domain.Salt = hexutil.Encode(domain.Salt[:])
+	}
 
 	// Convert field types
 	for typeName, fields := range types {
-		typedFields := make([]apitypes.Type, len(fields))
-		for i, field := range fields {
-			typedFields[i] = apitypes.Type{
-				Name: field.Name,
-				Type: field.Type,
-			}
-		}
-		typedData.Types[typeName] = typedFields
+		typedData.Types[typeName] = toAPITypes(fields)
 	}
 
 	// Add EIP712Domain type if not present
 	if _, exists := typedData.Types["EIP712Domain"]; !exists {
-		typedData.Types["EIP712Domain"] = []apitypes.Type{
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
+		typedData.Types["EIP712Domain"] = toAPITypes(domain.Fields())
+	}
+
+	// A domain without chainId drops it and a salted domain hashes the salt too, also when
+	// the caller listed the domain fields
+	if domain.OmitChainID && hasTypeField(typedData.Types["EIP712Domain"], "chainId") {
+		domainFields := make([]apitypes.Type, 0, len(typedData.Types["EIP712Domain"]))
+		for _, field := range typedData.Types["EIP712Domain"] {
+			if field.Name != "chainId" {
+				domainFields = append(domainFields, field)
+			}
 		}
+		typedData.Types["EIP712Domain"] = domainFields
+	}
+	if domain.HasSalt() && !hasTypeField(typedData.Types["EIP712Domain"], "salt") {
+		domainFields := append([]apitypes.Type{}, typedData.Types["EIP712Domain"]...)
+		typedData.Types["EIP712Domain"] = append(domainFields, apitypes.Type{Name: "salt", Type: "bytes32"})
 	}
 
 	// Hash the struct data
@@ -88,10 +100,155 @@ func HashTypedData(
 	return digest, nil
 }
 
+// toAPITypes converts fields to the go-ethereum representation
+func toAPITypes(fields []TypedDataField) []apitypes.Type {
+	converted := make([]apitypes.Type, len(fields))
+	for i, field := range fields {
+		converted[i] = apitypes.Type{Name: field.Name, Type: field.Type}
+	}
+	return converted
+}
+
+// hasTypeField reports whether fields contains a field called name
+func hasTypeField(fields []apitypes.Type, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SignTypedDataDigest hashes EIP-712 typed data locally and signs the digest with signer
+//
+// This is how the client schemes sign, so a DigestSigner is enough to create any payload.
+// ECDSA signatures are returned in low-S form (see SignDigestNormalized).
+func SignTypedDataDigest(
+	ctx context.Context,
+	signer DigestSigner,
+	domain TypedDataDomain,
+	types map[string][]TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	digest, err := HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+	return SignDigestNormalized(ctx, signer, digest)
+}
+
+// EIP712DomainTypeHash is keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")
+var EIP712DomainTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+
+// EIP712DomainWithSaltTypeHash is
+// keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract,bytes32 salt)")
+var EIP712DomainWithSaltTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract,bytes32 salt)"))
+
+// HashEIP712Domain computes the DOMAIN_SEPARATOR for a name/version/chainId/verifyingContract domain,
+// with a trailing salt field when domain.Salt is set and without chainId when domain.OmitChainID is
+//
+// The result matches the token's on-chain DOMAIN_SEPARATOR when the name and version are correct,
+// so it can be compared with QueryDomainSeparator before signing offline.
+func HashEIP712Domain(domain TypedDataDomain) ([]byte, error) {
+	if !domain.OmitChainID && (domain.ChainID == nil || domain.ChainID.Sign() < 0) {
+		return nil, fmt.Errorf("invalid chain ID: %v", domain.ChainID)
+	}
+	if !common.IsHexAddress(domain.VerifyingContract) {
+		return nil, fmt.Errorf("invalid verifying contract: %s", domain.VerifyingContract)
+	}
+
+	encoded := make([]byte, 0, 32*6)
+	encoded = append(encoded, domainTypeHash(domain.Fields())...)
+	encoded = append(encoded, crypto.Keccak256([]byte(domain.Name))...)
+	encoded = append(encoded, crypto.Keccak256([]byte(domain.Version))...)
+	if !domain.OmitChainID {
+		encoded = append(encoded, common.LeftPadBytes(domain.ChainID.Bytes(), 32)...)
+	}
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(domain.VerifyingContract).Bytes(), 32)...)
+	if domain.HasSalt() {
+		encoded = append(encoded, domain.Salt[:]...)
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// domainTypeHash returns keccak256 of the EIP712Domain type with the given fields
+func domainTypeHash(fields []TypedDataField) []byte {
+	members := make([]string, len(fields))
+	for i, field := range fields {
+		members[i] = field.Type + " " + field.Name
+	}
+	return crypto.Keccak256([]byte("EIP712Domain(" + strings.Join(members, ",") + ")"))
+}
+
+// DomainSaltFromExtra reads the optional EIP-712 domain salt from requirements.Extra["salt"]
+// as 0x-prefixed hex of 32 bytes. A missing salt returns the zero salt (no salt field).
+func DomainSaltFromExtra(extra map[string]interface{}) ([32]byte, error) {
+	var salt [32]byte
+	value, ok := extra["salt"]
+	if !ok || value == nil {
+		return salt, nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return salt, fmt.Errorf("invalid domain salt: expected a hex string, got %T", value)
+	}
+	decoded, err := hexutil.Decode(str)
+	if err != nil || len(decoded) != 32 {
+		return salt, fmt.Errorf("invalid domain salt %q: expected 32 bytes of 0x-prefixed hex", str)
+	}
+	copy(salt[:], decoded)
+	return salt, nil
+}
+
+// DomainOmitsChainID reports whether the EIP-712 domain of asset has no chainId field:
+// requirements.Extra["omitChainId"] when set, otherwise asset.OmitChainID
+func DomainOmitsChainID(extra map[string]interface{}, asset *AssetInfo) (bool, error) {
+	value, ok := extra["omitChainId"]
+	if !ok || value == nil {
+		return asset != nil && asset.OmitChainID, nil
+	}
+	omit, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid omitChainId: expected a bool, got %T", value)
+	}
+	return omit, nil
+}
+
+// ExactDomain returns the EIP-712 domain an exact EIP-3009 payment for asset is signed in:
+// the asset's name and version unless requirements.Extra["name"] and ["version"] override
+// them, the salt in Extra["salt"], and chainId unless DomainOmitsChainID
+func ExactDomain(chainID *big.Int, asset *AssetInfo, extra map[string]interface{}) (TypedDataDomain, error) {
+	domain := TypedDataDomain{
+		Name:              asset.Name,
+		Version:           asset.Version,
+		ChainID:           chainID,
+		VerifyingContract: asset.Address,
+	}
+	if name, ok := extra["name"].(string); ok {
+		domain.Name = name
+	}
+	if version, ok := extra["version"].(string); ok {
+		domain.Version = version
+	}
+
+	var err error
+	if domain.Salt, err = DomainSaltFromExtra(extra); err != nil {
+		return TypedDataDomain{}, err
+	}
+	if domain.OmitChainID, err = DomainOmitsChainID(extra, asset); err != nil {
+		return TypedDataDomain{}, err
+	}
+	return domain, nil
+}
+
 // HashEIP3009Authorization hashes a TransferWithAuthorization message for EIP-3009
 //
 // This is a convenience function that wraps HashTypedData with the specific
 // types and structure used by EIP-3009's transferWithAuthorization.
+// The result is the raw digest to sign, e.g. with an external signer that only does ECDSA;
+// the 65-byte r||s||v signature goes into ExactEIP3009Payload.Signature.
 //
 // Args:
 //
@@ -112,22 +269,20 @@ func HashEIP3009Authorization(
 	tokenName string,
 	tokenVersion string,
 ) ([]byte, error) {
-	// Create EIP-712 domain
-	domain := TypedDataDomain{
+	return HashEIP3009AuthorizationWithDomain(authorization, TypedDataDomain{
 		Name:              tokenName,
 		Version:           tokenVersion,
 		ChainID:           chainID,
 		VerifyingContract: verifyingContract,
-	}
+	})
+}
 
+// HashEIP3009AuthorizationWithDomain is HashEIP3009Authorization for a full EIP-712 domain,
+// e.g. one with a salt or without chainId
+func HashEIP3009AuthorizationWithDomain(authorization ExactEIP3009Authorization, domain TypedDataDomain) ([]byte, error) {
 	// Define EIP-712 types
 	types := map[string][]TypedDataField{
-		"EIP712Domain": {
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
-		},
+		"EIP712Domain": domain.Fields(),
 		"TransferWithAuthorization": {
 			{Name: "from", Type: "address"},
 			{Name: "to", Type: "address"},
@@ -160,3 +315,398 @@ func HashEIP3009Authorization(
 
 	return HashTypedData(domain, types, "TransferWithAuthorization", message)
 }
+
+// EIP3009TransferWithAuthorizationTypeHash is
+// keccak256("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)")
+var EIP3009TransferWithAuthorizationTypeHash = crypto.Keccak256([]byte("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)"))
+
+// HashEIP3009AuthorizationWithDomainSeparator hashes a TransferWithAuthorization message
+// using a DOMAIN_SEPARATOR read from chain instead of the token name/version
+//
+// Like HashEIP3009Authorization, it returns the raw digest for signers that sign hashes directly.
+func HashEIP3009AuthorizationWithDomainSeparator(authorization ExactEIP3009Authorization, domainSeparator []byte) ([]byte, error) {
+	if len(domainSeparator) != 32 {
+		return nil, fmt.Errorf("invalid domain separator length: %d", len(domainSeparator))
+	}
+	structHash, err := HashEIP3009AuthorizationStruct(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	// keccak256(0x19 || 0x01 || domainSeparator || structHash)
+	rawData := []byte{0x19, 0x01}
+	rawData = append(rawData, domainSeparator...)
+	rawData = append(rawData, structHash...)
+	return crypto.Keccak256(rawData), nil
+}
+
+// HashEIP3009AuthorizationStruct returns the EIP-712 struct hash of a TransferWithAuthorization
+// message, the part of the digest that does not depend on the token's domain
+func HashEIP3009AuthorizationStruct(authorization ExactEIP3009Authorization) ([]byte, error) {
+	value, _ := new(big.Int).SetString(authorization.Value, 10)
+	validAfter, _ := new(big.Int).SetString(authorization.ValidAfter, 10)
+	validBefore, _ := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if value == nil || validAfter == nil || validBefore == nil {
+		return nil, fmt.Errorf("invalid authorization amounts or timestamps")
+	}
+	nonceBytes, err := HexToBytes(authorization.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, fmt.Errorf("invalid authorization nonce: %s", authorization.Nonce)
+	}
+
+	// abi.encode(typeHash, from, to, value, validAfter, validBefore, nonce)
+	encoded := make([]byte, 0, 32*7)
+	encoded = append(encoded, EIP3009TransferWithAuthorizationTypeHash...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(authorization.From).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(authorization.To).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(value.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(validAfter.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(validBefore.Bytes(), 32)...)
+	encoded = append(encoded, nonceBytes...)
+	return crypto.Keccak256(encoded), nil
+}
+
+// EIP3009CancelAuthorizationTypes are the EIP-712 type definitions for EIP-3009 CancelAuthorization
+var EIP3009CancelAuthorizationTypes = map[string][]TypedDataField{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"CancelAuthorization": {
+		{Name: "authorizer", Type: "address"},
+		{Name: "nonce", Type: "bytes32"},
+	},
+}
+
+// EIP3009CancelAuthorizationTypeHash is keccak256("CancelAuthorization(address authorizer,bytes32 nonce)")
+var EIP3009CancelAuthorizationTypeHash = crypto.Keccak256([]byte("CancelAuthorization(address authorizer,bytes32 nonce)"))
+
+// HashEIP3009Cancellation hashes a CancelAuthorization message for a full EIP-712 domain
+func HashEIP3009Cancellation(cancellation ExactEIP3009Cancellation, domain TypedDataDomain) ([]byte, error) {
+	nonceBytes, err := HexToBytes(cancellation.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, fmt.Errorf("invalid cancellation nonce: %s", cancellation.Nonce)
+	}
+
+	message := map[string]interface{}{
+		"authorizer": common.HexToAddress(cancellation.Authorizer).Hex(),
+		"nonce":      nonceBytes,
+	}
+	return HashTypedData(domain, EIP3009CancelAuthorizationTypes, "CancelAuthorization", message)
+}
+
+// HashEIP3009CancellationWithDomainSeparator hashes a CancelAuthorization message using a
+// DOMAIN_SEPARATOR read from chain, like HashEIP3009AuthorizationWithDomainSeparator
+func HashEIP3009CancellationWithDomainSeparator(cancellation ExactEIP3009Cancellation, domainSeparator []byte) ([]byte, error) {
+	if len(domainSeparator) != 32 {
+		return nil, fmt.Errorf("invalid domain separator length: %d", len(domainSeparator))
+	}
+	nonceBytes, err := HexToBytes(cancellation.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, fmt.Errorf("invalid cancellation nonce: %s", cancellation.Nonce)
+	}
+
+	// abi.encode(typeHash, authorizer, nonce)
+	encoded := make([]byte, 0, 32*3)
+	encoded = append(encoded, EIP3009CancelAuthorizationTypeHash...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(cancellation.Authorizer).Bytes(), 32)...)
+	encoded = append(encoded, nonceBytes...)
+	structHash := crypto.Keccak256(encoded)
+
+	// keccak256(0x19 || 0x01 || domainSeparator || structHash)
+	rawData := []byte{0x19, 0x01}
+	rawData = append(rawData, domainSeparator...)
+	rawData = append(rawData, structHash...)
+	return crypto.Keccak256(rawData), nil
+}
+
+// EIP2612PermitTypes are the EIP-712 type definitions for EIP-2612 Permit
+var EIP2612PermitTypes = map[string][]TypedDataField{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"Permit": {
+		{Name: "owner", Type: "address"},
+		{Name: "spender", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+}
+
+// EIP2612PermitTypeHash is keccak256("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)")
+var EIP2612PermitTypeHash = crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+// EIP2612PermitMessage builds the EIP-712 message for a permit
+func EIP2612PermitMessage(permit ExactEIP2612Permit) (map[string]interface{}, error) {
+	value, ok := new(big.Int).SetString(permit.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit value: %s", permit.Value)
+	}
+	nonce, ok := new(big.Int).SetString(permit.Nonce, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit nonce: %s", permit.Nonce)
+	}
+	deadline, ok := new(big.Int).SetString(permit.Deadline, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit deadline: %s", permit.Deadline)
+	}
+
+	return map[string]interface{}{
+		"owner":    common.HexToAddress(permit.Owner).Hex(),
+		"spender":  common.HexToAddress(permit.Spender).Hex(),
+		"value":    value,
+		"nonce":    nonce,
+		"deadline": deadline,
+	}, nil
+}
+
+// HashEIP2612Permit hashes a Permit message for EIP-2612
+//
+// This is a convenience function that wraps HashTypedData with the specific
+// types and structure used by EIP-2612's permit.
+//
+// Args:
+//
+//	permit: The EIP-2612 permit data
+//	chainID: The chain ID for the EIP-712 domain
+//	verifyingContract: The token contract address
+//	tokenName: The token name
+//	tokenVersion: The token version (often "1")
+//
+// Returns:
+//
+//	32-byte hash suitable for signing or verification
+//	error if hashing fails
+func HashEIP2612Permit(
+	permit ExactEIP2612Permit,
+	chainID *big.Int,
+	verifyingContract string,
+	tokenName string,
+	tokenVersion string,
+) ([]byte, error) {
+	domain := TypedDataDomain{
+		Name:              tokenName,
+		Version:           tokenVersion,
+		ChainID:           chainID,
+		VerifyingContract: verifyingContract,
+	}
+
+	message, err := EIP2612PermitMessage(permit)
+	if err != nil {
+		return nil, err
+	}
+
+	return HashTypedData(domain, EIP2612PermitTypes, "Permit", message)
+}
+
+// HashEIP2612PermitWithDomainSeparator hashes a Permit message using a DOMAIN_SEPARATOR read from chain
+//
+// Use this when the token's domain name/version are unknown; the result matches
+// HashEIP2612Permit when the separator was derived from the same domain.
+func HashEIP2612PermitWithDomainSeparator(permit ExactEIP2612Permit, domainSeparator []byte) ([]byte, error) {
+	if len(domainSeparator) != 32 {
+		return nil, fmt.Errorf("invalid domain separator length: %d", len(domainSeparator))
+	}
+
+	message, err := EIP2612PermitMessage(permit)
+	if err != nil {
+		return nil, err
+	}
+
+	// abi.encode(typeHash, owner, spender, value, nonce, deadline)
+	encoded := make([]byte, 0, 32*6)
+	encoded = append(encoded, EIP2612PermitTypeHash...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(permit.Owner).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(permit.Spender).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(message["value"].(*big.Int).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(message["nonce"].(*big.Int).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(message["deadline"].(*big.Int).Bytes(), 32)...)
+	structHash := crypto.Keccak256(encoded)
+
+	// keccak256(0x19 || 0x01 || domainSeparator || structHash)
+	rawData := []byte{0x19, 0x01}
+	rawData = append(rawData, domainSeparator...)
+	rawData = append(rawData, structHash...)
+	return crypto.Keccak256(rawData), nil
+}
+
+// Permit2TransferFromTypes are the EIP-712 type definitions for Permit2 PermitTransferFrom
+// The Permit2 domain has no version field.
+var Permit2TransferFromTypes = map[string][]TypedDataField{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"PermitTransferFrom": {
+		{Name: "permitted", Type: "TokenPermissions"},
+		{Name: "spender", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+	"TokenPermissions": {
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+	},
+}
+
+// Permit2DomainName is the EIP-712 domain name of the Permit2 contract
+const Permit2DomainName = "Permit2"
+
+// Permit2TransferFromMessage builds the EIP-712 message for a Permit2 PermitTransferFrom
+func Permit2TransferFromMessage(authorization ExactPermit2Authorization) (map[string]interface{}, error) {
+	amount, ok := new(big.Int).SetString(authorization.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit2 amount: %s", authorization.Amount)
+	}
+	nonce, ok := new(big.Int).SetString(authorization.Nonce, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit2 nonce: %s", authorization.Nonce)
+	}
+	deadline, ok := new(big.Int).SetString(authorization.Deadline, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit2 deadline: %s", authorization.Deadline)
+	}
+
+	return map[string]interface{}{
+		"permitted": map[string]interface{}{
+			"token":  common.HexToAddress(authorization.Token).Hex(),
+			"amount": amount,
+		},
+		"spender":  common.HexToAddress(authorization.Spender).Hex(),
+		"nonce":    nonce,
+		"deadline": deadline,
+	}, nil
+}
+
+// HashPermit2TransferFrom hashes a Permit2 PermitTransferFrom message
+//
+// Args:
+//
+//	authorization: The Permit2 authorization data
+//	chainID: The chain ID for the EIP-712 domain
+//	permit2Address: The Permit2 contract address (see GetPermit2Address)
+//
+// Returns:
+//
+//	32-byte hash suitable for signing or verification
+//	error if hashing fails
+func HashPermit2TransferFrom(
+	authorization ExactPermit2Authorization,
+	chainID *big.Int,
+	permit2Address string,
+) ([]byte, error) {
+	domain := TypedDataDomain{
+		Name:              Permit2DomainName,
+		ChainID:           chainID,
+		VerifyingContract: permit2Address,
+	}
+
+	message, err := Permit2TransferFromMessage(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return HashTypedData(domain, Permit2TransferFromTypes, "PermitTransferFrom", message)
+}
+
+// Permit2PermitSingleTypes are the EIP-712 type definitions for a Permit2 AllowanceTransfer PermitSingle
+var Permit2PermitSingleTypes = map[string][]TypedDataField{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"PermitSingle": {
+		{Name: "details", Type: "PermitDetails"},
+		{Name: "spender", Type: "address"},
+		{Name: "sigDeadline", Type: "uint256"},
+	},
+	"PermitDetails": {
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint160"},
+		{Name: "expiration", Type: "uint48"},
+		{Name: "nonce", Type: "uint48"},
+	},
+}
+
+// Permit2PermitSingleMessage builds the EIP-712 message for a Permit2 PermitSingle
+// Amount must fit in a uint160, and expiration and nonce in a uint48, as Permit2 packs them.
+func Permit2PermitSingleMessage(authorization ExactPermit2AllowanceAuthorization) (map[string]interface{}, error) {
+	amount, err := parseUintBits(authorization.Amount, 160)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permit2 amount: %w", err)
+	}
+	expiration, err := parseUintBits(authorization.Expiration, 48)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permit2 expiration: %w", err)
+	}
+	nonce, err := parseUintBits(authorization.Nonce, 48)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permit2 nonce: %w", err)
+	}
+	sigDeadline, err := parseUintBits(authorization.SigDeadline, 256)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permit2 sigDeadline: %w", err)
+	}
+
+	return map[string]interface{}{
+		"details": map[string]interface{}{
+			"token":      common.HexToAddress(authorization.Token).Hex(),
+			"amount":     amount,
+			"expiration": expiration,
+			"nonce":      nonce,
+		},
+		"spender":     common.HexToAddress(authorization.Spender).Hex(),
+		"sigDeadline": sigDeadline,
+	}, nil
+}
+
+// HashPermit2PermitSingle hashes a Permit2 PermitSingle message
+//
+// Args:
+//
+//	authorization: The Permit2 allowance data
+//	chainID: The chain ID for the EIP-712 domain
+//	permit2Address: The Permit2 contract address (see GetPermit2Address)
+//
+// Returns:
+//
+//	32-byte hash suitable for signing or verification
+//	error if hashing fails
+func HashPermit2PermitSingle(
+	authorization ExactPermit2AllowanceAuthorization,
+	chainID *big.Int,
+	permit2Address string,
+) ([]byte, error) {
+	domain := TypedDataDomain{
+		Name:              Permit2DomainName,
+		ChainID:           chainID,
+		VerifyingContract: permit2Address,
+	}
+
+	message, err := Permit2PermitSingleMessage(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return HashTypedData(domain, Permit2PermitSingleTypes, "PermitSingle", message)
+}
+
+// parseUintBits parses a decimal string as an unsigned integer of at most bits bits
+func parseUintBits(value string, bits int) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not an unsigned integer", value)
+	}
+	if n.BitLen() > bits {
+		return nil, fmt.Errorf("%s does not fit in a uint%d", value, bits)
+	}
+	return n, nil
+}
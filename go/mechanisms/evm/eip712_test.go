@@ -0,0 +1,666 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Known vector computed independently from the EIP-712 spec:
+// domain {name: "Permit Token", version: "1", chainId: 1, verifyingContract: 0xCcCC...cccC}
+// owner = address of private key 0x01, spender = address of private key 0x02
+var testPermit = ExactEIP2612Permit{
+	Owner:    "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+	Spender:  "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+	Value:    "1000000",
+	Nonce:    "0",
+	Deadline: "1700000000",
+}
+
+const (
+	testPermitToken           = "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	testPermitDomainSeparator = "046d416d750bd86efc7e4c35b1b30c6a19cd05f1fb4f3dbe44148b747d57d274"
+	testPermitDigest          = "d01847752ccad13e354973723fe6f6707e25bace14bc02fb56d1e3788582f5a4"
+)
+
+func TestEIP2612PermitTypeHash(t *testing.T) {
+	want := "6e71edae12b1b97f4d1f60370fef10105fa2faae0126114a169c64845d6126c9"
+	if got := hex.EncodeToString(EIP2612PermitTypeHash); got != want {
+		t.Errorf("PERMIT_TYPEHASH = %s, want %s", got, want)
+	}
+}
+
+func TestHashEIP2612Permit(t *testing.T) {
+	digest, err := HashEIP2612Permit(testPermit, big.NewInt(1), testPermitToken, "Permit Token", "1")
+	if err != nil {
+		t.Fatalf("HashEIP2612Permit failed: %v", err)
+	}
+	if got := hex.EncodeToString(digest); got != testPermitDigest {
+		t.Errorf("digest = %s, want %s", got, testPermitDigest)
+	}
+}
+
+func TestHashEIP2612PermitWithDomainSeparator(t *testing.T) {
+	domainSeparator, _ := hex.DecodeString(testPermitDomainSeparator)
+
+	digest, err := HashEIP2612PermitWithDomainSeparator(testPermit, domainSeparator)
+	if err != nil {
+		t.Fatalf("HashEIP2612PermitWithDomainSeparator failed: %v", err)
+	}
+	if got := hex.EncodeToString(digest); got != testPermitDigest {
+		t.Errorf("digest = %s, want %s", got, testPermitDigest)
+	}
+
+	if _, err := HashEIP2612PermitWithDomainSeparator(testPermit, domainSeparator[:31]); err == nil {
+		t.Error("expected error for short domain separator")
+	}
+}
+
+func TestHashEIP2612PermitInvalidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(p *ExactEIP2612Permit)
+	}{
+		{"invalid value", func(p *ExactEIP2612Permit) { p.Value = "abc" }},
+		{"invalid nonce", func(p *ExactEIP2612Permit) { p.Nonce = "" }},
+		{"invalid deadline", func(p *ExactEIP2612Permit) { p.Deadline = "0x10" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			permit := testPermit
+			tt.mutate(&permit)
+			if _, err := HashEIP2612Permit(permit, big.NewInt(1), testPermitToken, "Permit Token", "1"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPermitPayloadRoundTrip(t *testing.T) {
+	payload := &ExactEIP2612Payload{Signature: "0xabcd", Permit: testPermit}
+
+	data := payload.ToMap()
+	if !IsPermitPayload(data) {
+		t.Fatal("expected permit payload to be detected")
+	}
+	if IsPermitPayload((&ExactEIP3009Payload{}).ToMap()) {
+		t.Error("expected EIP-3009 payload not to be detected as permit")
+	}
+
+	parsed, err := PermitPayloadFromMap(data)
+	if err != nil {
+		t.Fatalf("PermitPayloadFromMap failed: %v", err)
+	}
+	if *parsed != *payload {
+		t.Errorf("round trip mismatch: got %+v, want %+v", parsed, payload)
+	}
+}
+
+func TestHashPermit2TransferFrom(t *testing.T) {
+	// Vector computed independently; the domain separator matches the Permit2
+	// DOMAIN_SEPARATOR deployed on Ethereum mainnet (866a5aba...3f28).
+	authorization := ExactPermit2Authorization{
+		From:     "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+		Token:    testPermitToken,
+		Amount:   "1000000",
+		Spender:  "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+		Nonce:    "42",
+		Deadline: "1700000000",
+	}
+
+	digest, err := HashPermit2TransferFrom(authorization, big.NewInt(1), CanonicalPermit2Address)
+	if err != nil {
+		t.Fatalf("HashPermit2TransferFrom failed: %v", err)
+	}
+
+	want := "1020760c58f83efbb70dd7d06c5692b7d885d41e457007dcc5b95444f85705b9"
+	if got := hex.EncodeToString(digest); got != want {
+		t.Errorf("digest = %s, want %s", got, want)
+	}
+
+	// The owner is not part of the signed struct, but every signed field is
+	changed := authorization
+	changed.Spender = changed.From
+	other, err := HashPermit2TransferFrom(changed, big.NewInt(1), CanonicalPermit2Address)
+	if err != nil {
+		t.Fatalf("HashPermit2TransferFrom failed: %v", err)
+	}
+	if hex.EncodeToString(other) == want {
+		t.Error("expected spender to change the digest")
+	}
+}
+
+func TestHashPermit2PermitSingle(t *testing.T) {
+	authorization := ExactPermit2AllowanceAuthorization{
+		From:        "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+		Token:       testPermitToken,
+		Amount:      "1000000",
+		Expiration:  "1700003600",
+		Nonce:       "7",
+		Spender:     "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+		SigDeadline: "1700000000",
+	}
+
+	digest, err := HashPermit2PermitSingle(authorization, big.NewInt(1), CanonicalPermit2Address)
+	if err != nil {
+		t.Fatalf("HashPermit2PermitSingle failed: %v", err)
+	}
+
+	// Rebuild the digest from the type hashes in Permit2's PermitHash library and the
+	// mainnet Permit2 DOMAIN_SEPARATOR
+	word := func(n int64) []byte { return common.LeftPadBytes(big.NewInt(n).Bytes(), 32) }
+	address := func(a string) []byte { return common.LeftPadBytes(common.HexToAddress(a).Bytes(), 32) }
+	permitDetailsTypeHash, _ := hex.DecodeString("65626cad6cb96493bf6f5ebea28756c966f023ab9e8a83a7101849d5573b3678")
+	permitSingleTypeHash, _ := hex.DecodeString("f3841cd1ff0085026a6327b620b67997ce40f282c88a8e905a7a5626e310f3d0")
+	domainSeparator, _ := hex.DecodeString("866a5aba21966af95d6c7ab78eb2b2fc913915c28be3b9aa07cc04ff903e3f28")
+
+	detailsHash := crypto.Keccak256(permitDetailsTypeHash, address(testPermitToken), word(1000000), word(1700003600), word(7))
+	structHash := crypto.Keccak256(permitSingleTypeHash, detailsHash, address(authorization.Spender), word(1700000000))
+	want := crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+	if !bytes.Equal(digest, want) {
+		t.Errorf("digest = %x, want %x", digest, want)
+	}
+
+	// Permit2 packs amount into a uint160 and expiration and nonce into uint48s
+	for name, mutate := range map[string]func(*ExactPermit2AllowanceAuthorization){
+		"amount": func(a *ExactPermit2AllowanceAuthorization) { a.Amount = new(big.Int).Lsh(big.NewInt(1), 160).String() },
+		"expiration": func(a *ExactPermit2AllowanceAuthorization) {
+			a.Expiration = new(big.Int).Lsh(big.NewInt(1), 48).String()
+		},
+		"nonce": func(a *ExactPermit2AllowanceAuthorization) { a.Nonce = "-1" },
+	} {
+		invalid := authorization
+		mutate(&invalid)
+		if _, err := HashPermit2PermitSingle(invalid, big.NewInt(1), CanonicalPermit2Address); err == nil {
+			t.Errorf("expected an error for an out-of-range %s", name)
+		}
+	}
+}
+
+func TestPermit2AllowancePayloadRoundTrip(t *testing.T) {
+	payload := &ExactPermit2AllowancePayload{
+		Signature: "0xabcd",
+		Permit2Allowance: ExactPermit2AllowanceAuthorization{
+			From: "0x1", Token: "0x2", Amount: "3", Expiration: "4", Nonce: "5", Spender: "0x6", SigDeadline: "7",
+		},
+	}
+
+	data := payload.ToMap()
+	if !IsPermit2AllowancePayload(data) || IsPermit2Payload(data) {
+		t.Fatal("expected payload to be detected as a Permit2 PermitSingle only")
+	}
+
+	parsed, err := Permit2AllowancePayloadFromMap(data)
+	if err != nil {
+		t.Fatalf("Permit2AllowancePayloadFromMap failed: %v", err)
+	}
+	if *parsed != *payload {
+		t.Errorf("round trip mismatch: got %+v, want %+v", parsed, payload)
+	}
+}
+
+func TestGetPermit2Address(t *testing.T) {
+	if got := GetPermit2Address("eip155:1"); got != CanonicalPermit2Address {
+		t.Errorf("GetPermit2Address(eip155:1) = %s, want canonical", got)
+	}
+
+	const custom = "0x1111111111111111111111111111111111111111"
+	if err := RegisterNetwork("eip155:999999", NetworkConfig{ChainID: big.NewInt(999999), Permit2Address: custom}); err != nil {
+		t.Fatalf("RegisterNetwork failed: %v", err)
+	}
+
+	if got := GetPermit2Address("eip155:999999"); got != custom {
+		t.Errorf("GetPermit2Address(eip155:999999) = %s, want %s", got, custom)
+	}
+}
+
+func TestPermit2PayloadRoundTrip(t *testing.T) {
+	payload := &ExactPermit2Payload{
+		Signature: "0xabcd",
+		Permit2Authorization: ExactPermit2Authorization{
+			From: "0x1", Token: "0x2", Amount: "3", Spender: "0x4", Nonce: "5", Deadline: "6",
+		},
+	}
+
+	data := payload.ToMap()
+	if !IsPermit2Payload(data) || IsPermitPayload(data) {
+		t.Fatal("expected payload to be detected as Permit2 only")
+	}
+
+	parsed, err := Permit2PayloadFromMap(data)
+	if err != nil {
+		t.Fatalf("Permit2PayloadFromMap failed: %v", err)
+	}
+	if *parsed != *payload {
+		t.Errorf("round trip mismatch: got %+v, want %+v", parsed, payload)
+	}
+}
+
+// Base USDC TransferWithAuthorization signed by private key 1; vectors computed independently
+var testTransferAuthorization = ExactEIP3009Authorization{
+	From:        "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+	To:          "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+	Value:       "1000000",
+	ValidAfter:  "0",
+	ValidBefore: "1735689600",
+	Nonce:       "0x1111111111111111111111111111111111111111111111111111111111111111",
+}
+
+const (
+	testBaseUSDC                    = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	testBaseUSDCDomainSeparator     = "02fa7265e7c5d81118673727957699e4d68f74cd74b7db77da710fe8a2c7834f"
+	testTransferAuthorizationDigest = "112eed222b7cec581dfb84da0e888fe289008fe536a75a63cb28e5b97d5e2fbd"
+	testTransferAuthorizationSig    = "0xa7b9cb2176ac7bee57cdfc9f90d3616a3e965263bdbf712d70eee57907a60aa247aaa71ea52f90c4e0000229449d871b364d0439503d56b28142a24a0a65066d1c"
+)
+
+func TestHashEIP712Domain(t *testing.T) {
+	domainSeparator, err := HashEIP712Domain(TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: testBaseUSDC,
+	})
+	if err != nil {
+		t.Fatalf("HashEIP712Domain failed: %v", err)
+	}
+	if got := hex.EncodeToString(domainSeparator); got != testBaseUSDCDomainSeparator {
+		t.Errorf("domain separator = %s, want %s", got, testBaseUSDCDomainSeparator)
+	}
+
+	if _, err := HashEIP712Domain(TypedDataDomain{Name: "USD Coin", Version: "2", VerifyingContract: testBaseUSDC}); err == nil {
+		t.Error("expected error for missing chain ID")
+	}
+}
+
+func TestHashEIP3009AuthorizationDigest(t *testing.T) {
+	digest, err := HashEIP3009Authorization(testTransferAuthorization, big.NewInt(8453), testBaseUSDC, "USD Coin", "2")
+	if err != nil {
+		t.Fatalf("HashEIP3009Authorization failed: %v", err)
+	}
+	if got := hex.EncodeToString(digest); got != testTransferAuthorizationDigest {
+		t.Errorf("digest = %s, want %s", got, testTransferAuthorizationDigest)
+	}
+
+	domainSeparator, _ := hex.DecodeString(testBaseUSDCDomainSeparator)
+	withSeparator, err := HashEIP3009AuthorizationWithDomainSeparator(testTransferAuthorization, domainSeparator)
+	if err != nil {
+		t.Fatalf("HashEIP3009AuthorizationWithDomainSeparator failed: %v", err)
+	}
+	if got := hex.EncodeToString(withSeparator); got != testTransferAuthorizationDigest {
+		t.Errorf("digest with domain separator = %s, want %s", got, testTransferAuthorizationDigest)
+	}
+
+	// A signature produced over the raw digest is accepted for the authorization
+	signature, _ := HexToBytes(testTransferAuthorizationSig)
+	valid, err := VerifyEOASignature(digest, signature, common.HexToAddress(testTransferAuthorization.From))
+	if err != nil || !valid {
+		t.Errorf("expected signature to recover to %s, got valid=%v err=%v", testTransferAuthorization.From, valid, err)
+	}
+
+	tampered := testTransferAuthorization
+	tampered.Value = "1000001"
+	tamperedDigest, _ := HashEIP3009AuthorizationWithDomainSeparator(tampered, domainSeparator)
+	if valid, _ := VerifyEOASignature(tamperedDigest, signature, common.HexToAddress(testTransferAuthorization.From)); valid {
+		t.Error("expected signature not to match a tampered authorization")
+	}
+
+	if _, err := HashEIP3009AuthorizationWithDomainSeparator(testTransferAuthorization, domainSeparator[:31]); err == nil {
+		t.Error("expected error for short domain separator")
+	}
+}
+
+func TestHashEIP712DomainSalt(t *testing.T) {
+	unsalted := TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: testBaseUSDC,
+	}
+	salted := unsalted
+	salted.Salt = common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000002105")
+
+	plain, err := HashEIP712Domain(unsalted)
+	if err != nil {
+		t.Fatalf("HashEIP712Domain failed: %v", err)
+	}
+	withSalt, err := HashEIP712Domain(salted)
+	if err != nil {
+		t.Fatalf("HashEIP712Domain with salt failed: %v", err)
+	}
+	if hex.EncodeToString(plain) != testBaseUSDCDomainSeparator {
+		t.Errorf("expected the zero salt to leave the domain separator unchanged, got %x", plain)
+	}
+	if bytes.Equal(plain, withSalt) {
+		t.Error("expected the salt to change the domain separator")
+	}
+
+	// The typed-data digest hashes the salted domain like the on-chain separator
+	digest, err := HashEIP3009AuthorizationWithDomain(testTransferAuthorization, salted)
+	if err != nil {
+		t.Fatalf("HashEIP3009AuthorizationWithDomain failed: %v", err)
+	}
+	withSeparator, err := HashEIP3009AuthorizationWithDomainSeparator(testTransferAuthorization, withSalt)
+	if err != nil {
+		t.Fatalf("HashEIP3009AuthorizationWithDomainSeparator failed: %v", err)
+	}
+	if !bytes.Equal(digest, withSeparator) {
+		t.Errorf("salted digest = %x, want %x", digest, withSeparator)
+	}
+	if hex.EncodeToString(digest) == testTransferAuthorizationDigest {
+		t.Error("expected the salted digest to differ from the unsalted one")
+	}
+
+	unsaltedDigest, err := HashEIP3009AuthorizationWithDomain(testTransferAuthorization, unsalted)
+	if err != nil || hex.EncodeToString(unsaltedDigest) != testTransferAuthorizationDigest {
+		t.Errorf("unsalted digest = %x (err %v), want %s", unsaltedDigest, err, testTransferAuthorizationDigest)
+	}
+}
+
+func TestDomainSaltFromExtra(t *testing.T) {
+	saltHex := "0x000000000000000000000000000000000000000000000000000000000000abcd"
+
+	tests := []struct {
+		name    string
+		extra   map[string]interface{}
+		want    [32]byte
+		wantErr bool
+	}{
+		{name: "nil extra"},
+		{name: "no salt", extra: map[string]interface{}{"name": "USD Coin"}},
+		{name: "salt", extra: map[string]interface{}{"salt": saltHex}, want: common.HexToHash(saltHex)},
+		{name: "short salt", extra: map[string]interface{}{"salt": "0xabcd"}, wantErr: true},
+		{name: "missing 0x prefix", extra: map[string]interface{}{"salt": saltHex[2:]}, wantErr: true},
+		{name: "not a string", extra: map[string]interface{}{"salt": 42}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			salt, err := DomainSaltFromExtra(tt.extra)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got salt %x", salt)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if salt != tt.want {
+				t.Errorf("salt = %x, want %x", salt, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashEIP712DomainWithoutChainID(t *testing.T) {
+	salt := common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000002105")
+
+	tests := []struct {
+		name     string
+		salt     [32]byte
+		typeName string
+	}{
+		{name: "without salt", typeName: "EIP712Domain(string name,string version,address verifyingContract)"},
+		{name: "with salt", salt: salt, typeName: "EIP712Domain(string name,string version,address verifyingContract,bytes32 salt)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withChainID := TypedDataDomain{
+				Name:              "USD Coin",
+				Version:           "2",
+				ChainID:           big.NewInt(8453),
+				VerifyingContract: testBaseUSDC,
+				Salt:              tt.salt,
+			}
+			legacy := withChainID
+			legacy.OmitChainID = true
+
+			// Encoded by hand from the EIP-712 spec, without the chainId member
+			encoded := crypto.Keccak256([]byte(tt.typeName))
+			encoded = append(encoded, crypto.Keccak256([]byte("USD Coin"))...)
+			encoded = append(encoded, crypto.Keccak256([]byte("2"))...)
+			encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(testBaseUSDC).Bytes(), 32)...)
+			if tt.salt != ([32]byte{}) {
+				encoded = append(encoded, tt.salt[:]...)
+			}
+			want := crypto.Keccak256(encoded)
+
+			separator, err := HashEIP712Domain(legacy)
+			if err != nil {
+				t.Fatalf("HashEIP712Domain failed: %v", err)
+			}
+			if !bytes.Equal(separator, want) {
+				t.Errorf("domain separator = %x, want %x", separator, want)
+			}
+
+			// The chain ID is ignored, so it may be missing
+			legacy.ChainID = nil
+			if again, err := HashEIP712Domain(legacy); err != nil || !bytes.Equal(again, want) {
+				t.Errorf("domain separator without chain ID = %x (err %v), want %x", again, err, want)
+			}
+
+			// The typed-data digest drops chainId from the domain type too
+			digest, err := HashEIP3009AuthorizationWithDomain(testTransferAuthorization, legacy)
+			if err != nil {
+				t.Fatalf("HashEIP3009AuthorizationWithDomain failed: %v", err)
+			}
+			withSeparator, err := HashEIP3009AuthorizationWithDomainSeparator(testTransferAuthorization, want)
+			if err != nil {
+				t.Fatalf("HashEIP3009AuthorizationWithDomainSeparator failed: %v", err)
+			}
+			if !bytes.Equal(digest, withSeparator) {
+				t.Errorf("digest = %x, want %x", digest, withSeparator)
+			}
+
+			chainDigest, err := HashEIP3009AuthorizationWithDomain(testTransferAuthorization, withChainID)
+			if err != nil {
+				t.Fatalf("HashEIP3009AuthorizationWithDomain failed: %v", err)
+			}
+			if bytes.Equal(digest, chainDigest) {
+				t.Error("expected the digest without chainId to differ from the one with it")
+			}
+
+			// Callers listing the full domain type get the fields the domain has
+			listed, err := HashTypedData(legacy, map[string][]TypedDataField{
+				"EIP712Domain":              withChainID.Fields(),
+				"TransferWithAuthorization": transferWithAuthorizationFields(),
+			}, "TransferWithAuthorization", transferWithAuthorizationMessage(t))
+			if err != nil {
+				t.Fatalf("HashTypedData failed: %v", err)
+			}
+			if !bytes.Equal(listed, withSeparator) {
+				t.Errorf("digest with listed domain fields = %x, want %x", listed, withSeparator)
+			}
+		})
+	}
+}
+
+// transferWithAuthorizationFields is the EIP-3009 TransferWithAuthorization type
+func transferWithAuthorizationFields() []TypedDataField {
+	return []TypedDataField{
+		{Name: "from", Type: "address"},
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "validAfter", Type: "uint256"},
+		{Name: "validBefore", Type: "uint256"},
+		{Name: "nonce", Type: "bytes32"},
+	}
+}
+
+// transferWithAuthorizationMessage is testTransferAuthorization as a typed-data message
+func transferWithAuthorizationMessage(t *testing.T) map[string]interface{} {
+	t.Helper()
+	nonce, err := HexToBytes(testTransferAuthorization.Nonce)
+	if err != nil {
+		t.Fatalf("invalid nonce: %v", err)
+	}
+	value, _ := new(big.Int).SetString(testTransferAuthorization.Value, 10)
+	validAfter, _ := new(big.Int).SetString(testTransferAuthorization.ValidAfter, 10)
+	validBefore, _ := new(big.Int).SetString(testTransferAuthorization.ValidBefore, 10)
+	return map[string]interface{}{
+		"from":        common.HexToAddress(testTransferAuthorization.From).Hex(),
+		"to":          common.HexToAddress(testTransferAuthorization.To).Hex(),
+		"value":       value,
+		"validAfter":  validAfter,
+		"validBefore": validBefore,
+		"nonce":       nonce,
+	}
+}
+
+func TestExactDomain(t *testing.T) {
+	asset := &AssetInfo{Address: testBaseUSDC, Name: "USD Coin", Version: "2"}
+	legacyAsset := &AssetInfo{Address: testBaseUSDC, Name: "USD Coin", Version: "2", OmitChainID: true}
+	chainID := big.NewInt(8453)
+
+	tests := []struct {
+		name     string
+		asset    *AssetInfo
+		extra    map[string]interface{}
+		wantOmit bool
+		wantName string
+		wantErr  bool
+	}{
+		{name: "asset defaults", asset: asset, wantName: "USD Coin"},
+		{name: "extra overrides", asset: asset, extra: map[string]interface{}{"name": "Legacy USD", "omitChainId": true}, wantOmit: true, wantName: "Legacy USD"},
+		{name: "asset config", asset: legacyAsset, wantOmit: true, wantName: "USD Coin"},
+		{name: "extra restores chainId", asset: legacyAsset, extra: map[string]interface{}{"omitChainId": false}, wantName: "USD Coin"},
+		{name: "invalid flag", asset: asset, extra: map[string]interface{}{"omitChainId": "yes"}, wantErr: true},
+		{name: "invalid salt", asset: asset, extra: map[string]interface{}{"salt": "0xabcd"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, err := ExactDomain(chainID, tt.asset, tt.extra)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got domain %+v", domain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if domain.OmitChainID != tt.wantOmit || domain.Name != tt.wantName || domain.Version != "2" ||
+				domain.ChainID.Cmp(chainID) != 0 || domain.VerifyingContract != testBaseUSDC {
+				t.Errorf("unexpected domain %+v", domain)
+			}
+		})
+	}
+}
+
+func TestEIP3009CancelAuthorizationTypeHash(t *testing.T) {
+	want := "158b0a9edf7a828aad02f63cd515c68ef2f50ba807396f6d12842833a1597429"
+	if got := hex.EncodeToString(EIP3009CancelAuthorizationTypeHash); got != want {
+		t.Errorf("CANCEL_AUTHORIZATION_TYPEHASH = %s, want %s", got, want)
+	}
+}
+
+func TestHashEIP3009Cancellation(t *testing.T) {
+	cancellation := ExactEIP3009Cancellation{
+		Authorizer: testTransferAuthorization.From,
+		Nonce:      testTransferAuthorization.Nonce,
+	}
+	domainSeparator, _ := hex.DecodeString(testBaseUSDCDomainSeparator)
+
+	// structHash = keccak256(abi.encode(CANCEL_AUTHORIZATION_TYPEHASH, authorizer, nonce))
+	nonce, _ := HexToBytes(cancellation.Nonce)
+	structHash := crypto.Keccak256(
+		EIP3009CancelAuthorizationTypeHash,
+		common.LeftPadBytes(common.HexToAddress(cancellation.Authorizer).Bytes(), 32),
+		nonce,
+	)
+	want := crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+
+	withSeparator, err := HashEIP3009CancellationWithDomainSeparator(cancellation, domainSeparator)
+	if err != nil {
+		t.Fatalf("HashEIP3009CancellationWithDomainSeparator failed: %v", err)
+	}
+	if !bytes.Equal(withSeparator, want) {
+		t.Errorf("digest with domain separator = %x, want %x", withSeparator, want)
+	}
+
+	typed, err := HashEIP3009Cancellation(cancellation, TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: testBaseUSDC,
+	})
+	if err != nil {
+		t.Fatalf("HashEIP3009Cancellation failed: %v", err)
+	}
+	if !bytes.Equal(typed, want) {
+		t.Errorf("typed data digest = %x, want %x", typed, want)
+	}
+
+	// A cancellation never hashes like the transfer authorization it cancels
+	transfer, _ := HashEIP3009AuthorizationWithDomainSeparator(testTransferAuthorization, domainSeparator)
+	if bytes.Equal(transfer, want) {
+		t.Error("expected the cancellation digest to differ from the authorization digest")
+	}
+
+	if _, err := HashEIP3009CancellationWithDomainSeparator(cancellation, domainSeparator[:31]); err == nil {
+		t.Error("expected error for short domain separator")
+	}
+	if _, err := HashEIP3009CancellationWithDomainSeparator(ExactEIP3009Cancellation{Authorizer: cancellation.Authorizer, Nonce: "0x01"}, domainSeparator); err == nil {
+		t.Error("expected error for short nonce")
+	}
+}
+
+func TestTypedDataDomainJSON(t *testing.T) {
+	var salt [32]byte
+	salt[31] = 0x2a
+	tests := []struct {
+		name   string
+		domain TypedDataDomain
+		want   string
+	}{
+		{
+			name:   "plain",
+			domain: TypedDataDomain{Name: "USD Coin", Version: "2", ChainID: big.NewInt(8453), VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"},
+			want:   `{"name":"USD Coin","version":"2","chainId":8453,"verifyingContract":"0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}`,
+		},
+		{
+			name:   "salted without chainId",
+			domain: TypedDataDomain{Name: "Legacy", Version: "1", VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC", Salt: salt, OmitChainID: true},
+			want:   `{"name":"Legacy","version":"1","verifyingContract":"0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC","salt":"0x000000000000000000000000000000000000000000000000000000000000002a"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.domain)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, data)
+			}
+
+			var decoded TypedDataDomain
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.domain) {
+				t.Errorf("Expected the round trip to give %+v, got %+v", tt.domain, decoded)
+			}
+			if !reflect.DeepEqual(decoded.Fields(), tt.domain.Fields()) {
+				t.Errorf("Expected the same domain fields, got %v", decoded.Fields())
+			}
+		})
+	}
+
+	// Salts encoded as byte arrays still decode
+	var legacy TypedDataDomain
+	if err := json.Unmarshal([]byte(`{"name":"Legacy","chainId":1,"salt":[`+strings.Repeat("0,", 31)+`42]}`), &legacy); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if legacy.Salt != salt || legacy.OmitChainID {
+		t.Errorf("Expected the array salt to decode, got %+v", legacy)
+	}
+}
@@ -33,6 +33,43 @@ func IsERC6492Signature(sig []byte) bool {
 	return bytes.Equal(sig[len(sig)-32:], erc6492MagicBytes)
 }
 
+// WrapERC6492Signature wraps a signature for a counterfactual smart contract account
+//
+// ERC-6492 Format:
+//
+//	abi.encode((address factory, bytes factoryCalldata, bytes signature)) + magicBytes
+//
+// Args:
+//
+//	factory: The CREATE2 factory address (hex)
+//	factoryCalldata: Calldata that deploys the wallet
+//	sig: The inner signature (EIP-1271 or EOA) to wrap
+//
+// Returns:
+//
+//	The wrapped signature, ending with the ERC-6492 magic value
+//	error if the factory address is invalid or encoding fails
+func WrapERC6492Signature(factory string, factoryCalldata []byte, sig []byte) ([]byte, error) {
+	if !IsValidAddress(factory) {
+		return nil, fmt.Errorf("invalid ERC-6492 factory address: %s", factory)
+	}
+	if len(factoryCalldata) == 0 {
+		return nil, fmt.Errorf("ERC-6492 factory calldata is required")
+	}
+
+	arguments, err := erc6492Arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := arguments.Pack(common.HexToAddress(factory), factoryCalldata, sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ERC-6492 signature: %w", err)
+	}
+
+	return append(encoded, erc6492MagicBytes...), nil
+}
+
 // ParseERC6492Signature unwraps an ERC-6492 signature to extract its components
 //
 // ERC-6492 Format:
@@ -61,21 +98,10 @@ func ParseERC6492Signature(sig []byte) (*ERC6492SignatureData, error) {
 	// Strip magic value
 	payload := sig[:len(sig)-32]
 
-	// Define ABI types for (address, bytes, bytes)
-	addressTy, err := abi.NewType("address", "", nil)
+	arguments, err := erc6492Arguments()
 	if err != nil {
 		return nil, err
 	}
-	bytesTy, err := abi.NewType("bytes", "", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	arguments := abi.Arguments{
-		{Type: addressTy}, // factory
-		{Type: bytesTy},   // factoryCalldata
-		{Type: bytesTy},   // originalSignature
-	}
 
 	// Unpack the ABI-encoded data
 	unpacked, err := arguments.Unpack(payload)
@@ -112,3 +138,21 @@ func ParseERC6492Signature(sig []byte) (*ERC6492SignatureData, error) {
 		InnerSignature:  innerSignature,
 	}, nil
 }
+
+// erc6492Arguments returns the ABI arguments (address factory, bytes factoryCalldata, bytes signature)
+func erc6492Arguments() (abi.Arguments, error) {
+	addressTy, err := abi.NewType("address", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	bytesTy, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return abi.Arguments{
+		{Type: addressTy}, // factory
+		{Type: bytesTy},   // factoryCalldata
+		{Type: bytesTy},   // originalSignature
+	}, nil
+}
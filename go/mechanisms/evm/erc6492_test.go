@@ -274,3 +274,43 @@ func bytesEqual(a, b []byte) bool {
 	}
 	return true
 }
+
+// TestWrapERC6492Signature tests that wrapped signatures round-trip through ParseERC6492Signature
+func TestWrapERC6492Signature(t *testing.T) {
+	factory := "0x1234567890123456789012345678901234567890"
+	calldata := []byte{0xde, 0xad, 0xbe, 0xef}
+	inner := make([]byte, 65)
+	inner[64] = 27
+
+	wrapped, err := WrapERC6492Signature(factory, calldata, inner)
+	if err != nil {
+		t.Fatalf("WrapERC6492Signature failed: %v", err)
+	}
+	if !IsERC6492Signature(wrapped) {
+		t.Fatal("expected wrapped signature to end with the ERC-6492 magic value")
+	}
+
+	parsed, err := ParseERC6492Signature(wrapped)
+	if err != nil {
+		t.Fatalf("ParseERC6492Signature failed: %v", err)
+	}
+	if common.BytesToAddress(parsed.Factory[:]) != common.HexToAddress(factory) {
+		t.Errorf("factory = %x, want %s", parsed.Factory, factory)
+	}
+	if string(parsed.FactoryCalldata) != string(calldata) {
+		t.Errorf("factoryCalldata = %x, want %x", parsed.FactoryCalldata, calldata)
+	}
+	if string(parsed.InnerSignature) != string(inner) {
+		t.Errorf("innerSignature = %x, want %x", parsed.InnerSignature, inner)
+	}
+}
+
+// TestWrapERC6492SignatureInvalid tests rejection of invalid deployment data
+func TestWrapERC6492SignatureInvalid(t *testing.T) {
+	if _, err := WrapERC6492Signature("0x123", []byte{1}, make([]byte, 65)); err == nil {
+		t.Error("expected error for invalid factory")
+	}
+	if _, err := WrapERC6492Signature("0x1234567890123456789012345678901234567890", nil, make([]byte, 65)); err == nil {
+		t.Error("expected error for empty factory calldata")
+	}
+}
@@ -0,0 +1,123 @@
+package evmtest
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// assembler builds EVM bytecode, so the mock token needs no Solidity toolchain
+type assembler struct {
+	code   []byte
+	labels map[string]int // label -> JUMPDEST offset
+	jumps  map[int]string // offset of a PUSH2 operand -> label it refers to
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: map[string]int{}, jumps: map[int]string{}}
+}
+
+// op appends opcodes
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push appends the shortest PUSH of value
+func (a *assembler) push(value uint64) *assembler {
+	return a.pushBytes(new(big.Int).SetUint64(value).Bytes())
+}
+
+// pushBytes appends a PUSH of up to 32 bytes (PUSH1 0 for none)
+func (a *assembler) pushBytes(value []byte) *assembler {
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	if len(value) > 32 {
+		panic(fmt.Sprintf("evmtest: cannot push %d bytes", len(value)))
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(value)-1))
+	a.code = append(a.code, value...)
+	return a
+}
+
+// pushLabel appends a PUSH2 of a label's offset, resolved by bytecode
+func (a *assembler) pushLabel(label string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2), 0, 0)
+	a.jumps[len(a.code)-2] = label
+	return a
+}
+
+// jumpIf jumps to label when the top of the stack is non-zero
+func (a *assembler) jumpIf(label string) *assembler {
+	return a.pushLabel(label).op(vm.JUMPI)
+}
+
+// label marks a jump destination
+func (a *assembler) label(name string) *assembler {
+	if _, ok := a.labels[name]; ok {
+		panic("evmtest: duplicate label " + name)
+	}
+	a.labels[name] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+// mstore stores the top of the stack at memory offset
+func (a *assembler) mstore(offset uint64) *assembler {
+	return a.push(offset).op(vm.MSTORE)
+}
+
+// calldataWord pushes the 32-byte calldata word at offset
+func (a *assembler) calldataWord(offset uint64) *assembler {
+	return a.push(offset).op(vm.CALLDATALOAD)
+}
+
+// keccak pushes keccak256(memory[offset:offset+size])
+func (a *assembler) keccak(offset, size uint64) *assembler {
+	return a.push(size).push(offset).op(vm.KECCAK256)
+}
+
+// returnWord returns the top of the stack as a 32-byte word
+func (a *assembler) returnWord() *assembler {
+	return a.mstore(0).push(32).push(0).op(vm.RETURN)
+}
+
+// returnString returns s ABI-encoded as a string (at most 32 bytes)
+func (a *assembler) returnString(s string) *assembler {
+	a.push(32).mstore(0)
+	a.push(uint64(len(s))).mstore(32)
+	a.pushBytes(rightPad([]byte(s))).mstore(64)
+	return a.push(96).push(0).op(vm.RETURN)
+}
+
+// revertWith reverts with Error(reason), reason being at most 32 bytes
+func (a *assembler) revertWith(reason string) *assembler {
+	a.pushBytes(rightPad([]byte{0x08, 0xc3, 0x79, 0xa0})).mstore(0)
+	a.push(32).mstore(4)
+	a.push(uint64(len(reason))).mstore(36)
+	a.pushBytes(rightPad([]byte(reason))).mstore(68)
+	return a.push(100).push(0).op(vm.REVERT)
+}
+
+// bytecode resolves the labels and returns the code
+func (a *assembler) bytecode() []byte {
+	code := append([]byte(nil), a.code...)
+	for offset, label := range a.jumps {
+		target, ok := a.labels[label]
+		if !ok {
+			panic("evmtest: undefined label " + label)
+		}
+		code[offset], code[offset+1] = byte(target>>8), byte(target)
+	}
+	return code
+}
+
+// rightPad pads b with zeros to 32 bytes, as ABI-encoded bytes and strings are
+func rightPad(b []byte) []byte {
+	padded := make([]byte, 32)
+	copy(padded, b)
+	return padded
+}
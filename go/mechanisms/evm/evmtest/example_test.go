@@ -0,0 +1,77 @@
+package evmtest_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402 "github.com/gatechain/x402/go"
+	"github.com/gatechain/x402/go/mechanisms/evm/evmtest"
+	"github.com/gatechain/x402/go/mechanisms/evm/exact/client"
+	"github.com/gatechain/x402/go/mechanisms/evm/exact/facilitator"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+)
+
+// TestSettlePayment signs a payment with the exact client and settles it on the simulated chain
+func TestSettlePayment(t *testing.T) {
+	ctx := context.Background()
+
+	key, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(key.PublicKey)
+	payTo := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	token, err := evmtest.NewToken(&evmtest.TokenConfig{
+		Balances: map[common.Address]*big.Int{payer: big.NewInt(1_000_000)},
+	})
+	if err != nil {
+		t.Fatalf("NewToken failed: %v", err)
+	}
+	defer token.Close()
+
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(common.Bytes2Hex(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatalf("NewClientSignerFromPrivateKey failed: %v", err)
+	}
+	scheme := client.NewExactEvmScheme(signer)
+	if err := scheme.SetRPCURL(token.URL()); err != nil {
+		t.Fatalf("SetRPCURL failed: %v", err)
+	}
+	scheme.SetValidateDomain(true)
+
+	requirements := token.Requirements(payTo.Hex(), "1000")
+	payload, err := scheme.CreatePaymentPayload(ctx, requirements)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	payload.Accepted = requirements
+
+	settler := facilitator.NewExactEvmScheme(token.FacilitatorSigner(), nil)
+	if err := settler.SimulateSettle(ctx, payload, requirements); err != nil {
+		t.Fatalf("SimulateSettle failed: %v", err)
+	}
+	result, err := settler.Settle(ctx, payload, requirements)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if !result.Success || result.Transaction == "" || result.Network != x402.Network(token.Network()) {
+		t.Errorf("Unexpected settle response: %+v", result)
+	}
+
+	if balance, _ := token.BalanceOf(ctx, payer); balance.Int64() != 999_000 {
+		t.Errorf("Expected payer balance 999000, got %v", balance)
+	}
+	if balance, _ := token.BalanceOf(ctx, payTo); balance.Int64() != 1000 {
+		t.Errorf("Expected payTo balance 1000, got %v", balance)
+	}
+
+	// The authorization can only be settled once
+	_, err = settler.Settle(ctx, payload, requirements)
+	var settleErr *x402.SettleError
+	if !errors.As(err, &settleErr) || settleErr.Reason != facilitator.ErrNonceAlreadyUsed {
+		t.Errorf("Expected %s, got %v", facilitator.ErrNonceAlreadyUsed, err)
+	}
+}
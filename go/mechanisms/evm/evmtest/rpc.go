@@ -0,0 +1,107 @@
+package evmtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// URL returns an HTTP JSON-RPC endpoint for the simulated chain, for code that dials an RPC
+// URL (such as the exact client's SetRPCURL). The server is started on first use and
+// stopped by Close.
+func (t *Token) URL() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rpcServer == nil {
+		t.rpcServer = httptest.NewServer(rpcBridge{client: t.Client.Client()})
+	}
+	return t.rpcServer.URL
+}
+
+// rpcBridge forwards JSON-RPC requests (single or batched) to the in-process client
+type rpcBridge struct {
+	client *rpc.Client
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (b rpcBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var requests []rpcRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		responses := make([]rpcResponse, len(requests))
+		for i, request := range requests {
+			responses[i] = b.forward(r, request)
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var request rpcRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(b.forward(r, request))
+}
+
+// forward makes one call, keeping the error code and data (e.g. revert data) of failures
+func (b rpcBridge) forward(r *http.Request, request rpcRequest) rpcResponse {
+	response := rpcResponse{JSONRPC: "2.0", ID: request.ID}
+
+	params := make([]interface{}, len(request.Params))
+	for i, param := range request.Params {
+		params[i] = param
+	}
+
+	var result json.RawMessage
+	if err := b.client.CallContext(r.Context(), &result, request.Method, params...); err != nil {
+		response.Error = &rpcError{Code: -32000, Message: err.Error()}
+		var codeErr rpc.Error
+		if errors.As(err, &codeErr) {
+			response.Error.Code = codeErr.ErrorCode()
+		}
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) {
+			response.Error.Data = dataErr.ErrorData()
+		}
+		return response
+	}
+
+	if len(result) == 0 {
+		result = json.RawMessage("null")
+	}
+	response.Result = result
+	return response
+}
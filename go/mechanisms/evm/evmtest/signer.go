@@ -0,0 +1,143 @@
+package evmtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+)
+
+// submitGasLimit is the gas limit of the transactions FacilitatorSigner sends
+const submitGasLimit = 500_000
+
+// FacilitatorSigner returns an evm.FacilitatorEvmSigner for the simulated chain, sending
+// transactions from an account funded at genesis. WaitForTransactionReceipt mines the
+// pending transactions, so Settle completes without calling Backend.Commit.
+func (t *Token) FacilitatorSigner() evm.FacilitatorEvmSigner {
+	return &facilitatorSigner{token: t}
+}
+
+type facilitatorSigner struct {
+	token *Token
+}
+
+func (s *facilitatorSigner) address() common.Address {
+	return crypto.PubkeyToAddress(s.token.submitter.PublicKey)
+}
+
+func (s *facilitatorSigner) GetAddresses() []string {
+	return []string{s.address().Hex()}
+}
+
+func (s *facilitatorSigner) ReadContract(ctx context.Context, address string, abiJSON []byte, functionName string, args ...interface{}) (interface{}, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	data, err := contractABI.Pack(functionName, args...)
+	if err != nil {
+		return nil, err
+	}
+	to := common.HexToAddress(address)
+	result, err := s.token.Client.CallContract(ctx, ethereum.CallMsg{From: s.address(), To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := contractABI.Methods[functionName].Outputs.Unpack(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s result: %w", functionName, err)
+	}
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+	return outputs[0], nil
+}
+
+func (s *facilitatorSigner) VerifyTypedData(ctx context.Context, address string, domain evm.TypedDataDomain, types map[string][]evm.TypedDataField, primaryType string, message map[string]interface{}, signature []byte) (bool, error) {
+	hash, err := evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return false, err
+	}
+	return evm.VerifyEOASignature(hash, signature, common.HexToAddress(address))
+}
+
+func (s *facilitatorSigner) WriteContract(ctx context.Context, address string, abiJSON []byte, functionName string, args ...interface{}) (string, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return "", err
+	}
+	data, err := contractABI.Pack(functionName, args...)
+	if err != nil {
+		return "", err
+	}
+	return s.SendTransaction(ctx, address, data)
+}
+
+func (s *facilitatorSigner) SendTransaction(ctx context.Context, to string, data []byte) (string, error) {
+	client := s.token.Client
+	nonce, err := client.PendingNonceAt(ctx, s.address())
+	if err != nil {
+		return "", err
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	tx := types.NewTransaction(nonce, common.HexToAddress(to), big.NewInt(0), submitGasLimit, gasPrice, data)
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(s.token.ChainID), s.token.submitter)
+	if err != nil {
+		return "", err
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return "", err
+	}
+	return signed.Hash().Hex(), nil
+}
+
+func (s *facilitatorSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	hash := common.HexToHash(txHash)
+	receipt, err := s.token.Client.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		s.token.Backend.Commit()
+		receipt, err = s.token.Client.TransactionReceipt(ctx, hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt of %s: %w", txHash, err)
+	}
+	return &evm.TransactionReceipt{
+		Status:      receipt.Status,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		TxHash:      receipt.TxHash.Hex(),
+	}, nil
+}
+
+func (s *facilitatorSigner) GetBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	result, err := s.ReadContract(ctx, tokenAddress, evm.BalanceOfABI, evm.FunctionBalanceOf, common.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := result.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected balanceOf result %T", result)
+	}
+	return balance, nil
+}
+
+func (s *facilitatorSigner) GetChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(s.token.ChainID), nil
+}
+
+func (s *facilitatorSigner) GetCode(ctx context.Context, address string) ([]byte, error) {
+	return s.token.Client.CodeAt(ctx, common.HexToAddress(address), nil)
+}
@@ -0,0 +1,388 @@
+// Package evmtest runs a mock EIP-3009 token on go-ethereum's simulated backend, so the exact
+// EVM scheme can be tested end-to-end (DOMAIN_SEPARATOR queries, authorizationState checks,
+// settlement and its simulation) deterministically and without a live RPC.
+//
+// Example:
+//
+//	token, err := evmtest.NewToken(&evmtest.TokenConfig{
+//	    Balances: map[common.Address]*big.Int{payer: big.NewInt(1_000_000)},
+//	})
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer token.Close()
+//
+//	scheme := client.NewExactEvmScheme(signer)
+//	_ = scheme.SetRPCURL(token.URL())
+//	payload, _ := scheme.CreatePaymentPayload(ctx, token.Requirements(payTo, "1000"))
+//
+//	facilitator := facilitator.NewExactEvmScheme(token.FacilitatorSigner(), nil)
+//	_, err = facilitator.Settle(ctx, payload, requirements)
+package evmtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	x402types "github.com/gatechain/x402/go/types"
+)
+
+// Token defaults, used for empty TokenConfig fields
+const (
+	DefaultTokenName     = "Test USD"
+	DefaultTokenVersion  = "2"
+	DefaultTokenDecimals = 6
+)
+
+// DefaultTokenAddress is where the token is deployed unless TokenConfig.Address is set
+var DefaultTokenAddress = common.HexToAddress("0x0000000000000000000000000000000000003009")
+
+// Revert reasons of transferWithAuthorization (returned as Error(string))
+const (
+	ReasonAuthorizationNotYetValid = "authorization is not yet valid"
+	ReasonAuthorizationExpired     = "authorization is expired"
+	ReasonAuthorizationUsed        = "authorization is used"
+	ReasonInvalidSignature         = "invalid signature"
+	ReasonInsufficientBalance      = "transfer amount exceeds balance"
+)
+
+// Storage layout of the token: the domain separator in slot 0, balances at
+// keccak256(account . 1) and authorization states at keccak256(authorizer . nonce . 2)
+const (
+	domainSeparatorSlot = 0
+	balancesSlot        = 1
+	authorizationsSlot  = 2
+)
+
+// submitterBalance is the ether given to the account FacilitatorSigner sends transactions from
+var submitterBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+
+// TokenConfig configures the mock token (nil or empty fields use the defaults)
+type TokenConfig struct {
+	Name     string // EIP-712 domain name and name() (at most 32 bytes)
+	Version  string // EIP-712 domain version and version() (at most 32 bytes)
+	Decimals uint8  // decimals(); zero uses DefaultTokenDecimals
+	Address  common.Address
+
+	// Balances are the initial token balances
+	Balances map[common.Address]*big.Int
+
+	// EtherBalances are the initial native balances, e.g. of accounts that pay for gas
+	EtherBalances map[common.Address]*big.Int
+}
+
+// Token is a mock EIP-3009 token on a simulated chain. It implements DOMAIN_SEPARATOR(),
+// authorizationState(address,bytes32), balanceOf(address), name(), version(), decimals() and
+// the v,r,s transferWithAuthorization, which checks the validity window, the nonce, the
+// ECDSA signature over the EIP-712 digest and the balance like USDC does.
+//
+// Transactions are only mined by Backend.Commit; FacilitatorSigner commits while waiting
+// for receipts.
+type Token struct {
+	Backend *simulated.Backend
+	Client  *ethclient.Client // Client of the simulated chain, for code that needs *ethclient.Client
+
+	Address         common.Address
+	ChainID         *big.Int
+	Name            string
+	Version         string
+	Decimals        uint8
+	DomainSeparator [32]byte
+
+	submitter *ecdsa.PrivateKey
+
+	mu        sync.Mutex
+	rpcServer *httptest.Server
+}
+
+// NewToken starts a simulated chain with the token deployed at config.Address
+func NewToken(config *TokenConfig) (*Token, error) {
+	cfg := TokenConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultTokenName
+	}
+	if cfg.Version == "" {
+		cfg.Version = DefaultTokenVersion
+	}
+	if cfg.Decimals == 0 {
+		cfg.Decimals = DefaultTokenDecimals
+	}
+	if cfg.Address == (common.Address{}) {
+		cfg.Address = DefaultTokenAddress
+	}
+	if len(cfg.Name) > 32 || len(cfg.Version) > 32 {
+		return nil, fmt.Errorf("token name and version must be at most 32 bytes")
+	}
+
+	chainID := params.AllDevChainProtocolChanges.ChainID
+	domainSeparator, err := evm.HashEIP712Domain(evm.TypedDataDomain{
+		Name:              cfg.Name,
+		Version:           cfg.Version,
+		ChainID:           chainID,
+		VerifyingContract: cfg.Address.Hex(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	storage := map[common.Hash]common.Hash{
+		common.BigToHash(big.NewInt(domainSeparatorSlot)): common.BytesToHash(domainSeparator),
+	}
+	for account, balance := range cfg.Balances {
+		storage[balanceSlot(account)] = common.BigToHash(balance)
+	}
+
+	submitter, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	alloc := types.GenesisAlloc{
+		cfg.Address: {Code: tokenCode(cfg.Name, cfg.Version, cfg.Decimals), Storage: storage},
+		crypto.PubkeyToAddress(submitter.PublicKey): {Balance: submitterBalance},
+	}
+	for account, balance := range cfg.EtherBalances {
+		if _, ok := alloc[account]; ok {
+			continue
+		}
+		alloc[account] = types.Account{Balance: new(big.Int).Set(balance)}
+	}
+	backend := simulated.NewBackend(alloc, func(_ *node.Config, ethConf *ethconfig.Config) {
+		ethConf.Genesis.Timestamp = uint64(time.Now().Unix())
+	})
+	// Move past genesis so calls see a current block timestamp
+	backend.Commit()
+
+	// The simulated client wraps an *ethclient.Client in an embedded field named Client, which
+	// shadows the Client method, so the field is read by reflection.
+	client, ok := reflect.ValueOf(backend.Client()).FieldByName("Client").Interface().(*ethclient.Client)
+	if !ok {
+		_ = backend.Close()
+		return nil, fmt.Errorf("simulated client does not wrap an *ethclient.Client")
+	}
+
+	return &Token{
+		Backend:         backend,
+		Client:          client,
+		Address:         cfg.Address,
+		ChainID:         new(big.Int).Set(chainID),
+		Name:            cfg.Name,
+		Version:         cfg.Version,
+		Decimals:        cfg.Decimals,
+		DomainSeparator: [32]byte(domainSeparator),
+		submitter:       submitter,
+	}, nil
+}
+
+// Close stops the RPC server started by URL and the simulated chain
+func (t *Token) Close() error {
+	t.mu.Lock()
+	if t.rpcServer != nil {
+		t.rpcServer.Close()
+		t.rpcServer = nil
+	}
+	t.mu.Unlock()
+	return t.Backend.Close()
+}
+
+// Network returns the CAIP-2 network of the simulated chain (eip155:1337)
+func (t *Token) Network() string {
+	return "eip155:" + t.ChainID.String()
+}
+
+// AssetInfo describes the token for evm.RegisterAsset
+func (t *Token) AssetInfo() evm.AssetInfo {
+	return evm.AssetInfo{
+		Address:  t.Address.Hex(),
+		Name:     t.Name,
+		Version:  t.Version,
+		Decimals: int(t.Decimals),
+	}
+}
+
+// Requirements returns exact-scheme requirements paying amount (in the smallest unit) of
+// the token to payTo, with the EIP-712 name and version in Extra
+func (t *Token) Requirements(payTo, amount string) x402types.PaymentRequirements {
+	return x402types.PaymentRequirements{
+		Scheme:            evm.SchemeExact,
+		Network:           t.Network(),
+		Asset:             t.Address.Hex(),
+		Amount:            amount,
+		PayTo:             payTo,
+		MaxTimeoutSeconds: 60,
+		Extra:             map[string]interface{}{"name": t.Name, "version": t.Version},
+	}
+}
+
+// BalanceOf returns the token balance of account
+func (t *Token) BalanceOf(ctx context.Context, account common.Address) (*big.Int, error) {
+	result, err := t.call(ctx, evm.BalanceOfABI, evm.FunctionBalanceOf, account)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+// AuthorizationState reports whether authorizer's nonce has been used
+func (t *Token) AuthorizationState(ctx context.Context, authorizer common.Address, nonce [32]byte) (bool, error) {
+	result, err := t.call(ctx, evm.AuthorizationStateABI, evm.FunctionAuthorizationState, authorizer, nonce)
+	if err != nil {
+		return false, err
+	}
+	return new(big.Int).SetBytes(result).Sign() != 0, nil
+}
+
+func (t *Token) call(ctx context.Context, abiJSON []byte, method string, args ...interface{}) ([]byte, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return t.Client.CallContract(ctx, ethereum.CallMsg{To: &t.Address, Data: data}, nil)
+}
+
+// balanceSlot returns the storage slot of account's balance
+func balanceSlot(account common.Address) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(account.Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(balancesSlot).Bytes(), 32),
+	)
+}
+
+// selector returns the 4-byte function selector of signature
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// tokenCode assembles the token's runtime code
+func tokenCode(name, version string, decimals uint8) []byte {
+	a := newAssembler()
+
+	// Dispatch on the selector
+	a.push(0).op(vm.CALLDATALOAD).push(224).op(vm.SHR)
+	for _, fn := range []struct{ signature, label string }{
+		{"DOMAIN_SEPARATOR()", "domainSeparator"},
+		{"authorizationState(address,bytes32)", "authorizationState"},
+		{"balanceOf(address)", "balanceOf"},
+		{"name()", "name"},
+		{"version()", "version"},
+		{"decimals()", "decimals"},
+		{"transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)", "transferWithAuthorization"},
+	} {
+		a.op(vm.DUP1).pushBytes(selector(fn.signature)).op(vm.EQ).jumpIf(fn.label)
+	}
+	a.push(0).push(0).op(vm.REVERT)
+
+	a.label("domainSeparator").push(domainSeparatorSlot).op(vm.SLOAD).returnWord()
+
+	a.label("authorizationState")
+	authorizationSlot(a, 4, 36)
+	a.op(vm.SLOAD).returnWord()
+
+	a.label("balanceOf").calldataWord(4)
+	balanceSlotOf(a)
+	a.op(vm.SLOAD).returnWord()
+
+	a.label("name").returnString(name)
+	a.label("version").returnString(version)
+	a.label("decimals").push(uint64(decimals)).returnWord()
+
+	// transferWithAuthorization(from@4, to@36, value@68, validAfter@100, validBefore@132,
+	// nonce@164, v@196, r@228, s@260)
+	a.label("transferWithAuthorization")
+
+	// validAfter < block.timestamp < validBefore
+	a.op(vm.TIMESTAMP).calldataWord(100).op(vm.LT, vm.ISZERO).jumpIf("notYetValid")
+	a.calldataWord(132).op(vm.TIMESTAMP).op(vm.LT, vm.ISZERO).jumpIf("expired")
+
+	// The nonce must be unused; its slot stays on the stack
+	authorizationSlot(a, 4, 164)
+	a.op(vm.DUP1, vm.SLOAD).jumpIf("used")
+
+	// structHash = keccak256(typeHash . from . to . value . validAfter . validBefore . nonce)
+	a.pushBytes(evm.EIP3009TransferWithAuthorizationTypeHash).mstore(0)
+	a.push(192).push(4).push(32).op(vm.CALLDATACOPY)
+	a.keccak(0, 224)
+
+	// digest = keccak256(0x1901 . domainSeparator . structHash)
+	a.pushBytes(rightPad([]byte{0x19, 0x01})).mstore(0)
+	a.push(domainSeparatorSlot).op(vm.SLOAD).mstore(2)
+	a.mstore(34)
+	a.keccak(0, 66)
+
+	// ecrecover(digest, v, r, s) must be the non-zero from address
+	a.mstore(0)
+	a.push(96).push(196).push(32).op(vm.CALLDATACOPY)
+	a.push(0).mstore(128)
+	a.push(32).push(128).push(128).push(0).push(1).op(vm.GAS, vm.STATICCALL, vm.POP)
+	a.calldataWord(4).op(vm.ISZERO).jumpIf("invalidSignature")
+	a.push(128).op(vm.MLOAD).calldataWord(4).op(vm.EQ, vm.ISZERO).jumpIf("invalidSignature")
+
+	// Mark the nonce used
+	a.push(1).op(vm.SWAP1, vm.SSTORE)
+
+	// balances[from] -= value
+	a.calldataWord(4)
+	balanceSlotOf(a)
+	a.op(vm.DUP1, vm.SLOAD).calldataWord(68).op(vm.DUP2, vm.DUP2, vm.GT).jumpIf("insufficientBalance")
+	a.op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+
+	// balances[to] += value
+	a.calldataWord(36)
+	balanceSlotOf(a)
+	a.op(vm.DUP1, vm.SLOAD).calldataWord(68).op(vm.ADD, vm.SWAP1, vm.SSTORE)
+
+	// emit AuthorizationUsed(from, nonce) and Transfer(from, to, value)
+	a.calldataWord(164).calldataWord(4).pushBytes(crypto.Keccak256([]byte("AuthorizationUsed(address,bytes32)"))).push(0).push(0).op(vm.LOG3)
+	a.calldataWord(68).mstore(0)
+	a.calldataWord(36).calldataWord(4).pushBytes(crypto.Keccak256([]byte("Transfer(address,address,uint256)"))).push(32).push(0).op(vm.LOG3)
+	a.op(vm.STOP)
+
+	a.label("notYetValid").revertWith(ReasonAuthorizationNotYetValid)
+	a.label("expired").revertWith(ReasonAuthorizationExpired)
+	a.label("used").revertWith(ReasonAuthorizationUsed)
+	a.label("invalidSignature").revertWith(ReasonInvalidSignature)
+	a.label("insufficientBalance").revertWith(ReasonInsufficientBalance)
+
+	return a.bytecode()
+}
+
+// balanceSlotOf replaces the account on top of the stack with its balance slot
+func balanceSlotOf(a *assembler) {
+	a.mstore(0).push(balancesSlot).mstore(32).keccak(0, 64)
+}
+
+// authorizationSlot pushes the authorization state slot of the authorizer and nonce at
+// the given calldata offsets
+func authorizationSlot(a *assembler, authorizerOffset, nonceOffset uint64) {
+	a.calldataWord(authorizerOffset).mstore(0)
+	a.calldataWord(nonceOffset).mstore(32)
+	a.push(authorizationsSlot).mstore(64)
+	a.keccak(0, 96)
+}
@@ -0,0 +1,155 @@
+package evmtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+)
+
+func newTestToken(t *testing.T, balances map[common.Address]*big.Int) *Token {
+	t.Helper()
+	token, err := NewToken(&TokenConfig{Balances: balances})
+	if err != nil {
+		t.Fatalf("NewToken failed: %v", err)
+	}
+	t.Cleanup(func() { _ = token.Close() })
+	return token
+}
+
+func TestTokenMetadata(t *testing.T) {
+	ctx := context.Background()
+	token := newTestToken(t, nil)
+
+	if token.Network() != "eip155:1337" {
+		t.Errorf("Expected eip155:1337, got %s", token.Network())
+	}
+
+	domainSeparator, err := evm.QueryDomainSeparator(ctx, token.Client, token.Address.Hex())
+	if err != nil {
+		t.Fatalf("QueryDomainSeparator failed: %v", err)
+	}
+	want, _ := evm.HashEIP712Domain(evm.TypedDataDomain{Name: DefaultTokenName, Version: DefaultTokenVersion, ChainID: big.NewInt(1337), VerifyingContract: token.Address.Hex()})
+	if !bytes.Equal(domainSeparator, want) || !bytes.Equal(domainSeparator, token.DomainSeparator[:]) {
+		t.Errorf("Expected domain separator %x, got %x", want, domainSeparator)
+	}
+
+	info, err := evm.NewChainAssetResolver(token.Client).ResolveAsset(ctx, token.Network(), token.Address.Hex())
+	if err != nil {
+		t.Fatalf("ResolveAsset failed: %v", err)
+	}
+	if info.Name != DefaultTokenName || info.Version != DefaultTokenVersion || info.Decimals != DefaultTokenDecimals {
+		t.Errorf("Unexpected asset info: %+v", info)
+	}
+}
+
+func TestTokenTransferWithAuthorization(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(key.PublicKey)
+	payee := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := newTestToken(t, map[common.Address]*big.Int{payer: big.NewInt(1000)})
+	signer := token.FacilitatorSigner()
+
+	now := time.Now().Unix()
+	authorize := func(value int64, validAfter, validBefore int64, nonce byte, signingKey func() []byte) []interface{} {
+		authorization := evm.ExactEIP3009Authorization{
+			From:        payer.Hex(),
+			To:          payee.Hex(),
+			Value:       big.NewInt(value).String(),
+			ValidAfter:  big.NewInt(validAfter).String(),
+			ValidBefore: big.NewInt(validBefore).String(),
+			Nonce:       "0x" + strings.Repeat("0", 62) + common.Bytes2Hex([]byte{nonce}),
+		}
+		digest, err := evm.HashEIP3009AuthorizationWithDomainSeparator(authorization, token.DomainSeparator[:])
+		if err != nil {
+			t.Fatalf("HashEIP3009AuthorizationWithDomainSeparator failed: %v", err)
+		}
+		signature := signingKey()
+		if signature == nil {
+			signature, _ = crypto.Sign(digest, key)
+		}
+		var nonceBytes [32]byte
+		nonceBytes[31] = nonce
+		return []interface{}{
+			payer, payee, big.NewInt(value), big.NewInt(validAfter), big.NewInt(validBefore), nonceBytes,
+			signature[64] + 27, [32]byte(signature[:32]), [32]byte(signature[32:64]),
+		}
+	}
+	payerKey := func() []byte { return nil }
+	otherKey := func() []byte {
+		other, _ := crypto.GenerateKey()
+		signature, _ := crypto.Sign(crypto.Keccak256([]byte("unrelated")), other)
+		return signature
+	}
+
+	tests := []struct {
+		name       string
+		args       []interface{}
+		wantReason string
+	}{
+		{name: "not yet valid", args: authorize(100, now+3600, now+7200, 1, payerKey), wantReason: ReasonAuthorizationNotYetValid},
+		{name: "expired", args: authorize(100, 0, now-60, 1, payerKey), wantReason: ReasonAuthorizationExpired},
+		{name: "wrong signer", args: authorize(100, 0, now+3600, 1, otherKey), wantReason: ReasonInvalidSignature},
+		{name: "insufficient balance", args: authorize(1001, 0, now+3600, 1, payerKey), wantReason: ReasonInsufficientBalance},
+		{name: "valid", args: authorize(100, 0, now+3600, 1, payerKey)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.ReadContract(ctx, token.Address.Hex(), evm.TransferWithAuthorizationVRSABI, evm.FunctionTransferWithAuthorization, tt.args...)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("Expected the call to succeed, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(revertData(err), common.Bytes2Hex([]byte(tt.wantReason))) {
+				t.Errorf("Expected revert %q, got %v", tt.wantReason, err)
+			}
+		})
+	}
+
+	// Submit the valid authorization, then replay it
+	valid := authorize(100, 0, now+3600, 1, payerKey)
+	for i, wantStatus := range []uint64{evm.TxStatusSuccess, 0} {
+		txHash, err := signer.WriteContract(ctx, token.Address.Hex(), evm.TransferWithAuthorizationVRSABI, evm.FunctionTransferWithAuthorization, valid...)
+		if err != nil {
+			t.Fatalf("WriteContract failed: %v", err)
+		}
+		receipt, err := signer.WaitForTransactionReceipt(ctx, txHash)
+		if err != nil || receipt.Status != wantStatus {
+			t.Fatalf("Submission %d: expected status %d, got %+v, %v", i, wantStatus, receipt, err)
+		}
+	}
+
+	if balance, _ := token.BalanceOf(ctx, payer); balance.Int64() != 900 {
+		t.Errorf("Expected payer balance 900, got %v", balance)
+	}
+	if balance, _ := token.BalanceOf(ctx, payee); balance.Int64() != 100 {
+		t.Errorf("Expected payee balance 100, got %v", balance)
+	}
+	used, err := token.AuthorizationState(ctx, payer, valid[5].([32]byte))
+	if err != nil || !used {
+		t.Errorf("Expected the nonce to be used, got %v, %v", used, err)
+	}
+}
+
+// revertData returns the hex revert data of a failed eth_call
+func revertData(err error) string {
+	var dataErr interface{ ErrorData() interface{} }
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			return data
+		}
+	}
+	return ""
+}
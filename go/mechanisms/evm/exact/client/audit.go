@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+)
+
+// DomainSource says where the EIP-712 domain separator of a signature came from
+type DomainSource string
+
+const (
+	// DomainSourceChain is the token's DOMAIN_SEPARATOR() read over the RPC
+	DomainSourceChain DomainSource = "chain"
+	// DomainSourceComputed is derived from the token name, version, chain ID and salt
+	DomainSourceComputed DomainSource = "computed"
+	// DomainSourceHardcoded is a separator built into the scheme for a known token
+	DomainSourceHardcoded DomainSource = "hardcoded"
+)
+
+// SigningAuditRecord is what one CreatePaymentPayload call signed. Digest is
+// keccak256(0x19 || 0x01 || DomainSeparator || StructHash), and Signature is the signature
+// placed in the payload (ERC-6492 wrapped for undeployed smart wallets).
+type SigningAuditRecord struct {
+	Network       string
+	Asset         string
+	Authorization evm.ExactEIP3009Authorization
+
+	DomainSource    DomainSource
+	DomainSeparator []byte
+	StructHash      []byte
+	Digest          []byte
+	Signature       []byte
+}
+
+// AuditSink receives a SigningAuditRecord for every payload an ExactEvmScheme signs, e.g. to
+// write a compliance log. RecordSigning is called before CreatePaymentPayload returns, so it
+// should not block; implementations must be safe for concurrent use.
+type AuditSink interface {
+	RecordSigning(ctx context.Context, record SigningAuditRecord)
+}
+
+// SetAuditSink sets the sink that receives the intermediate values of every signature (optional)
+// Nil stops auditing.
+func (c *ExactEvmScheme) SetAuditSink(sink AuditSink) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auditSink = sink
+}
+
+// audit hands record to the audit sink, if any
+func (c *ExactEvmScheme) audit(ctx context.Context, record *SigningAuditRecord) {
+	c.mu.RLock()
+	sink := c.auditSink
+	c.mu.RUnlock()
+	if sink != nil {
+		sink.RecordSigning(ctx, *record)
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+)
+
+// recordingAuditSink keeps every record it receives
+type recordingAuditSink struct {
+	mu      sync.Mutex
+	records []SigningAuditRecord
+}
+
+func (s *recordingAuditSink) RecordSigning(ctx context.Context, record SigningAuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+}
+
+func TestExactEvmSchemeAuditSink(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	ctx := context.Background()
+	computed := domainSeparatorFor(t, evm.TypedDataDomain{
+		Name:              "Permit Token",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: testPermitToken,
+	})
+	onChain := domainSeparatorFor(t, evm.TypedDataDomain{
+		Name:              "Permit Token",
+		Version:           "2",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: testPermitToken,
+	})
+
+	tests := []struct {
+		name       string
+		rpc        bool
+		wantSource DomainSource
+		wantSep    []byte
+	}{
+		{name: "computed", wantSource: DomainSourceComputed, wantSep: computed},
+		{name: "chain", rpc: true, wantSource: DomainSourceChain, wantSep: onChain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingAuditSink{}
+			scheme := NewExactEvmScheme(signer)
+			scheme.SetDomainSeparatorCache(nil)
+			scheme.SetAuditSink(sink)
+			if tt.rpc {
+				rpc := fakeTokenRPCWithCalls(t, map[string][]byte{"DOMAIN_SEPARATOR()": onChain}, nil)
+				if err := scheme.SetRPCURL(rpc.URL); err != nil {
+					t.Fatalf("SetRPCURL failed: %v", err)
+				}
+			}
+
+			payload, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000"))
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+			if len(sink.records) != 1 {
+				t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+			}
+			record := sink.records[0]
+			evmPayload, err := evm.PayloadFromMap(payload.Payload)
+			if err != nil {
+				t.Fatalf("PayloadFromMap failed: %v", err)
+			}
+
+			if record.DomainSource != tt.wantSource || !bytes.Equal(record.DomainSeparator, tt.wantSep) {
+				t.Errorf("expected %s separator %x, got %s %x", tt.wantSource, tt.wantSep, record.DomainSource, record.DomainSeparator)
+			}
+			if record.Network != "eip155:1" || !strings.EqualFold(record.Asset, testPermitToken) || record.Authorization != evmPayload.Authorization {
+				t.Errorf("unexpected record context: %+v", record)
+			}
+
+			// Every value can be reproduced from the payload and the separator
+			structHash, err := evm.HashEIP3009AuthorizationStruct(evmPayload.Authorization)
+			if err != nil || !bytes.Equal(record.StructHash, structHash) {
+				t.Errorf("expected struct hash %x, got %x (%v)", structHash, record.StructHash, err)
+			}
+			digest := crypto.Keccak256([]byte{0x19, 0x01}, record.DomainSeparator, record.StructHash)
+			if !bytes.Equal(record.Digest, digest) {
+				t.Errorf("expected digest %x, got %x", digest, record.Digest)
+			}
+			if evm.BytesToHex(record.Signature) != evmPayload.Signature {
+				t.Errorf("expected the payload signature %s, got %x", evmPayload.Signature, record.Signature)
+			}
+			if ok, err := evm.VerifyEOASignature(record.Digest, record.Signature, common.HexToAddress(signer.Address())); !ok || err != nil {
+				t.Errorf("expected the signature to verify against the digest (%v)", err)
+			}
+		})
+	}
+
+	// Removing the sink stops auditing
+	sink := &recordingAuditSink{}
+	scheme := NewExactEvmScheme(signer)
+	scheme.SetAuditSink(sink)
+	scheme.SetAuditSink(nil)
+	if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000")); err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	if len(sink.records) != 0 {
+		t.Errorf("expected no audit records, got %d", len(sink.records))
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+// CreateCancelPayload signs an EIP-3009 CancelAuthorization for nonce, so a payer can
+// invalidate an authorization before the facilitator settles it. Submitting the result
+// with the token's cancelAuthorization (evm.CancelAuthorizationVRSABI) marks the nonce
+// used; whichever of the cancellation and the transfer lands first wins.
+//
+// requirements must be the ones the authorization was created for: their network, asset
+// and extra name/version/salt/omitChainId select the EIP-712 domain, as in CreatePaymentPayload. The
+// nonce is marked settled in the nonce store, since it can no longer be paid with.
+func (c *ExactEvmScheme) CreateCancelPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+	nonce [32]byte,
+) (*evm.ExactEIP3009CancelPayload, error) {
+	authorizer, err := evm.ChecksumAddress(c.signer.Address())
+	if err != nil {
+		return nil, fmt.Errorf(ErrInvalidAddress+": signer: %w", err)
+	}
+
+	networkStr := string(requirements.Network)
+	chainID, err := c.resolveChainID(ctx, networkStr)
+	if err != nil {
+		return nil, err
+	}
+	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := evm.ExactDomain(chainID, assetInfo, requirements.Extra)
+	if err != nil {
+		return nil, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+	}
+
+	c.mu.RLock()
+	offline := c.offline
+	c.mu.RUnlock()
+	if offline {
+		if err := checkOfflineDomain(networkStr, assetInfo.Address, requirements.Extra); err != nil {
+			return nil, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+		}
+	}
+
+	cancellation := evm.ExactEIP3009Cancellation{
+		Authorizer: authorizer,
+		Nonce:      evm.BytesToHex(nonce[:]),
+	}
+	domainSeparator, _, err := c.resolveDomainSeparator(ctx, chainID, assetInfo, domain)
+	if err != nil {
+		return nil, fmt.Errorf(ErrFailedToSignCancellation+": %w", err)
+	}
+	digest, err := evm.HashEIP3009CancellationWithDomainSeparator(cancellation, domainSeparator)
+	if err != nil {
+		return nil, fmt.Errorf(ErrFailedToSignCancellation+": %w", err)
+	}
+	signature, err := c.signDigest(ctx, digest)
+	if err != nil {
+		return nil, fmt.Errorf(ErrFailedToSignCancellation+": %w", err)
+	}
+
+	if store := c.store(); store != nil {
+		if err := store.MarkSettled(ctx, nonce); err != nil {
+			return nil, nonceStoreError(err)
+		}
+	}
+
+	return &evm.ExactEIP3009CancelPayload{
+		Network:      networkStr,
+		Asset:        assetInfo.Address,
+		Signature:    evm.BytesToHex(signature),
+		Cancellation: cancellation,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+)
+
+func TestExactEvmSchemeCreateCancelPayload(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	scheme := NewExactEvmScheme(signer)
+	requirements := testExactRequirements("1000")
+	nonce := [32]byte{0xca, 0xfe}
+
+	if _, err := scheme.CreatePaymentPayloadWithNonce(context.Background(), requirements, nonce); err != nil {
+		t.Fatalf("CreatePaymentPayloadWithNonce failed: %v", err)
+	}
+
+	cancel, err := scheme.CreateCancelPayload(context.Background(), requirements, nonce)
+	if err != nil {
+		t.Fatalf("CreateCancelPayload failed: %v", err)
+	}
+	if cancel.Network != "eip155:1" || cancel.Asset != evm.NormalizeAddress(testPermitToken) {
+		t.Errorf("unexpected network/asset: %s %s", cancel.Network, cancel.Asset)
+	}
+	if cancel.Cancellation.Authorizer != signer.Address() || cancel.Cancellation.Nonce != evm.BytesToHex(nonce[:]) {
+		t.Errorf("unexpected cancellation: %+v", cancel.Cancellation)
+	}
+
+	digest, err := evm.HashEIP3009Cancellation(cancel.Cancellation, evm.TypedDataDomain{
+		Name:              "Permit Token",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: testPermitToken,
+	})
+	if err != nil {
+		t.Fatalf("HashEIP3009Cancellation failed: %v", err)
+	}
+	signature, _ := evm.HexToBytes(cancel.Signature)
+	if valid, err := evm.VerifyEOASignature(digest, signature, common.HexToAddress(signer.Address())); err != nil || !valid {
+		t.Errorf("expected the cancellation to be signed by %s, got valid=%v err=%v", signer.Address(), valid, err)
+	}
+
+	// The cancelled nonce cannot be paid with again
+	_, err = scheme.CreatePaymentPayloadWithNonce(context.Background(), requirements, nonce)
+	if !errors.Is(err, evm.ErrNonceSettled) {
+		t.Errorf("expected ErrNonceSettled after cancelling, got %v", err)
+	}
+}
@@ -1,7 +1,79 @@
 package client
 
+import (
+	"fmt"
+	"math/big"
+)
+
 // Client error constants for the exact EVM scheme (V2)
 const (
+	ErrInvalidRequirements       = "invalid_exact_evm_client_requirements"
 	ErrInvalidAmount             = "invalid_exact_evm_client_amount"
 	ErrFailedToSignAuthorization = "invalid_exact_evm_client_failed_to_sign_authorization"
+	ErrMissingPermitSpender      = "invalid_exact_evm_client_missing_permit_spender"
+	ErrFailedToQueryPermitNonce  = "invalid_exact_evm_client_failed_to_query_permit_nonce"
+	ErrFailedToSignPermit        = "invalid_exact_evm_client_failed_to_sign_permit"
+	ErrFailedToSignPermit2       = "invalid_exact_evm_client_failed_to_sign_permit2"
+	ErrInsufficientBalance       = "invalid_exact_evm_client_insufficient_balance"
+	ErrFailedToCheckBalance      = "invalid_exact_evm_client_failed_to_check_balance"
+	ErrInvalidValidityWindow     = "invalid_exact_evm_client_validity_window"
+	ErrFailedToWrapSignature     = "invalid_exact_evm_client_failed_to_wrap_signature"
+	ErrDomainMismatch            = "invalid_exact_evm_client_domain_mismatch"
+	ErrFailedToValidateDomain    = "invalid_exact_evm_client_failed_to_validate_domain"
+	ErrChainIDMismatch           = "invalid_exact_evm_client_chain_id_mismatch"
+	ErrFailedToEstimateGas       = "invalid_exact_evm_client_failed_to_estimate_gas"
+	ErrInvalidAddress            = "invalid_exact_evm_client_address"
+	ErrNonceAlreadyUsed          = "invalid_exact_evm_client_nonce_already_used"
+	ErrFailedToReserveNonce      = "invalid_exact_evm_client_failed_to_reserve_nonce"
+	ErrFailedToSignCancellation  = "invalid_exact_evm_client_failed_to_sign_cancellation"
+	ErrAmountExceedsMax          = "invalid_exact_evm_client_amount_exceeds_max"
+	ErrFailedToQueryPermit2Nonce = "invalid_exact_evm_client_failed_to_query_permit2_nonce"
+	ErrFailedToSignPermitSingle  = "invalid_exact_evm_client_failed_to_sign_permit_single"
+	ErrZeroAmount                = "invalid_exact_evm_client_zero_amount"
 )
+
+// InsufficientBalanceError is returned by CreatePaymentPayload when the balance
+// precheck finds the payer holds less than the required amount
+type InsufficientBalanceError struct {
+	Address  string   // Payer address
+	Asset    string   // Token contract address
+	Required *big.Int // Amount the payment requires
+	Balance  *big.Int // Balance reported by the token
+}
+
+// Error implements the error interface
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("%s: %s holds %s of %s, needs %s", ErrInsufficientBalance, e.Address, e.Balance, e.Asset, e.Required)
+}
+
+// DomainMismatchError is returned by CreatePaymentPayload when domain validation finds that
+// the token name/version it would sign with do not match the token's EIP-712 domain
+type DomainMismatchError struct {
+	Asset          string // Token contract address
+	Name           string // Name the payload would be signed with
+	Version        string // Version the payload would be signed with
+	OnChainName    string // name() reported by the token (empty when unavailable)
+	OnChainVersion string // version() reported by the token (empty when unavailable)
+}
+
+// Error implements the error interface
+func (e *DomainMismatchError) Error() string {
+	if e.OnChainName == "" && e.OnChainVersion == "" {
+		return fmt.Sprintf("%s: name %q version %q do not match the DOMAIN_SEPARATOR of %s", ErrDomainMismatch, e.Name, e.Version, e.Asset)
+	}
+	return fmt.Sprintf("%s: name %q version %q do not match %s (name %q version %q)",
+		ErrDomainMismatch, e.Name, e.Version, e.Asset, e.OnChainName, e.OnChainVersion)
+}
+
+// AmountExceedsMaxError is returned by CreatePaymentPayload when the required amount is above
+// the cap set with SetMaxAmount, so nothing was signed
+type AmountExceedsMaxError struct {
+	Asset  string   // Token contract address
+	Amount *big.Int // Amount the requirements ask for
+	Max    *big.Int // Largest amount the scheme signs
+}
+
+// Error implements the error interface
+func (e *AmountExceedsMaxError) Error() string {
+	return fmt.Sprintf("%s: amount %s of %s exceeds the maximum of %s", ErrAmountExceedsMax, e.Amount, e.Asset, e.Max)
+}
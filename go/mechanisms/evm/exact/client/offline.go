@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+// NewOfflineExactEvmScheme creates an ExactEvmScheme for air-gapped signing (see SetOffline)
+func NewOfflineExactEvmScheme(signer evm.DigestSigner) *ExactEvmScheme {
+	scheme := NewExactEvmScheme(signer)
+	scheme.SetOffline(true)
+	return scheme
+}
+
+// SetOffline makes the scheme sign without any network access (optional)
+//
+// An offline scheme never uses the RPC set with SetRPCURL, skips the built-in DOMAIN_SEPARATOR
+// overrides and computes the EIP-712 domain separator from name, version, chain ID, token
+// contract and (when given) salt. Nothing is read from the environment. It needs:
+//   - requirements.Network as eip155:CHAIN_ID, or a built-in or registered network
+//   - requirements.Asset, Amount and PayTo
+//   - requirements.Extra["name"] and ["version"] matching the token's EIP-712 domain; they
+//     may be omitted only for a token configured for the network (see evm.RegisterAsset)
+//   - requirements.Extra["salt"] when the token's domain has one
+//   - requirements.Extra["omitChainId"] set to true when the token's domain has no chainId
+//     (or evm.AssetInfo.OmitChainID)
+//
+// The balance precheck, domain validation and EstimateSettleGas need an RPC and fail offline.
+func (c *ExactEvmScheme) SetOffline(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offline = enabled
+}
+
+// CreatePaymentHeader creates a complete V2 payment payload for requirements, with
+// requirements as the accepted kind and resource (optional) as the paid resource, and
+// encodes it as a PAYMENT-SIGNATURE / X-PAYMENT header value
+func (c *ExactEvmScheme) CreatePaymentHeader(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+	resource *types.ResourceInfo,
+) (string, error) {
+	payload, err := c.CreatePaymentPayload(ctx, requirements)
+	if err != nil {
+		return "", err
+	}
+	payload.Accepted = requirements
+	payload.Resource = resource
+	return types.EncodePaymentHeader(payload)
+}
+
+// checkOfflineDomain ensures the domain name and version come from the requirements or the
+// network configuration, since an unknown token's placeholders cannot be checked offline
+func checkOfflineDomain(network, asset string, extra map[string]interface{}) error {
+	_, hasName := extra["name"].(string)
+	_, hasVersion := extra["version"].(string)
+	if hasName && hasVersion {
+		return nil
+	}
+
+	assets, _ := evm.ListAssets(network)
+	for _, info := range assets {
+		if evm.NormalizeAddress(info.Address) == evm.NormalizeAddress(asset) {
+			return nil
+		}
+	}
+	return fmt.Errorf("offline signing for token %s requires extra.name and extra.version", asset)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+func TestOfflineExactEvmSchemePaymentHeader(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	// An RPC that must never be contacted
+	var calls int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "unexpected RPC call", http.StatusInternalServerError)
+	}))
+	defer rpc.Close()
+
+	gateLayer := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "gatelayer_testnet",
+		Asset:   "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF",
+		Amount:  "1000",
+		PayTo:   "0x0000000000000000000000000000000000000001",
+	}
+
+	tests := []struct {
+		name         string
+		requirements types.PaymentRequirements
+	}{
+		{name: "name and version in extra", requirements: testExactRequirements("1000")},
+		{name: "configured token without the built-in separator", requirements: gateLayer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := NewOfflineExactEvmScheme(signer)
+			if err := scheme.SetRPCURL(rpc.URL); err != nil {
+				t.Fatalf("SetRPCURL failed: %v", err)
+			}
+
+			resource := &types.ResourceInfo{URL: "https://api.example.com/report"}
+			header, err := scheme.CreatePaymentHeader(context.Background(), tt.requirements, resource)
+			if err != nil {
+				t.Fatalf("CreatePaymentHeader failed: %v", err)
+			}
+
+			payload, err := types.DecodePaymentHeader(header)
+			if err != nil {
+				t.Fatalf("DecodePaymentHeader failed: %v", err)
+			}
+			if payload.Accepted.Network != tt.requirements.Network || payload.Resource == nil || payload.Resource.URL != resource.URL {
+				t.Errorf("Expected accepted requirements and resource in the header, got %+v", payload)
+			}
+			payer, err := evm.RecoverPayer(payload, tt.requirements)
+			if err != nil {
+				t.Fatalf("RecoverPayer failed: %v", err)
+			}
+			if payer != signer.Address() {
+				t.Errorf("Expected payload to recover to %s, got %s", signer.Address(), payer)
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("Expected no RPC calls offline, got %d", calls)
+	}
+}
+
+func TestOfflineExactEvmSchemeRequirements(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	scheme := NewOfflineExactEvmScheme(signer)
+	ctx := context.Background()
+
+	unknownToken := testExactRequirements("1000")
+	unknownToken.Extra = nil
+	if _, err := scheme.CreatePaymentPayload(ctx, unknownToken); err == nil || !strings.Contains(err.Error(), "requires extra.name and extra.version") {
+		t.Errorf("Expected an error for an unknown token without name/version, got %v", err)
+	}
+
+	customNetwork := testExactRequirements("1000")
+	customNetwork.Network = "local-devnet"
+	if _, err := scheme.CreatePaymentPayload(ctx, customNetwork); err == nil {
+		t.Error("Expected an error for a network whose chain ID needs the RPC")
+	}
+
+	scheme.SetPrecheckBalance(true)
+	if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000")); err == nil || !strings.Contains(err.Error(), ErrFailedToCheckBalance) {
+		t.Errorf("Expected the balance precheck to fail offline, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ExactEvmPermit2AllowanceScheme implements the SchemeNetworkClient interface for EVM exact
+// payments (V2) by signing a Uniswap Permit2 AllowanceTransfer PermitSingle, which grants the
+// facilitator an expiring allowance it uses with permit followed by transferFrom.
+//
+// The payer must already have approved the Permit2 contract for the token, and the payment
+// requirements must name the facilitator address in Extra["spender"]. With an RPC URL the
+// allowance nonce is read from Permit2; without one, nonce 0 is signed, which is only valid
+// for the first permit the payer gives that spender for the token.
+type ExactEvmPermit2AllowanceScheme struct {
+	signer    evm.DigestSigner
+	rpcURL    string            // RPC URL for querying chain data
+	ethClient *ethclient.Client // ethclient for querying allowance(owner, token, spender)
+}
+
+// NewExactEvmPermit2AllowanceScheme creates a new ExactEvmPermit2AllowanceScheme
+func NewExactEvmPermit2AllowanceScheme(signer evm.DigestSigner) *ExactEvmPermit2AllowanceScheme {
+	return &ExactEvmPermit2AllowanceScheme{
+		signer: signer,
+	}
+}
+
+// SetRPCURL sets the RPC URL for querying chain data (optional)
+// It is SetRPCURLContext with context.Background(); use that to bound the dial.
+func (c *ExactEvmPermit2AllowanceScheme) SetRPCURL(rpcURL string) error {
+	return c.SetRPCURLContext(context.Background(), rpcURL)
+}
+
+// SetRPCURLContext sets the RPC URL for querying chain data (optional)
+// The RPC must report its chain ID before ctx is done, so a bad endpoint fails here rather
+// than on the first payment.
+func (c *ExactEvmPermit2AllowanceScheme) SetRPCURLContext(ctx context.Context, rpcURL string) error {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	if _, err := client.ChainID(ctx); err != nil {
+		client.Close()
+		return fmt.Errorf("failed to query RPC chain ID: %w", err)
+	}
+	c.rpcURL = rpcURL
+	c.ethClient = client
+	return nil
+}
+
+// Scheme returns the scheme identifier
+func (c *ExactEvmPermit2AllowanceScheme) Scheme() string {
+	return evm.SchemeExact
+}
+
+// CreatePaymentPayload creates a V2 payment payload carrying a signed Permit2 PermitSingle
+// The allowance is for exactly requirements.Amount and expires with the signature, one hour
+// after signing.
+func (c *ExactEvmPermit2AllowanceScheme) CreatePaymentPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+) (types.PaymentPayload, error) {
+	if err := requirements.Validate(); err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+	}
+
+	networkStr := string(requirements.Network)
+
+	chainID, err := evm.GetEvmChainId(networkStr)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	amount, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAmount+": %s", requirements.Amount)
+	}
+
+	var spender string
+	if requirements.Extra != nil {
+		spender, _ = requirements.Extra["spender"].(string)
+	}
+	if !evm.IsValidAddress(spender) {
+		return types.PaymentPayload{}, fmt.Errorf(ErrMissingPermitSpender+": %q", spender)
+	}
+
+	permit2Address := evm.GetPermit2Address(networkStr)
+	owner := c.signer.Address()
+	nonce, err := c.queryNonce(ctx, permit2Address, owner, assetInfo.Address, spender)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToQueryPermit2Nonce+": %w", err)
+	}
+
+	_, deadline := evm.CreateValidityWindow(time.Hour)
+
+	authorization := evm.ExactPermit2AllowanceAuthorization{
+		From:        owner,
+		Token:       assetInfo.Address,
+		Amount:      amount.String(),
+		Expiration:  deadline.String(),
+		Nonce:       nonce.String(),
+		Spender:     spender,
+		SigDeadline: deadline.String(),
+	}
+
+	signature, err := c.signPermitSingle(ctx, authorization, chainID, permit2Address)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToSignPermitSingle+": %w", err)
+	}
+
+	evmPayload := &evm.ExactPermit2AllowancePayload{
+		Signature:        evm.BytesToHex(signature),
+		Permit2Allowance: authorization,
+	}
+
+	// Return partial V2 payload (core will add accepted, resource, extensions)
+	return types.PaymentPayload{
+		X402Version: 2,
+		Payload:     evmPayload.ToMap(),
+	}, nil
+}
+
+// signPermitSingle signs the PermitSingle using the Permit2 EIP-712 domain
+func (c *ExactEvmPermit2AllowanceScheme) signPermitSingle(
+	ctx context.Context,
+	authorization evm.ExactPermit2AllowanceAuthorization,
+	chainID *big.Int,
+	permit2Address string,
+) ([]byte, error) {
+	domain := evm.TypedDataDomain{
+		Name:              evm.Permit2DomainName,
+		ChainID:           chainID,
+		VerifyingContract: permit2Address,
+	}
+
+	message, err := evm.Permit2PermitSingleMessage(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return evm.SignTypedDataDigest(ctx, c.signer, domain, evm.Permit2PermitSingleTypes, "PermitSingle", message)
+}
+
+// queryNonce reads the nonce of allowance(owner, token, spender) from Permit2, or returns 0
+// without an RPC
+func (c *ExactEvmPermit2AllowanceScheme) queryNonce(ctx context.Context, permit2Address, owner, token, spender string) (*big.Int, error) {
+	if c.ethClient == nil {
+		return big.NewInt(0), nil
+	}
+
+	contractABI, err := abi.JSON(bytes.NewReader(evm.Permit2AllowanceABI))
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := contractABI.Pack(evm.FunctionAllowance,
+		common.HexToAddress(owner), common.HexToAddress(token), common.HexToAddress(spender))
+	if err != nil {
+		return nil, err
+	}
+
+	addr := common.HexToAddress(permit2Address)
+	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &addr,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := contractABI.Unpack(evm.FunctionAllowance, result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowance result: %w", err)
+	}
+	nonce, ok := outputs[2].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected allowance nonce type %T", outputs[2])
+	}
+	return nonce, nil
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+)
+
+func TestExactEvmPermit2AllowanceSchemeCreatePaymentPayload(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	// allowance(owner, token, spender) returns (amount, expiration, nonce)
+	allowance := append(append(uint256Result(500), uint256Result(1700000000)...), uint256Result(5)...)
+	rpc := fakeTokenRPC(t, map[string][]byte{"allowance(address,address,address)": allowance})
+
+	tests := []struct {
+		name      string
+		rpcURL    string
+		wantNonce string
+	}{
+		{name: "without RPC", wantNonce: "0"},
+		{name: "nonce from Permit2", rpcURL: rpc.URL, wantNonce: "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := NewExactEvmPermit2AllowanceScheme(signer)
+			if tt.rpcURL != "" {
+				if err := scheme.SetRPCURL(tt.rpcURL); err != nil {
+					t.Fatalf("SetRPCURL failed: %v", err)
+				}
+			}
+
+			payload, err := scheme.CreatePaymentPayload(context.Background(), testPermitRequirements())
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+			allowancePayload, err := evm.Permit2AllowancePayloadFromMap(payload.Payload)
+			if err != nil {
+				t.Fatalf("Permit2AllowancePayloadFromMap failed: %v", err)
+			}
+			auth := allowancePayload.Permit2Allowance
+			if !strings.EqualFold(auth.Token, testPermitToken) || auth.Amount != "1000000" || auth.Spender != testPermitSpender {
+				t.Errorf("unexpected authorization: %+v", auth)
+			}
+			if auth.Nonce != tt.wantNonce {
+				t.Errorf("expected nonce %s, got %s", tt.wantNonce, auth.Nonce)
+			}
+			if auth.Expiration != auth.SigDeadline {
+				t.Errorf("expected the allowance to expire with the signature, got %s and %s", auth.Expiration, auth.SigDeadline)
+			}
+
+			digest, err := evm.HashPermit2PermitSingle(auth, big.NewInt(1), evm.CanonicalPermit2Address)
+			if err != nil {
+				t.Fatalf("HashPermit2PermitSingle failed: %v", err)
+			}
+			sig, _ := evm.HexToBytes(allowancePayload.Signature)
+			sig[64] -= 27
+			pub, err := crypto.SigToPub(digest, sig)
+			if err != nil {
+				t.Fatalf("failed to recover signer: %v", err)
+			}
+			if got := crypto.PubkeyToAddress(*pub); got != common.HexToAddress(signer.Address()) {
+				t.Errorf("recovered %s, want %s", got.Hex(), signer.Address())
+			}
+		})
+	}
+}
+
+func TestExactEvmPermit2AllowanceSchemeErrors(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	requirements := testPermitRequirements()
+	requirements.Extra = nil
+	_, err = NewExactEvmPermit2AllowanceScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+	if err == nil || !strings.Contains(err.Error(), ErrMissingPermitSpender) {
+		t.Errorf("expected %s, got %v", ErrMissingPermitSpender, err)
+	}
+
+	// Permit2 cannot hold an allowance above uint160
+	requirements = testPermitRequirements()
+	requirements.Amount = new(big.Int).Lsh(big.NewInt(1), 160).String()
+	_, err = NewExactEvmPermit2AllowanceScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+	if err == nil || !strings.Contains(err.Error(), ErrFailedToSignPermitSingle) {
+		t.Errorf("expected %s, got %v", ErrFailedToSignPermitSingle, err)
+	}
+
+	// A reverting allowance call fails instead of signing a stale nonce
+	scheme := NewExactEvmPermit2AllowanceScheme(signer)
+	if err := scheme.SetRPCURL(fakeTokenRPC(t, nil).URL); err != nil {
+		t.Fatalf("SetRPCURL failed: %v", err)
+	}
+	_, err = scheme.CreatePaymentPayload(context.Background(), testPermitRequirements())
+	if err == nil || !strings.Contains(err.Error(), ErrFailedToQueryPermit2Nonce) {
+		t.Errorf("expected %s, got %v", ErrFailedToQueryPermit2Nonce, err)
+	}
+}
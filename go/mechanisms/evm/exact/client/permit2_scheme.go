@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ExactEvmPermit2Scheme implements the SchemeNetworkClient interface for EVM exact payments (V2)
+// by signing a Uniswap Permit2 PermitTransferFrom instead of an EIP-3009 authorization.
+//
+// The payer must already have approved the Permit2 contract for the token, and the
+// payment requirements must name the facilitator address that will call
+// permitTransferFrom in Extra["spender"].
+type ExactEvmPermit2Scheme struct {
+	signer evm.DigestSigner
+}
+
+// NewExactEvmPermit2Scheme creates a new ExactEvmPermit2Scheme
+func NewExactEvmPermit2Scheme(signer evm.DigestSigner) *ExactEvmPermit2Scheme {
+	return &ExactEvmPermit2Scheme{
+		signer: signer,
+	}
+}
+
+// Scheme returns the scheme identifier
+func (c *ExactEvmPermit2Scheme) Scheme() string {
+	return evm.SchemeExact
+}
+
+// CreatePaymentPayload creates a V2 payment payload carrying a signed Permit2 PermitTransferFrom
+func (c *ExactEvmPermit2Scheme) CreatePaymentPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+) (types.PaymentPayload, error) {
+	if err := requirements.Validate(); err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+	}
+
+	networkStr := string(requirements.Network)
+
+	chainID, err := evm.GetEvmChainId(networkStr)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	amount, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAmount+": %s", requirements.Amount)
+	}
+
+	var spender string
+	if requirements.Extra != nil {
+		spender, _ = requirements.Extra["spender"].(string)
+	}
+	if !evm.IsValidAddress(spender) {
+		return types.PaymentPayload{}, fmt.Errorf(ErrMissingPermitSpender+": %q", spender)
+	}
+
+	// Permit2 nonces are unordered bitmaps, so a random 256-bit value is used
+	nonceHex, err := evm.CreateNonce()
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+	nonceBytes, err := evm.HexToBytes(nonceHex)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	_, deadline := evm.CreateValidityWindow(time.Hour)
+
+	authorization := evm.ExactPermit2Authorization{
+		From:     c.signer.Address(),
+		Token:    assetInfo.Address,
+		Amount:   amount.String(),
+		Spender:  spender,
+		Nonce:    new(big.Int).SetBytes(nonceBytes).String(),
+		Deadline: deadline.String(),
+	}
+
+	signature, err := c.signPermit2(ctx, authorization, chainID, evm.GetPermit2Address(networkStr))
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToSignPermit2+": %w", err)
+	}
+
+	evmPayload := &evm.ExactPermit2Payload{
+		Signature:            evm.BytesToHex(signature),
+		Permit2Authorization: authorization,
+	}
+
+	// Return partial V2 payload (core will add accepted, resource, extensions)
+	return types.PaymentPayload{
+		X402Version: 2,
+		Payload:     evmPayload.ToMap(),
+	}, nil
+}
+
+// signPermit2 signs the PermitTransferFrom using the Permit2 EIP-712 domain
+func (c *ExactEvmPermit2Scheme) signPermit2(
+	ctx context.Context,
+	authorization evm.ExactPermit2Authorization,
+	chainID *big.Int,
+	permit2Address string,
+) ([]byte, error) {
+	domain := evm.TypedDataDomain{
+		Name:              evm.Permit2DomainName,
+		ChainID:           chainID,
+		VerifyingContract: permit2Address,
+	}
+
+	message, err := evm.Permit2TransferFromMessage(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return evm.SignTypedDataDigest(ctx, c.signer, domain, evm.Permit2TransferFromTypes, "PermitTransferFrom", message)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+)
+
+func TestExactEvmPermit2SchemeCreatePaymentPayload(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	payload, err := NewExactEvmPermit2Scheme(signer).CreatePaymentPayload(context.Background(), testPermitRequirements())
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+
+	permit2Payload, err := evm.Permit2PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("Permit2PayloadFromMap failed: %v", err)
+	}
+	auth := permit2Payload.Permit2Authorization
+	if !strings.EqualFold(auth.Token, testPermitToken) || auth.Amount != "1000000" || auth.Spender != testPermitSpender {
+		t.Errorf("unexpected authorization: %+v", auth)
+	}
+
+	digest, err := evm.HashPermit2TransferFrom(auth, big.NewInt(1), evm.CanonicalPermit2Address)
+	if err != nil {
+		t.Fatalf("HashPermit2TransferFrom failed: %v", err)
+	}
+	sig, _ := evm.HexToBytes(permit2Payload.Signature)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if got := crypto.PubkeyToAddress(*pub); got != common.HexToAddress(signer.Address()) {
+		t.Errorf("recovered %s, want %s", got.Hex(), signer.Address())
+	}
+
+	// Each payload uses a fresh unordered nonce
+	second, err := NewExactEvmPermit2Scheme(signer).CreatePaymentPayload(context.Background(), testPermitRequirements())
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	secondPayload, _ := evm.Permit2PayloadFromMap(second.Payload)
+	if secondPayload.Permit2Authorization.Nonce == auth.Nonce {
+		t.Error("expected a new nonce for each payload")
+	}
+}
+
+func TestExactEvmPermit2SchemeMissingSpender(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	requirements := testPermitRequirements()
+	requirements.Extra = nil
+	_, err = NewExactEvmPermit2Scheme(signer).CreatePaymentPayload(context.Background(), requirements)
+	if err == nil || !strings.Contains(err.Error(), ErrMissingPermitSpender) {
+		t.Errorf("expected %s, got %v", ErrMissingPermitSpender, err)
+	}
+}
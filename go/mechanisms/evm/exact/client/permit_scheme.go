@@ -0,0 +1,233 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+// ExactEvmPermitScheme implements the SchemeNetworkClient interface for EVM exact payments (V2)
+// using EIP-2612 permit instead of EIP-3009, for ERC-20 tokens that only implement permit.
+//
+// The payment requirements must name the facilitator address that will submit
+// permit + transferFrom in Extra["spender"]. An RPC URL is required to read nonces(owner).
+type ExactEvmPermitScheme struct {
+	signer         evm.DigestSigner
+	rpcURL         string            // RPC URL for querying chain data
+	ethClient      *ethclient.Client // ethclient for querying nonces and DOMAIN_SEPARATOR
+	validityPeriod time.Duration     // Permit validity period (see SetValidityPeriod)
+}
+
+// NewExactEvmPermitScheme creates a new ExactEvmPermitScheme
+func NewExactEvmPermitScheme(signer evm.DigestSigner) *ExactEvmPermitScheme {
+	return &ExactEvmPermitScheme{
+		signer: signer,
+	}
+}
+
+// SetRPCURL sets the RPC URL for querying chain data
+// It is SetRPCURLContext with context.Background(); use that to bound the dial.
+func (c *ExactEvmPermitScheme) SetRPCURL(rpcURL string) error {
+	return c.SetRPCURLContext(context.Background(), rpcURL)
+}
+
+// SetRPCURLContext sets the RPC URL for querying chain data
+// The RPC must report its chain ID before ctx is done, so a bad endpoint fails here rather
+// than on the first payment. The client of a previous RPC URL is closed.
+func (c *ExactEvmPermitScheme) SetRPCURLContext(ctx context.Context, rpcURL string) error {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	if _, err := client.ChainID(ctx); err != nil {
+		client.Close()
+		return fmt.Errorf("failed to query RPC chain ID: %w", err)
+	}
+	if c.ethClient != nil {
+		c.ethClient.Close()
+	}
+	c.rpcURL = rpcURL
+	c.ethClient = client
+	return nil
+}
+
+// SetValidityPeriod sets how long signed permits stay valid (optional)
+// A validBefore or maxTimeoutSeconds hint in requirements.Extra takes precedence over this
+// value. A period of zero or less restores the default: requirements.MaxTimeoutSeconds when
+// set, otherwise DefaultValidityPeriod (1 hour).
+func (c *ExactEvmPermitScheme) SetValidityPeriod(d time.Duration) {
+	c.validityPeriod = d
+}
+
+// Scheme returns the scheme identifier
+func (c *ExactEvmPermitScheme) Scheme() string {
+	return evm.SchemeExact
+}
+
+// CreatePaymentPayload creates a V2 payment payload carrying a signed EIP-2612 permit
+func (c *ExactEvmPermitScheme) CreatePaymentPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+) (types.PaymentPayload, error) {
+	if err := requirements.Validate(); err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+	}
+
+	networkStr := string(requirements.Network)
+
+	chainID, err := evm.GetEvmChainId(networkStr)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	value, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAmount+": %s", requirements.Amount)
+	}
+
+	// The spender is the facilitator account that submits permit and transferFrom
+	tokenName := assetInfo.Name
+	tokenVersion := assetInfo.Version
+	var spender string
+	if requirements.Extra != nil {
+		spender, _ = requirements.Extra["spender"].(string)
+		if name, ok := requirements.Extra["name"].(string); ok {
+			tokenName = name
+		}
+		if ver, ok := requirements.Extra["version"].(string); ok {
+			tokenVersion = ver
+		}
+	}
+	if !evm.IsValidAddress(spender) {
+		return types.PaymentPayload{}, fmt.Errorf(ErrMissingPermitSpender+": %q", spender)
+	}
+
+	owner := c.signer.Address()
+	nonce, err := c.queryNonce(ctx, assetInfo.Address, owner)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToQueryPermitNonce+": %w", err)
+	}
+
+	_, deadline, err := validityWindowFor(requirements, c.permitPeriod(requirements))
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	permit := evm.ExactEIP2612Permit{
+		Owner:    owner,
+		Spender:  spender,
+		Value:    value.String(),
+		Nonce:    nonce.String(),
+		Deadline: deadline.String(),
+	}
+
+	signature, err := c.signPermit(ctx, permit, chainID, assetInfo, tokenName, tokenVersion)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToSignPermit+": %w", err)
+	}
+
+	evmPayload := &evm.ExactEIP2612Payload{
+		Signature: evm.BytesToHex(signature),
+		Permit:    permit,
+	}
+
+	// Return partial V2 payload (core will add accepted, resource, extensions)
+	return types.PaymentPayload{
+		X402Version: 2,
+		Payload:     evmPayload.ToMap(),
+	}, nil
+}
+
+// permitPeriod returns the validity period of a permit without a validity hint in
+// requirements.Extra: SetValidityPeriod, requirements.MaxTimeoutSeconds, then DefaultValidityPeriod
+func (c *ExactEvmPermitScheme) permitPeriod(requirements types.PaymentRequirements) time.Duration {
+	if c.validityPeriod > 0 {
+		return c.validityPeriod
+	}
+	if requirements.MaxTimeoutSeconds > 0 {
+		return time.Duration(requirements.MaxTimeoutSeconds) * time.Second
+	}
+	return time.Duration(evm.DefaultValidityPeriod) * time.Second
+}
+
+// signPermit signs the permit, preferring the token's on-chain DOMAIN_SEPARATOR when available
+func (c *ExactEvmPermitScheme) signPermit(
+	ctx context.Context,
+	permit evm.ExactEIP2612Permit,
+	chainID *big.Int,
+	asset *evm.AssetInfo,
+	tokenName string,
+	tokenVersion string,
+) ([]byte, error) {
+	verifyingContract := asset.Address
+	if c.ethClient != nil {
+		if domainSeparator, err := evm.QueryDomainSeparator(ctx, c.ethClient, verifyingContract, asset.DomainSeparatorMethod); err == nil {
+			digest, err := evm.HashEIP2612PermitWithDomainSeparator(permit, domainSeparator)
+			if err != nil {
+				return nil, err
+			}
+			return evm.SignDigestNormalized(ctx, c.signer, digest)
+		}
+	}
+
+	// Fallback to standard EIP-712 signing with name/version
+	domain := evm.TypedDataDomain{
+		Name:              tokenName,
+		Version:           tokenVersion,
+		ChainID:           chainID,
+		VerifyingContract: verifyingContract,
+	}
+
+	message, err := evm.EIP2612PermitMessage(permit)
+	if err != nil {
+		return nil, err
+	}
+
+	return evm.SignTypedDataDigest(ctx, c.signer, domain, evm.EIP2612PermitTypes, "Permit", message)
+}
+
+// queryNonce queries nonces(owner) from the token contract
+func (c *ExactEvmPermitScheme) queryNonce(ctx context.Context, tokenAddress string, owner string) (*big.Int, error) {
+	if c.ethClient == nil {
+		return nil, fmt.Errorf("RPC URL is required to read nonces(owner)")
+	}
+
+	contractABI, err := abi.JSON(bytes.NewReader(evm.NoncesABI))
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := contractABI.Pack(evm.FunctionNonces, common.HexToAddress(owner))
+	if err != nil {
+		return nil, err
+	}
+
+	addr := common.HexToAddress(tokenAddress)
+	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &addr,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) < 32 {
+		return nil, fmt.Errorf("invalid nonces result length: %d", len(result))
+	}
+
+	return new(big.Int).SetBytes(result[:32]), nil
+}
@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+const (
+	testPermitToken   = "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	testPermitSpender = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+	testPermitKey     = "0000000000000000000000000000000000000000000000000000000000000001"
+)
+
+// fakeTokenRPC answers eth_call with the result registered for the called function signature
+// (e.g. "nonces(address)") and eth_chainId with chain 1; unknown calls revert.
+func fakeTokenRPC(t *testing.T, results map[string][]byte) *httptest.Server {
+	t.Helper()
+	return fakeTokenRPCWithCalls(t, results, nil)
+}
+
+// fakeTokenRPCWithCalls is fakeTokenRPC, counting eth_call requests per function signature in calls
+func fakeTokenRPCWithCalls(t *testing.T, results map[string][]byte, calls map[string]*int32) *httptest.Server {
+	t.Helper()
+	bySelector := map[string][]byte{}
+	for signature, result := range results {
+		bySelector[hex.EncodeToString(crypto.Keccak256([]byte(signature))[:4])] = result
+	}
+	counters := map[string]*int32{}
+	for signature, counter := range calls {
+		counters[hex.EncodeToString(crypto.Keccak256([]byte(signature))[:4])] = counter
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		var call struct {
+			Data  string `json:"data"`
+			Input string `json:"input"`
+		}
+		if req.Method == "eth_call" && len(req.Params) > 0 {
+			_ = json.Unmarshal(req.Params[0], &call)
+		}
+		data := strings.TrimPrefix(call.Input+call.Data, "0x")
+		if len(data) >= 8 && counters[data[:8]] != nil {
+			atomic.AddInt32(counters[data[:8]], 1)
+		}
+
+		switch {
+		case req.Method == "eth_chainId":
+			resp["result"] = "0x1"
+		case len(data) >= 8 && bySelector[data[:8]] != nil:
+			resp["result"] = "0x" + hex.EncodeToString(bySelector[data[:8]])
+		default:
+			resp["error"] = map[string]interface{}{"code": -32000, "message": "execution reverted"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// uint256Result ABI-encodes n as a uint256 return value
+func uint256Result(n int64) []byte {
+	return common.LeftPadBytes(big.NewInt(n).Bytes(), 32)
+}
+
+func testPermitRequirements() types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:1",
+		Asset:   testPermitToken,
+		Amount:  "1000000",
+		PayTo:   "0x0000000000000000000000000000000000000001",
+		Extra: map[string]interface{}{
+			"spender": testPermitSpender,
+			"name":    "Permit Token",
+			"version": "1",
+		},
+	}
+}
+
+// recoverPermitSigner recovers the address that signed the permit in payload
+func recoverPermitSigner(t *testing.T, payload types.PaymentPayload) (*evm.ExactEIP2612Payload, common.Address) {
+	t.Helper()
+	permitPayload, err := evm.PermitPayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("PermitPayloadFromMap failed: %v", err)
+	}
+
+	digest, err := evm.HashEIP2612Permit(permitPayload.Permit, big.NewInt(1), testPermitToken, "Permit Token", "1")
+	if err != nil {
+		t.Fatalf("HashEIP2612Permit failed: %v", err)
+	}
+
+	sig, err := evm.HexToBytes(permitPayload.Signature)
+	if err != nil || len(sig) != 65 {
+		t.Fatalf("invalid signature %q", permitPayload.Signature)
+	}
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	return permitPayload, crypto.PubkeyToAddress(*pub)
+}
+
+func TestExactEvmPermitSchemeCreatePaymentPayload(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	domain := evm.TypedDataDomain{Name: "Permit Token", Version: "1", ChainID: big.NewInt(1), VerifyingContract: testPermitToken}
+	domainSeparator := domainSeparatorFor(t, domain)
+
+	tests := []struct {
+		name            string
+		domainSeparator []byte
+	}{
+		{"on-chain DOMAIN_SEPARATOR", domainSeparator},
+		{"typed data fallback", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := map[string][]byte{"nonces(address)": uint256Result(7)}
+			if tt.domainSeparator != nil {
+				results["DOMAIN_SEPARATOR()"] = tt.domainSeparator
+			}
+			rpc := fakeTokenRPC(t, results)
+			scheme := NewExactEvmPermitScheme(signer)
+			if err := scheme.SetRPCURL(rpc.URL); err != nil {
+				t.Fatalf("SetRPCURL failed: %v", err)
+			}
+
+			payload, err := scheme.CreatePaymentPayload(context.Background(), testPermitRequirements())
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+
+			permitPayload, recovered := recoverPermitSigner(t, payload)
+			if recovered != common.HexToAddress(signer.Address()) {
+				t.Errorf("recovered %s, want %s", recovered.Hex(), signer.Address())
+			}
+			if permitPayload.Permit.Nonce != "7" {
+				t.Errorf("nonce = %s, want 7", permitPayload.Permit.Nonce)
+			}
+			if permitPayload.Permit.Spender != testPermitSpender {
+				t.Errorf("spender = %s, want %s", permitPayload.Permit.Spender, testPermitSpender)
+			}
+			if permitPayload.Permit.Value != "1000000" {
+				t.Errorf("value = %s, want 1000000", permitPayload.Permit.Value)
+			}
+		})
+	}
+}
+
+func TestExactEvmPermitSchemeErrors(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	t.Run("missing spender", func(t *testing.T) {
+		requirements := testPermitRequirements()
+		delete(requirements.Extra, "spender")
+
+		_, err := NewExactEvmPermitScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+		if err == nil || !strings.Contains(err.Error(), ErrMissingPermitSpender) {
+			t.Errorf("expected %s, got %v", ErrMissingPermitSpender, err)
+		}
+	})
+
+	t.Run("no RPC configured", func(t *testing.T) {
+		_, err := NewExactEvmPermitScheme(signer).CreatePaymentPayload(context.Background(), testPermitRequirements())
+		if err == nil || !strings.Contains(err.Error(), ErrFailedToQueryPermitNonce) {
+			t.Errorf("expected %s, got %v", ErrFailedToQueryPermitNonce, err)
+		}
+	})
+}
+
+func TestExactEvmPermitSchemeDeadline(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	rpc := fakeTokenRPC(t, map[string][]byte{"nonces(address)": uint256Result(0)})
+	validBefore := time.Now().Add(10 * time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		period     time.Duration
+		maxTimeout int
+		extra      map[string]interface{}
+		want       time.Duration
+	}{
+		{name: "default", want: time.Hour},
+		{name: "requirements max timeout", maxTimeout: 120, want: 2 * time.Minute},
+		{name: "validity period", period: 5 * time.Minute, maxTimeout: 120, want: 5 * time.Minute},
+		{name: "extra max timeout", period: 5 * time.Minute, extra: map[string]interface{}{"maxTimeoutSeconds": float64(30)}, want: 30 * time.Second},
+		{name: "extra validBefore", extra: map[string]interface{}{"validBefore": float64(validBefore)}, want: time.Until(time.Unix(validBefore, 0))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := NewExactEvmPermitScheme(signer)
+			if err := scheme.SetRPCURL(rpc.URL); err != nil {
+				t.Fatalf("SetRPCURL failed: %v", err)
+			}
+			scheme.SetValidityPeriod(tt.period)
+			requirements := testPermitRequirements()
+			requirements.MaxTimeoutSeconds = tt.maxTimeout
+			for key, value := range tt.extra {
+				requirements.Extra[key] = value
+			}
+
+			payload, err := scheme.CreatePaymentPayload(context.Background(), requirements)
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+			permitPayload, _ := recoverPermitSigner(t, payload)
+			deadline, ok := new(big.Int).SetString(permitPayload.Permit.Deadline, 10)
+			if !ok {
+				t.Fatalf("invalid deadline %q", permitPayload.Permit.Deadline)
+			}
+			if got := time.Until(time.Unix(deadline.Int64(), 0)); got < tt.want-5*time.Second || got > tt.want+5*time.Second {
+				t.Errorf("deadline in %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("validBefore in the past", func(t *testing.T) {
+		scheme := NewExactEvmPermitScheme(signer)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+		requirements := testPermitRequirements()
+		requirements.Extra["validBefore"] = float64(time.Now().Add(-time.Minute).Unix())
+		if _, err := scheme.CreatePaymentPayload(context.Background(), requirements); err == nil || !strings.Contains(err.Error(), ErrInvalidValidityWindow) {
+			t.Errorf("expected %s, got %v", ErrInvalidValidityWindow, err)
+		}
+	})
+}
+
+// domainSeparatorFor computes hashStruct(EIP712Domain) for domain
+func domainSeparatorFor(t *testing.T, domain evm.TypedDataDomain) []byte {
+	t.Helper()
+	typeHash := crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	encoded := append([]byte{}, typeHash...)
+	encoded = append(encoded, crypto.Keccak256([]byte(domain.Name))...)
+	encoded = append(encoded, crypto.Keccak256([]byte(domain.Version))...)
+	encoded = append(encoded, common.LeftPadBytes(domain.ChainID.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(domain.VerifyingContract).Bytes(), 32)...)
+	return crypto.Keccak256(encoded)
+}
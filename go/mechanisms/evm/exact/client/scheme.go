@@ -1,47 +1,217 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gatechain/x402/go/mechanisms/evm"
 	"github.com/gatechain/x402/go/types"
 )
 
 // ExactEvmScheme implements the SchemeNetworkClient interface for EVM exact payments (V2)
+//
+// A scheme is safe for concurrent use: CreatePaymentPayload may be called from many goroutines
+// on the same instance, provided the signer is safe for concurrent use too (the signers in
+// signers/evm are). The setters may also be called at any time, but a payload being created
+// concurrently may see either the old or the new setting, so configure the scheme up front.
 type ExactEvmScheme struct {
-	signer    evm.ClientEvmSigner
-	rpcURL    string            // Optional RPC URL for querying chain data
-	ethClient *ethclient.Client // Optional ethclient for querying chain data
+	signer evm.DigestSigner
+
+	mu              sync.RWMutex      // Guards the settings below
+	rpcURL          string            // Optional RPC URL for querying chain data
+	ethClient       *ethclient.Client // Optional ethclient for querying chain data
+	precheckBalance bool              // Check balanceOf before signing (requires RPC)
+	validateDomain  bool              // Check the token name/version against the chain before signing (requires RPC)
+	validityPeriod  time.Duration     // Authorization validity period (defaults to DefaultValidityPeriod)
+	domainCache     *evm.DomainSeparatorCache
+	nonceStore      evm.NonceStore // Tracks issued nonces (nil disables tracking)
+	offline         bool           // Never use the RPC (see SetOffline)
+	maxAmount       *big.Int       // Largest amount signed (nil for no cap)
+	allowZeroAmount bool           // Sign zero-value authorizations for free-tier requirements
+	auditSink       AuditSink      // Receives what each payload signed (nil disables auditing)
+	rawVOffset      bool           // Express v of ECDSA signatures as 0/1 instead of 27/28
+
+	chainIDMu        sync.Mutex
+	rpcChainID       *big.Int          // Chain ID reported by the RPC, cached after the first query
+	rpcChainIDClient *ethclient.Client // Client rpcChainID was read from
 }
 
+// maxUint256 is the largest value of the authorization's uint256 amount
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
 // NewExactEvmScheme creates a new ExactEvmScheme
-func NewExactEvmScheme(signer evm.ClientEvmSigner) *ExactEvmScheme {
+func NewExactEvmScheme(signer evm.DigestSigner) *ExactEvmScheme {
 	return &ExactEvmScheme{
-		signer: signer,
+		signer:      signer,
+		domainCache: evm.DefaultDomainSeparatorCache,
+		nonceStore:  evm.NewMemoryNonceStore(evm.DefaultNonceStoreTTL, evm.DefaultNonceStoreMaxEntries),
 	}
 }
 
 // SetRPCURL sets the RPC URL for querying chain data (optional)
+// It is SetRPCURLContext with context.Background(); use that to bound the dial.
 func (c *ExactEvmScheme) SetRPCURL(rpcURL string) error {
-	client, err := ethclient.Dial(rpcURL)
+	return c.SetRPCURLContext(context.Background(), rpcURL)
+}
+
+// SetRPCURLContext sets the RPC URL for querying chain data (optional)
+// The RPC must report its chain ID before ctx is done, so a bad endpoint fails here rather
+// than on the first payment. An offline scheme never contacts the RPC and skips the check.
+func (c *ExactEvmScheme) SetRPCURLContext(ctx context.Context, rpcURL string) error {
+	client, err := ethclient.DialContext(ctx, rpcURL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to RPC: %w", err)
 	}
+	c.mu.RLock()
+	offline := c.offline
+	c.mu.RUnlock()
+	if !offline {
+		if _, err := c.queryChainID(ctx, client); err != nil {
+			client.Close()
+			return fmt.Errorf("failed to query RPC chain ID: %w", err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.rpcURL = rpcURL
 	c.ethClient = client
 	return nil
 }
 
+// SetPrecheckBalance enables checking the signer's token balance before signing (optional)
+// When enabled, CreatePaymentPayload returns an *InsufficientBalanceError if the balance is
+// below the required amount. Requires an RPC URL; leave disabled for offline signing.
+func (c *ExactEvmScheme) SetPrecheckBalance(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.precheckBalance = enabled
+}
+
+// SetValidateDomain enables checking the token name/version against the chain before signing (optional)
+// When enabled, CreatePaymentPayload compares the EIP-712 domain built from requirements.Extra
+// (or the asset defaults) with the token's DOMAIN_SEPARATOR, or its name() and version() when
+// it has none, and returns a *DomainMismatchError instead of a signature the token would reject.
+// Requires an RPC URL.
+func (c *ExactEvmScheme) SetValidateDomain(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validateDomain = enabled
+}
+
+// SetMaxAmount caps the amount CreatePaymentPayload signs, in the asset's smallest unit (optional)
+// Requirements asking for more fail with an *AmountExceedsMaxError before anything is signed,
+// guarding automated payment flows against a bad upstream amount. Nil removes the cap.
+func (c *ExactEvmScheme) SetMaxAmount(max *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if max != nil {
+		max = new(big.Int).Set(max)
+	}
+	c.maxAmount = max
+}
+
+// SetAllowZeroAmount controls requirements with an amount of zero (optional, defaults to off)
+// Free-tier resources may still challenge with a zero amount to identify the payer. When
+// enabled, CreatePaymentPayload signs a zero-value authorization that proves control of the
+// payer address and moves no funds; when disabled, such requirements fail with ErrZeroAmount.
+func (c *ExactEvmScheme) SetAllowZeroAmount(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.allowZeroAmount = enabled
+}
+
+// SetVOffset sets the convention of the v byte of ECDSA payload signatures (optional):
+// evm.VOffsetEthereum (27, the default) for v = 27/28, or evm.VOffsetRaw (0) for v = 0/1,
+// for chains and facilitators that expect the raw recovery ID. Smart wallet signatures are
+// never changed. Other offsets are rejected.
+func (c *ExactEvmScheme) SetVOffset(offset byte) error {
+	if offset != evm.VOffsetEthereum && offset != evm.VOffsetRaw {
+		return fmt.Errorf("invalid v offset %d: must be %d or %d", offset, evm.VOffsetRaw, evm.VOffsetEthereum)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rawVOffset = offset == evm.VOffsetRaw
+	return nil
+}
+
+// SetDomainSeparatorCache sets the cache for DOMAIN_SEPARATOR values read from chain (optional)
+// Schemes share evm.DefaultDomainSeparatorCache by default; nil disables caching.
+func (c *ExactEvmScheme) SetDomainSeparatorCache(cache *evm.DomainSeparatorCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.domainCache = cache
+}
+
+// SetValidityPeriod sets how long signed authorizations stay valid (optional, defaults to 1 hour)
+// A validBefore or maxTimeoutSeconds hint in requirements.Extra takes precedence over this value.
+// A period of zero or less restores the default.
+func (c *ExactEvmScheme) SetValidityPeriod(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validityPeriod = d
+}
+
+// SetNonceStore sets the store that tracks issued nonces (optional)
+// Each scheme starts with its own in-memory store; share a store (e.g. one backed by Redis)
+// to track nonces across schemes or processes, or pass nil to stop tracking.
+func (c *ExactEvmScheme) SetNonceStore(store evm.NonceStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nonceStore = store
+}
+
+// MarkNonceSettled records in the nonce store that payload was settled, so its nonce is
+// never reissued. Call it once the settlement response reports success.
+func (c *ExactEvmScheme) MarkNonceSettled(ctx context.Context, payload types.PaymentPayload) error {
+	store := c.store()
+	if store == nil {
+		return nil
+	}
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return err
+	}
+	nonce, err := nonceFromHex(evmPayload.Authorization.Nonce)
+	if err != nil {
+		return err
+	}
+	return store.MarkSettled(ctx, nonce)
+}
+
+// rpcClient returns the ethclient set with SetRPCURL, or nil (always nil when offline)
+func (c *ExactEvmScheme) rpcClient() *ethclient.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.offline {
+		return nil
+	}
+	return c.ethClient
+}
+
+// store returns the nonce store, or nil when nonces are not tracked
+func (c *ExactEvmScheme) store() evm.NonceStore {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nonceStore
+}
+
+// separatorCache returns the DOMAIN_SEPARATOR cache, or nil when caching is disabled
+func (c *ExactEvmScheme) separatorCache() *evm.DomainSeparatorCache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.domainCache
+}
+
 // Scheme returns the scheme identifier
 func (c *ExactEvmScheme) Scheme() string {
 	return evm.SchemeExact
@@ -52,10 +222,50 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	ctx context.Context,
 	requirements types.PaymentRequirements,
 ) (types.PaymentPayload, error) {
+	return c.createPaymentPayload(ctx, requirements, nil)
+}
+
+// CreatePaymentPayloadWithNonce creates a V2 payment payload using the given EIP-3009 nonce
+// instead of a random one. Pair it with evm.CreateDeterministicNonce so retries after a
+// transient failure reuse the nonce: only one of the resulting authorizations can settle.
+func (c *ExactEvmScheme) CreatePaymentPayloadWithNonce(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+	nonce [32]byte,
+) (types.PaymentPayload, error) {
+	return c.createPaymentPayload(ctx, requirements, &nonce)
+}
+
+// createPaymentPayload builds the payload; a nil nonce generates a random one
+func (c *ExactEvmScheme) createPaymentPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+	nonceOverride *[32]byte,
+) (types.PaymentPayload, error) {
+	c.mu.RLock()
+	allowZeroAmount := c.allowZeroAmount
+	c.mu.RUnlock()
+	if requirements.IsZeroAmount() && !allowZeroAmount {
+		return types.PaymentPayload{}, errors.New(ErrZeroAmount + ": requirements ask for no payment; see SetAllowZeroAmount")
+	}
+	if err := requirements.ValidateAllowZero(); err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+	}
+
+	// Checksum the addresses so the signed authorization matches what the token and facilitator compare
+	from, err := evm.ChecksumAddress(c.signer.Address())
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAddress+": signer: %w", err)
+	}
+	payTo, err := evm.ChecksumAddress(requirements.PayTo)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAddress+": payTo: %w", err)
+	}
+
 	networkStr := string(requirements.Network)
 
-	// Get chain ID - works for any EIP-155 network (eip155:CHAIN_ID)
-	chainID, err := evm.GetEvmChainId(networkStr)
+	// Get chain ID - works for any EIP-155 network (eip155:CHAIN_ID), or any network with an RPC
+	chainID, err := c.resolveChainID(ctx, networkStr)
 	if err != nil {
 		return types.PaymentPayload{}, err
 	}
@@ -71,67 +281,125 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	if !ok {
 		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAmount+": %s", requirements.Amount)
 	}
+	if value.Sign() < 0 || value.Cmp(maxUint256) > 0 {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAmount+": %s is not a uint256", requirements.Amount)
+	}
 
-	// Create nonce
-	nonce, err := evm.CreateNonce()
-	if err != nil {
-		return types.PaymentPayload{}, err
+	c.mu.RLock()
+	precheckBalance, validateDomain, offline, maxAmount := c.precheckBalance, c.validateDomain, c.offline, c.maxAmount
+	c.mu.RUnlock()
+
+	if maxAmount != nil && value.Cmp(maxAmount) > 0 {
+		return types.PaymentPayload{}, &AmountExceedsMaxError{Asset: assetInfo.Address, Amount: value, Max: maxAmount}
+	}
+
+	if precheckBalance {
+		if err := c.checkBalance(ctx, assetInfo.Address, value); err != nil {
+			return types.PaymentPayload{}, err
+		}
 	}
 
 	// V2 specific: No buffer on validAfter (can use immediately)
-	validAfter, validBefore := evm.CreateValidityWindow(time.Hour)
+	validAfter, validBefore, err := c.validityWindow(requirements)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
 
 	// Extract extra fields for EIP-3009
-	tokenName := assetInfo.Name
-	tokenVersion := assetInfo.Version
-	if requirements.Extra != nil {
-		if name, ok := requirements.Extra["name"].(string); ok {
-			tokenName = name
+	domain, err := evm.ExactDomain(chainID, assetInfo, requirements.Extra)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidRequirements+": %w", err)
+	}
+	if offline {
+		if err := checkOfflineDomain(networkStr, assetInfo.Address, requirements.Extra); err != nil {
+			return types.PaymentPayload{}, fmt.Errorf(ErrInvalidRequirements+": %w", err)
 		}
-		if ver, ok := requirements.Extra["version"].(string); ok {
-			tokenVersion = ver
+	}
+
+	if validateDomain {
+		if err := c.checkDomain(ctx, chainID, assetInfo, domain); err != nil {
+			return types.PaymentPayload{}, err
 		}
 	}
 
+	// Reserve the nonce last, so requirements rejected above leave the store untouched
+	nonce, err := c.issueNonce(ctx, nonceOverride)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
 	// Create authorization
 	authorization := evm.ExactEIP3009Authorization{
-		From:        c.signer.Address(),
-		To:          requirements.PayTo,
+		From:        from,
+		To:          payTo,
 		Value:       value.String(),
 		ValidAfter:  validAfter.String(),
 		ValidBefore: validBefore.String(),
 		Nonce:       nonce,
 	}
 
+	record := &SigningAuditRecord{Network: networkStr, Asset: assetInfo.Address, Authorization: authorization}
+
 	// For gatelayer_testnet with specific token, use hardcoded DOMAIN_SEPARATOR from chain
-	if networkStr == "gatelayer_testnet" && assetInfo.Address == "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF" {
+	if !offline && networkStr == "gatelayer_testnet" && assetInfo.Address == "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF" {
 		// Use hardcoded DOMAIN_SEPARATOR from chain: 0x2c2d6b621e73a4a094449d1894717413742130fb20149ec48340ca0354d1a707
 		domainSeparator, _ := hex.DecodeString("2c2d6b621e73a4a094449d1894717413742130fb20149ec48340ca0354d1a707")
 		if len(domainSeparator) == 32 {
-			signature, err := c.signWithDomainSeparator(ctx, authorization, domainSeparator)
+			record.DomainSource = DomainSourceHardcoded
+			signature, err := c.signWithDomainSeparator(ctx, authorization, domainSeparator, record)
 			if err == nil {
-				evmPayload := &evm.ExactEIP3009Payload{
-					Signature:     evm.BytesToHex(signature),
-					Authorization: authorization,
-				}
-				return types.PaymentPayload{
-					X402Version: 2,
-					Payload:     evmPayload.ToMap(),
-				}, nil
+				return c.buildPayload(ctx, authorization, signature, record)
 			}
 		}
 	}
 
 	// Sign the authorization (fallback to standard method)
-	signature, err := c.signAuthorization(ctx, authorization, chainID, assetInfo.Address, tokenName, tokenVersion)
+	signature, err := c.signAuthorization(ctx, authorization, chainID, assetInfo, domain, record)
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToSignAuthorization+": %w", err)
 	}
 
+	return c.buildPayload(ctx, authorization, signature, record)
+}
+
+// buildPayload wraps the signature for counterfactual smart wallets and builds the partial
+// V2 payload, then audits record with the final signature
+func (c *ExactEvmScheme) buildPayload(
+	ctx context.Context,
+	authorization evm.ExactEIP3009Authorization,
+	signature []byte,
+	record *SigningAuditRecord,
+) (types.PaymentPayload, error) {
+	// Smart wallets are verified with EIP-1271 even when their signature is 65 bytes long
+	signatureType := evm.DefaultSignatureType(signature)
+	_, smartWallet := c.signer.(evm.SmartWalletSigner)
+	if smartWallet {
+		signatureType = evm.SignatureTypeBytes
+	}
+
+	c.mu.RLock()
+	rawVOffset := c.rawVOffset
+	c.mu.RUnlock()
+	if rawVOffset && !smartWallet && len(signature) == 65 {
+		converted, err := evm.WithVOffset(signature, evm.VOffsetRaw)
+		if err != nil {
+			return types.PaymentPayload{}, fmt.Errorf(ErrFailedToSignAuthorization+": %w", err)
+		}
+		signature = converted
+	}
+
+	signature, err := c.wrapSmartWalletSignature(ctx, signature)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+	record.Signature = signature
+	c.audit(ctx, record)
+
 	// Create EVM payload
 	evmPayload := &evm.ExactEIP3009Payload{
 		Signature:     evm.BytesToHex(signature),
 		Authorization: authorization,
+		SignatureType: signatureType,
 	}
 
 	// Return partial V2 payload (core will add accepted, resource, extensions)
@@ -141,84 +409,366 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	}, nil
 }
 
+// wrapSmartWalletSignature wraps the signature per ERC-6492 when the signer is an undeployed smart wallet
+// Signatures from EOAs and deployed smart wallets are returned unchanged.
+func (c *ExactEvmScheme) wrapSmartWalletSignature(ctx context.Context, signature []byte) ([]byte, error) {
+	smartWallet, ok := c.signer.(evm.SmartWalletSigner)
+	if !ok {
+		return signature, nil
+	}
+
+	deployment, err := smartWallet.Deployment(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(ErrFailedToWrapSignature+": %w", err)
+	}
+	if deployment == nil {
+		return signature, nil
+	}
+
+	wrapped, err := evm.WrapERC6492Signature(deployment.Factory, deployment.FactoryCalldata, signature)
+	if err != nil {
+		return nil, fmt.Errorf(ErrFailedToWrapSignature+": %w", err)
+	}
+	return wrapped, nil
+}
+
 // signAuthorization signs the EIP-3009 authorization using EIP-712
+// The digest is always computed locally, with the chain's DOMAIN_SEPARATOR when available.
 func (c *ExactEvmScheme) signAuthorization(
 	ctx context.Context,
 	authorization evm.ExactEIP3009Authorization,
 	chainID *big.Int,
-	verifyingContract string,
-	tokenName string,
-	tokenVersion string,
+	asset *evm.AssetInfo,
+	domain evm.TypedDataDomain,
+	record *SigningAuditRecord,
 ) ([]byte, error) {
+	domainSeparator, source, err := c.resolveDomainSeparator(ctx, chainID, asset, domain)
+	if err != nil {
+		return nil, err
+	}
+	record.DomainSource = source
+	return c.signWithDomainSeparator(ctx, authorization, domainSeparator, record)
+}
+
+// resolveDomainSeparator returns the token's DOMAIN_SEPARATOR from chain when an RPC is
+// configured, otherwise the one derived from domain, and which it was
+func (c *ExactEvmScheme) resolveDomainSeparator(
+	ctx context.Context,
+	chainID *big.Int,
+	asset *evm.AssetInfo,
+	domain evm.TypedDataDomain,
+) ([]byte, DomainSource, error) {
 	// Try to query DOMAIN_SEPARATOR from chain if RPC is configured
-	var domainSeparator []byte
-	if c.ethClient != nil {
-		domainSep, err := c.queryDomainSeparator(ctx, verifyingContract)
+	if c.rpcClient() != nil {
+		domainSeparator, err := c.queryDomainSeparator(ctx, chainID, asset)
+		if err == nil && domainSeparator != nil {
+			return domainSeparator, DomainSourceChain, nil
+		}
+	}
+
+	// Otherwise derive it from the domain fields, so only SignDigest is needed
+	domainSeparator, err := evm.HashEIP712Domain(domain)
+	if err != nil {
+		return nil, "", err
+	}
+	return domainSeparator, DomainSourceComputed, nil
+}
+
+// issueNonce returns the authorization nonce, reserving it in the nonce store
+//
+// A random nonce the store already knows is replaced, which only a broken random source
+// causes. An explicit nonce may be reissued while it is only reserved, so retries with a
+// deterministic nonce keep working, but not once it has been marked settled.
+func (c *ExactEvmScheme) issueNonce(ctx context.Context, nonceOverride *[32]byte) (string, error) {
+	store := c.store()
+	if nonceOverride != nil {
+		if store != nil {
+			if err := store.Reserve(ctx, *nonceOverride); err != nil && !errors.Is(err, evm.ErrNonceReserved) {
+				return "", nonceStoreError(err)
+			}
+		}
+		return evm.BytesToHex(nonceOverride[:]), nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		nonceHex, err := evm.CreateNonce()
+		if err != nil {
+			return "", err
+		}
+		if store == nil {
+			return nonceHex, nil
+		}
+		nonce, err := nonceFromHex(nonceHex)
+		if err != nil {
+			return "", err
+		}
+		err = store.Reserve(ctx, nonce)
 		if err == nil {
-			domainSeparator = domainSep
+			return nonceHex, nil
+		}
+		if attempt+1 >= maxNonceAttempts || !(errors.Is(err, evm.ErrNonceReserved) || errors.Is(err, evm.ErrNonceSettled)) {
+			return "", nonceStoreError(err)
 		}
 	}
+}
 
-	// If we have domain separator from chain, use it directly
-	if domainSeparator != nil {
-		return c.signWithDomainSeparator(ctx, authorization, domainSeparator)
+// maxNonceAttempts bounds how many random nonces issueNonce tries before giving up
+const maxNonceAttempts = 3
+
+// nonceStoreError maps a NonceStore.Reserve error to a client error
+func nonceStoreError(err error) error {
+	if errors.Is(err, evm.ErrNonceReserved) || errors.Is(err, evm.ErrNonceSettled) {
+		return fmt.Errorf(ErrNonceAlreadyUsed+": %w", err)
+	}
+	return fmt.Errorf(ErrFailedToReserveNonce+": %w", err)
+}
+
+// nonceFromHex parses a 0x-prefixed 32-byte nonce
+func nonceFromHex(nonceHex string) ([32]byte, error) {
+	var nonce [32]byte
+	b, err := evm.HexToBytes(nonceHex)
+	if err != nil {
+		return nonce, fmt.Errorf("invalid nonce %q: %w", nonceHex, err)
+	}
+	if len(b) != len(nonce) {
+		return nonce, fmt.Errorf("invalid nonce %q: expected 32 bytes, got %d", nonceHex, len(b))
 	}
+	copy(nonce[:], b)
+	return nonce, nil
+}
 
-	// Fallback to standard EIP-712 signing with name/version
-	domain := evm.TypedDataDomain{
-		Name:              tokenName,
-		Version:           tokenVersion,
-		ChainID:           chainID,
-		VerifyingContract: verifyingContract,
+// resolveChainID returns the chain ID for network
+//
+// Known network keys and eip155:CHAIN_ID resolve without the chain. With an RPC URL, the
+// RPC's chain ID is used for any other network and must match the network's chain ID
+// otherwise, so a misconfigured RPC cannot sign for the wrong chain. An RPC that cannot
+// report its chain ID does not block networks that resolve without it.
+func (c *ExactEvmScheme) resolveChainID(ctx context.Context, network string) (*big.Int, error) {
+	chainID, err := evm.GetEvmChainId(network)
+	client := c.rpcClient()
+	if client == nil {
+		return chainID, err
 	}
 
-	types := map[string][]evm.TypedDataField{
-		"EIP712Domain": {
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
-		},
-		"TransferWithAuthorization": {
-			{Name: "from", Type: "address"},
-			{Name: "to", Type: "address"},
-			{Name: "value", Type: "uint256"},
-			{Name: "validAfter", Type: "uint256"},
-			{Name: "validBefore", Type: "uint256"},
-			{Name: "nonce", Type: "bytes32"},
-		},
+	rpcChainID, rpcErr := c.queryChainID(ctx, client)
+	if rpcErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("%w (RPC chain ID unavailable: %v)", err, rpcErr)
+		}
+		return chainID, nil
 	}
 
-	value, _ := new(big.Int).SetString(authorization.Value, 10)
-	validAfter, _ := new(big.Int).SetString(authorization.ValidAfter, 10)
-	validBefore, _ := new(big.Int).SetString(authorization.ValidBefore, 10)
-	nonceBytes, _ := evm.HexToBytes(authorization.Nonce)
+	if err == nil && chainID.Cmp(rpcChainID) != 0 {
+		return nil, fmt.Errorf(ErrChainIDMismatch+": network %s is chain %s but the RPC reports chain %s", network, chainID, rpcChainID)
+	}
+	return rpcChainID, nil
+}
 
-	message := map[string]interface{}{
-		"from":        authorization.From,
-		"to":          authorization.To,
-		"value":       value,
-		"validAfter":  validAfter,
-		"validBefore": validBefore,
-		"nonce":       nonceBytes,
+// queryChainID returns the chain ID of client, querying it once per client
+// Only successful queries are cached.
+func (c *ExactEvmScheme) queryChainID(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+	c.chainIDMu.Lock()
+	defer c.chainIDMu.Unlock()
+	if c.rpcChainID != nil && c.rpcChainIDClient == client {
+		return c.rpcChainID, nil
 	}
 
-	return c.signer.SignTypedData(ctx, domain, types, "TransferWithAuthorization", message)
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.rpcChainID = chainID
+	c.rpcChainIDClient = client
+	return chainID, nil
 }
 
-// queryDomainSeparator queries DOMAIN_SEPARATOR from the token contract
-func (c *ExactEvmScheme) queryDomainSeparator(ctx context.Context, tokenAddress string) ([]byte, error) {
-	const domainSeparatorABI = `[{"constant":true,"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}]`
+// queryDomainSeparator returns the token's DOMAIN_SEPARATOR from the cache or the chain
+// Only successful queries are cached.
+func (c *ExactEvmScheme) queryDomainSeparator(ctx context.Context, chainID *big.Int, asset *evm.AssetInfo) ([]byte, error) {
+	tokenAddress := asset.Address
+	cache := c.separatorCache()
+	if cache != nil {
+		if domainSeparator, ok := cache.Get(chainID, tokenAddress); ok {
+			return domainSeparator, nil
+		}
+	}
 
-	contractABI, err := abi.JSON(strings.NewReader(domainSeparatorABI))
+	client := c.rpcClient()
+	if client == nil {
+		return nil, fmt.Errorf("RPC URL is required to read DOMAIN_SEPARATOR")
+	}
+	domainSeparator, err := evm.QueryDomainSeparator(ctx, client, tokenAddress, asset.DomainSeparatorMethod)
 	if err != nil {
 		return nil, err
 	}
 
-	addr := common.HexToAddress(tokenAddress)
-	callData := contractABI.Methods["DOMAIN_SEPARATOR"].ID
+	if cache != nil {
+		cache.Set(chainID, tokenAddress, domainSeparator)
+	}
+	return domainSeparator, nil
+}
+
+// checkDomain verifies the token's EIP-712 domain matches the name and version of domain
+func (c *ExactEvmScheme) checkDomain(ctx context.Context, chainID *big.Int, asset *evm.AssetInfo, domain evm.TypedDataDomain) error {
+	tokenAddress := asset.Address
+	client := c.rpcClient()
+	if client == nil {
+		return fmt.Errorf(ErrFailedToValidateDomain + ": RPC URL is required for domain validation")
+	}
+
+	mismatch := &DomainMismatchError{Asset: tokenAddress, Name: domain.Name, Version: domain.Version}
+
+	onChain, err := c.queryDomainSeparator(ctx, chainID, asset)
+	if err == nil {
+		expected, err := evm.HashEIP712Domain(domain)
+		if err != nil {
+			return fmt.Errorf(ErrFailedToValidateDomain+": %w", err)
+		}
+		if bytes.Equal(expected, onChain) {
+			return nil
+		}
+		// Best effort: report what the token calls itself
+		mismatch.OnChainName, mismatch.OnChainVersion, _ = evm.QueryTokenNameVersion(ctx, client, tokenAddress)
+		return mismatch
+	}
+
+	// Tokens without DOMAIN_SEPARATOR: compare name() and version() directly
+	name, version, err := evm.QueryTokenNameVersion(ctx, client, tokenAddress)
+	if err != nil {
+		return fmt.Errorf(ErrFailedToValidateDomain+": %w", err)
+	}
+	if name != domain.Name || version != domain.Version {
+		mismatch.OnChainName, mismatch.OnChainVersion = name, version
+		return mismatch
+	}
+	return nil
+}
 
-	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
+// validityWindow returns validAfter/validBefore for an authorization
+//
+// Precedence:
+//  1. requirements.Extra["validBefore"]: absolute unix timestamp (seconds)
+//  2. requirements.Extra["maxTimeoutSeconds"]: validity period in seconds
+//  3. SetValidityPeriod
+//  4. DefaultValidityPeriod (1 hour)
+func (c *ExactEvmScheme) validityWindow(requirements types.PaymentRequirements) (*big.Int, *big.Int, error) {
+	period := time.Duration(evm.DefaultValidityPeriod) * time.Second
+	c.mu.RLock()
+	if c.validityPeriod > 0 {
+		period = c.validityPeriod
+	}
+	c.mu.RUnlock()
+	return validityWindowFor(requirements, period)
+}
+
+// validityWindowFor returns validAfter/validBefore for a signature valid for period, unless
+// requirements.Extra carries a validBefore or maxTimeoutSeconds hint
+func validityWindowFor(requirements types.PaymentRequirements, period time.Duration) (*big.Int, *big.Int, error) {
+	if requirements.Extra != nil {
+		if raw, ok := requirements.Extra["validBefore"]; ok {
+			validBefore, ok := extraInt(raw)
+			if !ok {
+				return nil, nil, fmt.Errorf(ErrInvalidValidityWindow+": validBefore %v", raw)
+			}
+			validAfter, _ := evm.CreateValidityWindow(0)
+			if validBefore <= time.Now().Unix() {
+				return nil, nil, fmt.Errorf(ErrInvalidValidityWindow+": validBefore %d is in the past", validBefore)
+			}
+			return validAfter, big.NewInt(validBefore), nil
+		}
+		if raw, ok := requirements.Extra["maxTimeoutSeconds"]; ok {
+			seconds, ok := extraInt(raw)
+			if !ok || seconds <= 0 {
+				return nil, nil, fmt.Errorf(ErrInvalidValidityWindow+": maxTimeoutSeconds %v", raw)
+			}
+			period = time.Duration(seconds) * time.Second
+		}
+	}
+
+	validAfter, validBefore := evm.CreateValidityWindow(period)
+	return validAfter, validBefore, nil
+}
+
+// extraInt reads an integer from a requirements.Extra value (JSON number, Go integer or decimal string)
+func extraInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, false
+		}
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case string:
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok || !n.IsInt64() {
+			return 0, false
+		}
+		return n.Int64(), true
+	default:
+		return 0, false
+	}
+}
+
+// EstimateSettleGas previews the cost of settling payload: the gas the facilitator's
+// transferWithAuthorization call will use and the RPC's suggested gas price.
+// Requires an RPC URL (see SetRPCURL).
+func (c *ExactEvmScheme) EstimateSettleGas(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (uint64, *big.Int, error) {
+	client := c.rpcClient()
+	if client == nil {
+		return 0, nil, fmt.Errorf(ErrFailedToEstimateGas + ": RPC URL is required for gas estimation")
+	}
+
+	gas, gasPrice, err := evm.EstimateSettleGas(ctx, client, payload, requirements)
+	if err != nil {
+		return 0, nil, fmt.Errorf(ErrFailedToEstimateGas+": %w", err)
+	}
+	return gas, gasPrice, nil
+}
+
+// checkBalance verifies the signer holds at least required of the token
+func (c *ExactEvmScheme) checkBalance(ctx context.Context, tokenAddress string, required *big.Int) error {
+	client := c.rpcClient()
+	if client == nil {
+		return fmt.Errorf(ErrFailedToCheckBalance + ": RPC URL is required for the balance precheck")
+	}
+
+	balance, err := callBalanceOf(ctx, client, tokenAddress, c.signer.Address())
+	if err != nil {
+		return fmt.Errorf(ErrFailedToCheckBalance+": %w", err)
+	}
+
+	if balance.Cmp(required) < 0 {
+		return &InsufficientBalanceError{
+			Address:  c.signer.Address(),
+			Asset:    tokenAddress,
+			Required: required,
+			Balance:  balance,
+		}
+	}
+	return nil
+}
+
+// callBalanceOf reads balanceOf(owner) from a token contract
+func callBalanceOf(ctx context.Context, client *ethclient.Client, tokenAddress string, owner string) (*big.Int, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(evm.BalanceOfABI))
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := contractABI.Pack(evm.FunctionBalanceOf, common.HexToAddress(owner))
+	if err != nil {
+		return nil, err
+	}
+
+	addr := common.HexToAddress(tokenAddress)
+	result, err := client.CallContract(ctx, ethereum.CallMsg{
 		To:   &addr,
 		Data: callData,
 	}, nil)
@@ -227,56 +777,35 @@ func (c *ExactEvmScheme) queryDomainSeparator(ctx context.Context, tokenAddress
 	}
 
 	if len(result) < 32 {
-		return nil, fmt.Errorf("invalid DOMAIN_SEPARATOR result length: %d", len(result))
+		return nil, fmt.Errorf("invalid balanceOf result length: %d", len(result))
 	}
 
-	return result[:32], nil
+	return new(big.Int).SetBytes(result[:32]), nil
 }
 
-// signWithDomainSeparator signs using the chain's DOMAIN_SEPARATOR directly
+// signWithDomainSeparator signs using the chain's DOMAIN_SEPARATOR directly, recording the
+// separator, struct hash and digest in record
 func (c *ExactEvmScheme) signWithDomainSeparator(
 	ctx context.Context,
 	authorization evm.ExactEIP3009Authorization,
 	domainSeparator []byte,
+	record *SigningAuditRecord,
 ) ([]byte, error) {
-	// Use standard EIP-3009 typehash
-	// TRANSFER_WITH_AUTHORIZATION_TYPEHASH = keccak256("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)")
-	typeHash := crypto.Keccak256([]byte("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)"))
-
-	// Parse values
-	value, _ := new(big.Int).SetString(authorization.Value, 10)
-	validAfter, _ := new(big.Int).SetString(authorization.ValidAfter, 10)
-	validBefore, _ := new(big.Int).SetString(authorization.ValidBefore, 10)
-	nonceBytes, _ := evm.HexToBytes(authorization.Nonce)
-	fromAddr := common.HexToAddress(authorization.From)
-	toAddr := common.HexToAddress(authorization.To)
-
-	// Encode struct using ABI encoding: abi.encode(typeHash, from, to, value, validAfter, validBefore, nonce)
-	// Manual encoding: each value is 32 bytes (ABI encoding pads to 32 bytes)
-	// Build encoded data: typeHash (32) + from (32) + to (32) + value (32) + validAfter (32) + validBefore (32) + nonce (32)
-	encoded := make([]byte, 0, 32*7)
-	encoded = append(encoded, typeHash...)
-	encoded = append(encoded, common.LeftPadBytes(fromAddr.Bytes(), 32)...)
-	encoded = append(encoded, common.LeftPadBytes(toAddr.Bytes(), 32)...)
-	encoded = append(encoded, common.LeftPadBytes(value.Bytes(), 32)...)
-	encoded = append(encoded, common.LeftPadBytes(validAfter.Bytes(), 32)...)
-	encoded = append(encoded, common.LeftPadBytes(validBefore.Bytes(), 32)...)
-	encoded = append(encoded, nonceBytes...)
-
-	structHash := crypto.Keccak256(encoded)
-
-	// Build digest: keccak256(0x19 || 0x01 || domainSeparator || structHash)
-	digest := crypto.Keccak256(
-		append([]byte{0x19, 0x01},
-			append(domainSeparator, structHash...)...,
-		),
-	)
+	digest, err := evm.HashEIP3009AuthorizationWithDomainSeparator(authorization, domainSeparator)
+	if err != nil {
+		return nil, err
+	}
+	structHash, err := evm.HashEIP3009AuthorizationStruct(authorization)
+	if err != nil {
+		return nil, err
+	}
+	record.DomainSeparator, record.StructHash, record.Digest = domainSeparator, structHash, digest
 
 	// Sign the digest directly
 	return c.signDigest(ctx, digest)
 }
 
-// signDigest signs a raw digest (used when we have DOMAIN_SEPARATOR from chain)
+// signDigest signs a raw digest, normalizing ECDSA signatures to low-S with v = 27/28
 func (c *ExactEvmScheme) signDigest(ctx context.Context, digest []byte) ([]byte, error) {
-	return c.signer.SignDigest(ctx, digest)
+	return evm.SignDigestNormalized(ctx, c.signer, digest)
 }
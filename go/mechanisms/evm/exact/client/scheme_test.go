@@ -0,0 +1,1339 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+func testExactRequirements(amount string) types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   testPermitToken,
+		Amount:  amount,
+		PayTo:   "0x0000000000000000000000000000000000000001",
+		Extra:   map[string]interface{}{"name": "Permit Token", "version": "1"},
+	}
+}
+
+func TestExactEvmSchemeBalancePrecheck(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	rpc := fakeTokenRPC(t, map[string][]byte{"balanceOf(address)": uint256Result(500)})
+	scheme := NewExactEvmScheme(signer)
+	if err := scheme.SetRPCURL(rpc.URL); err != nil {
+		t.Fatalf("SetRPCURL failed: %v", err)
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		if _, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1000")); err != nil {
+			t.Errorf("expected no precheck, got %v", err)
+		}
+	})
+
+	scheme.SetPrecheckBalance(true)
+
+	t.Run("sufficient balance", func(t *testing.T) {
+		if _, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("500")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		_, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("501"))
+
+		var balanceErr *InsufficientBalanceError
+		if !errors.As(err, &balanceErr) {
+			t.Fatalf("expected *InsufficientBalanceError, got %v", err)
+		}
+		if balanceErr.Required.Int64() != 501 || balanceErr.Balance.Int64() != 500 {
+			t.Errorf("unexpected amounts: required=%s balance=%s", balanceErr.Required, balanceErr.Balance)
+		}
+		if balanceErr.Address != signer.Address() {
+			t.Errorf("address = %s, want %s", balanceErr.Address, signer.Address())
+		}
+		if !strings.HasPrefix(err.Error(), ErrInsufficientBalance) {
+			t.Errorf("expected error code prefix, got %q", err.Error())
+		}
+	})
+}
+
+func TestExactEvmSchemeBalancePrecheckErrors(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	t.Run("no RPC configured", func(t *testing.T) {
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetPrecheckBalance(true)
+
+		_, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1"))
+		if err == nil || !strings.Contains(err.Error(), ErrFailedToCheckBalance) {
+			t.Errorf("expected %s, got %v", ErrFailedToCheckBalance, err)
+		}
+	})
+
+	t.Run("balanceOf reverts", func(t *testing.T) {
+		rpc := fakeTokenRPC(t, nil)
+		scheme := NewExactEvmScheme(signer)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+		scheme.SetPrecheckBalance(true)
+
+		_, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1"))
+		if err == nil || !strings.Contains(err.Error(), ErrFailedToCheckBalance) {
+			t.Errorf("expected %s, got %v", ErrFailedToCheckBalance, err)
+		}
+	})
+}
+
+func TestExactEvmSchemeValidityWindow(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	absolute := time.Now().Add(3 * time.Hour).Unix()
+
+	tests := []struct {
+		name     string
+		period   time.Duration
+		extra    map[string]interface{}
+		expected time.Duration // validBefore - now; ignored when absolute is expected
+		absolute int64
+	}{
+		{name: "default", expected: time.Hour},
+		{name: "setter", period: 10 * time.Minute, expected: 10 * time.Minute},
+		{name: "extra maxTimeoutSeconds beats setter", period: 10 * time.Minute, extra: map[string]interface{}{"maxTimeoutSeconds": float64(120)}, expected: 2 * time.Minute},
+		{name: "extra maxTimeoutSeconds as string", extra: map[string]interface{}{"maxTimeoutSeconds": "86400"}, expected: 24 * time.Hour},
+		{name: "extra validBefore beats maxTimeoutSeconds", period: time.Minute, extra: map[string]interface{}{"validBefore": float64(absolute), "maxTimeoutSeconds": 60}, absolute: absolute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := NewExactEvmScheme(signer)
+			scheme.SetValidityPeriod(tt.period)
+
+			requirements := testExactRequirements("1")
+			for k, v := range tt.extra {
+				requirements.Extra[k] = v
+			}
+
+			payload, err := scheme.CreatePaymentPayload(context.Background(), requirements)
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+			evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+
+			validBefore, ok := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
+			if !ok {
+				t.Fatalf("validBefore is not a decimal string: %q", evmPayload.Authorization.ValidBefore)
+			}
+			if _, ok := new(big.Int).SetString(evmPayload.Authorization.ValidAfter, 10); !ok {
+				t.Fatalf("validAfter is not a decimal string: %q", evmPayload.Authorization.ValidAfter)
+			}
+
+			if tt.absolute != 0 {
+				if validBefore.Int64() != tt.absolute {
+					t.Errorf("validBefore = %d, want %d", validBefore.Int64(), tt.absolute)
+				}
+				return
+			}
+			want := time.Now().Add(tt.expected).Unix()
+			if diff := validBefore.Int64() - want; diff < -2 || diff > 2 {
+				t.Errorf("validBefore = %d, want about %d", validBefore.Int64(), want)
+			}
+		})
+	}
+}
+
+func TestExactEvmSchemeValidityWindowInvalidHints(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	hints := []map[string]interface{}{
+		{"validBefore": float64(time.Now().Add(-time.Minute).Unix())},
+		{"validBefore": "soon"},
+		{"maxTimeoutSeconds": float64(0)},
+		{"maxTimeoutSeconds": 1.5},
+	}
+
+	for _, hint := range hints {
+		requirements := testExactRequirements("1")
+		for k, v := range hint {
+			requirements.Extra[k] = v
+		}
+		_, err := NewExactEvmScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+		if err == nil || !strings.Contains(err.Error(), ErrInvalidValidityWindow) {
+			t.Errorf("hint %v: expected %s, got %v", hint, ErrInvalidValidityWindow, err)
+		}
+	}
+}
+
+// testSmartWalletSigner wraps an EOA signer and reports configurable deployment data
+type testSmartWalletSigner struct {
+	evm.ClientEvmSigner
+	deployment *evm.SmartWalletDeployment
+}
+
+func (s *testSmartWalletSigner) Deployment(ctx context.Context) (*evm.SmartWalletDeployment, error) {
+	return s.deployment, nil
+}
+
+func TestExactEvmSchemeSmartWalletSignature(t *testing.T) {
+	owner, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	deployment := &evm.SmartWalletDeployment{
+		Factory:         "0x1234567890123456789012345678901234567890",
+		FactoryCalldata: []byte{0x01, 0x02, 0x03},
+	}
+
+	t.Run("undeployed wallet is wrapped", func(t *testing.T) {
+		scheme := NewExactEvmScheme(&testSmartWalletSigner{ClientEvmSigner: owner, deployment: deployment})
+		payload, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1"))
+		if err != nil {
+			t.Fatalf("CreatePaymentPayload failed: %v", err)
+		}
+
+		evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+		sig, _ := evm.HexToBytes(evmPayload.Signature)
+		if !evm.IsERC6492Signature(sig) {
+			t.Fatal("expected ERC-6492 magic suffix")
+		}
+
+		parsed, err := evm.ParseERC6492Signature(sig)
+		if err != nil {
+			t.Fatalf("ParseERC6492Signature failed: %v", err)
+		}
+		if string(parsed.FactoryCalldata) != string(deployment.FactoryCalldata) {
+			t.Errorf("factoryCalldata = %x, want %x", parsed.FactoryCalldata, deployment.FactoryCalldata)
+		}
+		if len(parsed.InnerSignature) != 65 {
+			t.Errorf("expected 65-byte inner signature, got %d", len(parsed.InnerSignature))
+		}
+	})
+
+	t.Run("deployed wallet is not wrapped", func(t *testing.T) {
+		scheme := NewExactEvmScheme(&testSmartWalletSigner{ClientEvmSigner: owner})
+		payload, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1"))
+		if err != nil {
+			t.Fatalf("CreatePaymentPayload failed: %v", err)
+		}
+
+		evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+		sig, _ := evm.HexToBytes(evmPayload.Signature)
+		if evm.IsERC6492Signature(sig) || len(sig) != 65 {
+			t.Errorf("expected plain 65-byte signature, got %d bytes", len(sig))
+		}
+	})
+}
+
+func TestExactEvmSchemeDeterministicNonce(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	scheme := NewExactEvmScheme(signer)
+	requirements := testExactRequirements("1000")
+	value := big.NewInt(1000)
+
+	nonce, err := evm.CreateDeterministicNonce("order-42", signer.Address(), value)
+	if err != nil {
+		t.Fatalf("CreateDeterministicNonce failed: %v", err)
+	}
+	again, _ := evm.CreateDeterministicNonce("order-42", signer.Address(), value)
+	if nonce != again {
+		t.Error("expected the same key to yield the same nonce")
+	}
+
+	data := append([]byte("order-42"), common.HexToAddress(signer.Address()).Bytes()...)
+	data = append(data, common.LeftPadBytes(value.Bytes(), 32)...)
+	if want := crypto.Keccak256(data); !bytes.Equal(nonce[:], want) {
+		t.Errorf("nonce = %x, want keccak256(key || from || value) = %x", nonce, want)
+	}
+
+	other, _ := evm.CreateDeterministicNonce("order-43", signer.Address(), value)
+	if other == nonce {
+		t.Error("expected different keys to yield different nonces")
+	}
+
+	var nonces []string
+	for i := 0; i < 2; i++ {
+		payload, err := scheme.CreatePaymentPayloadWithNonce(context.Background(), requirements, nonce)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayloadWithNonce failed: %v", err)
+		}
+		evmPayload, err := evm.PayloadFromMap(payload.Payload)
+		if err != nil {
+			t.Fatalf("PayloadFromMap failed: %v", err)
+		}
+		nonces = append(nonces, evmPayload.Authorization.Nonce)
+	}
+	if nonces[0] != evm.BytesToHex(nonce[:]) || nonces[1] != nonces[0] {
+		t.Errorf("expected both payloads to carry nonce %x, got %v", nonce, nonces)
+	}
+
+	if _, err := evm.CreateDeterministicNonce("", signer.Address(), value); err == nil {
+		t.Error("expected error for empty idempotency key")
+	}
+}
+
+func TestExactEvmSchemeValidatesRequirements(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	requirements := testExactRequirements("1.5")
+	requirements.PayTo = "not-an-address"
+
+	_, err = NewExactEvmScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+	if err == nil || !strings.HasPrefix(err.Error(), ErrInvalidRequirements) {
+		t.Fatalf("expected %s error, got %v", ErrInvalidRequirements, err)
+	}
+	for _, want := range []string{"amount", "payTo"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got %v", want, err)
+		}
+	}
+}
+
+func TestExactEvmSchemeMaxAmount(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	ctx := context.Background()
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	scheme := NewExactEvmScheme(signer)
+	max := big.NewInt(1_000_000)
+	scheme.SetMaxAmount(max)
+	max.SetInt64(1) // the scheme keeps its own copy
+
+	if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000000")); err != nil {
+		t.Fatalf("expected an amount at the cap to be signed, got %v", err)
+	}
+
+	_, err = scheme.CreatePaymentPayload(ctx, testExactRequirements("1000001"))
+	var maxErr *AmountExceedsMaxError
+	if !errors.As(err, &maxErr) {
+		t.Fatalf("expected *AmountExceedsMaxError, got %v", err)
+	}
+	if maxErr.Amount.Int64() != 1_000_001 || maxErr.Max.Int64() != 1_000_000 || !strings.EqualFold(maxErr.Asset, testPermitToken) {
+		t.Errorf("unexpected error fields: %+v", maxErr)
+	}
+	if !strings.HasPrefix(err.Error(), ErrAmountExceedsMax) {
+		t.Errorf("expected %s prefix, got %v", ErrAmountExceedsMax, err)
+	}
+
+	// A nil cap signs any uint256
+	scheme.SetMaxAmount(nil)
+	if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements(maxUint256.String())); err != nil {
+		t.Errorf("expected the uint256 maximum to be signed without a cap, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		amount  string
+		wantErr string
+	}{
+		{name: "above uint256", amount: new(big.Int).Add(maxUint256, big.NewInt(1)).String(), wantErr: ErrInvalidAmount},
+		{name: "negative", amount: "-1", wantErr: ErrInvalidRequirements},
+		{name: "zero", amount: "0", wantErr: ErrZeroAmount},
+		{name: "hex", amount: "0x10", wantErr: ErrInvalidRequirements},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scheme.CreatePaymentPayload(ctx, testExactRequirements(tt.amount))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("expected %s error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExactEvmSchemeZeroAmount(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	ctx := context.Background()
+	scheme := NewExactEvmScheme(signer)
+
+	for _, amount := range []string{"0", "000"} {
+		_, err := scheme.CreatePaymentPayload(ctx, testExactRequirements(amount))
+		if err == nil || !strings.HasPrefix(err.Error(), ErrZeroAmount) {
+			t.Errorf("expected %s error for amount %q, got %v", ErrZeroAmount, amount, err)
+		}
+	}
+
+	// Identity proof: a zero-value authorization signed by the payer
+	scheme.SetAllowZeroAmount(true)
+	requirements := testExactRequirements("0")
+	payload, err := scheme.CreatePaymentPayload(ctx, requirements)
+	if err != nil {
+		t.Fatalf("expected a zero-value authorization, got %v", err)
+	}
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("PayloadFromMap failed: %v", err)
+	}
+	if evmPayload.Authorization.Value != "0" {
+		t.Errorf("expected value 0, got %s", evmPayload.Authorization.Value)
+	}
+	payload.Accepted = requirements
+	payer, err := evm.RecoverPayer(payload, requirements)
+	if err != nil {
+		t.Fatalf("RecoverPayer failed: %v", err)
+	}
+	if !strings.EqualFold(payer, signer.Address()) {
+		t.Errorf("expected the signature to recover %s, got %s", signer.Address(), payer)
+	}
+
+	// Other invalid amounts are still rejected
+	if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("-1")); err == nil || !strings.HasPrefix(err.Error(), ErrInvalidRequirements) {
+		t.Errorf("expected %s error for a negative amount, got %v", ErrInvalidRequirements, err)
+	}
+}
+
+func TestExactEvmSchemeDomainSeparatorCache(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	domainSeparator := domainSeparatorFor(t, evm.TypedDataDomain{
+		Name:              "Permit Token",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: testPermitToken,
+	})
+	ctx := context.Background()
+
+	t.Run("hits after first query", func(t *testing.T) {
+		var calls int32
+		rpc := fakeTokenRPCWithCalls(t,
+			map[string][]byte{"DOMAIN_SEPARATOR()": domainSeparator},
+			map[string]*int32{"DOMAIN_SEPARATOR()": &calls},
+		)
+		cache := evm.NewDomainSeparatorCache(time.Hour, 10)
+
+		// Two scheme instances share the cache
+		for i := 0; i < 2; i++ {
+			scheme := NewExactEvmScheme(signer)
+			scheme.SetDomainSeparatorCache(cache)
+			if err := scheme.SetRPCURL(rpc.URL); err != nil {
+				t.Fatalf("SetRPCURL failed: %v", err)
+			}
+			for j := 0; j < 2; j++ {
+				if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000")); err != nil {
+					t.Fatalf("CreatePaymentPayload failed: %v", err)
+				}
+			}
+		}
+
+		if calls != 1 {
+			t.Errorf("expected 1 DOMAIN_SEPARATOR query, got %d", calls)
+		}
+		if cached, ok := cache.Get(big.NewInt(1), testPermitToken); !ok || !bytes.Equal(cached, domainSeparator) {
+			t.Error("expected DOMAIN_SEPARATOR to be cached")
+		}
+	})
+
+	t.Run("failed query is not cached", func(t *testing.T) {
+		var calls int32
+		rpc := fakeTokenRPCWithCalls(t, nil, map[string]*int32{"DOMAIN_SEPARATOR()": &calls})
+		cache := evm.NewDomainSeparatorCache(time.Hour, 10)
+
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetDomainSeparatorCache(cache)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000")); err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+		}
+
+		if calls != 2 {
+			t.Errorf("expected every payload to query after a failure, got %d queries", calls)
+		}
+		if cache.Len() != 0 {
+			t.Errorf("expected empty cache, got %d entries", cache.Len())
+		}
+	})
+
+	t.Run("nil cache disables caching", func(t *testing.T) {
+		var calls int32
+		rpc := fakeTokenRPCWithCalls(t,
+			map[string][]byte{"DOMAIN_SEPARATOR()": domainSeparator},
+			map[string]*int32{"DOMAIN_SEPARATOR()": &calls},
+		)
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetDomainSeparatorCache(nil)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000")); err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 DOMAIN_SEPARATOR queries, got %d", calls)
+		}
+	})
+}
+
+// stringResult ABI-encodes s as a string return value
+func stringResult(s string) []byte {
+	encoded := common.LeftPadBytes(big.NewInt(32).Bytes(), 32)
+	encoded = append(encoded, common.LeftPadBytes(big.NewInt(int64(len(s))).Bytes(), 32)...)
+	return append(encoded, common.RightPadBytes([]byte(s), (len(s)+31)/32*32)...)
+}
+
+func TestExactEvmSchemeDomainValidation(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	domainSeparator := domainSeparatorFor(t, evm.TypedDataDomain{
+		Name:              "Permit Token",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: testPermitToken,
+	})
+	metadata := map[string][]byte{"name()": stringResult("Permit Token"), "version()": stringResult("1")}
+
+	tests := []struct {
+		name        string
+		results     map[string][]byte
+		extra       map[string]interface{}
+		wantErr     bool
+		wantOnChain string
+	}{
+		{
+			name:    "matching domain separator",
+			results: map[string][]byte{"DOMAIN_SEPARATOR()": domainSeparator},
+		},
+		{
+			name:        "mismatching domain separator",
+			results:     map[string][]byte{"DOMAIN_SEPARATOR()": domainSeparator, "name()": stringResult("Permit Token"), "version()": stringResult("1")},
+			extra:       map[string]interface{}{"name": "USD Coin", "version": "2"},
+			wantErr:     true,
+			wantOnChain: "Permit Token",
+		},
+		{
+			name:    "matching name and version without domain separator",
+			results: metadata,
+		},
+		{
+			name:        "mismatching version without domain separator",
+			results:     metadata,
+			extra:       map[string]interface{}{"name": "Permit Token", "version": "2"},
+			wantErr:     true,
+			wantOnChain: "Permit Token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc := fakeTokenRPC(t, tt.results)
+			scheme := NewExactEvmScheme(signer)
+			scheme.SetDomainSeparatorCache(nil)
+			scheme.SetValidateDomain(true)
+			if err := scheme.SetRPCURL(rpc.URL); err != nil {
+				t.Fatalf("SetRPCURL failed: %v", err)
+			}
+
+			requirements := testExactRequirements("1000")
+			if tt.extra != nil {
+				requirements.Extra = tt.extra
+			}
+			_, err := scheme.CreatePaymentPayload(context.Background(), requirements)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("CreatePaymentPayload failed: %v", err)
+				}
+				return
+			}
+			var mismatch *DomainMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("expected *DomainMismatchError, got %v", err)
+			}
+			if mismatch.Name != requirements.Extra["name"] || mismatch.OnChainName != tt.wantOnChain || mismatch.OnChainVersion != "1" {
+				t.Errorf("unexpected mismatch details: %+v", mismatch)
+			}
+			if !strings.Contains(err.Error(), ErrDomainMismatch) {
+				t.Errorf("expected error to contain %s, got %v", ErrDomainMismatch, err)
+			}
+		})
+	}
+
+	t.Run("requires RPC", func(t *testing.T) {
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetValidateDomain(true)
+		_, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1000"))
+		if err == nil || !strings.Contains(err.Error(), ErrFailedToValidateDomain) {
+			t.Errorf("expected %s, got %v", ErrFailedToValidateDomain, err)
+		}
+	})
+
+	t.Run("unverifiable token", func(t *testing.T) {
+		rpc := fakeTokenRPC(t, nil)
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetDomainSeparatorCache(nil)
+		scheme.SetValidateDomain(true)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+		_, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1000"))
+		if err == nil || !strings.Contains(err.Error(), ErrFailedToValidateDomain) {
+			t.Errorf("expected %s, got %v", ErrFailedToValidateDomain, err)
+		}
+	})
+}
+
+// digestOnlySigner signs digests like a hardware wallet or KMS: it cannot hash typed data
+type digestOnlySigner struct {
+	key   *ecdsa.PrivateKey
+	calls int
+}
+
+func (s *digestOnlySigner) Address() string {
+	return crypto.PubkeyToAddress(s.key.PublicKey).Hex()
+}
+
+func (s *digestOnlySigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	s.calls++
+	sig, err := crypto.Sign(digest, s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func TestExactEvmSchemeDigestOnlySigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	signer := &digestOnlySigner{key: key}
+
+	// Offline: the domain separator is derived from the name/version in Extra
+	payload, err := NewExactEvmScheme(signer).CreatePaymentPayload(context.Background(), testExactRequirements("1000"))
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	if signer.calls != 1 {
+		t.Errorf("expected 1 SignDigest call, got %d", signer.calls)
+	}
+
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("PayloadFromMap failed: %v", err)
+	}
+	digest, err := evm.HashEIP3009Authorization(evmPayload.Authorization, big.NewInt(1), testPermitToken, "Permit Token", "1")
+	if err != nil {
+		t.Fatalf("HashEIP3009Authorization failed: %v", err)
+	}
+	sig, _ := evm.HexToBytes(evmPayload.Signature)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if got := crypto.PubkeyToAddress(*pub); got.Hex() != signer.Address() {
+		t.Errorf("recovered %s, want %s", got.Hex(), signer.Address())
+	}
+
+	// Permit2 signs typed data through the same digest path
+	if _, err := NewExactEvmPermit2Scheme(signer).CreatePaymentPayload(context.Background(), testPermitRequirements()); err != nil {
+		t.Fatalf("Permit2 CreatePaymentPayload failed: %v", err)
+	}
+	if signer.calls != 2 {
+		t.Errorf("expected 2 SignDigest calls, got %d", signer.calls)
+	}
+}
+
+// fakeChainRPC answers eth_chainId with chainID, counting queries in calls; other methods fail
+func fakeChainRPC(t *testing.T, chainID int64, calls *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if req.Method == "eth_chainId" {
+			atomic.AddInt32(calls, 1)
+			resp["result"] = fmt.Sprintf("0x%x", chainID)
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32000, "message": "execution reverted"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExactEvmSchemeChainIDFromRPC(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("custom network uses the RPC chain ID once", func(t *testing.T) {
+		var calls int32
+		rpc := fakeChainRPC(t, 31337, &calls)
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetDomainSeparatorCache(nil)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+
+		requirements := testExactRequirements("1000")
+		requirements.Network = "local-devnet"
+		var payload types.PaymentPayload
+		for i := 0; i < 2; i++ {
+			if payload, err = scheme.CreatePaymentPayload(ctx, requirements); err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 eth_chainId query, got %d", calls)
+		}
+
+		// The authorization is signed for chain 31337
+		evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+		digest, err := evm.HashEIP3009Authorization(evmPayload.Authorization, big.NewInt(31337), testPermitToken, "Permit Token", "1")
+		if err != nil {
+			t.Fatalf("HashEIP3009Authorization failed: %v", err)
+		}
+		sig, _ := evm.HexToBytes(evmPayload.Signature)
+		sig[64] -= 27
+		pub, err := crypto.SigToPub(digest, sig)
+		if err != nil || crypto.PubkeyToAddress(*pub).Hex() != signer.Address() {
+			t.Errorf("expected signature for chain 31337, got %v", err)
+		}
+	})
+
+	t.Run("eip155 hint must match the RPC", func(t *testing.T) {
+		var calls int32
+		rpc := fakeChainRPC(t, 8453, &calls)
+		scheme := NewExactEvmScheme(signer)
+		if err := scheme.SetRPCURL(rpc.URL); err != nil {
+			t.Fatalf("SetRPCURL failed: %v", err)
+		}
+
+		_, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000"))
+		if err == nil || !strings.Contains(err.Error(), ErrChainIDMismatch) {
+			t.Errorf("expected %s, got %v", ErrChainIDMismatch, err)
+		}
+	})
+
+	t.Run("custom network without a chain ID fails", func(t *testing.T) {
+		requirements := testExactRequirements("1000")
+		requirements.Network = "local-devnet"
+
+		if _, err := NewExactEvmScheme(signer).CreatePaymentPayload(ctx, requirements); err == nil {
+			t.Error("expected error without RPC")
+		}
+
+		// An RPC that cannot report its chain ID is rejected up front
+		rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer rpc.Close()
+		if err := NewExactEvmScheme(signer).SetRPCURL(rpc.URL); err == nil || !strings.Contains(err.Error(), "failed to query RPC chain ID") {
+			t.Errorf("expected RPC chain ID error, got %v", err)
+		}
+	})
+}
+
+func TestExactEvmSchemeSetRPCURLContextCanceled(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	// An RPC that never answers
+	release := make(chan struct{})
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer rpc.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	scheme := NewExactEvmScheme(signer)
+	start := time.Now()
+	err = scheme.SetRPCURLContext(ctx, rpc.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the dial to abort promptly, took %s", elapsed)
+	}
+	if scheme.rpcClient() != nil {
+		t.Error("expected no RPC to be set after a failed dial")
+	}
+
+	// An already canceled context fails without waiting on the endpoint
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := NewExactEvmPermitScheme(signer).SetRPCURLContext(canceled, rpc.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExactEvmSchemeEstimateSettleGasRequiresRPC(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	scheme := NewExactEvmScheme(signer)
+	_, _, err = scheme.EstimateSettleGas(context.Background(), types.PaymentPayload{}, testExactRequirements("1"))
+	if err == nil || !strings.HasPrefix(err.Error(), ErrFailedToEstimateGas) {
+		t.Errorf("expected %s, got %v", ErrFailedToEstimateGas, err)
+	}
+}
+
+// fixedAddressSigner reports a fixed, possibly malformed, address
+type fixedAddressSigner struct{ address string }
+
+func (s fixedAddressSigner) Address() string { return s.address }
+
+func (s fixedAddressSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	return make([]byte, 65), nil
+}
+
+func TestExactEvmSchemeAddressChecksum(t *testing.T) {
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+	t.Run("normalizes to checksum", func(t *testing.T) {
+		scheme := NewExactEvmScheme(fixedAddressSigner{address: strings.ToLower(checksummed)})
+		requirements := testExactRequirements("1")
+		requirements.PayTo = "0x" + strings.ToUpper(checksummed[2:])
+
+		payload, err := scheme.CreatePaymentPayload(context.Background(), requirements)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayload failed: %v", err)
+		}
+		evmPayload, err := evm.PayloadFromMap(payload.Payload)
+		if err != nil {
+			t.Fatalf("PayloadFromMap failed: %v", err)
+		}
+		if evmPayload.Authorization.From != checksummed || evmPayload.Authorization.To != checksummed {
+			t.Errorf("From = %s, To = %s, want %s", evmPayload.Authorization.From, evmPayload.Authorization.To, checksummed)
+		}
+	})
+
+	invalid := []string{
+		"0xzz" + strings.Repeat("0", 38),
+		"0x1234",
+		"0x" + strings.Repeat("0", 42),
+	}
+	for _, address := range invalid {
+		t.Run("rejects signer "+address, func(t *testing.T) {
+			scheme := NewExactEvmScheme(fixedAddressSigner{address: address})
+
+			_, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1"))
+			if err == nil || !strings.HasPrefix(err.Error(), ErrInvalidAddress) {
+				t.Errorf("expected %s, got %v", ErrInvalidAddress, err)
+			}
+		})
+
+		t.Run("rejects payTo "+address, func(t *testing.T) {
+			scheme := NewExactEvmScheme(fixedAddressSigner{address: checksummed})
+			requirements := testExactRequirements("1")
+			requirements.PayTo = address
+
+			if _, err := scheme.CreatePaymentPayload(context.Background(), requirements); err == nil {
+				t.Error("expected error for invalid payTo")
+			}
+		})
+	}
+}
+
+func TestExactEvmSchemeDomainSalt(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	scheme := NewExactEvmScheme(signer)
+
+	salted := testExactRequirements("1000")
+	salted.Extra["salt"] = "0x000000000000000000000000000000000000000000000000000000000000abcd"
+	payload, err := scheme.CreatePaymentPayload(context.Background(), salted)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+
+	// The signature only recovers to the signer when the salt is part of the domain
+	payer, err := evm.RecoverPayer(payload, salted)
+	if err != nil {
+		t.Fatalf("RecoverPayer failed: %v", err)
+	}
+	if payer != signer.Address() {
+		t.Errorf("Expected salted payload to recover to %s, got %s", signer.Address(), payer)
+	}
+	payer, err = evm.RecoverPayer(payload, testExactRequirements("1000"))
+	if err != nil {
+		t.Fatalf("RecoverPayer failed: %v", err)
+	}
+	if payer == signer.Address() {
+		t.Error("Expected the salt to change the signed digest")
+	}
+
+	invalid := testExactRequirements("1000")
+	invalid.Extra["salt"] = "0x1234"
+	if _, err := scheme.CreatePaymentPayload(context.Background(), invalid); err == nil || !strings.Contains(err.Error(), ErrInvalidRequirements) {
+		t.Errorf("Expected %s for an invalid salt, got %v", ErrInvalidRequirements, err)
+	}
+}
+
+func TestExactEvmSchemeDomainWithoutChainID(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	scheme := NewExactEvmScheme(signer)
+
+	legacy := testExactRequirements("1000")
+	legacy.Extra["omitChainId"] = true
+	payload, err := scheme.CreatePaymentPayload(context.Background(), legacy)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+
+	// The signature only recovers to the signer when chainId is left out of the domain
+	payer, err := evm.RecoverPayer(payload, legacy)
+	if err != nil {
+		t.Fatalf("RecoverPayer failed: %v", err)
+	}
+	if payer != signer.Address() {
+		t.Errorf("Expected payload without chainId to recover to %s, got %s", signer.Address(), payer)
+	}
+	payer, err = evm.RecoverPayer(payload, testExactRequirements("1000"))
+	if err != nil {
+		t.Fatalf("RecoverPayer failed: %v", err)
+	}
+	if payer == signer.Address() {
+		t.Error("Expected omitting chainId to change the signed digest")
+	}
+
+	invalid := testExactRequirements("1000")
+	invalid.Extra["omitChainId"] = "true"
+	if _, err := scheme.CreatePaymentPayload(context.Background(), invalid); err == nil || !strings.Contains(err.Error(), ErrInvalidRequirements) {
+		t.Errorf("Expected %s for an invalid omitChainId, got %v", ErrInvalidRequirements, err)
+	}
+}
+
+func TestExactEvmSchemeConcurrentUse(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	rpc := fakeTokenRPC(t, map[string][]byte{"balanceOf(address)": uint256Result(1_000_000)})
+	scheme := NewExactEvmScheme(signer)
+	if err := scheme.SetRPCURL(rpc.URL); err != nil {
+		t.Fatalf("SetRPCURL failed: %v", err)
+	}
+	scheme.SetPrecheckBalance(true)
+
+	const workers = 32
+	requirements := testExactRequirements("1000")
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload, err := scheme.CreatePaymentPayload(context.Background(), requirements)
+			if err != nil {
+				errs <- err
+				return
+			}
+			payer, err := evm.RecoverPayer(payload, requirements)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if payer != signer.Address() {
+				errs <- fmt.Errorf("payload recovers to %s, want %s", payer, signer.Address())
+			}
+		}()
+	}
+
+	// Setters may race with payload creation without corrupting it
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < workers; i++ {
+			scheme.SetValidityPeriod(time.Duration(i+1) * time.Minute)
+			scheme.SetDomainSeparatorCache(evm.DefaultDomainSeparatorCache)
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent CreatePaymentPayload: %v", err)
+	}
+}
+
+// failingNonceStore is a NonceStore whose backend is unavailable
+type failingNonceStore struct{}
+
+func (failingNonceStore) Reserve(ctx context.Context, nonce [32]byte) error {
+	return errors.New("store unavailable")
+}
+
+func (failingNonceStore) MarkSettled(ctx context.Context, nonce [32]byte) error {
+	return errors.New("store unavailable")
+}
+
+func (failingNonceStore) IsUsed(ctx context.Context, nonce [32]byte) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func TestExactEvmSchemeNonceStore(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	ctx := context.Background()
+	requirements := testExactRequirements("1000")
+
+	t.Run("random nonces are reserved", func(t *testing.T) {
+		store := evm.NewMemoryNonceStore(0, 0)
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetNonceStore(store)
+
+		payload, err := scheme.CreatePaymentPayload(ctx, requirements)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayload failed: %v", err)
+		}
+		evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+		nonce, err := nonceFromHex(evmPayload.Authorization.Nonce)
+		if err != nil {
+			t.Fatalf("nonceFromHex failed: %v", err)
+		}
+		if used, _ := store.IsUsed(ctx, nonce); !used {
+			t.Error("expected the payload nonce to be reserved")
+		}
+	})
+
+	t.Run("explicit nonce is reusable until settled", func(t *testing.T) {
+		scheme := NewExactEvmScheme(signer)
+		nonce := [32]byte{0x42}
+
+		payload, err := scheme.CreatePaymentPayloadWithNonce(ctx, requirements, nonce)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayloadWithNonce failed: %v", err)
+		}
+		if _, err := scheme.CreatePaymentPayloadWithNonce(ctx, requirements, nonce); err != nil {
+			t.Fatalf("expected a retry with a reserved nonce to succeed, got %v", err)
+		}
+
+		if err := scheme.MarkNonceSettled(ctx, payload); err != nil {
+			t.Fatalf("MarkNonceSettled failed: %v", err)
+		}
+		_, err = scheme.CreatePaymentPayloadWithNonce(ctx, requirements, nonce)
+		if err == nil || !strings.Contains(err.Error(), ErrNonceAlreadyUsed) || !errors.Is(err, evm.ErrNonceSettled) {
+			t.Errorf("expected %s for a settled nonce, got %v", ErrNonceAlreadyUsed, err)
+		}
+	})
+
+	t.Run("rejected requirements reserve no nonce", func(t *testing.T) {
+		store := evm.NewMemoryNonceStore(0, 0)
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetNonceStore(store)
+		expired := testExactRequirements("1000")
+		expired.Extra = map[string]interface{}{"validBefore": 1}
+		nonce := [32]byte{0x44}
+
+		if _, err := scheme.CreatePaymentPayload(ctx, expired); err == nil {
+			t.Fatal("expected an expired validity window to be rejected")
+		}
+		if _, err := scheme.CreatePaymentPayloadWithNonce(ctx, expired, nonce); err == nil {
+			t.Fatal("expected an expired validity window to be rejected")
+		}
+		if store.Len() != 0 {
+			t.Errorf("expected no nonce to be reserved, got %d", store.Len())
+		}
+	})
+
+	t.Run("store errors fail the payload", func(t *testing.T) {
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetNonceStore(failingNonceStore{})
+
+		_, err := scheme.CreatePaymentPayload(ctx, requirements)
+		if err == nil || !strings.Contains(err.Error(), ErrFailedToReserveNonce) {
+			t.Errorf("expected %s, got %v", ErrFailedToReserveNonce, err)
+		}
+	})
+
+	t.Run("nil store disables tracking", func(t *testing.T) {
+		scheme := NewExactEvmScheme(signer)
+		scheme.SetNonceStore(nil)
+		nonce := [32]byte{0x43}
+
+		payload, err := scheme.CreatePaymentPayloadWithNonce(ctx, requirements, nonce)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayloadWithNonce failed: %v", err)
+		}
+		if err := scheme.MarkNonceSettled(ctx, payload); err != nil {
+			t.Fatalf("MarkNonceSettled failed: %v", err)
+		}
+		if _, err := scheme.CreatePaymentPayloadWithNonce(ctx, requirements, nonce); err != nil {
+			t.Errorf("expected no tracking without a store, got %v", err)
+		}
+	})
+}
+
+func TestExactEvmSchemeAlternateDomainSeparatorSelector(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	// The token's domain differs from extra.name, so the signature shows which separator was used
+	onChain := domainSeparatorFor(t, evm.TypedDataDomain{
+		Name:              "On-chain Token",
+		Version:           "2",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: testPermitToken,
+	})
+
+	rpc := fakeTokenRPC(t, map[string][]byte{"getDomainSeparator()": onChain})
+	scheme := NewExactEvmScheme(signer)
+	scheme.SetDomainSeparatorCache(nil)
+	if err := scheme.SetRPCURL(rpc.URL); err != nil {
+		t.Fatalf("SetRPCURL failed: %v", err)
+	}
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), testExactRequirements("1000"))
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+	digest, err := evm.HashEIP3009AuthorizationWithDomainSeparator(evmPayload.Authorization, onChain)
+	if err != nil {
+		t.Fatalf("HashEIP3009AuthorizationWithDomainSeparator failed: %v", err)
+	}
+	sig, _ := evm.HexToBytes(evmPayload.Signature)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil || crypto.PubkeyToAddress(*pub).Hex() != signer.Address() {
+		t.Errorf("expected a signature over the getDomainSeparator() domain, got %v", err)
+	}
+}
+
+// highSSigner signs like digestOnlySigner but returns the high-S form of every signature
+type highSSigner struct{ digestOnlySigner }
+
+func (s *highSSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	sig, err := s.digestOnlySigner.SignDigest(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+	n := crypto.S256().Params().N
+	new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64])).FillBytes(sig[32:64])
+	sig[64] = 27 + ((sig[64] - 27) ^ 1)
+	return sig, nil
+}
+
+func TestExactEvmSchemeNormalizesHighS(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	signer := &highSSigner{digestOnlySigner{key: key}}
+	requirements := testExactRequirements("1000")
+
+	payload, err := NewExactEvmScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	evmPayload, _ := evm.PayloadFromMap(payload.Payload)
+	sig, _ := evm.HexToBytes(evmPayload.Signature)
+
+	halfN := new(big.Int).Rsh(crypto.S256().Params().N, 1)
+	if new(big.Int).SetBytes(sig[32:64]).Cmp(halfN) > 0 {
+		t.Error("expected the payload signature to be low-S")
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Errorf("expected v of 27 or 28, got %d", sig[64])
+	}
+	payer, err := evm.RecoverPayer(payload, requirements)
+	if err != nil || payer != signer.Address() {
+		t.Errorf("expected the normalized signature to recover to %s, got %s (%v)", signer.Address(), payer, err)
+	}
+}
+
+func TestExactEvmSchemeSignatureType(t *testing.T) {
+	owner, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	deployment := &evm.SmartWalletDeployment{
+		Factory:         "0x1234567890123456789012345678901234567890",
+		FactoryCalldata: []byte{0x01},
+	}
+
+	tests := []struct {
+		name   string
+		signer evm.ClientEvmSigner
+		want   string
+	}{
+		{"EOA", owner, evm.SignatureTypeEOA},
+		{"deployed smart wallet", &testSmartWalletSigner{ClientEvmSigner: owner}, evm.SignatureTypeBytes},
+		{"undeployed smart wallet", &testSmartWalletSigner{ClientEvmSigner: owner, deployment: deployment}, evm.SignatureTypeBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := NewExactEvmScheme(tt.signer).CreatePaymentPayload(context.Background(), testExactRequirements("1"))
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+			if got := payload.Payload["signatureType"]; got != tt.want {
+				t.Errorf("expected signatureType %q in the payload map, got %v", tt.want, got)
+			}
+
+			// The type survives the JSON round trip to the facilitator
+			data, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatalf("failed to marshal payload: %v", err)
+			}
+			var decoded struct {
+				Payload map[string]interface{} `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("failed to unmarshal payload: %v", err)
+			}
+			evmPayload, _ := evm.PayloadFromMap(decoded.Payload)
+			if evmPayload.SignatureType != tt.want {
+				t.Errorf("expected signatureType %q after decoding, got %q", tt.want, evmPayload.SignatureType)
+			}
+			sig, _ := evm.HexToBytes(evmPayload.Signature)
+			inner, _ := evm.ParseERC6492Signature(sig)
+			if got := evmPayload.UseVRSSignature(inner.InnerSignature); got != (tt.want == evm.SignatureTypeEOA) {
+				t.Errorf("expected UseVRSSignature %v, got %v", tt.want == evm.SignatureTypeEOA, got)
+			}
+		})
+	}
+}
+
+func TestExactEvmSchemeVOffset(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	key, err := crypto.HexToECDSA(testPermitKey)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		offset byte
+	}{
+		{name: "default", offset: evm.VOffsetEthereum},
+		{name: "raw", offset: evm.VOffsetRaw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingAuditSink{}
+			scheme := NewExactEvmScheme(signer)
+			scheme.SetAuditSink(sink)
+			if tt.offset != evm.VOffsetEthereum {
+				if err := scheme.SetVOffset(tt.offset); err != nil {
+					t.Fatalf("SetVOffset failed: %v", err)
+				}
+			}
+
+			payload, err := scheme.CreatePaymentPayload(ctx, testExactRequirements("1000"))
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload failed: %v", err)
+			}
+			evmPayload, err := evm.PayloadFromMap(payload.Payload)
+			if err != nil {
+				t.Fatalf("PayloadFromMap failed: %v", err)
+			}
+			if evmPayload.SignatureType != evm.SignatureTypeEOA {
+				t.Errorf("expected signature type %s, got %s", evm.SignatureTypeEOA, evmPayload.SignatureType)
+			}
+			signature, err := evm.HexToBytes(evmPayload.Signature)
+			if err != nil || len(signature) != 65 {
+				t.Fatalf("expected a 65-byte signature, got %s (%v)", evmPayload.Signature, err)
+			}
+			if v := signature[64]; v != tt.offset && v != tt.offset+1 {
+				t.Errorf("expected v %d or %d, got %d", tt.offset, tt.offset+1, v)
+			}
+
+			// r and s are those of the key's own signature, only v is shifted
+			digest := sink.records[0].Digest
+			raw, err := crypto.Sign(digest, key)
+			if err != nil {
+				t.Fatalf("crypto.Sign failed: %v", err)
+			}
+			if !bytes.Equal(signature[:64], raw[:64]) || signature[64] != raw[64]+tt.offset {
+				t.Errorf("expected signature %x with v offset %d, got %x", raw, tt.offset, signature)
+			}
+
+			valid, err := evm.VerifyEOASignature(digest, signature, common.HexToAddress(signer.Address()))
+			if err != nil || !valid {
+				t.Errorf("expected the signature to recover the signer, got %v (%v)", valid, err)
+			}
+		})
+	}
+
+	if err := NewExactEvmScheme(signer).SetVOffset(1); err == nil {
+		t.Error("expected error for v offset 1")
+	}
+}
@@ -13,6 +13,7 @@ const (
 	ErrInvalidAuthorizationValue = "invalid_exact_evm_authorization_value"
 	ErrInvalidRequiredAmount     = "invalid_exact_evm_required_amount"
 	ErrInsufficientAmount        = "invalid_exact_evm_insufficient_amount"
+	ErrAmountMismatch            = "invalid_exact_evm_amount_mismatch"
 	ErrFailedToCheckNonce        = "invalid_exact_evm_failed_to_check_nonce"
 	ErrNonceAlreadyUsed          = "invalid_exact_evm_nonce_already_used"
 	ErrFailedToGetBalance        = "invalid_exact_evm_failed_to_get_balance"
@@ -28,4 +29,20 @@ const (
 	ErrFailedToExecuteTransfer = "invalid_exact_evm_failed_to_execute_transfer"
 	ErrFailedToGetReceipt      = "invalid_exact_evm_failed_to_get_receipt"
 	ErrTransactionFailed       = "invalid_exact_evm_transaction_failed"
+	ErrSimulationReverted      = "invalid_exact_evm_simulation_reverted"
 )
+
+// SettleRevertError is wrapped by the SettleError SimulateSettle returns when the
+// settlement call would revert
+type SettleRevertError struct {
+	RevertReason string // Decoded revert reason, or the call error text
+	Err          error  // Underlying eth_call error
+}
+
+func (e *SettleRevertError) Error() string {
+	return "settlement would revert: " + e.RevertReason
+}
+
+func (e *SettleRevertError) Unwrap() error {
+	return e.Err
+}
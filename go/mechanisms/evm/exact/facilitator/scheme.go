@@ -7,7 +7,10 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	x402 "github.com/gatechain/x402/go"
 	"github.com/gatechain/x402/go/mechanisms/evm"
@@ -19,6 +22,11 @@ type ExactEvmSchemeConfig struct {
 	// DeployERC4337WithEIP6492 enables automatic deployment of ERC-4337 smart wallets
 	// via EIP-6492 when encountering undeployed contract signatures during settlement
 	DeployERC4337WithEIP6492 bool
+
+	// AmountToleranceBps lets an authorization's value exceed requirements.Amount by up to
+	// this many basis points (e.g. 50 = 0.5%), for fee-on-transfer tokens whose clients sign
+	// more to cover the fee. Zero, the default, requires an exact match. See evm.CheckAuthorizationValue.
+	AmountToleranceBps int
 }
 
 // ExactEvmScheme implements the SchemeNetworkFacilitator interface for EVM exact payments (V2)
@@ -128,8 +136,15 @@ func (f *ExactEvmScheme) Verify(
 		return nil, x402.NewVerifyError(ErrInvalidRequiredAmount, "", network, fmt.Errorf("invalid amount: %s", requirements.Amount))
 	}
 
-	if authValue.Cmp(requiredValue) < 0 {
-		return nil, x402.NewVerifyError(ErrInsufficientAmount, evmPayload.Authorization.From, network, nil)
+	if err := evm.CheckAuthorizationValue(authValue, requiredValue, f.config.AmountToleranceBps); err != nil {
+		var mismatch *evm.AmountMismatchError
+		if !errors.As(err, &mismatch) {
+			return nil, x402.NewVerifyError(ErrInvalidRequiredAmount, evmPayload.Authorization.From, network, err)
+		}
+		if mismatch.Short() {
+			return nil, x402.NewVerifyError(ErrInsufficientAmount, evmPayload.Authorization.From, network, err)
+		}
+		return nil, x402.NewVerifyError(ErrAmountMismatch, evmPayload.Authorization.From, network, err)
 	}
 
 	// Check if nonce has been used
@@ -261,57 +276,11 @@ func (f *ExactEvmScheme) Settle(
 	// Use inner signature for settlement
 	signatureBytes = sigData.InnerSignature
 
-	// Parse values
-	value, _ := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
-	validAfter, _ := new(big.Int).SetString(evmPayload.Authorization.ValidAfter, 10)
-	validBefore, _ := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
-	nonceBytes, _ := evm.HexToBytes(evmPayload.Authorization.Nonce)
-
-	// Determine signature type: ECDSA (65 bytes) or smart wallet (longer)
-	isECDSA := len(signatureBytes) == 65
-
-	var txHash string
-	if isECDSA {
-		// For EOA wallets, use v,r,s overload
-		r := signatureBytes[0:32]
-		s := signatureBytes[32:64]
-		v := signatureBytes[64]
-		if v == 0 || v == 1 {
-			v += 27
-		}
-
-		txHash, err = f.signer.WriteContract(
-			ctx,
-			assetInfo.Address,
-			evm.TransferWithAuthorizationVRSABI,
-			evm.FunctionTransferWithAuthorization,
-			common.HexToAddress(evmPayload.Authorization.From),
-			common.HexToAddress(evmPayload.Authorization.To),
-			value,
-			validAfter,
-			validBefore,
-			[32]byte(nonceBytes),
-			v,
-			[32]byte(r),
-			[32]byte(s),
-		)
-	} else {
-		// For smart wallets, use bytes signature overload
-		txHash, err = f.signer.WriteContract(
-			ctx,
-			assetInfo.Address,
-			evm.TransferWithAuthorizationBytesABI,
-			evm.FunctionTransferWithAuthorization,
-			common.HexToAddress(evmPayload.Authorization.From),
-			common.HexToAddress(evmPayload.Authorization.To),
-			value,
-			validAfter,
-			validBefore,
-			[32]byte(nonceBytes),
-			signatureBytes,
-		)
+	contractABI, args, err := transferWithAuthorizationArgs(evmPayload, signatureBytes)
+	if err != nil {
+		return nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", err)
 	}
-
+	txHash, err := f.signer.WriteContract(ctx, assetInfo.Address, contractABI, evm.FunctionTransferWithAuthorization, args...)
 	if err != nil {
 		return nil, x402.NewSettleError(ErrFailedToExecuteTransfer, verifyResp.Payer, network, "", err)
 	}
@@ -334,6 +303,120 @@ func (f *ExactEvmScheme) Settle(
 	}, nil
 }
 
+// SimulateSettle dry-runs the transferWithAuthorization call Settle would submit, using
+// eth_call through the signer's ReadContract, so a facilitator can catch state changes
+// since Verify (balance spent elsewhere, nonce consumed) before paying for gas.
+//
+// Returns nil if the call would succeed. A call that would revert returns a SettleError
+// with reason ErrSimulationReverted wrapping a *SettleRevertError with the revert reason.
+// Payloads from undeployed smart wallets cannot be simulated before deployment and fail
+// with evm.ErrUndeployedSmartWallet.
+func (f *ExactEvmScheme) SimulateSettle(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) error {
+	network := x402.Network(requirements.Network)
+
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return x402.NewSettleError(ErrInvalidPayload, "", network, "", err)
+	}
+	payer := evmPayload.Authorization.From
+
+	assetInfo, err := evm.GetAssetInfo(string(requirements.Network), requirements.Asset)
+	if err != nil {
+		return x402.NewSettleError(ErrFailedToGetAssetInfo, payer, network, "", err)
+	}
+
+	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
+	if err != nil {
+		return x402.NewSettleError(ErrInvalidSignatureFormat, payer, network, "", err)
+	}
+	sigData, err := evm.ParseERC6492Signature(signatureBytes)
+	if err != nil {
+		return x402.NewSettleError(ErrFailedToParseSignature, payer, network, "", err)
+	}
+
+	zeroFactory := [20]byte{}
+	if sigData.Factory != zeroFactory && len(sigData.FactoryCalldata) > 0 {
+		code, err := f.signer.GetCode(ctx, payer)
+		if err != nil {
+			return x402.NewSettleError(ErrFailedToCheckDeployment, payer, network, "", err)
+		}
+		if len(code) == 0 {
+			return x402.NewSettleError(evm.ErrUndeployedSmartWallet, payer, network, "", nil)
+		}
+	}
+
+	contractABI, args, err := transferWithAuthorizationArgs(evmPayload, sigData.InnerSignature)
+	if err != nil {
+		return x402.NewSettleError(ErrInvalidPayload, payer, network, "", err)
+	}
+	if _, err := f.signer.ReadContract(ctx, assetInfo.Address, contractABI, evm.FunctionTransferWithAuthorization, args...); err != nil {
+		return x402.NewSettleError(ErrSimulationReverted, payer, network, "", &SettleRevertError{
+			RevertReason: revertReason(err),
+			Err:          err,
+		})
+	}
+	return nil
+}
+
+// transferWithAuthorizationArgs returns the transferWithAuthorization overload and arguments
+// for a payload: v,r,s for ECDSA (65-byte) signatures, bytes for smart wallet signatures
+// and payloads declaring SignatureTypeBytes. Returns an error for amounts, timestamps or a
+// nonce the ABI cannot encode.
+func transferWithAuthorizationArgs(evmPayload *evm.ExactEIP3009Payload, signatureBytes []byte) ([]byte, []interface{}, error) {
+	authorization := evmPayload.Authorization
+	value, ok1 := new(big.Int).SetString(authorization.Value, 10)
+	validAfter, ok2 := new(big.Int).SetString(authorization.ValidAfter, 10)
+	validBefore, ok3 := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, nil, fmt.Errorf("invalid authorization amounts or timestamps")
+	}
+	nonceBytes, err := evm.HexToBytes(authorization.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, nil, fmt.Errorf("invalid authorization nonce: %s", authorization.Nonce)
+	}
+
+	args := []interface{}{
+		common.HexToAddress(evmPayload.Authorization.From),
+		common.HexToAddress(evmPayload.Authorization.To),
+		value,
+		validAfter,
+		validBefore,
+		[32]byte(nonceBytes),
+	}
+
+	if !evmPayload.UseVRSSignature(signatureBytes) {
+		return evm.TransferWithAuthorizationBytesABI, append(args, signatureBytes), nil
+	}
+
+	r := signatureBytes[0:32]
+	s := signatureBytes[32:64]
+	v := signatureBytes[64]
+	if v == 0 || v == 1 {
+		v += 27
+	}
+	return evm.TransferWithAuthorizationVRSABI, append(args, v, [32]byte(r), [32]byte(s)), nil
+}
+
+// revertReason decodes the Error(string) revert reason carried by an eth_call error,
+// falling back to the error text
+func revertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if revertData, decodeErr := hexutil.Decode(data); decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(revertData); unpackErr == nil {
+					return reason
+				}
+			}
+		}
+	}
+	return err.Error()
+}
+
 // deploySmartWallet deploys an ERC-4337 smart wallet using the ERC-6492 factory
 //
 // This function sends the pre-encoded factory calldata directly as a transaction.
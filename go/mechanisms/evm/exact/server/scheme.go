@@ -353,9 +353,5 @@ func (s *ExactEvmScheme) ConvertFromTokenAmount(tokenAmount string, network stri
 
 // GetSupportedNetworks returns the list of supported networks
 func (s *ExactEvmScheme) GetSupportedNetworks() []string {
-	networks := make([]string, 0, len(evm.NetworkConfigs))
-	for network := range evm.NetworkConfigs {
-		networks = append(networks, network)
-	}
-	return networks
+	return evm.RegisteredNetworks()
 }
@@ -13,11 +13,11 @@ import (
 
 // ExactEvmSchemeV1 implements the SchemeNetworkClientV1 interface for EVM exact payments (V1)
 type ExactEvmSchemeV1 struct {
-	signer evm.ClientEvmSigner
+	signer evm.DigestSigner
 }
 
 // NewExactEvmSchemeV1 creates a new ExactEvmSchemeV1
-func NewExactEvmSchemeV1(signer evm.ClientEvmSigner) *ExactEvmSchemeV1 {
+func NewExactEvmSchemeV1(signer evm.DigestSigner) *ExactEvmSchemeV1 {
 	return &ExactEvmSchemeV1{
 		signer: signer,
 	}
@@ -167,6 +167,6 @@ func (c *ExactEvmSchemeV1) signAuthorization(
 		"nonce":       nonceBytes,
 	}
 
-	// Sign the typed data
-	return c.signer.SignTypedData(ctx, domain, types, "TransferWithAuthorization", message)
+	// Hash the typed data locally and sign the digest
+	return evm.SignTypedDataDigest(ctx, c.signer, domain, types, "TransferWithAuthorization", message)
 }
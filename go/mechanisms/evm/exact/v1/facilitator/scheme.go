@@ -21,6 +21,11 @@ type ExactEvmSchemeV1Config struct {
 	// DeployERC4337WithEIP6492 enables automatic deployment of ERC-4337 smart wallets
 	// via EIP-6492 when encountering undeployed contract signatures during settlement
 	DeployERC4337WithEIP6492 bool
+
+	// AmountToleranceBps lets an authorization's value exceed requirements.MaxAmountRequired by
+	// up to this many basis points, for fee-on-transfer tokens. Zero, the default, requires an
+	// exact match. See evm.CheckAuthorizationValue.
+	AmountToleranceBps int
 }
 
 // ExactEvmSchemeV1 implements the SchemeNetworkFacilitatorV1 interface for EVM exact payments (V1)
@@ -144,8 +149,15 @@ func (f *ExactEvmSchemeV1) Verify(
 		return nil, x402.NewVerifyError(ErrInvalidRequiredAmount, evmPayload.Authorization.From, network, fmt.Errorf("invalid amount: %s", amountStr))
 	}
 
-	if authValue.Cmp(requiredValue) < 0 {
-		return nil, x402.NewVerifyError(ErrAuthorizationValueInsufficient, evmPayload.Authorization.From, network, nil)
+	if err := evm.CheckAuthorizationValue(authValue, requiredValue, f.config.AmountToleranceBps); err != nil {
+		var mismatch *evm.AmountMismatchError
+		if !errors.As(err, &mismatch) {
+			return nil, x402.NewVerifyError(ErrInvalidRequiredAmount, evmPayload.Authorization.From, network, err)
+		}
+		if mismatch.Short() {
+			return nil, x402.NewVerifyError(ErrAuthorizationValueInsufficient, evmPayload.Authorization.From, network, err)
+		}
+		return nil, x402.NewVerifyError(ErrAuthorizationValueMismatch, evmPayload.Authorization.From, network, err)
 	}
 
 	// V1 specific: Check validBefore is in the future (with 6 second buffer for block time)
@@ -276,8 +288,8 @@ func (f *ExactEvmSchemeV1) Settle(
 	validBefore, _ := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
 	nonceBytes, _ := evm.HexToBytes(evmPayload.Authorization.Nonce)
 
-	// Determine signature type: ECDSA (65 bytes) or smart wallet (longer)
-	isECDSA := len(signatureBytes) == 65
+	// Determine signature type: ECDSA (65 bytes) or smart wallet (longer, or declared bytes)
+	isECDSA := evmPayload.UseVRSSignature(signatureBytes)
 
 	var txHash string
 	if isECDSA {
@@ -0,0 +1,118 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gatechain/x402/go/types"
+)
+
+// GasEstimator is the subset of ethclient.Client used to preview settlement costs
+type GasEstimator interface {
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// EstimateSettleGas estimates the gas a facilitator will spend submitting the
+// transferWithAuthorization call for an EIP-3009 payload, and the suggested gas price
+//
+// The call is simulated without a sender, since any account may submit the authorization.
+// Payloads from undeployed smart wallets (ERC-6492) cannot be estimated until the wallet exists.
+//
+// Returns:
+//
+//	gas: Estimated gas units for transferWithAuthorization
+//	gasPrice: Suggested gas price in wei; gas × gasPrice approximates the settlement fee
+//	error if the payload is malformed or the chain calls fail
+func EstimateSettleGas(
+	ctx context.Context,
+	ethClient GasEstimator,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (uint64, *big.Int, error) {
+	evmPayload, err := ParseExactPayload(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid exact EVM payload: %w", err)
+	}
+
+	assetInfo, err := GetAssetInfo(string(requirements.Network), requirements.Asset)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	calldata, err := TransferWithAuthorizationCalldata(evmPayload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	token := common.HexToAddress(assetInfo.Address)
+	gas, err := ethClient.EstimateGas(ctx, ethereum.CallMsg{To: &token, Data: calldata})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	return gas, gasPrice, nil
+}
+
+// TransferWithAuthorizationCalldata encodes the transferWithAuthorization call a facilitator
+// submits for an EIP-3009 payload: the v,r,s overload for 65-byte ECDSA signatures and the
+// bytes overload for smart wallet signatures (see UseVRSSignature). ERC-6492 wrappers are
+// removed first.
+func TransferWithAuthorizationCalldata(payload *ExactEIP3009Payload) ([]byte, error) {
+	signature, err := HexToBytes(payload.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	sigData, err := ParseERC6492Signature(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	signature = sigData.InnerSignature
+
+	authorization := payload.Authorization
+	value, ok1 := new(big.Int).SetString(authorization.Value, 10)
+	validAfter, ok2 := new(big.Int).SetString(authorization.ValidAfter, 10)
+	validBefore, ok3 := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("invalid authorization amounts or timestamps")
+	}
+	nonceBytes, err := HexToBytes(authorization.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, fmt.Errorf("invalid authorization nonce: %s", authorization.Nonce)
+	}
+
+	from := common.HexToAddress(authorization.From)
+	to := common.HexToAddress(authorization.To)
+
+	if payload.UseVRSSignature(signature) {
+		contractABI, err := abi.JSON(bytes.NewReader(TransferWithAuthorizationVRSABI))
+		if err != nil {
+			return nil, err
+		}
+		v := signature[64]
+		if v == 0 || v == 1 {
+			v += 27
+		}
+		return contractABI.Pack(FunctionTransferWithAuthorization,
+			from, to, value, validAfter, validBefore, [32]byte(nonceBytes),
+			v, [32]byte(signature[0:32]), [32]byte(signature[32:64]),
+		)
+	}
+
+	contractABI, err := abi.JSON(bytes.NewReader(TransferWithAuthorizationBytesABI))
+	if err != nil {
+		return nil, err
+	}
+	return contractABI.Pack(FunctionTransferWithAuthorization,
+		from, to, value, validAfter, validBefore, [32]byte(nonceBytes), signature,
+	)
+}
@@ -0,0 +1,99 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// GasFees are the EIP-1559 fee caps of a transaction, in wei
+type GasFees struct {
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// GasOracle prices the transactions SettleOnChain sends on a network
+type GasOracle interface {
+	GasFees(ctx context.Context, network string) (GasFees, error)
+}
+
+var gasOracles = map[string]GasOracle{} // network -> oracle, guarded by registryMu
+
+// RegisterGasOracle makes SettleOnChain price transactions on network with oracle, for chains
+// whose fee market the node's suggestions do not capture. A nil oracle removes it, restoring
+// the NodeGasOracle default.
+func RegisterGasOracle(network string, oracle GasOracle) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	network = normalizeNetworkLocked(network)
+	if oracle == nil {
+		delete(gasOracles, network)
+		return
+	}
+	gasOracles[network] = oracle
+}
+
+func lookupGasOracle(network string) (GasOracle, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	oracle, ok := gasOracles[normalizeNetworkLocked(network)]
+	return oracle, ok
+}
+
+// FeeSuggester is the subset of ethclient.Client NodeGasOracle needs
+type FeeSuggester interface {
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+// NodeGasOracle prices transactions from the node's suggestions: the suggested tip, and a
+// fee cap of twice the latest base fee plus the tip, which stays valid through several
+// blocks of rising base fees
+type NodeGasOracle struct {
+	ethClient FeeSuggester
+}
+
+// NewNodeGasOracle creates an oracle that asks ethClient for fee suggestions
+func NewNodeGasOracle(ethClient FeeSuggester) *NodeGasOracle {
+	return &NodeGasOracle{ethClient: ethClient}
+}
+
+// GasFees returns the node's suggested fees; network is not consulted
+func (o *NodeGasOracle) GasFees(ctx context.Context, network string) (GasFees, error) {
+	tip, err := o.ethClient.SuggestGasTipCap(ctx)
+	if err != nil {
+		return GasFees{}, fmt.Errorf("failed to get gas tip: %w", err)
+	}
+	header, err := o.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return GasFees{}, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if header.BaseFee == nil {
+		return GasFees{}, fmt.Errorf("chain does not support EIP-1559 fees")
+	}
+
+	maxFee := new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+	return GasFees{MaxFeePerGas: maxFee.Add(maxFee, tip), MaxPriorityFeePerGas: tip}, nil
+}
+
+// gasFees asks the network's oracle, or the node, for the fees of a transaction
+func gasFees(ctx context.Context, ethClient FeeSuggester, network string) (GasFees, error) {
+	oracle, ok := lookupGasOracle(network)
+	if !ok {
+		oracle = NewNodeGasOracle(ethClient)
+	}
+	fees, err := oracle.GasFees(ctx, network)
+	if err != nil {
+		return GasFees{}, err
+	}
+	if fees.MaxFeePerGas == nil || fees.MaxPriorityFeePerGas == nil || fees.MaxPriorityFeePerGas.Sign() < 0 {
+		return GasFees{}, fmt.Errorf("gas oracle returned invalid fees")
+	}
+	if fees.MaxFeePerGas.Cmp(fees.MaxPriorityFeePerGas) < 0 {
+		return GasFees{}, fmt.Errorf("gas oracle returned maxFeePerGas %s below maxPriorityFeePerGas %s",
+			fees.MaxFeePerGas, fees.MaxPriorityFeePerGas)
+	}
+	return fees, nil
+}
@@ -0,0 +1,150 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gatechain/x402/go/types"
+)
+
+// mockGasEstimator records the simulated call and returns programmed results
+type mockGasEstimator struct {
+	gas         uint64
+	gasPrice    *big.Int
+	estimateErr error
+	priceErr    error
+	call        ethereum.CallMsg
+}
+
+func (m *mockGasEstimator) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	m.call = call
+	return m.gas, m.estimateErr
+}
+
+func (m *mockGasEstimator) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return m.gasPrice, m.priceErr
+}
+
+func gasTestPayload(signature []byte) types.PaymentPayload {
+	evmPayload := &ExactEIP3009Payload{
+		Signature: BytesToHex(signature),
+		Authorization: ExactEIP3009Authorization{
+			From:        "0x1111111111111111111111111111111111111111",
+			To:          "0x2222222222222222222222222222222222222222",
+			Value:       "1000000",
+			ValidAfter:  "0",
+			ValidBefore: "9999999999",
+			Nonce:       "0x" + strings.Repeat("ab", 32),
+		},
+	}
+	return types.PaymentPayload{X402Version: 2, Payload: evmPayload.ToMap()}
+}
+
+func TestEstimateSettleGas(t *testing.T) {
+	requirements := types.PaymentRequirements{
+		Scheme:  SchemeExact,
+		Network: "eip155:10087",
+		Asset:   "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF",
+		Amount:  "1000000",
+	}
+
+	vrsABI, _ := abi.JSON(bytes.NewReader(TransferWithAuthorizationVRSABI))
+	bytesABI, _ := abi.JSON(bytes.NewReader(TransferWithAuthorizationBytesABI))
+
+	tests := []struct {
+		name      string
+		signature []byte
+		selector  []byte
+	}{
+		{
+			name:      "EOA signature uses v,r,s overload",
+			signature: append(bytes.Repeat([]byte{0x01}, 64), 0x1b),
+			selector:  vrsABI.Methods[FunctionTransferWithAuthorization].ID,
+		},
+		{
+			name:      "smart wallet signature uses bytes overload",
+			signature: bytes.Repeat([]byte{0x02}, 100),
+			selector:  bytesABI.Methods[FunctionTransferWithAuthorization].ID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimator := &mockGasEstimator{gas: 65000, gasPrice: big.NewInt(1_000_000_000)}
+
+			gas, gasPrice, err := EstimateSettleGas(context.Background(), estimator, gasTestPayload(tt.signature), requirements)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if gas != 65000 || gasPrice.Cmp(big.NewInt(1_000_000_000)) != 0 {
+				t.Errorf("Expected 65000 gas at 1 gwei, got %d at %s", gas, gasPrice)
+			}
+			if estimator.call.To == nil || *estimator.call.To != common.HexToAddress(requirements.Asset) {
+				t.Errorf("Expected call to the token, got %v", estimator.call.To)
+			}
+			if !bytes.HasPrefix(estimator.call.Data, tt.selector) {
+				t.Errorf("Expected selector %x, got %x", tt.selector, estimator.call.Data[:4])
+			}
+		})
+	}
+
+	t.Run("normalizes v of 0 or 1", func(t *testing.T) {
+		evmPayload, _ := PayloadFromMap(gasTestPayload(append(bytes.Repeat([]byte{0x01}, 64), 0x00)).Payload)
+		calldata, err := TransferWithAuthorizationCalldata(evmPayload)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		args, err := vrsABI.Methods[FunctionTransferWithAuthorization].Inputs.Unpack(calldata[4:])
+		if err != nil {
+			t.Fatalf("Failed to unpack calldata: %v", err)
+		}
+		if v := args[6].(uint8); v != 27 {
+			t.Errorf("Expected v=27, got %d", v)
+		}
+	})
+
+	t.Run("declared bytes signature uses bytes overload", func(t *testing.T) {
+		evmPayload, _ := PayloadFromMap(gasTestPayload(append(bytes.Repeat([]byte{0x01}, 64), 0x1b)).Payload)
+		evmPayload.SignatureType = SignatureTypeBytes
+		calldata, err := TransferWithAuthorizationCalldata(evmPayload)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.HasPrefix(calldata, bytesABI.Methods[FunctionTransferWithAuthorization].ID) {
+			t.Errorf("Expected the bytes overload for a declared smart wallet signature, got %x", calldata[:4])
+		}
+	})
+
+	t.Run("propagates estimation errors", func(t *testing.T) {
+		reverted := errors.New("execution reverted")
+		estimator := &mockGasEstimator{estimateErr: reverted}
+		_, _, err := EstimateSettleGas(context.Background(), estimator, gasTestPayload(make([]byte, 65)), requirements)
+		if !errors.Is(err, reverted) {
+			t.Errorf("Expected estimation error, got %v", err)
+		}
+	})
+
+	t.Run("propagates gas price errors", func(t *testing.T) {
+		unavailable := errors.New("rpc unavailable")
+		estimator := &mockGasEstimator{gas: 65000, priceErr: unavailable}
+		_, _, err := EstimateSettleGas(context.Background(), estimator, gasTestPayload(make([]byte, 65)), requirements)
+		if !errors.Is(err, unavailable) {
+			t.Errorf("Expected gas price error, got %v", err)
+		}
+	})
+
+	t.Run("rejects malformed nonce", func(t *testing.T) {
+		payload := gasTestPayload(make([]byte, 65))
+		payload.Payload["authorization"].(map[string]interface{})["nonce"] = "0x1234"
+		if _, _, err := EstimateSettleGas(context.Background(), &mockGasEstimator{}, payload, requirements); err == nil {
+			t.Error("Expected error for malformed nonce")
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// networkAliases maps lowercase alternative names to canonical network keys, guarded by registryMu
+var networkAliases = map[string]string{
+	"base":              "eip155:8453",
+	"base-mainnet":      "eip155:8453",
+	"base-sepolia":      "eip155:84532",
+	"gatelayer-testnet": "gatelayer_testnet",
+}
+
+// RegisterNetworkAlias makes alias (matched case-insensitively) resolve to the network key,
+// which must be built in, registered, or in eip155:CHAIN_ID form
+//
+// Returns an error if alias is already an alias or a network key, unless WithOverride is given.
+func RegisterNetworkAlias(alias, key string, opts ...RegisterOption) error {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" {
+		return fmt.Errorf("network alias is required")
+	}
+	if strings.HasPrefix(alias, "eip155:") {
+		return fmt.Errorf("network alias %s cannot be in eip155: form", alias)
+	}
+
+	options := applyRegisterOptions(opts)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	key = normalizeNetworkLocked(key)
+	if _, ok := lookupNetworkLocked(key); !ok {
+		if _, ok := parseCAIP2ChainID(key); !ok {
+			return fmt.Errorf("network alias %s: unknown network %s", alias, key)
+		}
+	}
+
+	if !options.override {
+		if _, ok := lookupNetworkLocked(alias); ok {
+			return fmt.Errorf("network alias %s is a network key; use WithOverride to shadow it", alias)
+		}
+		if existing, ok := networkAliases[alias]; ok {
+			return fmt.Errorf("network alias %s already resolves to %s; use WithOverride to replace it", alias, existing)
+		}
+	}
+
+	networkAliases[alias] = key
+	return nil
+}
+
+// NormalizeNetwork returns the canonical key for a network identifier: surrounding spaces are
+// trimmed, the eip155 prefix is lowercased (with leading zeros dropped from the chain ID),
+// aliases such as "base" are resolved, and names matching a key case-insensitively are
+// lowercased. Unknown identifiers are returned trimmed but otherwise unchanged.
+func NormalizeNetwork(network string) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return normalizeNetworkLocked(network)
+}
+
+// normalizeNetworkLocked is NormalizeNetwork for callers holding registryMu
+func normalizeNetworkLocked(network string) string {
+	trimmed := strings.TrimSpace(network)
+	if len(trimmed) > len("eip155:") && strings.EqualFold(trimmed[:len("eip155:")], "eip155:") {
+		caip2 := "eip155:" + strings.TrimSpace(trimmed[len("eip155:"):])
+		if chainID, ok := parseCAIP2ChainID(caip2); ok {
+			return "eip155:" + chainID.String()
+		}
+		return caip2
+	}
+
+	// Exact keys win over aliases, so a registered network can take over an alias' name
+	if _, ok := lookupNetworkLocked(trimmed); ok {
+		return trimmed
+	}
+	lower := strings.ToLower(trimmed)
+	if key, ok := networkAliases[lower]; ok {
+		return key
+	}
+	if _, ok := lookupNetworkLocked(lower); ok {
+		return lower
+	}
+	return trimmed
+}
+
+// parseCAIP2ChainID returns the chain ID of an eip155:CHAIN_ID key
+func parseCAIP2ChainID(network string) (*big.Int, bool) {
+	chainIDStr, ok := strings.CutPrefix(network, "eip155:")
+	if !ok || chainIDStr == "" || !isDigits(chainIDStr) {
+		return nil, false
+	}
+	return new(big.Int).SetString(chainIDStr, 10)
+}
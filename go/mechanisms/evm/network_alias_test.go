@@ -0,0 +1,99 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestNormalizeNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		want    string
+	}{
+		{"eip155:10087", "eip155:10087"},
+		{"EIP155:10087", "eip155:10087"},
+		{"  eip155:10087 ", "eip155:10087"},
+		{"Eip155: 0010087", "eip155:10087"},
+		{"gatelayer_testnet", "gatelayer_testnet"},
+		{"GateLayer_Testnet", "gatelayer_testnet"},
+		{"gatelayer-testnet", "gatelayer_testnet"},
+		{"Base", "eip155:8453"},
+		{"base-sepolia", "eip155:84532"},
+		{"eip155:abc", "eip155:abc"},
+		{" solana ", "solana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			if got := NormalizeNetwork(tt.network); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNetworkLookupsAreNormalized(t *testing.T) {
+	for _, network := range []string{"EIP155:10087", "gatelayer-testnet", "GATELAYER_TESTNET", " eip155:10087"} {
+		chainID, err := GetEvmChainId(network)
+		if err != nil || chainID.Cmp(ChainIDGateLayerTestnet) != 0 {
+			t.Errorf("GetEvmChainId(%q): expected %s, got %v, %v", network, ChainIDGateLayerTestnet, chainID, err)
+		}
+		info, err := GetAssetInfo(network, "")
+		if err != nil || info.Address != NetworkConfigs["eip155:10087"].DefaultAsset.Address {
+			t.Errorf("GetAssetInfo(%q): expected the default asset, got %+v, %v", network, info, err)
+		}
+	}
+
+	for _, network := range []string{"gatelayer", "eip155:", "eip155:-1", "eip155:0x10"} {
+		if _, err := GetEvmChainId(network); !errors.Is(err, ErrUnsupportedNetwork) {
+			t.Errorf("GetEvmChainId(%q): expected ErrUnsupportedNetwork, got %v", network, err)
+		}
+	}
+}
+
+func TestRegisterNetworkAlias(t *testing.T) {
+	if err := RegisterNetworkAlias("GateLayer", "gatelayer_testnet"); err != nil {
+		t.Fatalf("RegisterNetworkAlias failed: %v", err)
+	}
+	chainID, err := GetEvmChainId("gatelayer")
+	if err != nil || chainID.Cmp(ChainIDGateLayerTestnet) != 0 {
+		t.Errorf("Expected the alias to resolve to %s, got %v, %v", ChainIDGateLayerTestnet, chainID, err)
+	}
+
+	// Assets registered under an alias are found under the canonical key
+	const token = "0x3333333333333333333333333333333333333333"
+	if err := RegisterAsset("GateLayer", token, AssetInfo{Name: "Alias Token", Version: "1", Decimals: 8}); err != nil {
+		t.Fatalf("RegisterAsset failed: %v", err)
+	}
+	info, err := GetAssetInfo("eip155:10087", token)
+	if err == nil && info.Name == "Alias Token" {
+		t.Errorf("Expected the asset to be registered for gatelayer_testnet, not eip155:10087")
+	}
+	info, err = GetAssetInfo("gatelayer_testnet", token)
+	if err != nil || info.Name != "Alias Token" {
+		t.Errorf("Expected the registered asset, got %+v, %v", info, err)
+	}
+
+	if err := RegisterNetworkAlias("gatelayer", "eip155:10087"); err == nil {
+		t.Error("Expected error re-registering an alias")
+	}
+	if err := RegisterNetworkAlias("gatelayer", "eip155:10087", WithOverride()); err != nil {
+		t.Errorf("Expected override to succeed, got %v", err)
+	}
+	if err := RegisterNetworkAlias("gatelayer_testnet", "eip155:1"); err == nil {
+		t.Error("Expected error aliasing a network key")
+	}
+	if err := RegisterNetworkAlias("mainnet", "ethereum"); err == nil {
+		t.Error("Expected error aliasing an unknown network")
+	}
+	if err := RegisterNetworkAlias("eip155:1", "eip155:8453"); err == nil {
+		t.Error("Expected error for an eip155: alias")
+	}
+	if err := RegisterNetworkAlias("ethereum", "eip155:1"); err != nil {
+		t.Errorf("Expected a CAIP-2 target to be accepted, got %v", err)
+	}
+	if chainID, err := GetEvmChainId("Ethereum"); err != nil || chainID.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("Expected chain 1, got %v, %v", chainID, err)
+	}
+}
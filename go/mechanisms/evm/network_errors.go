@@ -0,0 +1,167 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sentinels matched by UnsupportedNetworkError and UnsupportedAssetError with errors.Is
+var (
+	ErrUnsupportedNetwork = errors.New("unsupported network")
+	ErrUnsupportedAsset   = errors.New("unsupported asset")
+)
+
+// maxSuggestions is the number of near-miss keys reported in the errors below
+const maxSuggestions = 3
+
+// UnsupportedNetworkError is returned by GetEvmChainId and GetNetworkConfig when a network
+// is neither built in, registered, nor a valid eip155:CHAIN_ID identifier
+type UnsupportedNetworkError struct {
+	Network     string   // Network as requested
+	Suggestions []string // Closest built-in or registered keys, best first
+	CAIP2       string   // Registered eip155: key of the best suggestion's chain, if different from it
+}
+
+// Error implements the error interface
+func (e *UnsupportedNetworkError) Error() string {
+	msg := fmt.Sprintf("%s: %s (expected eip155:CHAIN_ID or a registered network)", ErrUnsupportedNetwork, e.Network)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf("; did you mean %s?", strings.Join(e.Suggestions, ", "))
+	}
+	if e.CAIP2 != "" {
+		msg += fmt.Sprintf(" (CAIP-2 form: %s)", e.CAIP2)
+	}
+	return msg
+}
+
+// Is matches ErrUnsupportedNetwork
+func (e *UnsupportedNetworkError) Is(target error) bool {
+	return target == ErrUnsupportedNetwork
+}
+
+// UnsupportedAssetError is returned by GetAssetInfo when no explicit address is given and
+// the network has no default asset
+type UnsupportedAssetError struct {
+	Network     string   // Network as requested
+	Asset       string   // Symbol as requested (empty for the default asset)
+	Suggestions []string // Addresses of the network's known assets, closest name first
+}
+
+// Error implements the error interface
+func (e *UnsupportedAssetError) Error() string {
+	msg := fmt.Sprintf("%s: no default asset configured for network %s", ErrUnsupportedAsset, e.Network)
+	if e.Asset != "" {
+		msg = fmt.Sprintf("%s: %s on network %s", ErrUnsupportedAsset, e.Asset, e.Network)
+	}
+	if len(e.Suggestions) > 0 {
+		return msg + fmt.Sprintf("; known assets: %s", strings.Join(e.Suggestions, ", "))
+	}
+	return msg + "; specify an explicit asset address or register a money parser"
+}
+
+// Is matches ErrUnsupportedAsset
+func (e *UnsupportedAssetError) Is(target error) bool {
+	return target == ErrUnsupportedAsset
+}
+
+// newUnsupportedNetworkError builds the error for network with suggestions from the registry
+func newUnsupportedNetworkError(network string) *UnsupportedNetworkError {
+	keys := RegisteredNetworks()
+	err := &UnsupportedNetworkError{Network: network, Suggestions: closestKeys(network, keys)}
+	if len(err.Suggestions) == 0 {
+		return err
+	}
+
+	best, _ := lookupNetwork(err.Suggestions[0])
+	if best.ChainID == nil {
+		return err
+	}
+	caip2 := "eip155:" + best.ChainID.String()
+	if caip2 == err.Suggestions[0] {
+		return err
+	}
+	if _, ok := lookupNetwork(caip2); ok {
+		err.CAIP2 = caip2
+	}
+	return err
+}
+
+// newUnsupportedAssetError builds the error for a symbol on network from its known assets
+func newUnsupportedAssetError(network, symbol string) *UnsupportedAssetError {
+	err := &UnsupportedAssetError{Network: network, Asset: symbol}
+	assets, listErr := ListAssets(network)
+	if listErr != nil {
+		return err
+	}
+
+	target := strings.ToLower(symbol)
+	sort.SliceStable(assets, func(i, j int) bool {
+		return editDistance(target, strings.ToLower(assets[i].Name)) < editDistance(target, strings.ToLower(assets[j].Name))
+	})
+	for i, info := range assets {
+		if i == maxSuggestions {
+			break
+		}
+		err.Suggestions = append(err.Suggestions, info.Address)
+	}
+	return err
+}
+
+// closestKeys returns up to maxSuggestions keys within a small edit distance of value,
+// closest first. Case is ignored and '-' matches '_'.
+func closestKeys(value string, keys []string) []string {
+	normalize := func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(s), "-", "_")
+	}
+	target := normalize(value)
+	limit := len(target) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	type candidate struct {
+		key      string
+		distance int
+	}
+	var candidates []candidate
+	for _, key := range keys {
+		if d := editDistance(target, normalize(key)); d <= limit {
+			candidates = append(candidates, candidate{key, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var suggestions []string
+	for i, c := range candidates {
+		if i == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, c.key)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
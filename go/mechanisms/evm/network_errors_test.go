@@ -0,0 +1,80 @@
+package evm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnsupportedNetworkSuggestions(t *testing.T) {
+	tests := []struct {
+		network     string
+		suggestions []string
+		caip2       string
+	}{
+		{"gatelayer-testnt", []string{"gatelayer_testnet"}, "eip155:10087"},
+		{"GateLayer_Testnt", []string{"gatelayer_testnet"}, "eip155:10087"},
+		{"gatelayer_testnt", []string{"gatelayer_testnet"}, "eip155:10087"},
+		{"eip155:1008x", []string{"eip155:10087"}, ""},
+		{"solana", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			for name, lookup := range map[string]func(string) error{
+				"GetEvmChainId":    func(n string) error { _, err := GetEvmChainId(n); return err },
+				"GetNetworkConfig": func(n string) error { _, err := GetNetworkConfig(n); return err },
+				"GetAssetInfo":     func(n string) error { _, err := GetAssetInfo(n, ""); return err },
+			} {
+				err := lookup(tt.network)
+				if !errors.Is(err, ErrUnsupportedNetwork) {
+					t.Fatalf("%s: expected ErrUnsupportedNetwork, got %v", name, err)
+				}
+				var unsupported *UnsupportedNetworkError
+				if !errors.As(err, &unsupported) {
+					t.Fatalf("%s: expected *UnsupportedNetworkError, got %T", name, err)
+				}
+				if unsupported.Network != tt.network {
+					t.Errorf("%s: expected network %q, got %q", name, tt.network, unsupported.Network)
+				}
+				if !reflect.DeepEqual(unsupported.Suggestions, tt.suggestions) {
+					t.Errorf("%s: expected suggestions %v, got %v", name, tt.suggestions, unsupported.Suggestions)
+				}
+				if unsupported.CAIP2 != tt.caip2 {
+					t.Errorf("%s: expected CAIP-2 form %q, got %q", name, tt.caip2, unsupported.CAIP2)
+				}
+			}
+		})
+	}
+
+	_, err := GetEvmChainId("gatelayer-testnt")
+	if msg := err.Error(); !strings.Contains(msg, "did you mean gatelayer_testnet") || !strings.Contains(msg, "eip155:10087") {
+		t.Errorf("expected suggestions in the message, got %q", msg)
+	}
+}
+
+func TestUnsupportedAssetSuggestions(t *testing.T) {
+	const token = "0x1212121212121212121212121212121212121212"
+	if err := RegisterAsset("eip155:700066", token, AssetInfo{Name: "USDC", Version: "2", Decimals: 6}); err != nil {
+		t.Fatalf("RegisterAsset failed: %v", err)
+	}
+
+	_, err := GetAssetInfo("eip155:700066", "USDC")
+	var unsupported *UnsupportedAssetError
+	if !errors.As(err, &unsupported) || !errors.Is(err, ErrUnsupportedAsset) {
+		t.Fatalf("expected *UnsupportedAssetError, got %v", err)
+	}
+	if unsupported.Network != "eip155:700066" || unsupported.Asset != "USDC" {
+		t.Errorf("unexpected error fields: %+v", unsupported)
+	}
+	if !reflect.DeepEqual(unsupported.Suggestions, []string{NormalizeAddress(token)}) {
+		t.Errorf("expected the registered asset as a suggestion, got %v", unsupported.Suggestions)
+	}
+
+	// A network with no known assets keeps the original guidance
+	_, err = GetAssetInfo("eip155:700067", "")
+	if !errors.Is(err, ErrUnsupportedAsset) || !strings.Contains(err.Error(), "register a money parser") {
+		t.Errorf("expected the default asset guidance, got %v", err)
+	}
+}
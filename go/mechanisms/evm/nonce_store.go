@@ -0,0 +1,145 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults for NewMemoryNonceStore as used by the client schemes
+const (
+	DefaultNonceStoreTTL        = time.Duration(DefaultValidityPeriod) * time.Second
+	DefaultNonceStoreMaxEntries = 10000
+)
+
+// Errors returned by NonceStore.Reserve
+var (
+	ErrNonceReserved = errors.New("nonce already reserved")
+	ErrNonceSettled  = errors.New("nonce already settled")
+)
+
+// NonceStore tracks the EIP-3009 nonces a client has issued, so a local bug or a retry
+// after a crash does not reissue a nonce that is still in flight or already settled.
+// It complements the token's own replay protection; implementations backed by shared
+// storage (e.g. Redis) extend the protection across processes.
+type NonceStore interface {
+	// Reserve records nonce as issued. It returns ErrNonceReserved if the nonce was already
+	// reserved and ErrNonceSettled if it was marked settled.
+	Reserve(ctx context.Context, nonce [32]byte) error
+	// MarkSettled records that the authorization carrying nonce was settled
+	MarkSettled(ctx context.Context, nonce [32]byte) error
+	// IsUsed reports whether nonce was reserved or settled
+	IsUsed(ctx context.Context, nonce [32]byte) (bool, error)
+}
+
+// MemoryNonceStore is a concurrency-safe in-process NonceStore. Entries expire after the
+// TTL; when the store is full, expired entries are dropped first and then the oldest entry
+// is evicted.
+type MemoryNonceStore struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[[32]byte]nonceEntry
+}
+
+type nonceEntry struct {
+	settled  bool
+	storedAt time.Time
+}
+
+// NewMemoryNonceStore creates a store whose entries live for ttl.
+// A ttl of zero or less keeps entries until evicted; maxEntries of zero or less means no size limit.
+func NewMemoryNonceStore(ttl time.Duration, maxEntries int) *MemoryNonceStore {
+	return &MemoryNonceStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[[32]byte]nonceEntry),
+	}
+}
+
+// Reserve implements NonceStore
+func (s *MemoryNonceStore) Reserve(ctx context.Context, nonce [32]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.liveLocked(nonce); ok {
+		if entry.settled {
+			return ErrNonceSettled
+		}
+		return ErrNonceReserved
+	}
+	s.storeLocked(nonce, false)
+	return nil
+}
+
+// MarkSettled implements NonceStore. Nonces that were never reserved are recorded as settled too.
+func (s *MemoryNonceStore) MarkSettled(ctx context.Context, nonce [32]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storeLocked(nonce, true)
+	return nil
+}
+
+// IsUsed implements NonceStore
+func (s *MemoryNonceStore) IsUsed(ctx context.Context, nonce [32]byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.liveLocked(nonce)
+	return ok, nil
+}
+
+// Len returns the number of tracked nonces, including ones that have expired but not been evicted
+func (s *MemoryNonceStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// liveLocked returns the entry for nonce unless it is missing or expired. s.mu must be held.
+func (s *MemoryNonceStore) liveLocked(nonce [32]byte) (nonceEntry, bool) {
+	entry, ok := s.entries[nonce]
+	if !ok {
+		return nonceEntry{}, false
+	}
+	if s.expired(entry) {
+		delete(s.entries, nonce)
+		return nonceEntry{}, false
+	}
+	return entry, true
+}
+
+// storeLocked records nonce, evicting if the store is full. s.mu must be held.
+func (s *MemoryNonceStore) storeLocked(nonce [32]byte, settled bool) {
+	if _, exists := s.entries[nonce]; !exists && s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.evictLocked()
+	}
+	s.entries[nonce] = nonceEntry{settled: settled, storedAt: s.now()}
+}
+
+// evictLocked drops expired entries, or the oldest entry if none expired. s.mu must be held.
+func (s *MemoryNonceStore) evictLocked() {
+	var oldestKey [32]byte
+	var oldest time.Time
+	evicted := false
+	for key, entry := range s.entries {
+		if s.expired(entry) {
+			delete(s.entries, key)
+			evicted = true
+			continue
+		}
+		if oldest.IsZero() || entry.storedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.storedAt
+		}
+	}
+	if !evicted {
+		delete(s.entries, oldestKey)
+	}
+}
+
+func (s *MemoryNonceStore) expired(entry nonceEntry) bool {
+	return s.ttl > 0 && !s.now().Before(entry.storedAt.Add(s.ttl))
+}
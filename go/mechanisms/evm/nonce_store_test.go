@@ -0,0 +1,79 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryNonceStore(time.Minute, 0)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	nonce := [32]byte{1}
+	if used, _ := store.IsUsed(ctx, nonce); used {
+		t.Error("expected a fresh nonce to be unused")
+	}
+
+	if err := store.Reserve(ctx, nonce); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if used, _ := store.IsUsed(ctx, nonce); !used {
+		t.Error("expected a reserved nonce to be used")
+	}
+	if err := store.Reserve(ctx, nonce); !errors.Is(err, ErrNonceReserved) {
+		t.Errorf("expected ErrNonceReserved, got %v", err)
+	}
+
+	if err := store.MarkSettled(ctx, nonce); err != nil {
+		t.Fatalf("MarkSettled failed: %v", err)
+	}
+	if err := store.Reserve(ctx, nonce); !errors.Is(err, ErrNonceSettled) {
+		t.Errorf("expected ErrNonceSettled, got %v", err)
+	}
+
+	// Settling a nonce that was never reserved still blocks it
+	other := [32]byte{2}
+	_ = store.MarkSettled(ctx, other)
+	if used, _ := store.IsUsed(ctx, other); !used {
+		t.Error("expected a settled nonce to be used")
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(time.Minute)
+	if used, _ := store.IsUsed(ctx, nonce); used {
+		t.Error("expected entry to expire")
+	}
+	if err := store.Reserve(ctx, nonce); err != nil {
+		t.Errorf("expected an expired nonce to be reservable, got %v", err)
+	}
+}
+
+func TestMemoryNonceStoreEviction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryNonceStore(0, 2)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	for i := byte(1); i <= 3; i++ {
+		if err := store.Reserve(ctx, [32]byte{i}); err != nil {
+			t.Fatalf("Reserve failed: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	if store.Len() != 2 {
+		t.Fatalf("expected size limit of 2, got %d", store.Len())
+	}
+	if used, _ := store.IsUsed(ctx, [32]byte{1}); used {
+		t.Error("expected oldest entry to be evicted")
+	}
+	for _, i := range []byte{2, 3} {
+		if used, _ := store.IsUsed(ctx, [32]byte{i}); !used {
+			t.Errorf("expected nonce %d to be kept", i)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+// ErrMalformedPayload is wrapped by ParseExactPayload errors, which name the offending field
+var ErrMalformedPayload = errors.New("malformed exact EVM payload")
+
+// ParseExactPayload extracts the EIP-3009 payload of an exact payment, the inverse of
+// ExactEIP3009Payload.ToMap
+//
+// Unlike PayloadFromMap, which skips missing fields and values of the wrong type, every field
+// is required and checked: the signature must be non-empty hex, from and to addresses, value,
+// validAfter and validBefore unsigned decimal strings and the nonce 32 bytes of hex. The
+// optional signatureType must be SignatureTypeEOA or SignatureTypeBytes.
+//
+// Returns an error wrapping ErrMalformedPayload that names the first invalid field.
+func ParseExactPayload(payload types.PaymentPayload) (*ExactEIP3009Payload, error) {
+	if payload.Payload == nil {
+		return nil, fmt.Errorf("%w: payload is missing", ErrMalformedPayload)
+	}
+	parsed := &ExactEIP3009Payload{}
+
+	signature, err := payloadString(payload.Payload, "signature", "")
+	if err != nil {
+		return nil, err
+	}
+	if decoded, err := HexToBytes(signature); err != nil || len(decoded) == 0 {
+		return nil, fmt.Errorf("%w: signature must be non-empty hex", ErrMalformedPayload)
+	}
+	parsed.Signature = signature
+
+	if raw, ok := payload.Payload["signatureType"]; ok {
+		signatureType, isString := raw.(string)
+		if !isString || (signatureType != SignatureTypeEOA && signatureType != SignatureTypeBytes) {
+			return nil, fmt.Errorf("%w: signatureType must be %q or %q", ErrMalformedPayload, SignatureTypeEOA, SignatureTypeBytes)
+		}
+		parsed.SignatureType = signatureType
+	}
+
+	auth, ok := payload.Payload["authorization"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: authorization must be an object", ErrMalformedPayload)
+	}
+	authorization := &parsed.Authorization
+
+	for _, field := range []struct {
+		name string
+		dest *string
+	}{
+		{"from", &authorization.From},
+		{"to", &authorization.To},
+	} {
+		if *field.dest, err = payloadString(auth, field.name, "authorization."); err != nil {
+			return nil, err
+		}
+		if !IsValidAddress(*field.dest) {
+			return nil, fmt.Errorf("%w: authorization.%s must be an address", ErrMalformedPayload, field.name)
+		}
+	}
+
+	for _, field := range []struct {
+		name string
+		dest *string
+	}{
+		{"value", &authorization.Value},
+		{"validAfter", &authorization.ValidAfter},
+		{"validBefore", &authorization.ValidBefore},
+	} {
+		if *field.dest, err = payloadString(auth, field.name, "authorization."); err != nil {
+			return nil, err
+		}
+		if !isUnsignedDecimal(*field.dest) {
+			return nil, fmt.Errorf("%w: authorization.%s must be an unsigned decimal string", ErrMalformedPayload, field.name)
+		}
+	}
+
+	if authorization.Nonce, err = payloadString(auth, "nonce", "authorization."); err != nil {
+		return nil, err
+	}
+	if nonce, err := HexToBytes(authorization.Nonce); err != nil || len(nonce) != 32 {
+		return nil, fmt.Errorf("%w: authorization.nonce must be 32 bytes of hex", ErrMalformedPayload)
+	}
+
+	return parsed, nil
+}
+
+// payloadString returns the string field name of data; prefix qualifies the name in errors
+func payloadString(data map[string]interface{}, name, prefix string) (string, error) {
+	raw, ok := data[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s%s is missing", ErrMalformedPayload, prefix, name)
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s%s must be a string, got %T", ErrMalformedPayload, prefix, name, raw)
+	}
+	return value, nil
+}
+
+// isUnsignedDecimal reports whether s is a base-10 integer without sign
+func isUnsignedDecimal(s string) bool {
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return false
+	}
+	_, ok := new(big.Int).SetString(s, 10)
+	return ok
+}
@@ -0,0 +1,91 @@
+package evm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+func testExactPayload() *ExactEIP3009Payload {
+	return &ExactEIP3009Payload{
+		Signature: "0x" + strings.Repeat("ab", 65),
+		Authorization: ExactEIP3009Authorization{
+			From:        "0x857b06519E91e3A54538791bDbb0E22373e36b66",
+			To:          testMerchant,
+			Value:       "1000000",
+			ValidAfter:  "0",
+			ValidBefore: "1900000000",
+			Nonce:       "0x" + strings.Repeat("01", 32),
+		},
+		SignatureType: SignatureTypeEOA,
+	}
+}
+
+func TestParseExactPayload(t *testing.T) {
+	want := testExactPayload()
+	got, err := ParseExactPayload(types.PaymentPayload{X402Version: 2, Payload: want.ToMap()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// signatureType is optional
+	want.SignatureType = ""
+	got, err = ParseExactPayload(types.PaymentPayload{X402Version: 2, Payload: want.ToMap()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestParseExactPayloadMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(data map[string]interface{})
+		wantErr string
+	}{
+		{name: "missing payload", mutate: nil, wantErr: "payload is missing"},
+		{name: "missing signature", mutate: func(d map[string]interface{}) { delete(d, "signature") }, wantErr: "signature is missing"},
+		{name: "signature not a string", mutate: func(d map[string]interface{}) { d["signature"] = 42 }, wantErr: "signature must be a string"},
+		{name: "signature not hex", mutate: func(d map[string]interface{}) { d["signature"] = "0xzz" }, wantErr: "signature must be non-empty hex"},
+		{name: "empty signature", mutate: func(d map[string]interface{}) { d["signature"] = "0x" }, wantErr: "signature must be non-empty hex"},
+		{name: "unknown signature type", mutate: func(d map[string]interface{}) { d["signatureType"] = "eip1271" }, wantErr: "signatureType must be"},
+		{name: "authorization not an object", mutate: func(d map[string]interface{}) { d["authorization"] = "{}" }, wantErr: "authorization must be an object"},
+		{name: "missing from", mutate: func(d map[string]interface{}) { delete(authorizationOf(d), "from") }, wantErr: "authorization.from is missing"},
+		{name: "invalid to", mutate: func(d map[string]interface{}) { authorizationOf(d)["to"] = "0x1234" }, wantErr: "authorization.to must be an address"},
+		{name: "numeric value", mutate: func(d map[string]interface{}) { authorizationOf(d)["value"] = float64(1000000) }, wantErr: "authorization.value must be a string, got float64"},
+		{name: "negative value", mutate: func(d map[string]interface{}) { authorizationOf(d)["value"] = "-1" }, wantErr: "authorization.value must be an unsigned decimal string"},
+		{name: "hex validBefore", mutate: func(d map[string]interface{}) { authorizationOf(d)["validBefore"] = "0x10" }, wantErr: "authorization.validBefore must be an unsigned decimal string"},
+		{name: "short nonce", mutate: func(d map[string]interface{}) { authorizationOf(d)["nonce"] = "0x01" }, wantErr: "authorization.nonce must be 32 bytes of hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]interface{}
+			if tt.mutate != nil {
+				data = testExactPayload().ToMap()
+				tt.mutate(data)
+			}
+
+			_, err := ParseExactPayload(types.PaymentPayload{X402Version: 2, Payload: data})
+			if !errors.Is(err, ErrMalformedPayload) {
+				t.Fatalf("Expected ErrMalformedPayload, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// authorizationOf returns the authorization map of a payload map built by ToMap
+func authorizationOf(data map[string]interface{}) map[string]interface{} {
+	return data["authorization"].(map[string]interface{})
+}
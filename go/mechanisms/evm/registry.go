@@ -0,0 +1,183 @@
+package evm
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Runtime registry for networks and assets that are not built into NetworkConfigs.
+// Registered entries take precedence over built-ins, so a built-in can be replaced
+// with WithOverride.
+var (
+	registryMu         sync.RWMutex
+	registeredNetworks = map[string]NetworkConfig{}
+	registeredAssets   = map[string]map[string]AssetInfo{} // network -> normalized address -> asset
+)
+
+// RegisterOption configures RegisterNetwork and RegisterAsset
+type RegisterOption func(*registerOptions)
+
+type registerOptions struct {
+	override bool
+}
+
+// WithOverride allows a registration to replace a built-in or previously registered entry
+func WithOverride() RegisterOption {
+	return func(o *registerOptions) {
+		o.override = true
+	}
+}
+
+// RegisterNetwork adds a network configuration used by GetEvmChainId, GetNetworkConfig and GetAssetInfo
+//
+// Returns an error if key is already built in or registered, unless WithOverride is given.
+//
+// Example:
+//
+//	err := evm.RegisterNetwork("eip155:8453", evm.NetworkConfig{
+//	    ChainID: big.NewInt(8453),
+//	    DefaultAsset: evm.AssetInfo{
+//	        Address:  "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+//	        Name:     "USD Coin",
+//	        Version:  "2",
+//	        Decimals: 6,
+//	    },
+//	})
+func RegisterNetwork(key string, cfg NetworkConfig, opts ...RegisterOption) error {
+	if key == "" {
+		return fmt.Errorf("network key is required")
+	}
+	if cfg.ChainID == nil || cfg.ChainID.Sign() <= 0 {
+		return fmt.Errorf("network %s: chain ID must be positive", key)
+	}
+	if cfg.DefaultAsset.Address != "" && !IsValidAddress(cfg.DefaultAsset.Address) {
+		return fmt.Errorf("network %s: invalid default asset address %s", key, cfg.DefaultAsset.Address)
+	}
+	for address := range cfg.Assets {
+		if !IsValidAddress(address) {
+			return fmt.Errorf("network %s: invalid asset address %s", key, address)
+		}
+	}
+
+	options := applyRegisterOptions(opts)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if !options.override {
+		if _, ok := NetworkConfigs[key]; ok {
+			return fmt.Errorf("network %s is built in; use WithOverride to replace it", key)
+		}
+		if _, ok := registeredNetworks[key]; ok {
+			return fmt.Errorf("network %s is already registered; use WithOverride to replace it", key)
+		}
+	}
+
+	registeredNetworks[key] = cfg
+	return nil
+}
+
+// RegisterAsset adds token metadata used by GetAssetInfo when the asset is given by address
+//
+// Returns an error if the address is already one of the network's configured assets or a
+// registered asset, unless WithOverride is given.
+func RegisterAsset(network, address string, info AssetInfo, opts ...RegisterOption) error {
+	if network == "" {
+		return fmt.Errorf("network is required")
+	}
+	if !IsValidAddress(address) {
+		return fmt.Errorf("invalid asset address: %s", address)
+	}
+
+	normalized := NormalizeAddress(address)
+	if info.Address == "" {
+		info.Address = normalized
+	}
+
+	options := applyRegisterOptions(opts)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	network = normalizeNetworkLocked(network)
+
+	if !options.override {
+		if config, ok := lookupNetworkLocked(network); ok {
+			if _, ok := config.asset(normalized); ok {
+				return fmt.Errorf("asset %s is a configured asset of %s; use WithOverride to replace it", address, network)
+			}
+		}
+		if _, ok := registeredAssets[network][normalized]; ok {
+			return fmt.Errorf("asset %s is already registered on %s; use WithOverride to replace it", address, network)
+		}
+	}
+
+	if registeredAssets[network] == nil {
+		registeredAssets[network] = map[string]AssetInfo{}
+	}
+	registeredAssets[network][normalized] = info
+	return nil
+}
+
+// RegisteredNetworks returns the keys of all built-in and registered networks, sorted
+func RegisteredNetworks() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	keys := make([]string, 0, len(NetworkConfigs)+len(registeredNetworks))
+	for key := range NetworkConfigs {
+		keys = append(keys, key)
+	}
+	for key := range registeredNetworks {
+		if _, ok := NetworkConfigs[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupNetwork returns the registered or built-in configuration for a normalized network key
+func lookupNetwork(network string) (NetworkConfig, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return lookupNetworkLocked(network)
+}
+
+// lookupNetworkLocked is lookupNetwork for callers holding registryMu
+func lookupNetworkLocked(network string) (NetworkConfig, bool) {
+	if config, ok := registeredNetworks[network]; ok {
+		return config, true
+	}
+	config, ok := NetworkConfigs[network]
+	return config, ok
+}
+
+// lookupAsset returns registered token metadata for a network and normalized address
+func lookupAsset(network, normalizedAddress string) (AssetInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info, ok := registeredAssets[normalizeNetworkLocked(network)][normalizedAddress]
+	return info, ok
+}
+
+// lookupAssets returns all registered token metadata for a network
+func lookupAssets(network string) []AssetInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	network = normalizeNetworkLocked(network)
+	assets := make([]AssetInfo, 0, len(registeredAssets[network]))
+	for _, info := range registeredAssets[network] {
+		assets = append(assets, info)
+	}
+	return assets
+}
+
+func applyRegisterOptions(opts []RegisterOption) registerOptions {
+	var options registerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
@@ -0,0 +1,247 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestRegisterNetwork(t *testing.T) {
+	cfg := NetworkConfig{
+		ChainID: big.NewInt(700001),
+		DefaultAsset: AssetInfo{
+			Address:  "0x1111111111111111111111111111111111111111",
+			Name:     "Test USD",
+			Version:  "1",
+			Decimals: 6,
+		},
+	}
+	if err := RegisterNetwork("testchain", cfg); err != nil {
+		t.Fatalf("RegisterNetwork failed: %v", err)
+	}
+
+	chainID, err := GetEvmChainId("testchain")
+	if err != nil || chainID.Cmp(big.NewInt(700001)) != 0 {
+		t.Errorf("GetEvmChainId(testchain) = %v, %v", chainID, err)
+	}
+
+	asset, err := GetAssetInfo("testchain", "")
+	if err != nil || asset.Name != "Test USD" {
+		t.Errorf("GetAssetInfo(testchain) = %+v, %v", asset, err)
+	}
+
+	if err := RegisterNetwork("testchain", cfg); err == nil {
+		t.Error("expected error for duplicate registration")
+	}
+
+	cfg.DefaultAsset.Decimals = 18
+	if err := RegisterNetwork("testchain", cfg, WithOverride()); err != nil {
+		t.Fatalf("RegisterNetwork with override failed: %v", err)
+	}
+	if asset, _ := GetAssetInfo("testchain", ""); asset.Decimals != 18 {
+		t.Errorf("expected overridden decimals 18, got %d", asset.Decimals)
+	}
+}
+
+func TestRegisterNetworkBuiltIn(t *testing.T) {
+	cfg := NetworkConfig{ChainID: big.NewInt(10087)}
+	if err := RegisterNetwork("gatelayer_testnet", cfg); err == nil {
+		t.Error("expected error when replacing a built-in network without override")
+	}
+
+	if _, err := GetAssetInfo("gatelayer_testnet", ""); err != nil {
+		t.Errorf("expected built-in default asset to be untouched, got %v", err)
+	}
+}
+
+func TestRegisterNetworkValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		cfg  NetworkConfig
+	}{
+		{"empty key", "", NetworkConfig{ChainID: big.NewInt(1)}},
+		{"missing chain ID", "nochain", NetworkConfig{}},
+		{"invalid default asset", "badasset", NetworkConfig{ChainID: big.NewInt(1), DefaultAsset: AssetInfo{Address: "0x123"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterNetwork(tt.key, tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestRegisterAsset(t *testing.T) {
+	const usdc = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	info := AssetInfo{Name: "USD Coin", Version: "2", Decimals: 6}
+
+	if err := RegisterAsset("eip155:8453", usdc, info); err != nil {
+		t.Fatalf("RegisterAsset failed: %v", err)
+	}
+
+	asset, err := GetAssetInfo("eip155:8453", usdc)
+	if err != nil {
+		t.Fatalf("GetAssetInfo failed: %v", err)
+	}
+	if asset.Name != "USD Coin" || asset.Decimals != 6 || asset.Address != NormalizeAddress(usdc) {
+		t.Errorf("unexpected asset info: %+v", asset)
+	}
+
+	// Other networks are unaffected
+	if other, _ := GetAssetInfo("eip155:1", usdc); other.Name != "Unknown Token" {
+		t.Errorf("expected unknown token on another network, got %+v", other)
+	}
+
+	if err := RegisterAsset("eip155:8453", usdc, info); err == nil {
+		t.Error("expected error for duplicate asset registration")
+	}
+	if err := RegisterAsset("eip155:8453", usdc, info, WithOverride()); err != nil {
+		t.Errorf("RegisterAsset with override failed: %v", err)
+	}
+
+	// The built-in default asset can only be replaced with override
+	if err := RegisterAsset("gatelayer_testnet", "0x9be8Df37C788B244cFc28E46654aD5Ec28a880AF", info); err == nil {
+		t.Error("expected error when replacing a built-in default asset")
+	}
+
+	if err := RegisterAsset("eip155:8453", "not-an-address", info); err == nil {
+		t.Error("expected error for invalid address")
+	}
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	const workers = 32
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("eip155:%d", 800000+i)
+			if err := RegisterNetwork(key, NetworkConfig{ChainID: big.NewInt(int64(800000 + i))}); err != nil {
+				errs <- err
+				return
+			}
+			address := fmt.Sprintf("0x%040x", i+1)
+			if err := RegisterAsset(key, address, AssetInfo{Name: "T", Decimals: 6}); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := GetAssetInfo(key, address); err != nil {
+				errs <- err
+			}
+			_ = RegisteredNetworks()
+		}(i)
+	}
+
+	// Everyone racing on the same key: exactly one wins
+	var winners int32
+	var mu sync.Mutex
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := RegisterNetwork("contended", NetworkConfig{ChainID: big.NewInt(900000)}); err == nil {
+				mu.Lock()
+				winners++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if winners != 1 {
+		t.Errorf("expected exactly one registration of a contended key, got %d", winners)
+	}
+
+	for i := 0; i < workers; i++ {
+		chainID, err := GetEvmChainId(fmt.Sprintf("eip155:%d", 800000+i))
+		if err != nil || chainID.Int64() != int64(800000+i) {
+			t.Errorf("network %d: got %v, %v", i, chainID, err)
+		}
+	}
+}
+
+func TestNetworkConfigAssets(t *testing.T) {
+	const (
+		usdc = "0x1111111111111111111111111111111111111112"
+		usdt = "0x2222222222222222222222222222222222222223"
+		dai  = "0x3333333333333333333333333333333333333334"
+	)
+	cfg := NetworkConfig{
+		ChainID:      big.NewInt(700002),
+		DefaultAsset: AssetInfo{Address: usdc, Name: "USD Coin", Version: "2", Decimals: 6},
+		Assets: map[string]AssetInfo{
+			usdt: {Name: "Tether USD", Version: "1", Decimals: 6},
+		},
+	}
+	if err := RegisterNetwork("multiasset", cfg); err != nil {
+		t.Fatalf("RegisterNetwork failed: %v", err)
+	}
+
+	t.Run("address-specific lookup", func(t *testing.T) {
+		asset, err := GetAssetInfo("multiasset", "0x2222222222222222222222222222222222222223")
+		if err != nil {
+			t.Fatalf("GetAssetInfo failed: %v", err)
+		}
+		if asset.Name != "Tether USD" || asset.Address != NormalizeAddress(usdt) {
+			t.Errorf("unexpected asset info: %+v", asset)
+		}
+	})
+
+	t.Run("default fallback", func(t *testing.T) {
+		asset, err := GetAssetInfo("multiasset", "")
+		if err != nil || asset.Name != "USD Coin" {
+			t.Errorf("GetAssetInfo(multiasset, \"\") = %+v, %v", asset, err)
+		}
+
+		// An unknown address does not fall back to the default asset
+		if unknown, _ := GetAssetInfo("multiasset", dai); unknown.Name != "Unknown Token" {
+			t.Errorf("expected unknown token, got %+v", unknown)
+		}
+	})
+
+	t.Run("configured assets need override", func(t *testing.T) {
+		if err := RegisterAsset("multiasset", usdt, AssetInfo{Name: "T", Decimals: 6}); err == nil {
+			t.Error("expected error when replacing a configured asset")
+		}
+	})
+
+	t.Run("list assets", func(t *testing.T) {
+		if err := RegisterAsset("multiasset", dai, AssetInfo{Name: "Dai", Version: "1", Decimals: 18}); err != nil {
+			t.Fatalf("RegisterAsset failed: %v", err)
+		}
+
+		assets, err := ListAssets("multiasset")
+		if err != nil {
+			t.Fatalf("ListAssets failed: %v", err)
+		}
+		var names []string
+		for _, asset := range assets {
+			names = append(names, asset.Name)
+		}
+		if fmt.Sprint(names) != "[USD Coin Tether USD Dai]" {
+			t.Errorf("ListAssets = %v, want [USD Coin Tether USD Dai]", names)
+		}
+
+		if _, err := ListAssets("not-a-network"); err == nil {
+			t.Error("expected error for invalid network")
+		}
+	})
+
+	t.Run("invalid asset address", func(t *testing.T) {
+		bad := NetworkConfig{ChainID: big.NewInt(1), Assets: map[string]AssetInfo{"0x123": {}}}
+		if err := RegisterNetwork("badassets", bad); err == nil {
+			t.Error("expected error for invalid asset address")
+		}
+	})
+}
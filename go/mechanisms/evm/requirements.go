@@ -0,0 +1,168 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+// DefaultRequirementsMaxTimeoutSeconds is the MaxTimeoutSeconds RequirementsBuilder uses
+// unless WithMaxTimeoutSeconds is given, matching the resource server's default
+const DefaultRequirementsMaxTimeoutSeconds = 60
+
+// RequirementsBuilder builds exact-scheme payment requirements from merchant inputs: the
+// amount in whole tokens, the network by any registered name and the asset by address (or
+// the network's default asset). It is the merchant-side counterpart of the client schemes.
+//
+// Example:
+//
+//	requirements, err := evm.NewRequirements("gatelayer_testnet", payTo).
+//	    WithHumanAmount("5.00").
+//	    Build()
+type RequirementsBuilder struct {
+	network           string
+	payTo             string
+	asset             string
+	humanAmount       string
+	amount            string
+	maxTimeoutSeconds int
+	extra             map[string]interface{}
+}
+
+// NewRequirements starts requirements paying payTo on network
+func NewRequirements(network, payTo string) *RequirementsBuilder {
+	return &RequirementsBuilder{
+		network:           network,
+		payTo:             payTo,
+		maxTimeoutSeconds: DefaultRequirementsMaxTimeoutSeconds,
+	}
+}
+
+// WithHumanAmount sets the price in whole tokens (e.g. "5.00"), converted with the asset's decimals
+func (b *RequirementsBuilder) WithHumanAmount(amount string) *RequirementsBuilder {
+	b.humanAmount, b.amount = amount, ""
+	return b
+}
+
+// WithAmount sets the price in the asset's smallest unit
+func (b *RequirementsBuilder) WithAmount(amount string) *RequirementsBuilder {
+	b.amount, b.humanAmount = amount, ""
+	return b
+}
+
+// WithAsset sets the token address (defaults to the network's default asset)
+func (b *RequirementsBuilder) WithAsset(address string) *RequirementsBuilder {
+	b.asset = address
+	return b
+}
+
+// WithMaxTimeoutSeconds sets how long a signed payment stays valid
+func (b *RequirementsBuilder) WithMaxTimeoutSeconds(seconds int) *RequirementsBuilder {
+	b.maxTimeoutSeconds = seconds
+	return b
+}
+
+// WithExtra sets an Extra field. Values given here take precedence over the asset's
+// EIP-712 name and version.
+func (b *RequirementsBuilder) WithExtra(key string, value interface{}) *RequirementsBuilder {
+	if b.extra == nil {
+		b.extra = make(map[string]interface{})
+	}
+	b.extra[key] = value
+	return b
+}
+
+// Build resolves the asset and returns validated requirements with the network in CAIP-2
+// form, the amount in the smallest unit and the asset's EIP-712 name and version in Extra.
+//
+// The asset must be known (configured, registered with RegisterAsset, or resolved by a
+// RegisterAssetResolver resolver): guessing its decimals or EIP-712 domain would produce a
+// wrong price or unverifiable signatures. An unknown asset is only accepted with WithAmount
+// and both "name" and "version" given through WithExtra.
+func (b *RequirementsBuilder) Build() (types.PaymentRequirements, error) {
+	return b.BuildContext(context.Background())
+}
+
+// BuildContext is Build with a context for the AssetResolver lookup
+func (b *RequirementsBuilder) BuildContext(ctx context.Context) (types.PaymentRequirements, error) {
+	chainID, err := GetEvmChainId(b.network)
+	if err != nil {
+		return types.PaymentRequirements{}, err
+	}
+	if !IsValidAddress(b.payTo) {
+		return types.PaymentRequirements{}, fmt.Errorf("invalid payTo address: %q", b.payTo)
+	}
+	if b.asset != "" && !IsValidAddress(b.asset) {
+		return types.PaymentRequirements{}, fmt.Errorf("invalid asset address: %q", b.asset)
+	}
+	if b.maxTimeoutSeconds <= 0 {
+		return types.PaymentRequirements{}, fmt.Errorf("maxTimeoutSeconds must be positive, got %d", b.maxTimeoutSeconds)
+	}
+
+	info, known, err := lookupAssetInfo(ctx, b.network, b.asset)
+	if err != nil {
+		return types.PaymentRequirements{}, err
+	}
+	extra := make(map[string]interface{}, len(b.extra)+2)
+	if known {
+		extra["name"] = info.Name
+		extra["version"] = info.Version
+	}
+	for key, value := range b.extra {
+		extra[key] = value
+	}
+	if !known {
+		_, hasName := extra["name"]
+		_, hasVersion := extra["version"]
+		if b.humanAmount != "" || !hasName || !hasVersion {
+			return types.PaymentRequirements{}, fmt.Errorf("unknown asset %s on %s: register it, or use WithAmount with the EIP-712 name and version in WithExtra", b.asset, b.network)
+		}
+	}
+
+	amount, err := b.atomicAmount(info)
+	if err != nil {
+		return types.PaymentRequirements{}, err
+	}
+
+	asset := b.asset
+	if known {
+		asset = info.Address
+	}
+	return types.PaymentRequirements{
+		Scheme:            SchemeExact,
+		Network:           "eip155:" + chainID.String(),
+		Asset:             asset,
+		Amount:            amount.String(),
+		PayTo:             b.payTo,
+		MaxTimeoutSeconds: b.maxTimeoutSeconds,
+		Extra:             extra,
+	}, nil
+}
+
+// atomicAmount returns the positive amount in the smallest unit; info is nil for unknown assets
+func (b *RequirementsBuilder) atomicAmount(info *AssetInfo) (*big.Int, error) {
+	var amount *big.Int
+	switch {
+	case b.humanAmount != "":
+		parsed, err := parseExactAmount(b.humanAmount, info.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		amount = parsed
+	case b.amount != "":
+		parsed, ok := new(big.Int).SetString(b.amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount: %q", b.amount)
+		}
+		amount = parsed
+	default:
+		return nil, fmt.Errorf("amount is required: use WithHumanAmount or WithAmount")
+	}
+
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive, got %s", amount)
+	}
+	return amount, nil
+}
@@ -0,0 +1,99 @@
+package evm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+const testMerchant = "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"
+
+func TestRequirementsBuilder(t *testing.T) {
+	usdc := NetworkConfigs["gatelayer_testnet"].DefaultAsset
+
+	got, err := NewRequirements("gatelayer_testnet", testMerchant).WithHumanAmount("5.00").Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := types.PaymentRequirements{
+		Scheme:            SchemeExact,
+		Network:           "eip155:10087",
+		Asset:             usdc.Address,
+		Amount:            "5000000",
+		PayTo:             testMerchant,
+		MaxTimeoutSeconds: DefaultRequirementsMaxTimeoutSeconds,
+		Extra:             map[string]interface{}{"name": usdc.Name, "version": usdc.Version},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// Explicit asset, atomic amount, timeout and extra overrides
+	got, err = NewRequirements("eip155:10087", testMerchant).
+		WithAsset(usdc.Address).
+		WithAmount("1500").
+		WithMaxTimeoutSeconds(300).
+		WithExtra("version", "3").
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Amount != "1500" || got.MaxTimeoutSeconds != 300 || got.Extra["version"] != "3" || got.Extra["name"] != usdc.Name {
+		t.Errorf("Unexpected requirements: %+v", got)
+	}
+}
+
+func TestRequirementsBuilderUnknownAsset(t *testing.T) {
+	const token = "0x2222222222222222222222222222222222222222"
+
+	if _, err := NewRequirements("gatelayer_testnet", testMerchant).WithAsset(token).WithHumanAmount("1").Build(); err == nil {
+		t.Error("Expected error for a human amount of an unknown asset")
+	}
+	if _, err := NewRequirements("gatelayer_testnet", testMerchant).WithAsset(token).WithAmount("1").Build(); err == nil {
+		t.Error("Expected error for an unknown asset without EIP-712 name and version")
+	}
+
+	got, err := NewRequirements("gatelayer_testnet", testMerchant).
+		WithAsset(token).
+		WithAmount("1").
+		WithExtra("name", "Token").
+		WithExtra("version", "1").
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Asset != token || got.Extra["name"] != "Token" {
+		t.Errorf("Unexpected requirements: %+v", got)
+	}
+}
+
+func TestRequirementsBuilderInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *RequirementsBuilder
+	}{
+		{name: "invalid amount", builder: NewRequirements("gatelayer_testnet", testMerchant).WithHumanAmount("5.0O")},
+		{name: "too many decimals", builder: NewRequirements("gatelayer_testnet", testMerchant).WithHumanAmount("0.0000001")},
+		{name: "negative amount", builder: NewRequirements("gatelayer_testnet", testMerchant).WithAmount("-1")},
+		{name: "zero amount", builder: NewRequirements("gatelayer_testnet", testMerchant).WithHumanAmount("0")},
+		{name: "missing amount", builder: NewRequirements("gatelayer_testnet", testMerchant)},
+		{name: "invalid payTo", builder: NewRequirements("gatelayer_testnet", "merchant").WithHumanAmount("1")},
+		{name: "invalid asset", builder: NewRequirements("gatelayer_testnet", testMerchant).WithAsset("USDC").WithHumanAmount("1")},
+		{name: "invalid timeout", builder: NewRequirements("gatelayer_testnet", testMerchant).WithHumanAmount("1").WithMaxTimeoutSeconds(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.builder.Build(); err == nil {
+				t.Errorf("Expected error, got %+v", got)
+			}
+		})
+	}
+
+	_, err := NewRequirements("gatelayer-testnt", testMerchant).WithHumanAmount("1").Build()
+	if !errors.Is(err, ErrUnsupportedNetwork) {
+		t.Errorf("Expected ErrUnsupportedNetwork, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gatechain/x402/go/types"
+)
+
+// TransactionSubmitter is the subset of *ethclient.Client SettleOnChain needs
+type TransactionSubmitter interface {
+	GasEstimator
+	FeeSuggester
+	ChainID(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error
+}
+
+// SettleOnChain submits the transferWithAuthorization call for an EIP-3009 payload itself,
+// paying the gas from submitterKey, instead of handing the payload to a facilitator
+//
+// The authorization must pay requirements.PayTo exactly requirements.Amount, and ethClient
+// must be connected to the chain of requirements.Network. The gas limit is estimated from
+// the submitter, so an authorization the token would reject (expired, already used, bad
+// signature) fails here without spending gas. The transaction is an EIP-1559 one, priced by
+// the GasOracle registered for the network (NodeGasOracle by default). It is not waited for; use
+// WatchSettlement or WaitForTransactionReceipt with the returned hash.
+//
+// Returns:
+//
+//	txHash: Hash of the submitted transaction (0x-prefixed)
+//	error if the payload does not match the requirements, the call would revert or submission fails
+func SettleOnChain(
+	ctx context.Context,
+	ethClient TransactionSubmitter,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+	submitterKey *ecdsa.PrivateKey,
+) (string, error) {
+	if submitterKey == nil {
+		return "", fmt.Errorf("submitter key is required")
+	}
+
+	evmPayload, err := PayloadFromMap(payload.Payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid exact EVM payload: %w", err)
+	}
+	authorization := evmPayload.Authorization
+	if !strings.EqualFold(authorization.To, requirements.PayTo) {
+		return "", fmt.Errorf("authorization pays %s, requirements pay %s", authorization.To, requirements.PayTo)
+	}
+	value, ok := new(big.Int).SetString(authorization.Value, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid authorization value: %s", authorization.Value)
+	}
+	required, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid required amount: %s", requirements.Amount)
+	}
+	if err := CheckAuthorizationValue(value, required, 0); err != nil {
+		return "", err
+	}
+
+	wantChainID, err := GetEvmChainId(string(requirements.Network))
+	if err != nil {
+		return "", err
+	}
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if chainID.Cmp(wantChainID) != 0 {
+		return "", fmt.Errorf("RPC is on chain %s, requirements are for chain %s", chainID, wantChainID)
+	}
+
+	assetInfo, err := GetAssetInfo(string(requirements.Network), requirements.Asset)
+	if err != nil {
+		return "", err
+	}
+	calldata, err := TransferWithAuthorizationCalldata(evmPayload)
+	if err != nil {
+		return "", err
+	}
+
+	submitter := crypto.PubkeyToAddress(submitterKey.PublicKey)
+	token := common.HexToAddress(assetInfo.Address)
+	gas, err := ethClient.EstimateGas(ctx, ethereum.CallMsg{From: submitter, To: &token, Data: calldata})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	fees, err := gasFees(ctx, ethClient, string(requirements.Network))
+	if err != nil {
+		return "", fmt.Errorf("failed to get gas fees: %w", err)
+	}
+	nonce, err := ethClient.PendingNonceAt(ctx, submitter)
+	if err != nil {
+		return "", fmt.Errorf("failed to get submitter nonce: %w", err)
+	}
+
+	tx := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: fees.MaxPriorityFeePerGas,
+		GasFeeCap: fees.MaxFeePerGas,
+		Gas:       gas,
+		To:        &token,
+		Data:      calldata,
+	})
+	signed, err := ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(chainID), submitterKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := ethClient.SendTransaction(ctx, signed); err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return signed.Hash().Hex(), nil
+}
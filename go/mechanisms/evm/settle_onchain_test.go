@@ -0,0 +1,167 @@
+package evm_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	"github.com/gatechain/x402/go/mechanisms/evm/evmtest"
+	"github.com/gatechain/x402/go/mechanisms/evm/exact/client"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+// settleOnChainFixture is a funded payer's payment of 1000 to payTo on a simulated chain,
+// and a relayer with ether for gas
+type settleOnChainFixture struct {
+	token        *evmtest.Token
+	payload      types.PaymentPayload
+	requirements types.PaymentRequirements
+	relayerKey   *ecdsa.PrivateKey
+	relayer      common.Address
+	payTo        common.Address
+}
+
+func newSettleOnChainFixture(t *testing.T) *settleOnChainFixture {
+	t.Helper()
+	payerKey, _ := crypto.GenerateKey()
+	relayerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+	relayer := crypto.PubkeyToAddress(relayerKey.PublicKey)
+	payTo := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	token, err := evmtest.NewToken(&evmtest.TokenConfig{
+		Balances:      map[common.Address]*big.Int{payer: big.NewInt(1_000_000)},
+		EtherBalances: map[common.Address]*big.Int{relayer: big.NewInt(1e18)},
+	})
+	if err != nil {
+		t.Fatalf("NewToken failed: %v", err)
+	}
+	t.Cleanup(func() { token.Close() })
+
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(common.Bytes2Hex(crypto.FromECDSA(payerKey)))
+	if err != nil {
+		t.Fatalf("NewClientSignerFromPrivateKey failed: %v", err)
+	}
+	requirements := token.Requirements(payTo.Hex(), "1000")
+	payload, err := client.NewExactEvmScheme(signer).CreatePaymentPayload(context.Background(), requirements)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	return &settleOnChainFixture{
+		token:        token,
+		payload:      payload,
+		requirements: requirements,
+		relayerKey:   relayerKey,
+		relayer:      relayer,
+		payTo:        payTo,
+	}
+}
+
+func TestSettleOnChain(t *testing.T) {
+	ctx := context.Background()
+	fixture := newSettleOnChainFixture(t)
+	token, payload, requirements := fixture.token, fixture.payload, fixture.requirements
+	relayerKey, relayer, payTo := fixture.relayerKey, fixture.relayer, fixture.payTo
+
+	t.Run("rejects mismatched requirements", func(t *testing.T) {
+		otherPayTo := requirements
+		otherPayTo.PayTo = "0x3333333333333333333333333333333333333333"
+		higherAmount := requirements
+		higherAmount.Amount = "2000"
+		otherChain := requirements
+		otherChain.Network = "eip155:1"
+
+		for name, mismatched := range map[string]types.PaymentRequirements{"payTo": otherPayTo, "amount": higherAmount, "network": otherChain} {
+			if _, err := evm.SettleOnChain(ctx, token.Client, payload, mismatched, relayerKey); err == nil {
+				t.Errorf("Expected an error for a different %s", name)
+			}
+		}
+		if _, err := evm.SettleOnChain(ctx, token.Client, payload, requirements, nil); err == nil {
+			t.Error("Expected an error without a submitter key")
+		}
+	})
+
+	txHash, err := evm.SettleOnChain(ctx, token.Client, payload, requirements, relayerKey)
+	if err != nil {
+		t.Fatalf("SettleOnChain failed: %v", err)
+	}
+	token.Backend.Commit()
+
+	receipt, err := token.Client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		t.Fatalf("TransactionReceipt failed: %v", err)
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		t.Errorf("Expected a successful transaction, got status %d", receipt.Status)
+	}
+	if balance, _ := token.BalanceOf(ctx, payTo); balance.Int64() != 1000 {
+		t.Errorf("Expected payTo balance 1000, got %v", balance)
+	}
+	if balance, _ := token.Client.BalanceAt(ctx, relayer, nil); balance.Cmp(big.NewInt(1e18)) >= 0 {
+		t.Errorf("Expected the relayer to pay for gas, balance is %v", balance)
+	}
+
+	// A used authorization fails gas estimation instead of being submitted
+	_, err = evm.SettleOnChain(ctx, token.Client, payload, requirements, relayerKey)
+	if err == nil || !strings.Contains(err.Error(), "failed to estimate gas") {
+		t.Errorf("Expected a gas estimation error for a used authorization, got %v", err)
+	}
+}
+
+// fixedGasOracle returns the same fees for every network
+type fixedGasOracle struct {
+	fees evm.GasFees
+	err  error
+}
+
+func (o fixedGasOracle) GasFees(ctx context.Context, network string) (evm.GasFees, error) {
+	return o.fees, o.err
+}
+
+func TestSettleOnChainGasOracle(t *testing.T) {
+	ctx := context.Background()
+	fixture := newSettleOnChainFixture(t)
+	network := fixture.requirements.Network
+	t.Cleanup(func() { evm.RegisterGasOracle(string(network), nil) })
+
+	invalid := map[string]fixedGasOracle{
+		"failing":      {err: errors.New("oracle down")},
+		"missing fees": {fees: evm.GasFees{MaxFeePerGas: big.NewInt(1)}},
+		"tip over cap": {fees: evm.GasFees{MaxFeePerGas: big.NewInt(1), MaxPriorityFeePerGas: big.NewInt(2)}},
+	}
+	for name, oracle := range invalid {
+		evm.RegisterGasOracle(string(network), oracle)
+		if _, err := evm.SettleOnChain(ctx, fixture.token.Client, fixture.payload, fixture.requirements, fixture.relayerKey); err == nil {
+			t.Errorf("Expected an error from a %s oracle", name)
+		}
+	}
+
+	fees := evm.GasFees{MaxFeePerGas: big.NewInt(50_000_000_000), MaxPriorityFeePerGas: big.NewInt(3_000_000_000)}
+	evm.RegisterGasOracle(string(network), fixedGasOracle{fees: fees})
+	txHash, err := evm.SettleOnChain(ctx, fixture.token.Client, fixture.payload, fixture.requirements, fixture.relayerKey)
+	if err != nil {
+		t.Fatalf("SettleOnChain failed: %v", err)
+	}
+
+	tx, _, err := fixture.token.Client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		t.Fatalf("TransactionByHash failed: %v", err)
+	}
+	if tx.GasFeeCap().Cmp(fees.MaxFeePerGas) != 0 || tx.GasTipCap().Cmp(fees.MaxPriorityFeePerGas) != 0 {
+		t.Errorf("Expected fees %v/%v, got %v/%v", fees.MaxFeePerGas, fees.MaxPriorityFeePerGas, tx.GasFeeCap(), tx.GasTipCap())
+	}
+
+	fixture.token.Backend.Commit()
+	receipt, err := fixture.token.Client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil || receipt.Status != evm.TxStatusSuccess {
+		t.Errorf("Expected a successful transaction, got %+v (%v)", receipt, err)
+	}
+}
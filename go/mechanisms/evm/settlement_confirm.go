@@ -0,0 +1,112 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// DefaultSettlementMaxWait bounds how long SettleAndConfirm waits for confirmations unless
+// WithMaxWait is given
+const DefaultSettlementMaxWait = 5 * time.Minute
+
+// ErrSettlementNotConfirmed is returned by SettleAndConfirm when the transaction did not
+// reach the requested confirmations before the max wait or the context ended
+var ErrSettlementNotConfirmed = errors.New("settlement not confirmed")
+
+// Settler settles payments; x402.FacilitatorClient and *http.HTTPFacilitatorClient satisfy it
+type Settler interface {
+	Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error)
+}
+
+// SettlementConfirmation is the outcome of SettleAndConfirm
+type SettlementConfirmation struct {
+	Response      *x402.SettleResponse // The facilitator's settle response
+	Status        uint64               // TxStatusSuccess or TxStatusFailed
+	BlockNumber   uint64               // Block including the transaction
+	Confirmations uint64               // Confirmations observed, at least the requested number
+}
+
+// SettlementConfirmer settles through a facilitator and then waits on chain until the
+// settlement transaction is final
+type SettlementConfirmer struct {
+	settler Settler
+	client  ReceiptReader
+	opts    []WatchOption
+}
+
+// NewSettlementConfirmer creates a confirmer that settles with settler and reads receipts
+// through client. opts (e.g. WithPollInterval, or WithMaxWait(0) to wait until ctx is done)
+// apply to every wait.
+func NewSettlementConfirmer(settler Settler, client ReceiptReader, opts ...WatchOption) *SettlementConfirmer {
+	return &SettlementConfirmer{
+		settler: settler,
+		client:  client,
+		opts:    append([]WatchOption{WithMaxWait(DefaultSettlementMaxWait)}, opts...),
+	}
+}
+
+// SettleAndConfirm settles the payment, then waits for its transaction to have confirmations
+// blocks (zero or less uses the network's finality, as WatchSettlement does).
+//
+// A transaction that was mined but reverted is still final: it is returned with Status
+// TxStatusFailed and no error. A failed or unsuccessful Settle is returned as an error
+// without waiting. If the confirmations are not reached within the max wait or before ctx
+// is done, the error wraps ErrSettlementNotConfirmed and the confirmation reports the
+// progress so far (nil while the transaction is still pending).
+func (c *SettlementConfirmer) SettleAndConfirm(ctx context.Context, payloadBytes, requirementsBytes []byte, confirmations int) (*SettlementConfirmation, error) {
+	response, err := c.settler.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil || !response.Success {
+		reason := "no response"
+		if response != nil {
+			reason = response.ErrorReason
+		}
+		return nil, fmt.Errorf("settlement failed: %s", reason)
+	}
+	if response.Transaction == "" {
+		return nil, fmt.Errorf("settlement response has no transaction hash")
+	}
+	return c.Confirm(ctx, response, confirmations)
+}
+
+// Confirm waits for the transaction of an already successful settle response, as SettleAndConfirm does
+func (c *SettlementConfirmer) Confirm(ctx context.Context, response *x402.SettleResponse, confirmations int) (*SettlementConfirmation, error) {
+	opts := c.opts
+	if confirmations > 0 {
+		opts = append(append([]WatchOption(nil), c.opts...), WithConfirmations(uint64(confirmations)))
+	}
+	statuses, err := WatchSettlement(ctx, c.client, response.Transaction, string(response.Network), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var last SettlementStatus
+	for status := range statuses {
+		last = status
+	}
+
+	var confirmation *SettlementConfirmation
+	if last.State != "" && last.State != SettlementPending {
+		confirmation = &SettlementConfirmation{
+			Response:      response,
+			Status:        last.Status,
+			BlockNumber:   last.BlockNumber,
+			Confirmations: last.Confirmations,
+		}
+	}
+	if last.State != SettlementFinalized {
+		err := fmt.Errorf("%w: %s on %s after %d confirmations",
+			ErrSettlementNotConfirmed, response.Transaction, response.Network, last.Confirmations)
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: %w", err, ctx.Err())
+		}
+		return confirmation, err
+	}
+	return confirmation, nil
+}
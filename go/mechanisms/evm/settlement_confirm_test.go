@@ -0,0 +1,118 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	x402 "github.com/gatechain/x402/go"
+)
+
+// fakeSettler returns a fixed settle response
+type fakeSettler struct {
+	response *x402.SettleResponse
+	err      error
+}
+
+func (s fakeSettler) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	return s.response, s.err
+}
+
+func settledAt(tx string) fakeSettler {
+	return fakeSettler{response: &x402.SettleResponse{Success: true, Transaction: tx, Network: "eip155:10087"}}
+}
+
+// mineEvery advances the chain until ctx is done, including the transaction in the first block
+func mineEvery(ctx context.Context, chain *mockChain, interval time.Duration, status uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	first := true
+	for {
+		select {
+		case <-ticker.C:
+			chain.mine(first, status)
+			first = false
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func TestSettleAndConfirm(t *testing.T) {
+	for _, status := range []uint64{TxStatusSuccess, TxStatusFailed} {
+		chain := &mockChain{head: 100}
+		ctx, cancel := context.WithCancel(context.Background())
+		go mineEvery(ctx, chain, 5*time.Millisecond, status)
+
+		confirmer := NewSettlementConfirmer(settledAt(testSettlementTx), chain, WithPollInterval(time.Millisecond))
+		got, err := confirmer.SettleAndConfirm(ctx, nil, nil, 3)
+		cancel()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.Status != status {
+			t.Errorf("Expected status %d, got %d", status, got.Status)
+		}
+		if got.BlockNumber != 101 {
+			t.Errorf("Expected block 101, got %d", got.BlockNumber)
+		}
+		if got.Confirmations < 3 {
+			t.Errorf("Expected at least 3 confirmations, got %d", got.Confirmations)
+		}
+		if got.Response == nil || got.Response.Transaction != testSettlementTx {
+			t.Errorf("Expected the settle response, got %+v", got.Response)
+		}
+	}
+}
+
+func TestSettleAndConfirmMaxWait(t *testing.T) {
+	chain := &mockChain{head: 100}
+	chain.mine(true, TxStatusSuccess)
+
+	confirmer := NewSettlementConfirmer(settledAt(testSettlementTx), chain,
+		WithPollInterval(time.Millisecond), WithMaxWait(20*time.Millisecond))
+	got, err := confirmer.SettleAndConfirm(context.Background(), nil, nil, 5)
+	if !errors.Is(err, ErrSettlementNotConfirmed) {
+		t.Fatalf("Expected ErrSettlementNotConfirmed, got %v", err)
+	}
+	if got == nil || got.Confirmations != 1 || got.BlockNumber != 101 {
+		t.Errorf("Expected progress with 1 confirmation, got %+v", got)
+	}
+}
+
+func TestSettleAndConfirmCancel(t *testing.T) {
+	chain := &mockChain{head: 100}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	confirmer := NewSettlementConfirmer(settledAt(testSettlementTx), chain, WithPollInterval(time.Millisecond))
+	got, err := confirmer.SettleAndConfirm(ctx, nil, nil, 1)
+	if !errors.Is(err, ErrSettlementNotConfirmed) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected ErrSettlementNotConfirmed and context.Canceled, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected no confirmation while pending, got %+v", got)
+	}
+}
+
+func TestSettleAndConfirmSettleFailure(t *testing.T) {
+	chain := &mockChain{head: 100}
+	tests := []struct {
+		name    string
+		settler fakeSettler
+	}{
+		{name: "error", settler: fakeSettler{err: errors.New("facilitator unavailable")}},
+		{name: "unsuccessful", settler: fakeSettler{response: &x402.SettleResponse{ErrorReason: "insufficient_funds"}}},
+		{name: "no transaction", settler: fakeSettler{response: &x402.SettleResponse{Success: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirmer := NewSettlementConfirmer(tt.settler, chain)
+			if got, err := confirmer.SettleAndConfirm(context.Background(), nil, nil, 1); err == nil {
+				t.Errorf("Expected error, got %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Defaults for WatchSettlement
+const (
+	DefaultFinalityConfirmations  = 12
+	DefaultSettlementPollInterval = 2 * time.Second
+)
+
+// SettlementState is the progress of a settlement transaction
+type SettlementState string
+
+const (
+	SettlementPending   SettlementState = "pending"   // Not yet in a block (or dropped by a reorg)
+	SettlementIncluded  SettlementState = "included"  // In a block with fewer than the required confirmations
+	SettlementFinalized SettlementState = "finalized" // Has the required confirmations; the last status sent
+)
+
+// SettlementStatus is sent by WatchSettlement whenever the state or confirmation count changes
+type SettlementStatus struct {
+	State         SettlementState
+	TxHash        string
+	Network       string
+	BlockNumber   uint64 // Block including the transaction (0 while pending)
+	Confirmations uint64 // Blocks from BlockNumber to the head, inclusive (0 while pending)
+	Status        uint64 // TxStatusSuccess or TxStatusFailed once included
+}
+
+// ReceiptReader is the subset of *ethclient.Client WatchSettlement needs
+// Clients that also implement HeadSubscriber (WebSocket and IPC connections) are woken on
+// new heads instead of polled.
+type ReceiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethtypes.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// HeadSubscriber is implemented by clients that can push new chain heads
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *ethtypes.Header) (ethereum.Subscription, error)
+}
+
+// WatchOption configures WatchSettlement
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	confirmations uint64
+	pollInterval  time.Duration
+	maxWait       time.Duration
+}
+
+// WithConfirmations sets how many confirmations make a settlement final
+// Defaults to NetworkConfig.FinalityConfirmations, or DefaultFinalityConfirmations.
+func WithConfirmations(n uint64) WatchOption {
+	return func(o *watchOptions) {
+		o.confirmations = n
+	}
+}
+
+// WithPollInterval sets how often the receipt is checked when new heads cannot be subscribed to
+func WithPollInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.pollInterval = d
+	}
+}
+
+// WithMaxWait stops watching after d, as if ctx had been cancelled
+func WithMaxWait(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.maxWait = d
+	}
+}
+
+// WatchSettlement follows a settlement transaction until it is final
+//
+// The returned channel receives a SettlementStatus on every transition from pending to
+// included to finalized, and on each new confirmation in between. The finalized status
+// carries the receipt status (TxStatusSuccess or TxStatusFailed) and is the last one sent.
+// The channel is closed after it, or when ctx is done or WithMaxWait elapses. RPC errors
+// while watching are treated as transient and retried on the next head or poll.
+func WatchSettlement(ctx context.Context, client ReceiptReader, txHash string, network string, opts ...WatchOption) (<-chan SettlementStatus, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required to watch a settlement")
+	}
+	hashBytes, err := HexToBytes(txHash)
+	if err != nil || len(hashBytes) != common.HashLength {
+		return nil, fmt.Errorf("invalid transaction hash %q", txHash)
+	}
+
+	options := watchOptions{pollInterval: DefaultSettlementPollInterval}
+	if config, err := GetNetworkConfig(network); err == nil && config.FinalityConfirmations > 0 {
+		options.confirmations = config.FinalityConfirmations
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.confirmations == 0 {
+		options.confirmations = DefaultFinalityConfirmations
+	}
+
+	w := &settlementWatcher{
+		client:  client,
+		hash:    common.BytesToHash(hashBytes),
+		options: options,
+		last:    SettlementStatus{TxHash: txHash, Network: network},
+	}
+	statuses := make(chan SettlementStatus)
+	cancel := context.CancelFunc(func() {})
+	if options.maxWait > 0 {
+		ctx, cancel = context.WithTimeout(ctx, options.maxWait)
+	}
+	go func() {
+		defer cancel()
+		w.run(ctx, statuses)
+	}()
+	return statuses, nil
+}
+
+// settlementWatcher holds the state of one WatchSettlement call
+type settlementWatcher struct {
+	client  ReceiptReader
+	hash    common.Hash
+	options watchOptions
+	last    SettlementStatus // Last status sent; State is empty before the first
+}
+
+func (w *settlementWatcher) run(ctx context.Context, statuses chan<- SettlementStatus) {
+	defer close(statuses)
+
+	wake, stop := w.wakeups(ctx)
+	defer stop()
+
+	for {
+		if status, ok := w.check(ctx); ok && status != w.last {
+			select {
+			case statuses <- status:
+			case <-ctx.Done():
+				return
+			}
+			w.last = status
+			if status.State == SettlementFinalized {
+				return
+			}
+		}
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// wakeups returns a channel signalled on every new head, falling back to a poll ticker
+// when heads cannot be subscribed to or the subscription fails (e.g. a dropped WebSocket)
+func (w *settlementWatcher) wakeups(ctx context.Context) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+	done := make(chan struct{})
+	signal := func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	poll := func() {
+		ticker := time.NewTicker(w.options.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				signal()
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	if subscriber, ok := w.client.(HeadSubscriber); ok {
+		heads := make(chan *ethtypes.Header)
+		if sub, err := subscriber.SubscribeNewHead(ctx, heads); err == nil {
+			go func() {
+				for {
+					select {
+					case <-heads:
+						signal()
+					case <-sub.Err():
+						// No more heads will arrive: check now, then poll
+						signal()
+						poll()
+						return
+					case <-done:
+						return
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return wake, func() {
+				close(done)
+				sub.Unsubscribe()
+			}
+		}
+	}
+
+	go poll()
+	return wake, func() { close(done) }
+}
+
+// check reads the receipt and head; ok is false when the RPC could not be read
+func (w *settlementWatcher) check(ctx context.Context) (SettlementStatus, bool) {
+	status := SettlementStatus{State: SettlementPending, TxHash: w.last.TxHash, Network: w.last.Network}
+
+	receipt, err := w.client.TransactionReceipt(ctx, w.hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return status, true
+	}
+	if err != nil || receipt == nil || receipt.BlockNumber == nil {
+		return status, false
+	}
+
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return status, false
+	}
+
+	status.BlockNumber = receipt.BlockNumber.Uint64()
+	status.Status = receipt.Status
+	status.Confirmations = 1 // A lagging node may report a head before the receipt's block
+	if head >= status.BlockNumber {
+		status.Confirmations = head - status.BlockNumber + 1
+	}
+	status.State = SettlementIncluded
+	if status.Confirmations >= w.options.confirmations {
+		status.State = SettlementFinalized
+	}
+	return status, true
+}
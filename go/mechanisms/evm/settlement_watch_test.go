@@ -0,0 +1,243 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+const testSettlementTx = "0x1111111111111111111111111111111111111111111111111111111111111111"
+
+// mockChain is a ReceiptReader whose head and receipt the test advances
+type mockChain struct {
+	mu       sync.Mutex
+	head     uint64
+	included uint64 // Block including the transaction; 0 while pending
+	status   uint64
+	failing  bool // Make every call fail
+}
+
+func (m *mockChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethtypes.Receipt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failing {
+		return nil, errors.New("connection refused")
+	}
+	if m.included == 0 {
+		return nil, ethereum.NotFound
+	}
+	return &ethtypes.Receipt{Status: m.status, BlockNumber: new(big.Int).SetUint64(m.included), TxHash: txHash}, nil
+}
+
+func (m *mockChain) BlockNumber(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failing {
+		return 0, errors.New("connection refused")
+	}
+	return m.head, nil
+}
+
+// mine advances the head by one block, including the transaction in it when include is set
+func (m *mockChain) mine(include bool, status uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.head++
+	if include {
+		m.included, m.status = m.head, status
+	}
+}
+
+// subscribingChain is a mockChain that pushes new heads
+type subscribingChain struct {
+	mockChain
+	heads chan<- *ethtypes.Header
+}
+
+func (m *subscribingChain) SubscribeNewHead(ctx context.Context, ch chan<- *ethtypes.Header) (ethereum.Subscription, error) {
+	m.mu.Lock()
+	m.heads = ch
+	m.mu.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	}), nil
+}
+
+// droppingChain is a subscribingChain whose subscription fails once drop is closed
+type droppingChain struct {
+	subscribingChain
+	drop chan struct{}
+}
+
+func (m *droppingChain) SubscribeNewHead(ctx context.Context, ch chan<- *ethtypes.Header) (ethereum.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case <-m.drop:
+			return errors.New("websocket: close 1006 (abnormal closure)")
+		case <-quit:
+			return nil
+		}
+	}), nil
+}
+
+func (m *subscribingChain) mineAndNotify(include bool, status uint64) {
+	m.mine(include, status)
+	m.mu.Lock()
+	heads := m.heads
+	m.mu.Unlock()
+	heads <- &ethtypes.Header{Number: new(big.Int).SetUint64(m.head)}
+}
+
+// nextStatus waits for the next status, failing the test on timeout
+func nextStatus(t *testing.T, statuses <-chan SettlementStatus) (SettlementStatus, bool) {
+	t.Helper()
+	select {
+	case status, ok := <-statuses:
+		return status, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a settlement status")
+		return SettlementStatus{}, false
+	}
+}
+
+func TestWatchSettlementPolling(t *testing.T) {
+	chain := &mockChain{head: 100}
+	statuses, err := WatchSettlement(context.Background(), chain, testSettlementTx, "eip155:8453",
+		WithConfirmations(3), WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("WatchSettlement failed: %v", err)
+	}
+
+	status, _ := nextStatus(t, statuses)
+	if status.State != SettlementPending || status.TxHash != testSettlementTx || status.Network != "eip155:8453" {
+		t.Errorf("Expected pending status, got %+v", status)
+	}
+
+	want := []SettlementStatus{
+		{State: SettlementIncluded, BlockNumber: 101, Confirmations: 1},
+		{State: SettlementIncluded, BlockNumber: 101, Confirmations: 2},
+		{State: SettlementFinalized, BlockNumber: 101, Confirmations: 3},
+	}
+	for i, w := range want {
+		chain.mine(i == 0, TxStatusSuccess)
+		status, _ := nextStatus(t, statuses)
+		if status.State != w.State || status.BlockNumber != w.BlockNumber || status.Confirmations != w.Confirmations {
+			t.Errorf("Status %d: expected %+v, got %+v", i, w, status)
+		}
+		if status.Status != TxStatusSuccess {
+			t.Errorf("Status %d: expected TxStatusSuccess, got %d", i, status.Status)
+		}
+	}
+
+	if _, ok := nextStatus(t, statuses); ok {
+		t.Error("Expected the channel to close after finalization")
+	}
+}
+
+func TestWatchSettlementFailedTransaction(t *testing.T) {
+	chain := &mockChain{head: 10, included: 10, status: TxStatusFailed}
+	statuses, err := WatchSettlement(context.Background(), chain, testSettlementTx, "eip155:8453",
+		WithConfirmations(1), WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("WatchSettlement failed: %v", err)
+	}
+
+	status, _ := nextStatus(t, statuses)
+	if status.State != SettlementFinalized || status.Status != TxStatusFailed {
+		t.Errorf("Expected a finalized failed status, got %+v", status)
+	}
+}
+
+func TestWatchSettlementSubscription(t *testing.T) {
+	chain := &subscribingChain{mockChain: mockChain{head: 5}}
+	// A poll interval this long shows that statuses follow the pushed heads
+	statuses, err := WatchSettlement(context.Background(), chain, testSettlementTx, "eip155:8453",
+		WithConfirmations(2), WithPollInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("WatchSettlement failed: %v", err)
+	}
+
+	if status, _ := nextStatus(t, statuses); status.State != SettlementPending {
+		t.Fatalf("Expected pending status, got %+v", status)
+	}
+	chain.mineAndNotify(true, TxStatusSuccess)
+	if status, _ := nextStatus(t, statuses); status.State != SettlementIncluded || status.Confirmations != 1 {
+		t.Errorf("Expected included status, got %+v", status)
+	}
+	chain.mineAndNotify(false, 0)
+	if status, _ := nextStatus(t, statuses); status.State != SettlementFinalized || status.Confirmations != 2 {
+		t.Errorf("Expected finalized status, got %+v", status)
+	}
+}
+
+func TestWatchSettlementSubscriptionDropped(t *testing.T) {
+	chain := &droppingChain{subscribingChain: subscribingChain{mockChain: mockChain{head: 5}}, drop: make(chan struct{})}
+	statuses, err := WatchSettlement(context.Background(), chain, testSettlementTx, "eip155:8453",
+		WithConfirmations(2), WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("WatchSettlement failed: %v", err)
+	}
+
+	if status, _ := nextStatus(t, statuses); status.State != SettlementPending {
+		t.Fatalf("Expected pending status, got %+v", status)
+	}
+
+	// After the subscription fails no head is pushed, so the watch continues by polling
+	close(chain.drop)
+	chain.mine(true, TxStatusSuccess)
+	if status, _ := nextStatus(t, statuses); status.State != SettlementIncluded || status.Confirmations != 1 {
+		t.Errorf("Expected included status, got %+v", status)
+	}
+	chain.mine(false, 0)
+	if status, _ := nextStatus(t, statuses); status.State != SettlementFinalized || status.Confirmations != 2 {
+		t.Errorf("Expected finalized status, got %+v", status)
+	}
+}
+
+func TestWatchSettlementCancel(t *testing.T) {
+	chain := &mockChain{failing: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	statuses, err := WatchSettlement(ctx, chain, testSettlementTx, "eip155:8453", WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("WatchSettlement failed: %v", err)
+	}
+
+	// RPC errors produce no status; cancelling closes the channel
+	cancel()
+	if status, ok := nextStatus(t, statuses); ok {
+		t.Errorf("Expected the channel to close on cancel, got %+v", status)
+	}
+}
+
+func TestWatchSettlementNetworkConfirmations(t *testing.T) {
+	if err := RegisterNetwork("watch-testnet", NetworkConfig{ChainID: big.NewInt(424242), FinalityConfirmations: 2}, WithOverride()); err != nil {
+		t.Fatalf("RegisterNetwork failed: %v", err)
+	}
+
+	chain := &mockChain{head: 2, included: 1, status: TxStatusSuccess}
+	statuses, err := WatchSettlement(context.Background(), chain, testSettlementTx, "watch-testnet", WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("WatchSettlement failed: %v", err)
+	}
+	if status, _ := nextStatus(t, statuses); status.State != SettlementFinalized {
+		t.Errorf("Expected 2 confirmations to be final on watch-testnet, got %+v", status)
+	}
+}
+
+func TestWatchSettlementInvalidArguments(t *testing.T) {
+	if _, err := WatchSettlement(context.Background(), nil, testSettlementTx, "eip155:8453"); err == nil {
+		t.Error("Expected error without a client")
+	}
+	if _, err := WatchSettlement(context.Background(), &mockChain{}, "0x1234", "eip155:8453"); err == nil {
+		t.Error("Expected error for a short transaction hash")
+	}
+}
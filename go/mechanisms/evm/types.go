@@ -1,7 +1,10 @@
 package evm
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"math/big"
 )
 
@@ -15,10 +18,52 @@ type ExactEIP3009Authorization struct {
 	Nonce       string `json:"nonce"`       // 32-byte nonce as hex string
 }
 
+// Signature types of an ExactEIP3009Payload, selecting the transferWithAuthorization overload
+const (
+	SignatureTypeEOA   = "eoa"   // 65-byte ECDSA signature, submitted as v,r,s
+	SignatureTypeBytes = "bytes" // Smart wallet (EIP-1271 / ERC-6492) signature, submitted as bytes
+)
+
 // ExactEIP3009Payload represents the exact payment payload for EVM networks
 type ExactEIP3009Payload struct {
 	Signature     string                    `json:"signature,omitempty"`
 	Authorization ExactEIP3009Authorization `json:"authorization"`
+
+	// SignatureType is SignatureTypeEOA or SignatureTypeBytes (optional; when empty the
+	// facilitator infers it from the signature length)
+	SignatureType string `json:"signatureType,omitempty"`
+}
+
+// DefaultSignatureType returns the signature type implied by a signature's length:
+// SignatureTypeEOA for 65 bytes and SignatureTypeBytes otherwise
+func DefaultSignatureType(signature []byte) string {
+	if len(signature) == 65 {
+		return SignatureTypeEOA
+	}
+	return SignatureTypeBytes
+}
+
+// UseVRSSignature reports whether transferWithAuthorization should be called with the v,r,s
+// overload for signature (the payload signature with any ERC-6492 wrapper removed).
+// Only 65-byte signatures can be, and never when the payload declares SignatureTypeBytes.
+func (p *ExactEIP3009Payload) UseVRSSignature(signature []byte) bool {
+	return len(signature) == 65 && p.SignatureType != SignatureTypeBytes
+}
+
+// ExactEIP3009Cancellation represents an EIP-3009 CancelAuthorization message
+// Submitting it with cancelAuthorization marks the nonce used, so the authorization
+// carrying the same nonce can no longer be settled.
+type ExactEIP3009Cancellation struct {
+	Authorizer string `json:"authorizer"` // Ethereum address (hex) that signed the authorization
+	Nonce      string `json:"nonce"`      // 32-byte nonce as hex string
+}
+
+// ExactEIP3009CancelPayload is a signed cancellation, ready to submit to the token
+type ExactEIP3009CancelPayload struct {
+	Network      string                   `json:"network"`
+	Asset        string                   `json:"asset"` // Token contract to call cancelAuthorization on
+	Signature    string                   `json:"signature"`
+	Cancellation ExactEIP3009Cancellation `json:"cancellation"`
 }
 
 // ExactEvmPayloadV1 is an alias for ExactEIP3009Payload (v1 compatibility)
@@ -27,17 +72,108 @@ type ExactEvmPayloadV1 = ExactEIP3009Payload
 // ExactEvmPayloadV2 is an alias for ExactEIP3009Payload (v2 compatibility)
 type ExactEvmPayloadV2 = ExactEIP3009Payload
 
-// ClientEvmSigner defines the interface for client-side EVM signing operations
-type ClientEvmSigner interface {
+// ExactEIP2612Permit represents the EIP-2612 Permit data
+// The facilitator (spender) submits permit and then transferFrom(owner, payTo, value).
+type ExactEIP2612Permit struct {
+	Owner    string `json:"owner"`    // Ethereum address (hex)
+	Spender  string `json:"spender"`  // Ethereum address (hex)
+	Value    string `json:"value"`    // Amount in wei as string
+	Nonce    string `json:"nonce"`    // Token nonces(owner) as decimal string
+	Deadline string `json:"deadline"` // Unix timestamp as string
+}
+
+// ExactEIP2612Payload represents the exact payment payload for tokens that only implement EIP-2612
+type ExactEIP2612Payload struct {
+	Signature string             `json:"signature,omitempty"`
+	Permit    ExactEIP2612Permit `json:"permit"`
+}
+
+// ExactPermit2Authorization represents a Uniswap Permit2 PermitTransferFrom (witness-less)
+// The facilitator (spender) calls permitTransferFrom on the Permit2 contract to move the tokens.
+type ExactPermit2Authorization struct {
+	From     string `json:"from"`     // Token owner address (hex)
+	Token    string `json:"token"`    // ERC-20 token address (hex)
+	Amount   string `json:"amount"`   // Amount in wei as string
+	Spender  string `json:"spender"`  // Ethereum address (hex) allowed to transfer
+	Nonce    string `json:"nonce"`    // Unordered Permit2 nonce as decimal string
+	Deadline string `json:"deadline"` // Unix timestamp as string
+}
+
+// ExactPermit2Payload represents the exact payment payload signed for the Permit2 contract
+type ExactPermit2Payload struct {
+	Signature            string                    `json:"signature,omitempty"`
+	Permit2Authorization ExactPermit2Authorization `json:"permit2Authorization"`
+}
+
+// ExactPermit2AllowanceAuthorization represents a Uniswap Permit2 AllowanceTransfer PermitSingle
+// The facilitator (spender) calls permit on the Permit2 contract to set its allowance, then
+// transferFrom to move the tokens. Unlike PermitTransferFrom, the nonce is ordered per
+// (owner, token, spender) and read from allowance(owner, token, spender).
+type ExactPermit2AllowanceAuthorization struct {
+	From        string `json:"from"`        // Token owner address (hex)
+	Token       string `json:"token"`       // ERC-20 token address (hex)
+	Amount      string `json:"amount"`      // Allowance in wei as string (uint160)
+	Expiration  string `json:"expiration"`  // Unix timestamp the allowance expires at (uint48)
+	Nonce       string `json:"nonce"`       // Permit2 allowance nonce as decimal string (uint48)
+	Spender     string `json:"spender"`     // Ethereum address (hex) granted the allowance
+	SigDeadline string `json:"sigDeadline"` // Unix timestamp the signature expires at
+}
+
+// ExactPermit2AllowancePayload represents the exact payment payload signed as a Permit2 PermitSingle
+type ExactPermit2AllowancePayload struct {
+	Signature        string                             `json:"signature,omitempty"`
+	Permit2Allowance ExactPermit2AllowanceAuthorization `json:"permit2Allowance"`
+}
+
+// UpToEIP3009Payload represents the "upto" payment payload for EVM networks
+// The authorization is signed for MaxAmount; Value is the amount to settle, at most MaxAmount.
+type UpToEIP3009Payload struct {
+	Signature     string                    `json:"signature,omitempty"`
+	Authorization ExactEIP3009Authorization `json:"authorization"`
+	MaxAmount     string                    `json:"maxAmount"` // Upper bound in wei as string
+	Value         string                    `json:"value"`     // Amount to settle in wei as string
+
+	// SignatureType is SignatureTypeEOA or SignatureTypeBytes, as in ExactEIP3009Payload
+	SignatureType string `json:"signatureType,omitempty"`
+}
+
+// DigestSigner is the minimal client-side EVM signer: it signs 32-byte digests that the
+// client schemes compute locally, so hardware wallets and remote KMS signers that cannot
+// hash EIP-712 typed data themselves can be used.
+type DigestSigner interface {
 	// Address returns the signer's Ethereum address
 	Address() string
 
+	// SignDigest signs a raw digest (32-byte hash), returning a 65-byte r||s||v signature
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// ClientEvmSigner defines the interface for client-side EVM signing operations
+//
+// The client schemes only require DigestSigner; SignTypedData is kept for signers that
+// implement it, but every EIP-712 digest is now computed locally and signed with SignDigest.
+type ClientEvmSigner interface {
+	DigestSigner
+
 	// SignTypedData signs EIP-712 typed data
 	SignTypedData(ctx context.Context, domain TypedDataDomain, types map[string][]TypedDataField, primaryType string, message map[string]interface{}) ([]byte, error)
+}
 
-	// SignDigest signs a raw digest (32-byte hash)
-	// This is used when we have DOMAIN_SEPARATOR from chain and want to sign directly
-	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+// SmartWalletSigner is a DigestSigner backed by a smart contract wallet
+// Signatures are produced by the wallet's owner key and validated on-chain via EIP-1271.
+// If the wallet is not deployed yet, clients wrap signatures per ERC-6492 so the
+// facilitator can deploy the wallet before verifying.
+type SmartWalletSigner interface {
+	DigestSigner
+
+	// Deployment returns how to deploy the wallet, or nil if it is already deployed
+	Deployment(ctx context.Context) (*SmartWalletDeployment, error)
+}
+
+// SmartWalletDeployment describes how to deploy a counterfactual smart wallet
+type SmartWalletDeployment struct {
+	Factory         string // CREATE2 factory address (hex)
+	FactoryCalldata []byte // Calldata that deploys the wallet when sent to Factory
 }
 
 // FacilitatorEvmSigner defines the interface for facilitator EVM operations
@@ -75,11 +211,103 @@ type FacilitatorEvmSigner interface {
 }
 
 // TypedDataDomain represents the EIP-712 domain separator
+// Salt is optional: the domain only has a salt field when it is non-zero.
+// OmitChainID drops the chainId field, for legacy tokens whose domain has none.
+//
+// In JSON the domain has the fields it signs: chainId is left out when OmitChainID is set
+// (and a domain without it decodes with OmitChainID set), and salt is 0x-prefixed hex,
+// present only when non-zero.
 type TypedDataDomain struct {
-	Name              string   `json:"name"`
-	Version           string   `json:"version"`
-	ChainID           *big.Int `json:"chainId"`
-	VerifyingContract string   `json:"verifyingContract"`
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract string
+	Salt              [32]byte
+	OmitChainID       bool
+}
+
+// typedDataDomainJSON is the JSON form of a TypedDataDomain
+type typedDataDomainJSON struct {
+	Name              string          `json:"name"`
+	Version           string          `json:"version"`
+	ChainID           json.RawMessage `json:"chainId,omitempty"`
+	VerifyingContract string          `json:"verifyingContract"`
+	Salt              json.RawMessage `json:"salt,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (d TypedDataDomain) MarshalJSON() ([]byte, error) {
+	out := typedDataDomainJSON{Name: d.Name, Version: d.Version, VerifyingContract: d.VerifyingContract}
+	if !d.OmitChainID {
+		chainID, err := json.Marshal(d.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		out.ChainID = chainID
+	}
+	if d.HasSalt() {
+		out.Salt, _ = json.Marshal(BytesToHex(d.Salt[:]))
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A salt may also be an array of 32 numbers, as
+// domains were encoded before salts were hex.
+func (d *TypedDataDomain) UnmarshalJSON(data []byte) error {
+	var in typedDataDomainJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	domain := TypedDataDomain{Name: in.Name, Version: in.Version, VerifyingContract: in.VerifyingContract}
+
+	if len(in.ChainID) == 0 {
+		domain.OmitChainID = true
+	} else if err := json.Unmarshal(in.ChainID, &domain.ChainID); err != nil {
+		return fmt.Errorf("invalid domain chainId: %w", err)
+	}
+
+	switch {
+	case len(in.Salt) == 0 || bytes.Equal(in.Salt, []byte("null")):
+	case in.Salt[0] == '[':
+		if err := json.Unmarshal(in.Salt, &domain.Salt); err != nil {
+			return fmt.Errorf("invalid domain salt: %w", err)
+		}
+	default:
+		var hexSalt string
+		if err := json.Unmarshal(in.Salt, &hexSalt); err != nil {
+			return fmt.Errorf("invalid domain salt: %w", err)
+		}
+		salt, err := HexToBytes(hexSalt)
+		if err != nil || len(salt) != 32 {
+			return fmt.Errorf("invalid domain salt: %s", hexSalt)
+		}
+		copy(domain.Salt[:], salt)
+	}
+
+	*d = domain
+	return nil
+}
+
+// HasSalt reports whether the domain includes a salt field
+func (d TypedDataDomain) HasSalt() bool {
+	return d.Salt != [32]byte{}
+}
+
+// Fields returns the EIP712Domain type of the domain, in canonical order and with only
+// the fields it has: name, version, chainId (unless OmitChainID), verifyingContract and salt
+func (d TypedDataDomain) Fields() []TypedDataField {
+	fields := []TypedDataField{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+	}
+	if !d.OmitChainID {
+		fields = append(fields, TypedDataField{Name: "chainId", Type: "uint256"})
+	}
+	fields = append(fields, TypedDataField{Name: "verifyingContract", Type: "address"})
+	if d.HasSalt() {
+		fields = append(fields, TypedDataField{Name: "salt", Type: "bytes32"})
+	}
+	return fields
 }
 
 // TypedDataField represents a field in EIP-712 typed data
@@ -101,6 +329,14 @@ type AssetInfo struct {
 	Name     string
 	Version  string
 	Decimals int
+
+	// DomainSeparatorMethod names the view function returning the token's EIP-712 domain
+	// separator when it is not one of DomainSeparatorMethods (optional)
+	DomainSeparatorMethod string
+
+	// OmitChainID marks tokens whose EIP-712 domain has no chainId field (optional).
+	// requirements.Extra["omitChainId"] overrides it per payment.
+	OmitChainID bool
 }
 
 // NetworkConfig contains network-specific configuration
@@ -108,6 +344,17 @@ type AssetInfo struct {
 type NetworkConfig struct {
 	ChainID      *big.Int
 	DefaultAsset AssetInfo
+
+	// Assets lists further accepted tokens, keyed by address (optional)
+	// DefaultAsset is always treated as one of them.
+	Assets map[string]AssetInfo
+
+	// Permit2Address overrides the canonical Permit2 deployment (optional)
+	Permit2Address string
+
+	// FinalityConfirmations is the number of confirmations WatchSettlement treats as final
+	// (optional, defaults to DefaultFinalityConfirmations)
+	FinalityConfirmations uint64
 }
 
 // PayloadToMap converts an ExactEIP3009Payload to a map for JSON marshaling
@@ -125,6 +372,9 @@ func (p *ExactEIP3009Payload) ToMap() map[string]interface{} {
 	if p.Signature != "" {
 		result["signature"] = p.Signature
 	}
+	if p.SignatureType != "" {
+		result["signatureType"] = p.SignatureType
+	}
 	return result
 }
 
@@ -135,6 +385,9 @@ func PayloadFromMap(data map[string]interface{}) (*ExactEIP3009Payload, error) {
 	if sig, ok := data["signature"].(string); ok {
 		payload.Signature = sig
 	}
+	if sigType, ok := data["signatureType"].(string); ok {
+		payload.SignatureType = sigType
+	}
 
 	if auth, ok := data["authorization"].(map[string]interface{}); ok {
 		if from, ok := auth["from"].(string); ok {
@@ -168,3 +421,200 @@ type ERC6492SignatureData struct {
 	FactoryCalldata []byte   // Calldata to deploy the wallet (empty if not ERC-6492)
 	InnerSignature  []byte   // The actual signature (EIP-1271 or EOA)
 }
+
+// ToMap converts an ExactEIP2612Payload to a map for JSON marshaling
+func (p *ExactEIP2612Payload) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"permit": map[string]interface{}{
+			"owner":    p.Permit.Owner,
+			"spender":  p.Permit.Spender,
+			"value":    p.Permit.Value,
+			"nonce":    p.Permit.Nonce,
+			"deadline": p.Permit.Deadline,
+		},
+	}
+	if p.Signature != "" {
+		result["signature"] = p.Signature
+	}
+	return result
+}
+
+// PermitPayloadFromMap creates an ExactEIP2612Payload from a map
+func PermitPayloadFromMap(data map[string]interface{}) (*ExactEIP2612Payload, error) {
+	payload := &ExactEIP2612Payload{}
+
+	if sig, ok := data["signature"].(string); ok {
+		payload.Signature = sig
+	}
+
+	permit, ok := data["permit"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing permit in payload")
+	}
+	if owner, ok := permit["owner"].(string); ok {
+		payload.Permit.Owner = owner
+	}
+	if spender, ok := permit["spender"].(string); ok {
+		payload.Permit.Spender = spender
+	}
+	if value, ok := permit["value"].(string); ok {
+		payload.Permit.Value = value
+	}
+	if nonce, ok := permit["nonce"].(string); ok {
+		payload.Permit.Nonce = nonce
+	}
+	if deadline, ok := permit["deadline"].(string); ok {
+		payload.Permit.Deadline = deadline
+	}
+
+	return payload, nil
+}
+
+// IsPermitPayload reports whether a payload map carries an EIP-2612 permit rather than an EIP-3009 authorization
+func IsPermitPayload(data map[string]interface{}) bool {
+	_, ok := data["permit"].(map[string]interface{})
+	return ok
+}
+
+// ToMap converts an ExactPermit2Payload to a map for JSON marshaling
+func (p *ExactPermit2Payload) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"permit2Authorization": map[string]interface{}{
+			"from":     p.Permit2Authorization.From,
+			"token":    p.Permit2Authorization.Token,
+			"amount":   p.Permit2Authorization.Amount,
+			"spender":  p.Permit2Authorization.Spender,
+			"nonce":    p.Permit2Authorization.Nonce,
+			"deadline": p.Permit2Authorization.Deadline,
+		},
+	}
+	if p.Signature != "" {
+		result["signature"] = p.Signature
+	}
+	return result
+}
+
+// Permit2PayloadFromMap creates an ExactPermit2Payload from a map
+func Permit2PayloadFromMap(data map[string]interface{}) (*ExactPermit2Payload, error) {
+	payload := &ExactPermit2Payload{}
+
+	if sig, ok := data["signature"].(string); ok {
+		payload.Signature = sig
+	}
+
+	auth, ok := data["permit2Authorization"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing permit2Authorization in payload")
+	}
+	if from, ok := auth["from"].(string); ok {
+		payload.Permit2Authorization.From = from
+	}
+	if token, ok := auth["token"].(string); ok {
+		payload.Permit2Authorization.Token = token
+	}
+	if amount, ok := auth["amount"].(string); ok {
+		payload.Permit2Authorization.Amount = amount
+	}
+	if spender, ok := auth["spender"].(string); ok {
+		payload.Permit2Authorization.Spender = spender
+	}
+	if nonce, ok := auth["nonce"].(string); ok {
+		payload.Permit2Authorization.Nonce = nonce
+	}
+	if deadline, ok := auth["deadline"].(string); ok {
+		payload.Permit2Authorization.Deadline = deadline
+	}
+
+	return payload, nil
+}
+
+// ToMap converts an ExactPermit2AllowancePayload to a map for JSON marshaling
+func (p *ExactPermit2AllowancePayload) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"permit2Allowance": map[string]interface{}{
+			"from":        p.Permit2Allowance.From,
+			"token":       p.Permit2Allowance.Token,
+			"amount":      p.Permit2Allowance.Amount,
+			"expiration":  p.Permit2Allowance.Expiration,
+			"nonce":       p.Permit2Allowance.Nonce,
+			"spender":     p.Permit2Allowance.Spender,
+			"sigDeadline": p.Permit2Allowance.SigDeadline,
+		},
+	}
+	if p.Signature != "" {
+		result["signature"] = p.Signature
+	}
+	return result
+}
+
+// Permit2AllowancePayloadFromMap creates an ExactPermit2AllowancePayload from a map
+func Permit2AllowancePayloadFromMap(data map[string]interface{}) (*ExactPermit2AllowancePayload, error) {
+	payload := &ExactPermit2AllowancePayload{}
+
+	if sig, ok := data["signature"].(string); ok {
+		payload.Signature = sig
+	}
+
+	auth, ok := data["permit2Allowance"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing permit2Allowance in payload")
+	}
+	fields := map[string]*string{
+		"from":        &payload.Permit2Allowance.From,
+		"token":       &payload.Permit2Allowance.Token,
+		"amount":      &payload.Permit2Allowance.Amount,
+		"expiration":  &payload.Permit2Allowance.Expiration,
+		"nonce":       &payload.Permit2Allowance.Nonce,
+		"spender":     &payload.Permit2Allowance.Spender,
+		"sigDeadline": &payload.Permit2Allowance.SigDeadline,
+	}
+	for key, field := range fields {
+		if value, ok := auth[key].(string); ok {
+			*field = value
+		}
+	}
+
+	return payload, nil
+}
+
+// IsPermit2AllowancePayload reports whether a payload map carries a Permit2 PermitSingle
+func IsPermit2AllowancePayload(data map[string]interface{}) bool {
+	_, ok := data["permit2Allowance"].(map[string]interface{})
+	return ok
+}
+
+// IsPermit2Payload reports whether a payload map carries a Permit2 authorization
+func IsPermit2Payload(data map[string]interface{}) bool {
+	_, ok := data["permit2Authorization"].(map[string]interface{})
+	return ok
+}
+
+// ToMap converts an UpToEIP3009Payload to a map for JSON marshaling
+func (p *UpToEIP3009Payload) ToMap() map[string]interface{} {
+	result := (&ExactEIP3009Payload{Signature: p.Signature, Authorization: p.Authorization, SignatureType: p.SignatureType}).ToMap()
+	result["maxAmount"] = p.MaxAmount
+	result["value"] = p.Value
+	return result
+}
+
+// UpToPayloadFromMap creates an UpToEIP3009Payload from a map
+func UpToPayloadFromMap(data map[string]interface{}) (*UpToEIP3009Payload, error) {
+	exact, err := PayloadFromMap(data)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &UpToEIP3009Payload{
+		Signature:     exact.Signature,
+		Authorization: exact.Authorization,
+		SignatureType: exact.SignatureType,
+	}
+	if maxAmount, ok := data["maxAmount"].(string); ok {
+		payload.MaxAmount = maxAmount
+	}
+	if value, ok := data["value"].(string); ok {
+		payload.Value = value
+	}
+
+	return payload, nil
+}
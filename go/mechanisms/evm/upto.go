@@ -0,0 +1,49 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Validate checks the amounts of an "upto" payload: the authorization is signed for
+// MaxAmount, and Value is a positive amount no greater than MaxAmount.
+//
+// EIP-3009 always transfers the signed value, so settling less than MaxAmount needs a
+// token that supports partial settlement or a PayTo escrow contract that forwards Value
+// and refunds the rest to the payer.
+func (p *UpToEIP3009Payload) Validate() error {
+	maxAmount, ok := new(big.Int).SetString(p.MaxAmount, 10)
+	if !ok || maxAmount.Sign() <= 0 {
+		return fmt.Errorf("invalid upto maxAmount %q", p.MaxAmount)
+	}
+	if p.Authorization.Value != maxAmount.String() {
+		return fmt.Errorf("upto authorization value %s does not match maxAmount %s", p.Authorization.Value, maxAmount)
+	}
+	return checkUpToValue(p.Value, maxAmount)
+}
+
+// SetValue sets the amount to settle, rejecting amounts above MaxAmount
+// Servers metering usage call it before settling with the consumed amount.
+func (p *UpToEIP3009Payload) SetValue(value string) error {
+	maxAmount, ok := new(big.Int).SetString(p.MaxAmount, 10)
+	if !ok {
+		return fmt.Errorf("invalid upto maxAmount %q", p.MaxAmount)
+	}
+	if err := checkUpToValue(value, maxAmount); err != nil {
+		return err
+	}
+	p.Value = value
+	return nil
+}
+
+// checkUpToValue checks that value is a positive decimal amount no greater than maxAmount
+func checkUpToValue(value string, maxAmount *big.Int) error {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok || v.Sign() <= 0 {
+		return fmt.Errorf("invalid upto value %q", value)
+	}
+	if v.Cmp(maxAmount) > 0 {
+		return fmt.Errorf("upto value %s exceeds maxAmount %s", v, maxAmount)
+	}
+	return nil
+}
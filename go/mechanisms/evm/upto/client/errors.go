@@ -0,0 +1,7 @@
+package client
+
+// Client error constants for the upto EVM scheme (V2)
+const (
+	ErrFailedToCreateAuthorization = "invalid_upto_evm_client_failed_to_create_authorization"
+	ErrInvalidMaxAmount            = "invalid_upto_evm_client_max_amount"
+)
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	exactclient "github.com/gatechain/x402/go/mechanisms/evm/exact/client"
+	"github.com/gatechain/x402/go/types"
+)
+
+// UpToEvmScheme implements the SchemeNetworkClient interface for EVM "upto" payments (V2)
+//
+// The payer signs an EIP-3009 authorization for requirements.Amount, the maximum the
+// payment may cost, and the facilitator settles the consumed value, at most that amount.
+// The payload starts with value equal to maxAmount; see evm.UpToEIP3009Payload.SetValue.
+type UpToEvmScheme struct {
+	exact *exactclient.ExactEvmScheme // Signs the authorization for maxAmount
+}
+
+// NewUpToEvmScheme creates a new UpToEvmScheme
+func NewUpToEvmScheme(signer evm.DigestSigner) *UpToEvmScheme {
+	return &UpToEvmScheme{
+		exact: exactclient.NewExactEvmScheme(signer),
+	}
+}
+
+// SetRPCURL sets the RPC URL for querying chain data
+func (c *UpToEvmScheme) SetRPCURL(rpcURL string) error {
+	return c.exact.SetRPCURL(rpcURL)
+}
+
+// SetRPCURLContext sets the RPC URL, failing if the RPC cannot report its chain ID before ctx is done
+func (c *UpToEvmScheme) SetRPCURLContext(ctx context.Context, rpcURL string) error {
+	return c.exact.SetRPCURLContext(ctx, rpcURL)
+}
+
+// SetPrecheckBalance enables a balanceOf check for maxAmount before signing (requires RPC)
+func (c *UpToEvmScheme) SetPrecheckBalance(enabled bool) {
+	c.exact.SetPrecheckBalance(enabled)
+}
+
+// SetValidityPeriod sets how long a new authorization stays valid
+func (c *UpToEvmScheme) SetValidityPeriod(d time.Duration) {
+	c.exact.SetValidityPeriod(d)
+}
+
+// SetNonceStore sets the store that tracks issued nonces; nil stops tracking
+func (c *UpToEvmScheme) SetNonceStore(store evm.NonceStore) {
+	c.exact.SetNonceStore(store)
+}
+
+// Scheme returns the scheme identifier
+func (c *UpToEvmScheme) Scheme() string {
+	return evm.SchemeUpTo
+}
+
+// CreatePaymentPayload creates a V2 payment payload authorizing up to requirements.Amount
+func (c *UpToEvmScheme) CreatePaymentPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+) (types.PaymentPayload, error) {
+	payload, err := c.exact.CreatePaymentPayload(ctx, requirements)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToCreateAuthorization+": %w", err)
+	}
+
+	exactPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToCreateAuthorization+": %w", err)
+	}
+
+	maxAmount := exactPayload.Authorization.Value
+	evmPayload := &evm.UpToEIP3009Payload{
+		Signature:     exactPayload.Signature,
+		Authorization: exactPayload.Authorization,
+		MaxAmount:     maxAmount,
+		Value:         maxAmount,
+		SignatureType: exactPayload.SignatureType,
+	}
+	if err := evmPayload.Validate(); err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidMaxAmount+": %w", err)
+	}
+
+	// Return partial V2 payload (core will add accepted, resource, extensions)
+	return types.PaymentPayload{
+		X402Version: 2,
+		Payload:     evmPayload.ToMap(),
+	}, nil
+}
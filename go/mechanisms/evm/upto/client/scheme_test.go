@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gatechain/x402/go/mechanisms/evm"
+	evmsigners "github.com/gatechain/x402/go/signers/evm"
+	"github.com/gatechain/x402/go/types"
+)
+
+const testUpToKey = "0000000000000000000000000000000000000000000000000000000000000001"
+
+func testUpToRequirements(maxAmount string) types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:  evm.SchemeUpTo,
+		Network: "eip155:1",
+		Asset:   "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		Amount:  maxAmount,
+		PayTo:   "0x0000000000000000000000000000000000000001",
+		Extra:   map[string]interface{}{"name": "Metered Token", "version": "1"},
+	}
+}
+
+func TestUpToEvmSchemeCreatePaymentPayload(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testUpToKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	scheme := NewUpToEvmScheme(signer)
+	if scheme.Scheme() != "upto" {
+		t.Fatalf("Expected scheme upto, got %s", scheme.Scheme())
+	}
+
+	requirements := testUpToRequirements("5000")
+	payload, err := scheme.CreatePaymentPayload(context.Background(), requirements)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	if payload.X402Version != 2 {
+		t.Errorf("Expected x402Version 2, got %d", payload.X402Version)
+	}
+
+	upTo, err := evm.UpToPayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("UpToPayloadFromMap failed: %v", err)
+	}
+	if upTo.MaxAmount != "5000" || upTo.Value != "5000" || upTo.Authorization.Value != "5000" {
+		t.Errorf("Expected maxAmount, value and authorization value 5000, got %s, %s, %s",
+			upTo.MaxAmount, upTo.Value, upTo.Authorization.Value)
+	}
+	if err := upTo.Validate(); err != nil {
+		t.Errorf("Expected a valid upto payload, got %v", err)
+	}
+
+	// The signature authorizes maxAmount, so lowering value keeps it valid
+	if err := upTo.SetValue("1200"); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	payer, err := evm.RecoverPayer(types.PaymentPayload{Payload: upTo.ToMap()}, requirements)
+	if err != nil {
+		t.Fatalf("RecoverPayer failed: %v", err)
+	}
+	if payer != signer.Address() {
+		t.Errorf("Expected payer %s, got %s", signer.Address(), payer)
+	}
+}
+
+func TestUpToEvmSchemeInvalidMaxAmount(t *testing.T) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(testUpToKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	_, err = NewUpToEvmScheme(signer).CreatePaymentPayload(context.Background(), testUpToRequirements("0"))
+	if err == nil || !strings.Contains(err.Error(), ErrFailedToCreateAuthorization) {
+		t.Errorf("Expected %s, got %v", ErrFailedToCreateAuthorization, err)
+	}
+}
+
+// deployedSmartWallet is a deployed smart wallet whose owner key signs 65-byte signatures
+type deployedSmartWallet struct {
+	evm.ClientEvmSigner
+}
+
+func (deployedSmartWallet) Deployment(ctx context.Context) (*evm.SmartWalletDeployment, error) {
+	return nil, nil
+}
+
+func TestUpToEvmSchemeSmartWalletSignatureType(t *testing.T) {
+	owner, err := evmsigners.NewClientSignerFromPrivateKey(testUpToKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	payload, err := NewUpToEvmScheme(deployedSmartWallet{owner}).CreatePaymentPayload(context.Background(), testUpToRequirements("5000"))
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+
+	upTo, err := evm.UpToPayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("UpToPayloadFromMap failed: %v", err)
+	}
+	if upTo.SignatureType != evm.SignatureTypeBytes {
+		t.Errorf("Expected signature type %s, got %q", evm.SignatureTypeBytes, upTo.SignatureType)
+	}
+
+	// The facilitator reads the payload as an exact one and must use the bytes overload
+	exact, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("PayloadFromMap failed: %v", err)
+	}
+	signature, err := evm.HexToBytes(exact.Signature)
+	if err != nil || len(signature) != 65 {
+		t.Fatalf("Expected a 65-byte signature, got %s (%v)", exact.Signature, err)
+	}
+	if exact.UseVRSSignature(signature) {
+		t.Error("Expected a smart wallet signature not to be submitted as v,r,s")
+	}
+}
@@ -0,0 +1,95 @@
+package evm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpToPayloadValidate(t *testing.T) {
+	valid := UpToEIP3009Payload{
+		Authorization: ExactEIP3009Authorization{Value: "1000"},
+		MaxAmount:     "1000",
+		Value:         "250",
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(p *UpToEIP3009Payload)
+		wantErr string
+	}{
+		{name: "value below max", mutate: func(p *UpToEIP3009Payload) {}},
+		{name: "value equals max", mutate: func(p *UpToEIP3009Payload) { p.Value = "1000" }},
+		{name: "value above max", mutate: func(p *UpToEIP3009Payload) { p.Value = "1001" }, wantErr: "exceeds maxAmount"},
+		{name: "zero value", mutate: func(p *UpToEIP3009Payload) { p.Value = "0" }, wantErr: "invalid upto value"},
+		{name: "missing value", mutate: func(p *UpToEIP3009Payload) { p.Value = "" }, wantErr: "invalid upto value"},
+		{name: "missing maxAmount", mutate: func(p *UpToEIP3009Payload) { p.MaxAmount = "" }, wantErr: "invalid upto maxAmount"},
+		{
+			name:    "authorization not for maxAmount",
+			mutate:  func(p *UpToEIP3009Payload) { p.Authorization.Value = "250" },
+			wantErr: "does not match maxAmount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.mutate(&p)
+			err := p.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUpToPayloadSetValue(t *testing.T) {
+	p := UpToEIP3009Payload{MaxAmount: "1000", Value: "1000"}
+
+	if err := p.SetValue("400"); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	if p.Value != "400" {
+		t.Errorf("Expected value 400, got %s", p.Value)
+	}
+
+	if err := p.SetValue("1500"); err == nil {
+		t.Error("Expected SetValue above maxAmount to fail")
+	}
+	if p.Value != "400" {
+		t.Errorf("Expected a rejected value to leave value 400, got %s", p.Value)
+	}
+}
+
+func TestUpToPayloadMapRoundTrip(t *testing.T) {
+	original := &UpToEIP3009Payload{
+		Signature: "0xabcd",
+		Authorization: ExactEIP3009Authorization{
+			From:  "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+			To:    "0x0000000000000000000000000000000000000001",
+			Value: "1000",
+			Nonce: "0x01",
+		},
+		MaxAmount:     "1000",
+		Value:         "600",
+		SignatureType: SignatureTypeBytes,
+	}
+
+	data := original.ToMap()
+	if IsPermitPayload(data) || IsPermit2Payload(data) {
+		t.Error("Expected an upto payload not to look like a permit payload")
+	}
+
+	decoded, err := UpToPayloadFromMap(data)
+	if err != nil {
+		t.Fatalf("UpToPayloadFromMap failed: %v", err)
+	}
+	if *decoded != *original {
+		t.Errorf("Expected %+v, got %+v", *original, *decoded)
+	}
+}
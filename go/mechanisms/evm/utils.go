@@ -1,40 +1,34 @@
 package evm
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // GetEvmChainId returns the chain ID for a given network
+// Unknown networks return an *UnsupportedNetworkError listing the closest registered keys.
 func GetEvmChainId(network string) (*big.Int, error) {
-	networkStr := network
-
-	// Normalize network name
-	switch networkStr {
-	case "base", "base-mainnet":
-		networkStr = "eip155:8453"
-	case "base-sepolia":
-		networkStr = "eip155:84532"
-	}
+	networkStr := NormalizeNetwork(network)
 
-	if config, ok := NetworkConfigs[networkStr]; ok {
+	if config, ok := lookupNetwork(networkStr); ok {
 		return config.ChainID, nil
 	}
 
 	// Try to parse from CAIP-2 format (eip155:chainId)
-	if strings.HasPrefix(networkStr, "eip155:") {
-		chainIdStr := strings.TrimPrefix(networkStr, "eip155:")
-		chainId, ok := new(big.Int).SetString(chainIdStr, 10)
-		if ok {
-			return chainId, nil
-		}
+	if chainId, ok := parseCAIP2ChainID(networkStr); ok {
+		return chainId, nil
 	}
 
-	return nil, fmt.Errorf("unsupported network: %s", network)
+	return nil, newUnsupportedNetworkError(network)
 }
 
 // CreateNonce generates a random 32-byte nonce
@@ -47,6 +41,30 @@ func CreateNonce() (string, error) {
 	return "0x" + hex.EncodeToString(nonce), nil
 }
 
+// CreateDeterministicNonce derives a 32-byte nonce from an idempotency key
+// nonce = keccak256(key || from || uint256(value))
+//
+// Reusing the same key for the same payer and value yields the same nonce, so a retried
+// payload carries the same EIP-3009 nonce and the token's replay protection lets at most
+// one of them settle.
+func CreateDeterministicNonce(idempotencyKey string, from string, value *big.Int) ([32]byte, error) {
+	var nonce [32]byte
+	if idempotencyKey == "" {
+		return nonce, fmt.Errorf("idempotency key is required")
+	}
+	if !IsValidAddress(from) {
+		return nonce, fmt.Errorf("invalid from address: %s", from)
+	}
+	if value == nil || value.Sign() < 0 {
+		return nonce, fmt.Errorf("invalid value: %v", value)
+	}
+
+	data := append([]byte(idempotencyKey), common.HexToAddress(from).Bytes()...)
+	data = append(data, common.LeftPadBytes(value.Bytes(), 32)...)
+	copy(nonce[:], crypto.Keccak256(data))
+	return nonce, nil
+}
+
 // NormalizeAddress ensures an Ethereum address is in the correct format
 func NormalizeAddress(address string) string {
 	// Remove 0x prefix if present
@@ -71,6 +89,23 @@ func IsValidAddress(address string) bool {
 	return err == nil
 }
 
+// ChecksumAddress validates a hex address and returns it in EIP-55 checksum form
+//
+// Unlike common.HexToAddress, which silently truncates or zero-pads malformed input,
+// anything other than 0x followed by 40 hex characters is rejected.
+func ChecksumAddress(address string) (string, error) {
+	if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return "", fmt.Errorf("invalid address %q: missing 0x prefix", address)
+	}
+	if len(address) != 42 {
+		return "", fmt.Errorf("invalid address %q: expected 20 bytes (40 hex characters)", address)
+	}
+	if _, err := hex.DecodeString(address[2:]); err != nil {
+		return "", fmt.Errorf("invalid address %q: not hex", address)
+	}
+	return common.HexToAddress(address).Hex(), nil
+}
+
 // ParseAmount converts a decimal string amount to wei based on token decimals
 func ParseAmount(amount string, decimals int) (*big.Int, error) {
 	// Parse the decimal amount
@@ -144,40 +179,31 @@ func FormatAmount(amount *big.Int, decimals int) string {
 //
 // Returns:
 //   - NetworkConfig with chain ID (and default asset if configured)
-//   - Error if the network format is invalid
+//   - *UnsupportedNetworkError if the network is not known and not in eip155:CHAIN_ID format
 func GetNetworkConfig(network string) (*NetworkConfig, error) {
-	networkStr := network
-
-	// Normalize network name
-	switch networkStr {
-	case "base", "base-mainnet":
-		networkStr = "eip155:8453"
-	case "base-sepolia":
-		networkStr = "eip155:84532"
-	}
+	networkStr := NormalizeNetwork(network)
 
-	// Check if we have a pre-configured network with default asset
-	if config, ok := NetworkConfigs[networkStr]; ok {
+	// Check if we have a pre-configured or registered network with default asset
+	if config, ok := lookupNetwork(networkStr); ok {
 		return &config, nil
 	}
 
 	// For any valid EIP-155 network, dynamically create a config with just the chain ID
-	if strings.HasPrefix(networkStr, "eip155:") {
-		chainIdStr := strings.TrimPrefix(networkStr, "eip155:")
-		chainId, ok := new(big.Int).SetString(chainIdStr, 10)
-		if ok {
-			return &NetworkConfig{
-				ChainID: chainId,
-				// No DefaultAsset - callers using TokenAsset don't need it
-			}, nil
-		}
+	if chainId, ok := parseCAIP2ChainID(networkStr); ok {
+		return &NetworkConfig{
+			ChainID: chainId,
+			// No DefaultAsset - callers using TokenAsset don't need it
+		}, nil
 	}
 
-	return nil, fmt.Errorf("invalid network format: %s (expected eip155:CHAIN_ID)", network)
+	return nil, newUnsupportedNetworkError(network)
 }
 
 // GetAssetInfo returns information about an asset on a network.
-// If assetSymbolOrAddress is a valid address, returns info for that specific token.
+// If assetSymbolOrAddress is a valid address, returns info for that specific token,
+// taken from the registered or configured assets (NetworkConfig.Assets and DefaultAsset) when known.
+// Unknown addresses are looked up through the network's AssetResolver, if one is registered,
+// and otherwise described by an "Unknown Token" placeholder with 18 decimals.
 // If assetSymbolOrAddress is empty or a symbol, attempts to use the network's default asset.
 //
 // Args:
@@ -186,41 +212,137 @@ func GetNetworkConfig(network string) (*NetworkConfig, error) {
 //
 // Returns:
 //   - AssetInfo for the requested asset
-//   - Error if default asset is requested but not configured for this network
+//   - *UnsupportedNetworkError if the network is unknown, or *UnsupportedAssetError if the
+//     default asset is requested but not configured for this network
 func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, error) {
+	return GetAssetInfoContext(context.Background(), network, assetSymbolOrAddress)
+}
+
+// GetAssetInfoContext is GetAssetInfo with a context for the AssetResolver lookup
+func GetAssetInfoContext(ctx context.Context, network string, assetSymbolOrAddress string) (*AssetInfo, error) {
+	info, known, err := lookupAssetInfo(ctx, network, assetSymbolOrAddress)
+	if err != nil || known {
+		return info, err
+	}
+
+	// Unknown token - return basic info (works for any EVM network)
+	return &AssetInfo{
+		Address:  NormalizeAddress(assetSymbolOrAddress),
+		Name:     "Unknown Token",
+		Version:  "1",
+		Decimals: 18, // Default to 18 decimals for unknown tokens
+	}, nil
+}
+
+// lookupAssetInfo is GetAssetInfoContext without the placeholder: known is false, with a nil
+// info, for an address that is neither registered, configured nor resolved
+func lookupAssetInfo(ctx context.Context, network string, assetSymbolOrAddress string) (_ *AssetInfo, known bool, _ error) {
 	// Check if it's an explicit address - works for ANY network
 	if IsValidAddress(assetSymbolOrAddress) {
 		normalizedAddr := NormalizeAddress(assetSymbolOrAddress)
 
-		// Check if this matches a known default asset for richer metadata
-		config, err := GetNetworkConfig(network)
-		if err == nil && config.DefaultAsset.Address != "" {
-			if normalizedAddr == NormalizeAddress(config.DefaultAsset.Address) {
-				return &config.DefaultAsset, nil
+		// Registered assets carry explicit metadata
+		if info, ok := lookupAsset(network, normalizedAddr); ok {
+			return &info, true, nil
+		}
+
+		// Check if this matches one of the network's configured assets for richer metadata
+		if config, err := GetNetworkConfig(network); err == nil {
+			if info, ok := config.asset(normalizedAddr); ok {
+				return &info, true, nil
 			}
 		}
 
-		// Unknown token - return basic info (works for any EVM network)
-		return &AssetInfo{
-			Address:  normalizedAddr,
-			Name:     "Unknown Token",
-			Version:  "1",
-			Decimals: 18, // Default to 18 decimals for unknown tokens
-		}, nil
+		// Unknown token - ask the chain, if a resolver is registered for the network
+		if info, ok := resolveAsset(ctx, network, normalizedAddr); ok {
+			return info, true, nil
+		}
+		return nil, false, nil
 	}
 
 	// Not an explicit address - need the network's default asset
 	config, err := GetNetworkConfig(network)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Check if default asset is configured
 	if config.DefaultAsset.Address == "" {
-		return nil, fmt.Errorf("no default asset configured for network %s; specify an explicit asset address or register a money parser", network)
+		return nil, false, newUnsupportedAssetError(network, assetSymbolOrAddress)
+	}
+
+	return &config.DefaultAsset, true, nil
+}
+
+// ListAssets returns the known assets of a network: the default asset first, then the
+// configured and registered assets sorted by address. Registered metadata takes precedence.
+//
+// Returns an error if the network format is invalid.
+func ListAssets(network string) ([]AssetInfo, error) {
+	config, err := GetNetworkConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := map[string]AssetInfo{}
+	for address, info := range config.Assets {
+		byAddress[NormalizeAddress(address)] = withAssetAddress(info, address)
+	}
+	for _, info := range lookupAssets(network) {
+		byAddress[NormalizeAddress(info.Address)] = info
+	}
+
+	var assets []AssetInfo
+	if config.DefaultAsset.Address != "" {
+		defaultAddr := NormalizeAddress(config.DefaultAsset.Address)
+		if info, ok := byAddress[defaultAddr]; ok {
+			assets = append(assets, info)
+		} else {
+			assets = append(assets, config.DefaultAsset)
+		}
+		delete(byAddress, defaultAddr)
+	}
+
+	addresses := make([]string, 0, len(byAddress))
+	for address := range byAddress {
+		addresses = append(addresses, address)
 	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		assets = append(assets, byAddress[address])
+	}
+	return assets, nil
+}
 
-	return &config.DefaultAsset, nil
+// asset returns the configured asset with the given normalized address, checking
+// DefaultAsset and Assets
+func (c NetworkConfig) asset(normalizedAddress string) (AssetInfo, bool) {
+	if c.DefaultAsset.Address != "" && NormalizeAddress(c.DefaultAsset.Address) == normalizedAddress {
+		return c.DefaultAsset, true
+	}
+	for address, info := range c.Assets {
+		if NormalizeAddress(address) == normalizedAddress {
+			return withAssetAddress(info, address), true
+		}
+	}
+	return AssetInfo{}, false
+}
+
+// withAssetAddress fills in the address of an Assets entry that omits it
+func withAssetAddress(info AssetInfo, address string) AssetInfo {
+	if info.Address == "" {
+		info.Address = NormalizeAddress(address)
+	}
+	return info
+}
+
+// GetPermit2Address returns the Permit2 contract for a network
+// Uses NetworkConfig.Permit2Address when set, otherwise the canonical deployment.
+func GetPermit2Address(network string) string {
+	if config, err := GetNetworkConfig(network); err == nil && config.Permit2Address != "" {
+		return config.Permit2Address
+	}
+	return CanonicalPermit2Address
 }
 
 // CreateValidityWindow creates valid after/before timestamps
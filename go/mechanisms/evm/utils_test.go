@@ -0,0 +1,38 @@
+package evm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+	tests := []struct {
+		name    string
+		address string
+		want    string
+		wantErr bool
+	}{
+		{"checksummed", checksummed, checksummed, false},
+		{"lowercase", strings.ToLower(checksummed), checksummed, false},
+		{"uppercase hex", "0x" + strings.ToUpper(checksummed[2:]), checksummed, false},
+		{"invalid hex", "0xzz" + strings.Repeat("0", 38), "", true},
+		{"short", "0x1234", "", true},
+		{"long", "0x" + strings.Repeat("0", 42), "", true},
+		{"missing prefix", checksummed[2:], "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChecksumAddress(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChecksumAddress(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ChecksumAddress(%q) = %s, want %s", tt.address, got, tt.want)
+			}
+		})
+	}
+}
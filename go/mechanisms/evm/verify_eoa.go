@@ -1,12 +1,20 @@
 package evm
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
 // VerifyEOASignature verifies an ECDSA signature from an externally owned account (EOA)
 //
 // This function uses secp256k1 public key recovery to verify that the signature
@@ -28,8 +36,20 @@ func VerifyEOASignature(
 	signature []byte,
 	expectedAddress common.Address,
 ) (bool, error) {
+	recoveredAddress, err := RecoverEOAAddress(hash, signature)
+	if err != nil {
+		return false, err
+	}
+
+	// Compare the recovered address with the expected address
+	return recoveredAddress == expectedAddress, nil
+}
+
+// RecoverEOAAddress recovers the address that produced a 65-byte ECDSA signature over hash
+// Both v = 27/28 and v = 0/1 are accepted.
+func RecoverEOAAddress(hash []byte, signature []byte) (common.Address, error) {
 	if len(signature) != 65 {
-		return false, errors.New("invalid EOA signature length: expected 65 bytes")
+		return common.Address{}, errors.New("invalid EOA signature length: expected 65 bytes")
 	}
 
 	// Create a copy to avoid modifying the original signature
@@ -46,12 +66,80 @@ func VerifyEOASignature(
 	// Recover the public key from the signature
 	pubKey, err := crypto.SigToPub(hash, sig)
 	if err != nil {
-		return false, err
+		return common.Address{}, err
 	}
 
 	// Derive the Ethereum address from the recovered public key
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey)
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
 
-	// Compare the recovered address with the expected address
-	return recoveredAddress == expectedAddress, nil
+// NormalizeSignature returns a 65-byte ECDSA signature in the canonical form tokens accept:
+// s in the lower half of the curve order (EIP-2) and v as 27 or 28. A high-S signature is
+// replaced by the equivalent (r, n-s) with the recovery bit flipped, which recovers to the
+// same address. The input is not modified.
+func NormalizeSignature(signature []byte) ([]byte, error) {
+	if len(signature) != 65 {
+		return nil, errors.New("invalid EOA signature length: expected 65 bytes")
+	}
+
+	v := signature[64]
+	switch v {
+	case 0, 1:
+	case 27, 28:
+		v -= 27
+	default:
+		return nil, fmt.Errorf("invalid signature v value: %d", signature[64])
+	}
+
+	s := new(big.Int).SetBytes(signature[32:64])
+	normalized := make([]byte, 65)
+	copy(normalized, signature[:32])
+	if s.Cmp(secp256k1HalfN) > 0 && s.Cmp(secp256k1N) < 0 {
+		s.Sub(secp256k1N, s)
+		v ^= 1
+	}
+	s.FillBytes(normalized[32:64])
+	normalized[64] = v + 27
+	return normalized, nil
+}
+
+// Recovery ID offsets of the v byte of 65-byte ECDSA signatures
+const (
+	// VOffsetEthereum is the v = 27 or 28 convention of Ethereum and NormalizeSignature
+	VOffsetEthereum byte = 27
+	// VOffsetRaw is the v = 0 or 1 convention some chains and wallets expect
+	VOffsetRaw byte = 0
+)
+
+// WithVOffset returns a copy of a 65-byte ECDSA signature with v expressed with offset
+// (VOffsetEthereum or VOffsetRaw). Both conventions recover to the same address.
+func WithVOffset(signature []byte, offset byte) ([]byte, error) {
+	if len(signature) != 65 {
+		return nil, errors.New("invalid EOA signature length: expected 65 bytes")
+	}
+	if offset != VOffsetEthereum && offset != VOffsetRaw {
+		return nil, fmt.Errorf("invalid v offset %d: must be %d or %d", offset, VOffsetRaw, VOffsetEthereum)
+	}
+
+	recoveryID := signature[64]
+	if recoveryID >= VOffsetEthereum {
+		recoveryID -= VOffsetEthereum
+	}
+	if recoveryID > 1 {
+		return nil, fmt.Errorf("invalid signature v value: %d", signature[64])
+	}
+
+	converted := append([]byte(nil), signature...)
+	converted[64] = recoveryID + offset
+	return converted, nil
+}
+
+// SignDigestNormalized signs digest with signer and normalizes 65-byte ECDSA signatures
+// with NormalizeSignature. Other signatures (e.g. smart wallet formats) are returned as is.
+func SignDigestNormalized(ctx context.Context, signer DigestSigner, digest []byte) ([]byte, error) {
+	signature, err := signer.SignDigest(ctx, digest)
+	if err != nil || len(signature) != 65 {
+		return signature, err
+	}
+	return NormalizeSignature(signature)
 }
@@ -1,6 +1,8 @@
 package evm
 
 import (
+	"bytes"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -141,3 +143,104 @@ func TestVerifyEOASignature_VValueAdjustment(t *testing.T) {
 		}
 	})
 }
+
+// highS returns the high-S twin of a low-S 65-byte signature with v = 27/28
+func highS(sig []byte) []byte {
+	twin := append([]byte(nil), sig...)
+	s := new(big.Int).SetBytes(sig[32:64])
+	new(big.Int).Sub(secp256k1N, s).FillBytes(twin[32:64])
+	twin[64] = 27 + ((sig[64] - 27) ^ 1)
+	return twin
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	key, err := crypto.HexToECDSA("0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("failed to load key: %v", err)
+	}
+	hash := crypto.Keccak256([]byte("test message"))
+	raw, err := crypto.Sign(hash, key) // Low-S with v = 0/1
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	canonical := append([]byte(nil), raw...)
+	canonical[64] += 27
+
+	tests := []struct {
+		name      string
+		signature []byte
+		wantErr   bool
+	}{
+		{name: "canonical", signature: canonical},
+		{name: "v as 0/1", signature: raw},
+		{name: "high S", signature: highS(canonical)},
+		{name: "high S with v as 0/1", signature: func() []byte { sig := highS(canonical); sig[64] -= 27; return sig }()},
+		{name: "short", signature: canonical[:64], wantErr: true},
+		{name: "invalid v", signature: append(append([]byte(nil), canonical[:64]...), 29), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]byte(nil), tt.signature...)
+			got, err := NormalizeSignature(tt.signature)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %x", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeSignature failed: %v", err)
+			}
+			if !bytes.Equal(got, canonical) {
+				t.Errorf("Expected %x, got %x", canonical, got)
+			}
+			if !bytes.Equal(tt.signature, original) {
+				t.Error("Expected the input signature to be left unmodified")
+			}
+			if addr, err := RecoverEOAAddress(hash, got); err != nil || addr != crypto.PubkeyToAddress(key.PublicKey) {
+				t.Errorf("Expected the normalized signature to recover to the signer, got %s %v", addr.Hex(), err)
+			}
+		})
+	}
+}
+
+func TestWithVOffset(t *testing.T) {
+	key, err := crypto.HexToECDSA("0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("failed to load key: %v", err)
+	}
+	hash := crypto.Keccak256([]byte("test message"))
+	raw, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	ethereum := append([]byte(nil), raw...)
+	ethereum[64] += 27
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	for _, input := range [][]byte{raw, ethereum} {
+		for offset, want := range map[byte][]byte{VOffsetRaw: raw, VOffsetEthereum: ethereum} {
+			got, err := WithVOffset(input, offset)
+			if err != nil {
+				t.Fatalf("WithVOffset(%d) failed: %v", offset, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("WithVOffset(v=%d, %d): expected %x, got %x", input[64], offset, want, got)
+			}
+			if ok, err := VerifyEOASignature(hash, got, signer); !ok || err != nil {
+				t.Errorf("Expected v=%d to recover the signer (%v)", got[64], err)
+			}
+		}
+	}
+
+	if _, err := WithVOffset(raw, 1); err == nil {
+		t.Error("Expected an error for offset 1")
+	}
+	if _, err := WithVOffset(append(append([]byte(nil), raw[:64]...), 29), VOffsetRaw); err == nil {
+		t.Error("Expected an error for v = 29")
+	}
+	if _, err := WithVOffset(raw[:64], VOffsetRaw); err == nil {
+		t.Error("Expected an error for a short signature")
+	}
+}
@@ -0,0 +1,382 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+// ContractCaller is the subset of *ethclient.Client needed for on-chain signature checks
+type ContractCaller interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// ErrPayerNotRecoverable is returned by RecoverPayer for smart wallet signatures, whose
+// signer cannot be recovered from the signature (verify them with VerifyExactEvmPayload)
+var ErrPayerNotRecoverable = errors.New("payer cannot be recovered from a smart wallet signature")
+
+// ExactEvmVerification is the outcome of VerifyExactEvmPayload
+type ExactEvmVerification struct {
+	// Valid is true when the signature is valid for authorization.From and the window is open
+	Valid bool
+
+	// Reason is the error code when Valid is false: ErrInvalidSignature, ErrAuthorizationExpired,
+	// ErrAuthorizationNotYetValid, ErrUndeployedSmartWallet or ErrNonceAlreadyUsed
+	Reason string
+
+	// NonceChecked is true when authorizationState was read from the token.
+	// It stays false without chain access or when the token does not implement it.
+	NonceChecked bool
+
+	// SignatureData holds the parsed (possibly ERC-6492 wrapped) signature.
+	// For ErrUndeployedSmartWallet it carries the factory data needed to deploy the wallet.
+	SignatureData *ERC6492SignatureData
+}
+
+// VerifyExactEvmPayload checks the signature and validity window of an EIP-3009 payload
+//
+// The EIP-712 digest is built from the token's on-chain DOMAIN_SEPARATOR when ethClient
+// is set, falling back to the token name/version (requirements.Extra overrides the
+// asset defaults). EOA signatures are recovered and compared with authorization.From;
+// deployed smart wallets are checked with EIP-1271 isValidSignature. With ethClient set,
+// a valid signature is also checked against authorizationState so replays are rejected.
+//
+// Args:
+//
+//	ctx: Context for RPC calls
+//	payload: The EIP-3009 payload to verify
+//	requirements: The payment requirements the payload was created for
+//	ethClient: Chain access for DOMAIN_SEPARATOR, deployment and EIP-1271 checks (optional for EOAs)
+//
+// Returns:
+//
+//	ExactEvmVerification with Valid or the failure Reason
+//	error if the payload is malformed or a chain call fails
+func VerifyExactEvmPayload(
+	ctx context.Context,
+	payload *ExactEIP3009Payload,
+	requirements types.PaymentRequirements,
+	ethClient ContractCaller,
+) (*ExactEvmVerification, error) {
+	if payload == nil {
+		return nil, fmt.Errorf("payload is required")
+	}
+	authorization := payload.Authorization
+
+	// Validity window: validAfter <= now < validBefore
+	validAfter, ok := new(big.Int).SetString(authorization.ValidAfter, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid validAfter: %s", authorization.ValidAfter)
+	}
+	validBefore, ok := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid validBefore: %s", authorization.ValidBefore)
+	}
+	now := big.NewInt(time.Now().Unix())
+	if validBefore.Cmp(now) <= 0 {
+		return &ExactEvmVerification{Reason: ErrAuthorizationExpired}, nil
+	}
+	if validAfter.Cmp(now) > 0 {
+		return &ExactEvmVerification{Reason: ErrAuthorizationNotYetValid}, nil
+	}
+
+	signature, err := HexToBytes(payload.Signature)
+	if err != nil || len(signature) == 0 {
+		return &ExactEvmVerification{Reason: ErrInvalidSignature}, nil
+	}
+
+	hash, err := exactEvmDigest(ctx, authorization, requirements, ethClient)
+	if err != nil {
+		return nil, err
+	}
+
+	sigData, err := ParseERC6492Signature(signature)
+	if err != nil {
+		return &ExactEvmVerification{Reason: ErrInvalidSignature}, nil
+	}
+
+	result := &ExactEvmVerification{SignatureData: sigData}
+	from := common.HexToAddress(authorization.From)
+	zeroFactory := [20]byte{}
+
+	// Plain 65-byte signatures are checked as EOA signatures
+	if len(sigData.InnerSignature) == 65 && sigData.Factory == zeroFactory {
+		valid, err := VerifyEOASignature(hash, sigData.InnerSignature, from)
+		if err != nil || !valid {
+			result.Reason = ErrInvalidSignature
+			return result, nil
+		}
+		return checkExactEvmNonce(ctx, result, authorization, requirements, ethClient)
+	}
+
+	if ethClient == nil {
+		return nil, fmt.Errorf("chain access is required to verify smart wallet signatures")
+	}
+
+	code, err := ethClient.CodeAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code for %s: %w", authorization.From, err)
+	}
+
+	if len(code) == 0 {
+		if sigData.Factory != zeroFactory && len(sigData.FactoryCalldata) > 0 {
+			result.Reason = ErrUndeployedSmartWallet
+		} else {
+			result.Reason = ErrInvalidSignature
+		}
+		return result, nil
+	}
+
+	valid, err := callIsValidSignature(ctx, ethClient, from, hash, sigData.InnerSignature)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		result.Reason = ErrInvalidSignature
+		return result, nil
+	}
+	return checkExactEvmNonce(ctx, result, authorization, requirements, ethClient)
+}
+
+// RecoverPayer returns the checksummed address that signed an EIP-3009 payload
+//
+// The EIP-712 digest is built offline from the token name/version, like VerifyExactEvmPayload
+// without chain access. Only the signature is checked: compare the result with
+// authorization.From, or use VerifyExactEvmPayload, before trusting the payment.
+//
+// Returns ErrPayerNotRecoverable for smart wallet (non-65-byte or ERC-6492) signatures.
+func RecoverPayer(payload types.PaymentPayload, requirements types.PaymentRequirements) (string, error) {
+	evmPayload, err := ParseExactPayload(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid exact EVM payload: %w", err)
+	}
+
+	signature, err := HexToBytes(evmPayload.Signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	sigData, err := ParseERC6492Signature(signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	if len(sigData.InnerSignature) != 65 || sigData.Factory != ([20]byte{}) {
+		return "", ErrPayerNotRecoverable
+	}
+
+	hash, err := exactEvmDigest(context.Background(), evmPayload.Authorization, requirements, nil)
+	if err != nil {
+		return "", err
+	}
+
+	payer, err := RecoverEOAAddress(hash, sigData.InnerSignature)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover payer: %w", err)
+	}
+	return payer.Hex(), nil
+}
+
+// checkExactEvmNonce marks a signature-valid result as valid unless its nonce was already used
+func checkExactEvmNonce(
+	ctx context.Context,
+	result *ExactEvmVerification,
+	authorization ExactEIP3009Authorization,
+	requirements types.PaymentRequirements,
+	ethClient ContractCaller,
+) (*ExactEvmVerification, error) {
+	if ethClient != nil {
+		assetInfo, err := GetAssetInfo(requirements.Network, requirements.Asset)
+		if err != nil {
+			return nil, err
+		}
+
+		used, checked, err := QueryAuthorizationState(ctx, ethClient, assetInfo.Address, authorization.From, authorization.Nonce)
+		if err != nil {
+			return nil, err
+		}
+		result.NonceChecked = checked
+		if used {
+			result.Reason = ErrNonceAlreadyUsed
+			return result, nil
+		}
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+// exactEvmDigest builds the EIP-712 digest the payer signed
+func exactEvmDigest(
+	ctx context.Context,
+	authorization ExactEIP3009Authorization,
+	requirements types.PaymentRequirements,
+	ethClient ContractCaller,
+) ([]byte, error) {
+	chainID, err := GetEvmChainId(requirements.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	assetInfo, err := GetAssetInfo(requirements.Network, requirements.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if ethClient != nil {
+		if domainSeparator, err := QueryDomainSeparator(ctx, ethClient, assetInfo.Address, assetInfo.DomainSeparatorMethod); err == nil {
+			return HashEIP3009AuthorizationWithDomainSeparator(authorization, domainSeparator)
+		}
+	}
+
+	domain, err := ExactDomain(chainID, assetInfo, requirements.Extra)
+	if err != nil {
+		return nil, err
+	}
+	return HashEIP3009AuthorizationWithDomain(authorization, domain)
+}
+
+// QueryDomainSeparator reads the EIP-712 domain separator of a token contract
+// The view functions in methods (e.g. AssetInfo.DomainSeparatorMethod) are tried first,
+// then DomainSeparatorMethods, and the first one returning 32 bytes wins.
+func QueryDomainSeparator(ctx context.Context, ethClient ContractCaller, tokenAddress string, methods ...string) ([]byte, error) {
+	addr := common.HexToAddress(tokenAddress)
+	tried := make(map[string]bool)
+	var lastErr error
+	candidates := append(append([]string(nil), methods...), DomainSeparatorMethods...)
+	for _, method := range candidates {
+		if method == "" || tried[method] {
+			continue
+		}
+		tried[method] = true
+
+		result, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+			To:   &addr,
+			Data: crypto.Keccak256([]byte(method + "()"))[:4],
+		}, nil)
+		if err != nil {
+			lastErr = fmt.Errorf("%s(): %w", method, err)
+			continue
+		}
+		if len(result) < 32 {
+			lastErr = fmt.Errorf("invalid %s() result length: %d", method, len(result))
+			continue
+		}
+		return result[:32], nil
+	}
+	return nil, lastErr
+}
+
+// QueryTokenNameVersion reads name() and version() from a token contract, the values its
+// EIP-712 domain is usually built from
+func QueryTokenNameVersion(ctx context.Context, ethClient ContractCaller, tokenAddress string) (string, string, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(TokenMetadataABI))
+	if err != nil {
+		return "", "", err
+	}
+
+	addr := common.HexToAddress(tokenAddress)
+	values := make([]string, 0, 2)
+	for _, method := range []string{FunctionName, FunctionVersion} {
+		result, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+			To:   &addr,
+			Data: contractABI.Methods[method].ID,
+		}, nil)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to query %s(): %w", method, err)
+		}
+
+		var value string
+		if err := contractABI.UnpackIntoInterface(&value, method, result); err != nil {
+			return "", "", fmt.Errorf("invalid %s() result: %w", method, err)
+		}
+		values = append(values, value)
+	}
+	return values[0], values[1], nil
+}
+
+// QueryAuthorizationState reads EIP-3009 authorizationState(authorizer, nonce) from a token contract
+//
+// Returns:
+//
+//	used: true if the nonce has already been consumed
+//	checked: false if the token does not implement authorizationState (the call reverts or returns no data)
+//	error if the nonce is malformed or the chain call fails
+func QueryAuthorizationState(
+	ctx context.Context,
+	ethClient ContractCaller,
+	tokenAddress string,
+	authorizer string,
+	nonce string,
+) (used bool, checked bool, err error) {
+	nonceBytes, err := HexToBytes(nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return false, false, fmt.Errorf("invalid nonce: %s", nonce)
+	}
+
+	contractABI, err := abi.JSON(bytes.NewReader(AuthorizationStateABI))
+	if err != nil {
+		return false, false, err
+	}
+
+	callData, err := contractABI.Pack(FunctionAuthorizationState, common.HexToAddress(authorizer), [32]byte(nonceBytes))
+	if err != nil {
+		return false, false, err
+	}
+
+	addr := common.HexToAddress(tokenAddress)
+	result, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &addr,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "revert") {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to call authorizationState on %s: %w", tokenAddress, err)
+	}
+	if len(result) < 32 {
+		return false, false, nil
+	}
+
+	return new(big.Int).SetBytes(result[:32]).Sign() != 0, true, nil
+}
+
+// callIsValidSignature calls EIP-1271 isValidSignature on a deployed wallet
+func callIsValidSignature(ctx context.Context, ethClient ContractCaller, wallet common.Address, hash []byte, signature []byte) (bool, error) {
+	contractABI, err := abi.JSON(strings.NewReader(eip1271ABI))
+	if err != nil {
+		return false, err
+	}
+
+	var hash32 [32]byte
+	copy(hash32[:], hash)
+	callData, err := contractABI.Pack("isValidSignature", hash32, signature)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &wallet,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		// Wallets may revert instead of returning a non-magic value
+		if strings.Contains(err.Error(), "revert") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to call isValidSignature on %s: %w", wallet.Hex(), err)
+	}
+
+	return len(result) >= 4 && bytes.Equal(result[:4], common.FromHex(EIP1271MagicValue)), nil
+}
@@ -0,0 +1,442 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gatechain/x402/go/types"
+)
+
+// mockContractCaller serves code, DOMAIN_SEPARATOR, authorizationState, decimals and isValidSignature results.
+// A nil result makes the call revert.
+type mockContractCaller struct {
+	code               []byte
+	domainSeparator    []byte
+	authorizationState []byte
+	decimals           []byte
+	isValidResult      []byte
+	isValidError       error
+}
+
+func (m *mockContractCaller) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return m.code, nil
+}
+
+func (m *mockContractCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	switch {
+	case bytes.HasPrefix(call.Data, crypto.Keccak256([]byte("DOMAIN_SEPARATOR()"))[:4]):
+		result = m.domainSeparator
+	case bytes.HasPrefix(call.Data, crypto.Keccak256([]byte("authorizationState(address,bytes32)"))[:4]):
+		result = m.authorizationState
+	case bytes.HasPrefix(call.Data, crypto.Keccak256([]byte("decimals()"))[:4]):
+		result = m.decimals
+	default:
+		if m.isValidError != nil {
+			return nil, m.isValidError
+		}
+		result = m.isValidResult
+	}
+	if result == nil {
+		return nil, errors.New("execution reverted")
+	}
+	return result, nil
+}
+
+func testExactVerifyRequirements() types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:  SchemeExact,
+		Network: "eip155:1",
+		Asset:   testPermitToken,
+		Amount:  "1000000",
+		PayTo:   "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+		Extra:   map[string]interface{}{"name": "Permit Token", "version": "1"},
+	}
+}
+
+// signedExactPayload signs an authorization from privkey 1 with the name/version domain
+func signedExactPayload(t *testing.T, mutate func(*ExactEIP3009Authorization)) *ExactEIP3009Payload {
+	t.Helper()
+	key, err := crypto.HexToECDSA("0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("Failed to load key: %v", err)
+	}
+
+	validAfter, validBefore := CreateValidityWindow(time.Hour)
+	authorization := ExactEIP3009Authorization{
+		From:        crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		To:          "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+		Value:       "1000000",
+		ValidAfter:  validAfter.String(),
+		ValidBefore: validBefore.String(),
+		Nonce:       "0x" + fmt.Sprintf("%064x", 42),
+	}
+
+	digest, err := HashEIP3009Authorization(authorization, big.NewInt(1), testPermitToken, "Permit Token", "1")
+	if err != nil {
+		t.Fatalf("Failed to hash authorization: %v", err)
+	}
+	signature, err := crypto.Sign(digest, key)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	signature[64] += 27
+
+	if mutate != nil {
+		mutate(&authorization)
+	}
+	return &ExactEIP3009Payload{Signature: BytesToHex(signature), Authorization: authorization}
+}
+
+func TestVerifyExactEvmPayload(t *testing.T) {
+	ctx := context.Background()
+	now := big.NewInt(time.Now().Unix())
+
+	tests := []struct {
+		name       string
+		payload    *ExactEIP3009Payload
+		wantValid  bool
+		wantReason string
+	}{
+		{
+			name:      "valid EOA signature",
+			payload:   signedExactPayload(t, nil),
+			wantValid: true,
+		},
+		{
+			name:       "tampered value",
+			payload:    signedExactPayload(t, func(a *ExactEIP3009Authorization) { a.Value = "2000000" }),
+			wantReason: ErrInvalidSignature,
+		},
+		{
+			name: "tampered recipient",
+			payload: signedExactPayload(t, func(a *ExactEIP3009Authorization) {
+				a.To = "0x0000000000000000000000000000000000000001"
+			}),
+			wantReason: ErrInvalidSignature,
+		},
+		{
+			name: "wrong signer",
+			payload: signedExactPayload(t, func(a *ExactEIP3009Authorization) {
+				a.From = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+			}),
+			wantReason: ErrInvalidSignature,
+		},
+		{
+			name: "expired",
+			payload: signedExactPayload(t, func(a *ExactEIP3009Authorization) {
+				a.ValidBefore = new(big.Int).Sub(now, big.NewInt(1)).String()
+			}),
+			wantReason: ErrAuthorizationExpired,
+		},
+		{
+			name: "not yet valid",
+			payload: signedExactPayload(t, func(a *ExactEIP3009Authorization) {
+				a.ValidAfter = new(big.Int).Add(now, big.NewInt(3600)).String()
+			}),
+			wantReason: ErrAuthorizationNotYetValid,
+		},
+		{
+			name: "malformed signature",
+			payload: func() *ExactEIP3009Payload {
+				p := signedExactPayload(t, nil)
+				p.Signature = "0xzz"
+				return p
+			}(),
+			wantReason: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := VerifyExactEvmPayload(ctx, tt.payload, testExactVerifyRequirements(), nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Valid != tt.wantValid {
+				t.Errorf("Expected Valid=%v, got %v (reason %q)", tt.wantValid, result.Valid, result.Reason)
+			}
+			if result.Reason != tt.wantReason {
+				t.Errorf("Expected reason %q, got %q", tt.wantReason, result.Reason)
+			}
+		})
+	}
+}
+
+func TestVerifyExactEvmPayloadOnChainDomainSeparator(t *testing.T) {
+	payload := signedExactPayload(t, nil)
+
+	domainSeparator, _ := hex.DecodeString(testPermitDomainSeparator)
+
+	// Requirements name a different version; the on-chain separator wins
+	requirements := testExactVerifyRequirements()
+	requirements.Extra["version"] = "2"
+
+	result, err := VerifyExactEvmPayload(context.Background(), payload, requirements, &mockContractCaller{domainSeparator: domainSeparator})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Valid {
+		t.Errorf("Expected valid signature with on-chain DOMAIN_SEPARATOR, got %q", result.Reason)
+	}
+
+	// Without chain access the wrong version is used and the signature no longer matches
+	result, _ = VerifyExactEvmPayload(context.Background(), payload, requirements, nil)
+	if result.Valid {
+		t.Error("Expected invalid signature with mismatched domain version")
+	}
+}
+
+func TestVerifyExactEvmPayloadSmartWallet(t *testing.T) {
+	ctx := context.Background()
+	innerSig := bytes.Repeat([]byte{0xab}, 96)
+	factory := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	wrapped := createERC6492Signature(t, factory, []byte{0x01, 0x02}, innerSig)
+
+	payloadWith := func(signature []byte) *ExactEIP3009Payload {
+		p := signedExactPayload(t, nil)
+		p.Signature = BytesToHex(signature)
+		return p
+	}
+
+	t.Run("undeployed wallet", func(t *testing.T) {
+		result, err := VerifyExactEvmPayload(ctx, payloadWith(wrapped), testExactVerifyRequirements(), &mockContractCaller{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Valid || result.Reason != ErrUndeployedSmartWallet {
+			t.Errorf("Expected %s, got valid=%v reason=%q", ErrUndeployedSmartWallet, result.Valid, result.Reason)
+		}
+		if result.SignatureData == nil || result.SignatureData.Factory != factory {
+			t.Error("Expected factory data in result")
+		}
+	})
+
+	t.Run("no code without deployment info", func(t *testing.T) {
+		result, err := VerifyExactEvmPayload(ctx, payloadWith(innerSig), testExactVerifyRequirements(), &mockContractCaller{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Reason != ErrInvalidSignature {
+			t.Errorf("Expected %s, got %q", ErrInvalidSignature, result.Reason)
+		}
+	})
+
+	t.Run("deployed wallet accepts", func(t *testing.T) {
+		caller := &mockContractCaller{code: []byte{0x60}, isValidResult: common.FromHex(EIP1271MagicValue + "00000000000000000000000000000000000000000000000000000000")}
+		result, err := VerifyExactEvmPayload(ctx, payloadWith(innerSig), testExactVerifyRequirements(), caller)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Valid {
+			t.Errorf("Expected valid EIP-1271 signature, got %q", result.Reason)
+		}
+	})
+
+	t.Run("deployed wallet rejects", func(t *testing.T) {
+		caller := &mockContractCaller{code: []byte{0x60}, isValidResult: make([]byte, 32)}
+		result, err := VerifyExactEvmPayload(ctx, payloadWith(innerSig), testExactVerifyRequirements(), caller)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Valid || result.Reason != ErrInvalidSignature {
+			t.Errorf("Expected %s, got valid=%v reason=%q", ErrInvalidSignature, result.Valid, result.Reason)
+		}
+	})
+
+	t.Run("deployed wallet reverts", func(t *testing.T) {
+		caller := &mockContractCaller{code: []byte{0x60}, isValidError: errors.New("execution reverted")}
+		result, err := VerifyExactEvmPayload(ctx, payloadWith(innerSig), testExactVerifyRequirements(), caller)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Reason != ErrInvalidSignature {
+			t.Errorf("Expected %s, got %q", ErrInvalidSignature, result.Reason)
+		}
+	})
+
+	t.Run("smart wallet without chain access", func(t *testing.T) {
+		if _, err := VerifyExactEvmPayload(ctx, payloadWith(innerSig), testExactVerifyRequirements(), nil); err == nil {
+			t.Error("Expected error without chain access")
+		}
+	})
+}
+
+// selectorCaller serves fixed results per function signature; other calls revert
+type selectorCaller map[string][]byte
+
+func (m selectorCaller) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (m selectorCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	for signature, result := range m {
+		if bytes.Equal(call.Data, crypto.Keccak256([]byte(signature))[:4]) {
+			return result, nil
+		}
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestQueryDomainSeparatorSelectors(t *testing.T) {
+	ctx := context.Background()
+	separator := bytes.Repeat([]byte{0xab}, 32)
+
+	tests := []struct {
+		name    string
+		caller  selectorCaller
+		methods []string
+		wantErr bool
+	}{
+		{name: "DOMAIN_SEPARATOR", caller: selectorCaller{"DOMAIN_SEPARATOR()": separator}},
+		{name: "getDomainSeparator", caller: selectorCaller{"getDomainSeparator()": separator}},
+		{name: "domainSeparator", caller: selectorCaller{"domainSeparator()": separator}},
+		{name: "configured method", caller: selectorCaller{"eip712Domain_separator()": separator}, methods: []string{"eip712Domain_separator"}},
+		{name: "configured method is tried first", caller: selectorCaller{"custom()": separator, "DOMAIN_SEPARATOR()": make([]byte, 32)}, methods: []string{"custom"}},
+		{name: "short result falls through", caller: selectorCaller{"DOMAIN_SEPARATOR()": {0x01}, "getDomainSeparator()": separator}},
+		{name: "unknown method", caller: selectorCaller{"eip712Domain_separator()": separator}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryDomainSeparator(ctx, tt.caller, testPermitToken, tt.methods...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %x", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, separator) {
+				t.Errorf("Expected %x, got %x", separator, got)
+			}
+		})
+	}
+}
+
+func TestQueryAuthorizationState(t *testing.T) {
+	ctx := context.Background()
+	nonce := "0x" + fmt.Sprintf("%064x", 42)
+	from := "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+
+	tests := []struct {
+		name        string
+		result      []byte
+		wantUsed    bool
+		wantChecked bool
+	}{
+		{name: "unused", result: common.LeftPadBytes([]byte{0}, 32), wantChecked: true},
+		{name: "used", result: common.LeftPadBytes([]byte{1}, 32), wantUsed: true, wantChecked: true},
+		{name: "not implemented", result: nil},
+		{name: "empty result", result: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, checked, err := QueryAuthorizationState(ctx, &mockContractCaller{authorizationState: tt.result}, testPermitToken, from, nonce)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if used != tt.wantUsed || checked != tt.wantChecked {
+				t.Errorf("Expected used=%v checked=%v, got used=%v checked=%v", tt.wantUsed, tt.wantChecked, used, checked)
+			}
+		})
+	}
+
+	if _, _, err := QueryAuthorizationState(ctx, &mockContractCaller{}, testPermitToken, from, "0x1234"); err == nil {
+		t.Error("Expected error for short nonce")
+	}
+}
+
+func TestVerifyExactEvmPayloadNonceReplay(t *testing.T) {
+	ctx := context.Background()
+	payload := signedExactPayload(t, nil)
+
+	result, err := VerifyExactEvmPayload(ctx, payload, testExactVerifyRequirements(), &mockContractCaller{
+		authorizationState: common.LeftPadBytes([]byte{1}, 32),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Valid || result.Reason != ErrNonceAlreadyUsed || !result.NonceChecked {
+		t.Errorf("Expected %s, got valid=%v reason=%q checked=%v", ErrNonceAlreadyUsed, result.Valid, result.Reason, result.NonceChecked)
+	}
+
+	result, err = VerifyExactEvmPayload(ctx, payload, testExactVerifyRequirements(), &mockContractCaller{
+		authorizationState: common.LeftPadBytes([]byte{0}, 32),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Valid || !result.NonceChecked {
+		t.Errorf("Expected valid checked result, got valid=%v reason=%q checked=%v", result.Valid, result.Reason, result.NonceChecked)
+	}
+
+	// Tokens without authorizationState are accepted unchecked
+	result, err = VerifyExactEvmPayload(ctx, payload, testExactVerifyRequirements(), &mockContractCaller{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Valid || result.NonceChecked {
+		t.Errorf("Expected valid unchecked result, got valid=%v reason=%q checked=%v", result.Valid, result.Reason, result.NonceChecked)
+	}
+}
+
+func TestRecoverPayer(t *testing.T) {
+	requirements := testExactVerifyRequirements()
+	// Address of private key 1, the signer of signedExactPayload
+	const payer = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+
+	t.Run("EOA signature", func(t *testing.T) {
+		payload := types.PaymentPayload{X402Version: 2, Payload: signedExactPayload(t, nil).ToMap()}
+
+		got, err := RecoverPayer(payload, requirements)
+		if err != nil {
+			t.Fatalf("RecoverPayer failed: %v", err)
+		}
+		if got != payer {
+			t.Errorf("RecoverPayer = %s, want %s", got, payer)
+		}
+	})
+
+	t.Run("tampered authorization recovers another address", func(t *testing.T) {
+		evmPayload := signedExactPayload(t, func(a *ExactEIP3009Authorization) { a.Value = "2000000" })
+		payload := types.PaymentPayload{X402Version: 2, Payload: evmPayload.ToMap()}
+
+		got, err := RecoverPayer(payload, requirements)
+		if err != nil {
+			t.Fatalf("RecoverPayer failed: %v", err)
+		}
+		if got == payer {
+			t.Error("expected a different address for a tampered authorization")
+		}
+	})
+
+	t.Run("smart wallet signature", func(t *testing.T) {
+		evmPayload := signedExactPayload(t, nil)
+		evmPayload.Signature = BytesToHex(make([]byte, 130))
+		payload := types.PaymentPayload{X402Version: 2, Payload: evmPayload.ToMap()}
+
+		if _, err := RecoverPayer(payload, requirements); !errors.Is(err, ErrPayerNotRecoverable) {
+			t.Errorf("expected ErrPayerNotRecoverable, got %v", err)
+		}
+	})
+
+	t.Run("malformed payload", func(t *testing.T) {
+		if _, err := RecoverPayer(types.PaymentPayload{X402Version: 2}, requirements); err == nil {
+			t.Error("expected error for missing payload")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package x402
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EncodeSettlementHeader encodes a settlement response as the base64 JSON value of the
+// PAYMENT-RESPONSE (v2) or X-PAYMENT-RESPONSE (v1) response header
+//
+// The header carries the transaction hash and network, so a paying client can confirm the
+// settlement on-chain from the response alone.
+func EncodeSettlementHeader(response SettleResponse) (string, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal settle response: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodeSettlementHeader decodes a PAYMENT-RESPONSE or X-PAYMENT-RESPONSE header value
+//
+// A successful settlement must name its transaction; anything that is not base64 of a JSON
+// settle response is rejected.
+func DecodeSettlementHeader(header string) (SettleResponse, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header))
+	if err != nil {
+		return SettleResponse{}, fmt.Errorf("invalid base64 settlement header: %w", err)
+	}
+
+	var response SettleResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return SettleResponse{}, fmt.Errorf("invalid settlement header JSON: %w", err)
+	}
+	if response.Success && response.Transaction == "" {
+		return SettleResponse{}, fmt.Errorf("settlement header reports success without a transaction")
+	}
+	return response, nil
+}
@@ -0,0 +1,79 @@
+package x402
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestSettlementHeaderRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		response SettleResponse
+	}{
+		{
+			name: "settled",
+			response: SettleResponse{
+				Success:     true,
+				Payer:       "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+				Transaction: "0x9f2c0e3a7d6b5c4a3b2e1f0d9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b",
+				Network:     "eip155:8453",
+			},
+		},
+		{
+			name: "failed",
+			response: SettleResponse{
+				Success:     false,
+				ErrorReason: "insufficient_funds",
+				Payer:       "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+				Network:     "eip155:8453",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := EncodeSettlementHeader(tt.response)
+			if err != nil {
+				t.Fatalf("EncodeSettlementHeader failed: %v", err)
+			}
+			decoded, err := DecodeSettlementHeader(header)
+			if err != nil {
+				t.Fatalf("DecodeSettlementHeader failed: %v", err)
+			}
+			if decoded != tt.response {
+				t.Errorf("Expected %+v, got %+v", tt.response, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeSettlementHeaderMalformed(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr string
+	}{
+		{name: "empty", header: "", wantErr: "invalid settlement header JSON"},
+		{name: "not base64", header: "not base64!", wantErr: "invalid base64"},
+		{name: "not json", header: encode("settled"), wantErr: "invalid settlement header JSON"},
+		{name: "json array", header: encode(`[1,2]`), wantErr: "invalid settlement header JSON"},
+		{name: "wrong field type", header: encode(`{"success":"yes"}`), wantErr: "invalid settlement header JSON"},
+		{
+			name:    "success without transaction",
+			header:  encode(`{"success":true,"network":"eip155:8453"}`),
+			wantErr: "without a transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeSettlementHeader(tt.header)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -7,9 +7,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 
 	x402evm "github.com/gatechain/x402/go/mechanisms/evm"
 )
@@ -59,6 +57,15 @@ func NewClientSignerFromPrivateKey(privateKeyHex string) (x402evm.ClientEvmSigne
 	}, nil
 }
 
+// NewPrivateKeySigner creates a client signer from a hex-encoded private key.
+//
+// It is NewClientSignerFromPrivateKey under the name most integrators look for: the
+// returned signer hashes EIP-712 typed data itself and returns 65-byte signatures
+// with v normalized to 27/28.
+func NewPrivateKeySigner(hexKey string) (x402evm.ClientEvmSigner, error) {
+	return NewClientSignerFromPrivateKey(hexKey)
+}
+
 // Address returns the Ethereum address of the signer.
 func (s *ClientSigner) Address() string {
 	return s.address.Hex()
@@ -85,59 +92,11 @@ func (s *ClientSigner) SignTypedData(
 	primaryType string,
 	message map[string]interface{},
 ) ([]byte, error) {
-	// Convert x402 types to go-ethereum apitypes
-	typedData := apitypes.TypedData{
-		Types:       make(apitypes.Types),
-		PrimaryType: primaryType,
-		Domain: apitypes.TypedDataDomain{
-			Name:              domain.Name,
-			Version:           domain.Version,
-			ChainId:           (*math.HexOrDecimal256)(domain.ChainID),
-			VerifyingContract: domain.VerifyingContract,
-		},
-		Message: message,
-	}
-
-	// Convert field types
-	for typeName, fields := range types {
-		typedFields := make([]apitypes.Type, len(fields))
-		for i, field := range fields {
-			typedFields[i] = apitypes.Type{
-				Name: field.Name,
-				Type: field.Type,
-			}
-		}
-		typedData.Types[typeName] = typedFields
-	}
-
-	// Add EIP712Domain type if not present
-	if _, exists := typedData.Types["EIP712Domain"]; !exists {
-		typedData.Types["EIP712Domain"] = []apitypes.Type{
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
-		}
-	}
-
-	// Hash the struct data
-	dataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash struct: %w", err)
-	}
-
-	// Hash the domain
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	digest, err := x402evm.HashTypedData(domain, types, primaryType, message)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash domain: %w", err)
+		return nil, err
 	}
 
-	// Create EIP-712 digest: 0x19 0x01 <domainSeparator> <dataHash>
-	rawData := []byte{0x19, 0x01}
-	rawData = append(rawData, domainSeparator...)
-	rawData = append(rawData, dataHash...)
-	digest := crypto.Keccak256(rawData)
-
 	// Sign the digest with ECDSA
 	signature, err := crypto.Sign(digest, s.privateKey)
 	if err != nil {
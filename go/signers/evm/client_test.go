@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/crypto"
+
 	x402evm "github.com/gatechain/x402/go/mechanisms/evm"
 )
 
@@ -198,23 +200,113 @@ func TestClientSigner_SignTypedData_WithEIP712DomainInTypes(t *testing.T) {
 	}
 }
 
-// testRecovery verifies that a signature can be recovered to the expected address
-func testRecovery(t *testing.T, signature []byte, _ string, _ x402evm.TypedDataDomain, _ map[string][]x402evm.TypedDataField, _ map[string]interface{}) {
-	// This would require implementing the full recovery logic
-	// For now, we just check the signature format
-	// The actual recovery is tested in the mechanisms/evm package
+// testRecovery verifies that a signature recovers to the expected address
+func testRecovery(t *testing.T, signature []byte, wantAddress string, domain x402evm.TypedDataDomain, types map[string][]x402evm.TypedDataField, message map[string]interface{}) {
+	t.Helper()
 
 	if len(signature) != 65 {
-		t.Errorf("invalid signature length: %d", len(signature))
+		t.Fatalf("invalid signature length: %d", len(signature))
 	}
-
 	v := signature[64]
 	if v != 27 && v != 28 {
-		t.Errorf("invalid v value: %d", v)
+		t.Fatalf("invalid v value: %d", v)
+	}
+
+	var primaryType string
+	for name := range types {
+		if name != "EIP712Domain" {
+			primaryType = name
+		}
+	}
+	digest, err := x402evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		t.Fatalf("HashTypedData() failed: %v", err)
+	}
+	if recovered := recoverAddress(t, digest, signature); !equalAddresses(recovered, wantAddress) {
+		t.Errorf("recovered address = %s, want %s", recovered, wantAddress)
 	}
 }
 
+// recoverAddress recovers the signer of digest from a 65-byte signature with v of 27/28
+func recoverAddress(t *testing.T, digest []byte, signature []byte) string {
+	t.Helper()
+	sig := append([]byte{}, signature...)
+	sig[64] -= 27
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		t.Fatalf("SigToPub() failed: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pubKey).Hex()
+}
+
 // equalAddresses compares two Ethereum addresses (case-insensitive)
 func equalAddresses(a, b string) bool {
 	return strings.EqualFold(strings.ToLower(a), strings.ToLower(b))
 }
+
+func TestNewPrivateKeySigner(t *testing.T) {
+	signer, err := NewPrivateKeySigner("0x" + testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner() failed: %v", err)
+	}
+	if !equalAddresses(signer.Address(), "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266") {
+		t.Errorf("Address() = %v", signer.Address())
+	}
+	if _, err := NewPrivateKeySigner("0x1234"); err == nil {
+		t.Error("expected error for invalid key")
+	}
+
+	// A known EIP-3009 authorization, hashed the way facilitators verify it
+	authorization := x402evm.ExactEIP3009Authorization{
+		From:        signer.Address(),
+		To:          "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		Value:       "1000000",
+		ValidAfter:  "0",
+		ValidBefore: "9999999999",
+		Nonce:       "0x0102030405060708091011121314151617181920212223242526272829303132",
+	}
+	digest, err := x402evm.HashEIP3009Authorization(authorization, big.NewInt(84532), "0x036CbD53842c5426634e7929541eC2318f3dCF7e", "USD Coin", "2")
+	if err != nil {
+		t.Fatalf("HashEIP3009Authorization() failed: %v", err)
+	}
+
+	signature, err := signer.SignDigest(context.Background(), digest)
+	if err != nil {
+		t.Fatalf("SignDigest() failed: %v", err)
+	}
+	if v := signature[64]; v != 27 && v != 28 {
+		t.Errorf("SignDigest() v value = %d, want 27 or 28", v)
+	}
+	if recovered := recoverAddress(t, digest, signature); !equalAddresses(recovered, signer.Address()) {
+		t.Errorf("recovered address = %s, want %s", recovered, signer.Address())
+	}
+
+	// SignTypedData over the same authorization produces a signature for the same digest
+	nonce, _ := x402evm.HexToBytes(authorization.Nonce)
+	typed, err := signer.SignTypedData(context.Background(),
+		x402evm.TypedDataDomain{Name: "USD Coin", Version: "2", ChainID: big.NewInt(84532), VerifyingContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e"},
+		map[string][]x402evm.TypedDataField{"TransferWithAuthorization": {
+			{Name: "from", Type: "address"},
+			{Name: "to", Type: "address"},
+			{Name: "value", Type: "uint256"},
+			{Name: "validAfter", Type: "uint256"},
+			{Name: "validBefore", Type: "uint256"},
+			{Name: "nonce", Type: "bytes32"},
+		}},
+		"TransferWithAuthorization",
+		map[string]interface{}{
+			"from":        authorization.From,
+			"to":          authorization.To,
+			"value":       big.NewInt(1000000),
+			"validAfter":  big.NewInt(0),
+			"validBefore": big.NewInt(9999999999),
+			"nonce":       nonce,
+		},
+	)
+	if err != nil {
+		t.Fatalf("SignTypedData() failed: %v", err)
+	}
+	if recovered := recoverAddress(t, digest, typed); !equalAddresses(recovered, signer.Address()) {
+		t.Errorf("typed data signature recovered to %s, want %s", recovered, signer.Address())
+	}
+}
@@ -0,0 +1,165 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402evm "github.com/gatechain/x402/go/mechanisms/evm"
+)
+
+// ErrSignerLocked is returned by a locked KeystoreSigner
+var ErrSignerLocked = errors.New("keystore signer is locked")
+
+// KeystoreSigner implements x402evm.ClientEvmSigner with a key decrypted from a
+// Web3 Secret Storage (keystore JSON) file.
+//
+// The decrypted key can be wiped from memory with Lock and restored with Unlock.
+// It is safe for concurrent use: signing waits for a concurrent Lock or Unlock.
+type KeystoreSigner struct {
+	mu      sync.RWMutex
+	signer  *ClientSigner // nil while locked
+	address common.Address
+	keyJSON []byte // encrypted keyfile, nil when created from a decrypted key
+}
+
+// NewKeystoreSigner creates a client signer from a keystore JSON file.
+//
+// Args:
+//
+//	keystorePath: Path to the Web3 Secret Storage file
+//	passphrase: Passphrase the file is encrypted with
+//
+// Returns:
+//
+//	*KeystoreSigner, unlocked and ready for use with evm.NewExactEvmClient()
+//	Error if the file cannot be read or decrypted
+//
+// Go strings cannot be wiped, so the passphrase stays in memory until the garbage
+// collector reclaims it; the decrypted private key is zeroed by Lock.
+func NewKeystoreSigner(keystorePath, passphrase string) (*KeystoreSigner, error) {
+	keyJSON, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	s := &KeystoreSigner{keyJSON: keyJSON}
+	if err := s.Unlock(passphrase); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewKeystoreKeySigner creates a client signer from a key already decrypted with
+// go-ethereum's keystore package (keystore.DecryptKey).
+//
+// The signer takes ownership of key: Lock zeroes its private key, and a signer created
+// this way cannot be unlocked again.
+func NewKeystoreKeySigner(key *keystore.Key) (*KeystoreSigner, error) {
+	if key == nil || key.PrivateKey == nil {
+		return nil, fmt.Errorf("invalid keystore key: missing private key")
+	}
+
+	return &KeystoreSigner{
+		signer:  newClientSigner(key.PrivateKey),
+		address: crypto.PubkeyToAddress(key.PrivateKey.PublicKey),
+	}, nil
+}
+
+// Unlock decrypts the keyfile with passphrase, making the signer usable again after Lock
+func (s *KeystoreSigner) Unlock(passphrase string) error {
+	if s.keyJSON == nil {
+		return fmt.Errorf("keystore signer has no keyfile to unlock")
+	}
+
+	key, err := keystore.DecryptKey(s.keyJSON, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.signer != nil {
+		zeroKey(s.signer.privateKey)
+	}
+	s.signer = newClientSigner(key.PrivateKey)
+	s.address = key.Address
+	return nil
+}
+
+// Lock zeroes the decrypted private key; signing fails with ErrSignerLocked until Unlock
+func (s *KeystoreSigner) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.signer != nil {
+		zeroKey(s.signer.privateKey)
+		s.signer = nil
+	}
+}
+
+// IsLocked reports whether the private key has been wiped by Lock
+func (s *KeystoreSigner) IsLocked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signer == nil
+}
+
+// Address returns the Ethereum address of the signer, also while locked.
+func (s *KeystoreSigner) Address() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.address.Hex()
+}
+
+// SignTypedData signs EIP-712 typed data like ClientSigner.SignTypedData.
+func (s *KeystoreSigner) SignTypedData(
+	ctx context.Context,
+	domain x402evm.TypedDataDomain,
+	types map[string][]x402evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.signer == nil {
+		return nil, ErrSignerLocked
+	}
+	return s.signer.SignTypedData(ctx, domain, types, primaryType, message)
+}
+
+// SignDigest signs a raw digest (32-byte hash) like ClientSigner.SignDigest.
+func (s *KeystoreSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.signer == nil {
+		return nil, ErrSignerLocked
+	}
+	return s.signer.SignDigest(ctx, digest)
+}
+
+// newClientSigner wraps an ECDSA private key in a ClientSigner
+func newClientSigner(privateKey *ecdsa.PrivateKey) *ClientSigner {
+	return &ClientSigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+// zeroKey overwrites the secret scalar of a private key
+func zeroKey(privateKey *ecdsa.PrivateKey) {
+	if privateKey == nil || privateKey.D == nil {
+		return
+	}
+	b := privateKey.D.Bits()
+	for i := range b {
+		b[i] = 0
+	}
+	privateKey.D.SetInt64(0)
+}
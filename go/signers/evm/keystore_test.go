@@ -0,0 +1,161 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+const testKeystorePassphrase = "correct horse battery staple"
+
+// writeTestKeystore encrypts the test private key into a keystore file with light scrypt parameters
+func writeTestKeystore(t *testing.T) string {
+	t.Helper()
+	privateKey, err := crypto.HexToECDSA(testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("HexToECDSA() failed: %v", err)
+	}
+	key := &keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+	keyJSON, err := keystore.EncryptKey(key, testKeystorePassphrase, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey() failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keyfile.json")
+	if err := os.WriteFile(path, keyJSON, 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	return path
+}
+
+func TestNewKeystoreSigner(t *testing.T) {
+	path := writeTestKeystore(t)
+
+	signer, err := NewKeystoreSigner(path, testKeystorePassphrase)
+	if err != nil {
+		t.Fatalf("NewKeystoreSigner() failed: %v", err)
+	}
+	if !equalAddresses(signer.Address(), "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266") {
+		t.Errorf("Address() = %v", signer.Address())
+	}
+
+	digest := crypto.Keccak256([]byte("x402"))
+	signature, err := signer.SignDigest(context.Background(), digest)
+	if err != nil {
+		t.Fatalf("SignDigest() failed: %v", err)
+	}
+	if recovered := recoverAddress(t, digest, signature); !equalAddresses(recovered, signer.Address()) {
+		t.Errorf("recovered address = %s, want %s", recovered, signer.Address())
+	}
+
+	if _, err := NewKeystoreSigner(path, "wrong passphrase"); err == nil {
+		t.Error("expected error for wrong passphrase")
+	}
+	if _, err := NewKeystoreSigner(filepath.Join(t.TempDir(), "missing.json"), testKeystorePassphrase); err == nil {
+		t.Error("expected error for missing keyfile")
+	}
+}
+
+func TestKeystoreSignerLockUnlock(t *testing.T) {
+	signer, err := NewKeystoreSigner(writeTestKeystore(t), testKeystorePassphrase)
+	if err != nil {
+		t.Fatalf("NewKeystoreSigner() failed: %v", err)
+	}
+	digest := crypto.Keccak256([]byte("x402"))
+
+	signer.Lock()
+	if !signer.IsLocked() {
+		t.Error("expected signer to be locked")
+	}
+	if _, err := signer.SignDigest(context.Background(), digest); !errors.Is(err, ErrSignerLocked) {
+		t.Errorf("expected ErrSignerLocked, got %v", err)
+	}
+	if !equalAddresses(signer.Address(), "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266") {
+		t.Errorf("expected address while locked, got %v", signer.Address())
+	}
+
+	if err := signer.Unlock("wrong passphrase"); err == nil || !signer.IsLocked() {
+		t.Errorf("expected wrong passphrase to leave the signer locked, got %v", err)
+	}
+	if err := signer.Unlock(testKeystorePassphrase); err != nil {
+		t.Fatalf("Unlock() failed: %v", err)
+	}
+	if _, err := signer.SignDigest(context.Background(), digest); err != nil {
+		t.Errorf("SignDigest() after Unlock failed: %v", err)
+	}
+}
+
+func TestNewKeystoreKeySigner(t *testing.T) {
+	keyJSON, err := os.ReadFile(writeTestKeystore(t))
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, testKeystorePassphrase)
+	if err != nil {
+		t.Fatalf("DecryptKey() failed: %v", err)
+	}
+
+	signer, err := NewKeystoreKeySigner(key)
+	if err != nil {
+		t.Fatalf("NewKeystoreKeySigner() failed: %v", err)
+	}
+	if !equalAddresses(signer.Address(), key.Address.Hex()) {
+		t.Errorf("Address() = %v, want %v", signer.Address(), key.Address.Hex())
+	}
+
+	signer.Lock()
+	if key.PrivateKey.D.Sign() != 0 {
+		t.Error("expected Lock to zero the private key")
+	}
+	if err := signer.Unlock(testKeystorePassphrase); err == nil {
+		t.Error("expected Unlock to fail without a keyfile")
+	}
+
+	if _, err := NewKeystoreKeySigner(nil); err == nil {
+		t.Error("expected error for nil key")
+	}
+}
+
+func TestKeystoreSignerConcurrent(t *testing.T) {
+	signer, err := NewKeystoreSigner(writeTestKeystore(t), testKeystorePassphrase)
+	if err != nil {
+		t.Fatalf("NewKeystoreSigner() failed: %v", err)
+	}
+	digest := crypto.Keccak256([]byte("x402"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				signer.Lock()
+				_ = signer.Unlock(testKeystorePassphrase)
+				return
+			}
+			signature, err := signer.SignDigest(context.Background(), digest)
+			if errors.Is(err, ErrSignerLocked) {
+				return
+			}
+			if err != nil {
+				t.Errorf("SignDigest() failed: %v", err)
+				return
+			}
+			if recovered := recoverAddress(t, digest, signature); !equalAddresses(recovered, signer.Address()) {
+				t.Errorf("recovered address = %s", recovered)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,127 @@
+package evm
+
+import (
+	"context"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402evm "github.com/gatechain/x402/go/mechanisms/evm"
+)
+
+// DigestSignFunc signs a 32-byte digest with a key held elsewhere (hardware wallet, KMS, gRPC
+// signing service) and returns a 65-byte r||s||v signature with v of 0/1 or 27/28
+type DigestSignFunc func(ctx context.Context, digest []byte) ([]byte, error)
+
+// RemoteDigestSigner implements x402evm.DigestSigner by delegating to a DigestSignFunc.
+// The client schemes compute every EIP-712 digest locally, so this is all a remote
+// signer has to provide.
+type RemoteDigestSigner struct {
+	address common.Address
+	sign    DigestSignFunc
+}
+
+// NewRemoteDigestSigner creates a signer for the key at address that signs with sign.
+//
+// Example, for a KMS that returns ASN.1 DER signatures without a recovery id:
+//
+//	signer, err := evm.NewRemoteDigestSigner(kmsAddress, func(ctx context.Context, digest []byte) ([]byte, error) {
+//	    resp, err := kms.Sign(ctx, &kmspb.SignRequest{KeyId: keyID, Digest: digest})
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return evm.SignatureFromDER(digest, resp.Signature, kmsAddress)
+//	})
+func NewRemoteDigestSigner(address string, sign DigestSignFunc) (x402evm.DigestSigner, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid signer address: %s", address)
+	}
+	if sign == nil {
+		return nil, fmt.Errorf("sign function is required")
+	}
+
+	return &RemoteDigestSigner{
+		address: common.HexToAddress(address),
+		sign:    sign,
+	}, nil
+}
+
+// Address returns the Ethereum address of the remote key.
+func (s *RemoteDigestSigner) Address() string {
+	return s.address.Hex()
+}
+
+// SignDigest signs a raw digest (32-byte hash) remotely, normalizing v to 27/28.
+// The signature must recover to the signer's address, so a misconfigured key or a wrong
+// recovery id fails here rather than at the facilitator.
+func (s *RemoteDigestSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("digest must be 32 bytes, got %d", len(digest))
+	}
+
+	signature, err := s.sign(ctx, digest)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer failed: %w", err)
+	}
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("remote signature must be 65 bytes, got %d", len(signature))
+	}
+
+	signature = append([]byte{}, signature...)
+	switch signature[64] {
+	case 0, 1:
+		signature[64] += 27
+	case 27, 28:
+	default:
+		return nil, fmt.Errorf("invalid remote signature v value: %d", signature[64])
+	}
+
+	if recovered, ok := recoverSigner(digest, signature); !ok || recovered != s.address {
+		return nil, fmt.Errorf("remote signature does not recover to %s", s.address.Hex())
+	}
+	return signature, nil
+}
+
+// SignatureFromDER converts an ASN.1 DER ECDSA signature of digest, as returned by most KMS
+// APIs, to the 65-byte r||s||v form with v of 27/28. s is normalized to the lower half of
+// the curve order (EIP-2), and v is the recovery id that recovers address.
+func SignatureFromDER(digest, der []byte, address string) ([]byte, error) {
+	var parsed struct{ R, S *big.Int }
+	rest, err := asn1.Unmarshal(der, &parsed)
+	if err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("invalid DER signature")
+	}
+	n := crypto.S256().Params().N
+	if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 || parsed.R.Cmp(n) >= 0 || parsed.S.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid DER signature")
+	}
+	if parsed.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		parsed.S = new(big.Int).Sub(n, parsed.S)
+	}
+
+	signature := make([]byte, 65)
+	parsed.R.FillBytes(signature[:32])
+	parsed.S.FillBytes(signature[32:64])
+	want := common.HexToAddress(address)
+	for _, v := range []byte{27, 28} {
+		signature[64] = v
+		if recovered, ok := recoverSigner(digest, signature); ok && recovered == want {
+			return signature, nil
+		}
+	}
+	return nil, fmt.Errorf("DER signature does not recover to %s", want.Hex())
+}
+
+// recoverSigner returns the address that signed digest, for a signature with v of 27/28
+func recoverSigner(digest, signature []byte) (common.Address, bool) {
+	sig := append([]byte{}, signature...)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, false
+	}
+	return crypto.PubkeyToAddress(*pub), true
+}
@@ -0,0 +1,124 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRemoteDigestSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("HexToECDSA() failed: %v", err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	// The backend returns raw recovery IDs, like most KMS APIs
+	signer, err := NewRemoteDigestSigner(address, func(ctx context.Context, digest []byte) ([]byte, error) {
+		return crypto.Sign(digest, key)
+	})
+	if err != nil {
+		t.Fatalf("NewRemoteDigestSigner() failed: %v", err)
+	}
+
+	digest := crypto.Keccak256([]byte("x402"))
+	signature, err := signer.SignDigest(context.Background(), digest)
+	if err != nil {
+		t.Fatalf("SignDigest() failed: %v", err)
+	}
+	if v := signature[64]; v != 27 && v != 28 {
+		t.Errorf("SignDigest() v value = %d, want 27 or 28", v)
+	}
+	if recovered := recoverAddress(t, digest, signature); !equalAddresses(recovered, address) {
+		t.Errorf("recovered address = %s, want %s", recovered, address)
+	}
+
+	if _, err := signer.SignDigest(context.Background(), []byte("short")); err == nil {
+		t.Error("expected error for short digest")
+	}
+}
+
+func TestRemoteDigestSignerErrors(t *testing.T) {
+	address := "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+
+	if _, err := NewRemoteDigestSigner("not-an-address", func(context.Context, []byte) ([]byte, error) { return nil, nil }); err == nil {
+		t.Error("expected error for invalid address")
+	}
+	if _, err := NewRemoteDigestSigner(address, nil); err == nil {
+		t.Error("expected error for nil sign function")
+	}
+
+	unavailable := errors.New("kms unavailable")
+	tests := []struct {
+		name string
+		sign DigestSignFunc
+	}{
+		{"backend error", func(context.Context, []byte) ([]byte, error) { return nil, unavailable }},
+		{"wrong length", func(context.Context, []byte) ([]byte, error) { return make([]byte, 64), nil }},
+		{"invalid v", func(context.Context, []byte) ([]byte, error) { sig := make([]byte, 65); sig[64] = 5; return sig, nil }},
+		{"other key", func(ctx context.Context, digest []byte) ([]byte, error) {
+			key, _ := crypto.GenerateKey()
+			return crypto.Sign(digest, key)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewRemoteDigestSigner(address, tt.sign)
+			if err != nil {
+				t.Fatalf("NewRemoteDigestSigner() failed: %v", err)
+			}
+			if _, err := signer.SignDigest(context.Background(), make([]byte, 32)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSignatureFromDER(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("HexToECDSA() failed: %v", err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	n := crypto.S256().Params().N
+
+	for i := 0; i < 8; i++ {
+		digest := crypto.Keccak256([]byte{byte(i)})
+		signature, err := crypto.Sign(digest, key)
+		if err != nil {
+			t.Fatalf("Sign() failed: %v", err)
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64])
+		// KMS signatures may carry the high-s form
+		if i%2 == 1 {
+			s.Sub(n, s)
+		}
+		der, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if err != nil {
+			t.Fatalf("asn1.Marshal() failed: %v", err)
+		}
+
+		signer, err := NewRemoteDigestSigner(address, func(ctx context.Context, digest []byte) ([]byte, error) {
+			return SignatureFromDER(digest, der, address)
+		})
+		if err != nil {
+			t.Fatalf("NewRemoteDigestSigner() failed: %v", err)
+		}
+		converted, err := signer.SignDigest(context.Background(), digest)
+		if err != nil {
+			t.Fatalf("SignDigest() failed: %v", err)
+		}
+		if !bytes.Equal(converted[:64], signature[:64]) || converted[64] != signature[64]+27 {
+			t.Errorf("Digest %d: expected %x, got %x", i, signature, converted)
+		}
+	}
+
+	if _, err := SignatureFromDER(make([]byte, 32), []byte("not der"), address); err == nil {
+		t.Error("expected error for malformed DER")
+	}
+}
@@ -37,6 +37,13 @@ func (m *mockClientEvmSigner) SignTypedData(
 	return sig, nil
 }
 
+func (m *mockClientEvmSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	// Return a mock signature (65 bytes)
+	sig := make([]byte, 65)
+	sig[64] = 27
+	return sig, nil
+}
+
 // TestEVMVersionMismatch tests that V1 and V2 don't mix
 func TestEVMVersionMismatch(t *testing.T) {
 	t.Run("V1 Client with V2 Requirements Should Fail", func(t *testing.T) {
@@ -52,7 +59,7 @@ func TestEVMVersionMismatch(t *testing.T) {
 		v1Requirements := types.PaymentRequirementsV1{
 			Scheme:            evm.SchemeExact,
 			Network:           "eip155:8453",
-			Asset:             "erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+			Asset:             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
 			MaxAmountRequired: "1000000",
 			PayTo:             "0x9876543210987654321098765432109876543210",
 		}
@@ -82,7 +89,7 @@ func TestEVMVersionMismatch(t *testing.T) {
 		requirements := types.PaymentRequirements{
 			Scheme:  evm.SchemeExact,
 			Network: "eip155:8453",
-			Asset:   "erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+			Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
 			Amount:  "1000000",
 			PayTo:   "0x9876543210987654321098765432109876543210",
 		}
@@ -125,7 +132,7 @@ func TestEVMDualVersionSupport(t *testing.T) {
 		v1Requirements := types.PaymentRequirementsV1{
 			Scheme:            evm.SchemeExact,
 			Network:           "eip155:8453",
-			Asset:             "erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+			Asset:             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
 			MaxAmountRequired: "1000000",
 			PayTo:             "0x9876543210987654321098765432109876543210",
 		}
@@ -149,7 +156,7 @@ func TestEVMDualVersionSupport(t *testing.T) {
 		v2Requirements := types.PaymentRequirements{
 			Scheme:  evm.SchemeExact,
 			Network: "eip155:8453",
-			Asset:   "erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+			Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
 			Amount:  "1000000",
 			PayTo:   "0x9876543210987654321098765432109876543210",
 			Extra: map[string]interface{}{
@@ -191,7 +198,7 @@ func TestEVMDualVersionSupport(t *testing.T) {
 		requirements := types.PaymentRequirements{
 			Scheme:  evm.SchemeExact,
 			Network: "eip155:8453",
-			Asset:   "erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+			Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
 			Amount:  "1000000",
 			PayTo:   "0x9876543210987654321098765432109876543210",
 		}
@@ -84,6 +84,10 @@ type VerifyResponse struct {
 	IsValid       bool   `json:"isValid"`
 	InvalidReason string `json:"invalidReason,omitempty"`
 	Payer         string `json:"payer,omitempty"`
+
+	// Envelope is the facilitator's business code and message, when the response came through
+	// an enveloped HTTP facilitator. Not serialized.
+	Envelope *FacilitatorEnvelope `json:"-"`
 }
 
 // SettleResponse contains the settlement result
@@ -94,6 +98,22 @@ type SettleResponse struct {
 	Payer       string  `json:"payer,omitempty"`
 	Transaction string  `json:"transaction"`
 	Network     Network `json:"network"`
+
+	// SettledAmount is the amount actually charged, in the asset's smallest unit. Metered
+	// ("upto") settlements may charge less than the requirements; HTTPFacilitatorClient fills
+	// in the required amount when a successful settlement omits it.
+	SettledAmount string `json:"settledAmount,omitempty"`
+
+	// Envelope is the facilitator's business code and message, when the response came through
+	// an enveloped HTTP facilitator. Not serialized.
+	Envelope *FacilitatorEnvelope `json:"-"`
+}
+
+// FacilitatorEnvelope is the raw business status a facilitator returned alongside its data
+// Code is 0 on success; Msg is kept verbatim (it may carry warnings even when Code is 0).
+type FacilitatorEnvelope struct {
+	Code int
+	Msg  string
 }
 
 // ResourceConfig defines payment configuration for a protected resource
@@ -0,0 +1,143 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// UpgradeV1ToV2 converts a v1 payment payload, with the v1 requirements it was created for,
+// into a v2 payload, for proxying v1 clients to v2 facilitators
+//
+// The scheme payload is carried over unchanged. The requirements become Accepted
+// (MaxAmountRequired → Amount) and their resource URL, description and MIME type become
+// Resource. Networks are not renamed: a v1 network name is kept as is.
+//
+// Returns an error when the conversion would lose information:
+//   - the payload is not version 1, or names another scheme or network than the requirements
+//   - the requirements carry an OutputSchema, which v2 requirements have no field for
+//   - the requirements' Extra is not a JSON object
+func UpgradeV1ToV2(payload PaymentPayloadV1, requirements PaymentRequirementsV1) (PaymentPayload, error) {
+	if payload.X402Version != 1 {
+		return PaymentPayload{}, fmt.Errorf("invalid version for v1 payment payload: %d", payload.X402Version)
+	}
+	if payload.Scheme != requirements.Scheme || payload.Network != requirements.Network {
+		return PaymentPayload{}, fmt.Errorf("payload %s/%s does not match requirements %s/%s",
+			payload.Scheme, payload.Network, requirements.Scheme, requirements.Network)
+	}
+
+	accepted, resource, err := UpgradeRequirementsV1ToV2(requirements)
+	if err != nil {
+		return PaymentPayload{}, err
+	}
+
+	return PaymentPayload{
+		X402Version: 2,
+		Payload:     copyMap(payload.Payload),
+		Accepted:    accepted,
+		Resource:    resource,
+	}, nil
+}
+
+// DowngradeV2ToV1 converts a v2 payment payload into a v1 payload and the v1 requirements it
+// was created for, for proxying v2 clients to v1 facilitators
+//
+// Scheme and network are taken from Accepted; Resource fills the v1 resource URL,
+// description and MIME type. Networks are not renamed: a CAIP-2 network is kept as is.
+//
+// Returns an error when the conversion would lose information:
+//   - the payload is not version 2
+//   - the payload carries Extensions, which v1 has no field for
+func DowngradeV2ToV1(payload PaymentPayload) (PaymentPayloadV1, PaymentRequirementsV1, error) {
+	if payload.X402Version != 2 {
+		return PaymentPayloadV1{}, PaymentRequirementsV1{}, fmt.Errorf("invalid version for v2 payment payload: %d", payload.X402Version)
+	}
+	if len(payload.Extensions) > 0 {
+		return PaymentPayloadV1{}, PaymentRequirementsV1{}, fmt.Errorf("v1 payloads cannot carry extensions")
+	}
+
+	requirements, err := DowngradeRequirementsV2ToV1(payload.Accepted, payload.Resource)
+	if err != nil {
+		return PaymentPayloadV1{}, PaymentRequirementsV1{}, err
+	}
+
+	return PaymentPayloadV1{
+		X402Version: 1,
+		Scheme:      payload.Accepted.Scheme,
+		Network:     payload.Accepted.Network,
+		Payload:     copyMap(payload.Payload),
+	}, requirements, nil
+}
+
+// UpgradeRequirementsV1ToV2 splits v1 requirements into v2 requirements and resource info
+// The resource info is nil when the v1 requirements name no resource.
+// An OutputSchema, or an Extra that is not a JSON object, cannot be converted and is an error.
+func UpgradeRequirementsV1ToV2(requirements PaymentRequirementsV1) (PaymentRequirements, *ResourceInfo, error) {
+	if requirements.OutputSchema != nil {
+		return PaymentRequirements{}, nil, fmt.Errorf("v2 requirements cannot carry an output schema")
+	}
+
+	var extra map[string]interface{}
+	if requirements.Extra != nil {
+		if err := json.Unmarshal(*requirements.Extra, &extra); err != nil {
+			return PaymentRequirements{}, nil, fmt.Errorf("invalid v1 extra: %w", err)
+		}
+	}
+
+	var resource *ResourceInfo
+	if requirements.Resource != "" || requirements.Description != "" || requirements.MimeType != "" {
+		resource = &ResourceInfo{
+			URL:         requirements.Resource,
+			Description: requirements.Description,
+			MimeType:    requirements.MimeType,
+		}
+	}
+
+	return PaymentRequirements{
+		Scheme:            requirements.Scheme,
+		Network:           requirements.Network,
+		Asset:             requirements.Asset,
+		Amount:            requirements.MaxAmountRequired,
+		PayTo:             requirements.PayTo,
+		MaxTimeoutSeconds: requirements.MaxTimeoutSeconds,
+		Extra:             extra,
+	}, resource, nil
+}
+
+// DowngradeRequirementsV2ToV1 merges v2 requirements and optional resource info into v1 requirements
+func DowngradeRequirementsV2ToV1(requirements PaymentRequirements, resource *ResourceInfo) (PaymentRequirementsV1, error) {
+	result := PaymentRequirementsV1{
+		Scheme:            requirements.Scheme,
+		Network:           requirements.Network,
+		MaxAmountRequired: requirements.Amount,
+		PayTo:             requirements.PayTo,
+		MaxTimeoutSeconds: requirements.MaxTimeoutSeconds,
+		Asset:             requirements.Asset,
+	}
+	if resource != nil {
+		result.Resource = resource.URL
+		result.Description = resource.Description
+		result.MimeType = resource.MimeType
+	}
+
+	if requirements.Extra != nil {
+		extraBytes, err := json.Marshal(requirements.Extra)
+		if err != nil {
+			return PaymentRequirementsV1{}, fmt.Errorf("failed to marshal extra: %w", err)
+		}
+		extra := json.RawMessage(extraBytes)
+		result.Extra = &extra
+	}
+	return result, nil
+}
+
+// copyMap returns a shallow copy of m, or nil for a nil map
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
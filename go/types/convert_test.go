@@ -0,0 +1,184 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testPayloadV1() (PaymentPayloadV1, PaymentRequirementsV1) {
+	extra := json.RawMessage(`{"name":"USD Coin","version":"2"}`)
+	payload := PaymentPayloadV1{
+		X402Version: 1,
+		Scheme:      "exact",
+		Network:     "base-sepolia",
+		Payload: map[string]interface{}{
+			"signature":     "0xsig",
+			"authorization": map[string]interface{}{"from": "0xfrom", "value": "1000"},
+		},
+	}
+	requirements := PaymentRequirementsV1{
+		Scheme:            "exact",
+		Network:           "base-sepolia",
+		MaxAmountRequired: "1000",
+		Resource:          "https://example.com/resource",
+		Description:       "Premium content",
+		MimeType:          "application/json",
+		PayTo:             "0xpayto",
+		MaxTimeoutSeconds: 60,
+		Asset:             "0xasset",
+		Extra:             &extra,
+	}
+	return payload, requirements
+}
+
+func TestUpgradeV1ToV2(t *testing.T) {
+	v1Payload, v1Requirements := testPayloadV1()
+
+	payload, err := UpgradeV1ToV2(v1Payload, v1Requirements)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if payload.X402Version != 2 {
+		t.Errorf("X402Version = %d, want 2", payload.X402Version)
+	}
+	if payload.Accepted.Amount != "1000" || payload.Accepted.Network != "base-sepolia" || payload.Accepted.PayTo != "0xpayto" {
+		t.Errorf("Unexpected accepted requirements: %+v", payload.Accepted)
+	}
+	if payload.Accepted.Extra["name"] != "USD Coin" {
+		t.Errorf("Expected extra to be decoded, got %v", payload.Accepted.Extra)
+	}
+	if payload.Resource == nil || payload.Resource.URL != v1Requirements.Resource || payload.Resource.MimeType != "application/json" {
+		t.Errorf("Unexpected resource: %+v", payload.Resource)
+	}
+	if !reflect.DeepEqual(payload.Payload, v1Payload.Payload) {
+		t.Errorf("Payload = %v, want %v", payload.Payload, v1Payload.Payload)
+	}
+}
+
+func TestV1V2RoundTrip(t *testing.T) {
+	t.Run("v1 to v2 to v1", func(t *testing.T) {
+		v1Payload, v1Requirements := testPayloadV1()
+
+		upgraded, err := UpgradeV1ToV2(v1Payload, v1Requirements)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		payload, requirements, err := DowngradeV2ToV1(upgraded)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(payload, v1Payload) {
+			t.Errorf("Payload = %+v, want %+v", payload, v1Payload)
+		}
+		if !reflect.DeepEqual(requirements.GetExtra(), v1Requirements.GetExtra()) {
+			t.Errorf("Extra = %v, want %v", requirements.GetExtra(), v1Requirements.GetExtra())
+		}
+		requirements.Extra, v1Requirements.Extra = nil, nil
+		if !reflect.DeepEqual(requirements, v1Requirements) {
+			t.Errorf("Requirements = %+v, want %+v", requirements, v1Requirements)
+		}
+	})
+
+	t.Run("v2 to v1 to v2", func(t *testing.T) {
+		v2Payload := PaymentPayload{
+			X402Version: 2,
+			Payload:     map[string]interface{}{"signature": "0xsig"},
+			Accepted: PaymentRequirements{
+				Scheme:            "exact",
+				Network:           "eip155:8453",
+				Asset:             "0xasset",
+				Amount:            "1000",
+				PayTo:             "0xpayto",
+				MaxTimeoutSeconds: 60,
+				Extra:             map[string]interface{}{"name": "USD Coin"},
+			},
+			Resource: &ResourceInfo{URL: "https://example.com/resource"},
+		}
+
+		v1Payload, v1Requirements, err := DowngradeV2ToV1(v2Payload)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if v1Payload.X402Version != 1 || v1Payload.Scheme != "exact" || v1Payload.Network != "eip155:8453" {
+			t.Errorf("Unexpected v1 payload: %+v", v1Payload)
+		}
+
+		payload, err := UpgradeV1ToV2(v1Payload, v1Requirements)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(payload, v2Payload) {
+			t.Errorf("Payload = %+v, want %+v", payload, v2Payload)
+		}
+	})
+
+	t.Run("without resource", func(t *testing.T) {
+		v2Payload := PaymentPayload{
+			X402Version: 2,
+			Accepted:    PaymentRequirements{Scheme: "exact", Network: "eip155:8453", Amount: "1"},
+		}
+		v1Payload, v1Requirements, err := DowngradeV2ToV1(v2Payload)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		payload, err := UpgradeV1ToV2(v1Payload, v1Requirements)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(payload, v2Payload) {
+			t.Errorf("Payload = %+v, want %+v", payload, v2Payload)
+		}
+	})
+}
+
+func TestV1V2ConversionErrors(t *testing.T) {
+	t.Run("upgrade wrong version", func(t *testing.T) {
+		payload, requirements := testPayloadV1()
+		payload.X402Version = 2
+		if _, err := UpgradeV1ToV2(payload, requirements); err == nil {
+			t.Error("Expected error for a non-v1 payload")
+		}
+	})
+
+	t.Run("upgrade mismatched requirements", func(t *testing.T) {
+		payload, requirements := testPayloadV1()
+		requirements.Network = "base"
+		if _, err := UpgradeV1ToV2(payload, requirements); err == nil {
+			t.Error("Expected error for mismatched network")
+		}
+	})
+
+	t.Run("upgrade output schema", func(t *testing.T) {
+		payload, requirements := testPayloadV1()
+		schema := json.RawMessage(`{"type":"object"}`)
+		requirements.OutputSchema = &schema
+		if _, err := UpgradeV1ToV2(payload, requirements); err == nil {
+			t.Error("Expected error for output schema")
+		}
+	})
+
+	t.Run("upgrade non-object extra", func(t *testing.T) {
+		payload, requirements := testPayloadV1()
+		extra := json.RawMessage(`["not", "an", "object"]`)
+		requirements.Extra = &extra
+		if _, err := UpgradeV1ToV2(payload, requirements); err == nil {
+			t.Error("Expected error for non-object extra")
+		}
+	})
+
+	t.Run("downgrade wrong version", func(t *testing.T) {
+		if _, _, err := DowngradeV2ToV1(PaymentPayload{X402Version: 1}); err == nil {
+			t.Error("Expected error for a non-v2 payload")
+		}
+	})
+
+	t.Run("downgrade extensions", func(t *testing.T) {
+		payload := PaymentPayload{X402Version: 2, Extensions: map[string]interface{}{"bazaar": true}}
+		if _, _, err := DowngradeV2ToV1(payload); err == nil {
+			t.Error("Expected error for extensions")
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Errors returned by CheckValidity when now is outside the authorization's validity window
+var (
+	ErrAuthorizationExpired     = errors.New("authorization expired")
+	ErrAuthorizationNotYetValid = errors.New("authorization not yet valid")
+)
+
+// ValidityWindow returns the validAfter and validBefore unix times of the payload's
+// authorization (EIP-3009 payloads). ok is false when the payload has no such window.
+func (p PaymentPayload) ValidityWindow() (validAfter, validBefore int64, ok bool) {
+	return validityWindow(p.Payload)
+}
+
+// IsExpired reports whether the authorization's validBefore is at or before now
+// Payloads without a validity window never expire.
+func (p PaymentPayload) IsExpired(now time.Time) bool {
+	return errors.Is(p.CheckValidity(now), ErrAuthorizationExpired)
+}
+
+// CheckValidity returns an error wrapping ErrAuthorizationExpired or ErrAuthorizationNotYetValid
+// when now is outside the authorization's validity window, and nil otherwise (including for
+// payloads without a window). validBefore itself is already expired, as tokens enforce.
+func (p PaymentPayload) CheckValidity(now time.Time) error {
+	return checkValidity(p.Payload, now)
+}
+
+// ValidityWindow is PaymentPayload.ValidityWindow for V1 payloads
+func (p PaymentPayloadV1) ValidityWindow() (validAfter, validBefore int64, ok bool) {
+	return validityWindow(p.Payload)
+}
+
+// CheckValidity is PaymentPayload.CheckValidity for V1 payloads
+func (p PaymentPayloadV1) CheckValidity(now time.Time) error {
+	return checkValidity(p.Payload, now)
+}
+
+func checkValidity(payload map[string]interface{}, now time.Time) error {
+	validAfter, validBefore, ok := validityWindow(payload)
+	if !ok {
+		return nil
+	}
+	if unix := now.Unix(); unix >= validBefore {
+		return fmt.Errorf("%w: validBefore %s is not after %s", ErrAuthorizationExpired,
+			time.Unix(validBefore, 0).UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339))
+	} else if unix < validAfter {
+		return fmt.Errorf("%w: validAfter %s is after %s", ErrAuthorizationNotYetValid,
+			time.Unix(validAfter, 0).UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// validityWindow reads authorization.validAfter/validBefore, given as decimal strings or numbers
+func validityWindow(payload map[string]interface{}) (validAfter, validBefore int64, ok bool) {
+	authorization, _ := payload["authorization"].(map[string]interface{})
+	if authorization == nil {
+		return 0, 0, false
+	}
+	validAfter, okAfter := unixSeconds(authorization["validAfter"])
+	validBefore, okBefore := unixSeconds(authorization["validBefore"])
+	if !okAfter || !okBefore {
+		return 0, 0, false
+	}
+	return validAfter, validBefore, true
+}
+
+func unixSeconds(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		return int64(v), v == float64(int64(v))
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
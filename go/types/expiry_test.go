@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPaymentPayloadCheckValidity(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	window := func(validAfter, validBefore interface{}) PaymentPayload {
+		return PaymentPayload{X402Version: 2, Payload: map[string]interface{}{
+			"authorization": map[string]interface{}{"validAfter": validAfter, "validBefore": validBefore},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		payload     PaymentPayload
+		wantErr     error
+		wantExpired bool
+	}{
+		{name: "in window", payload: window("1699999000", "1700000600")},
+		{name: "numeric window", payload: window(float64(1699999000), float64(1700000600))},
+		{name: "expired", payload: window("0", "1699999999"), wantErr: ErrAuthorizationExpired, wantExpired: true},
+		{name: "expires now", payload: window("0", "1700000000"), wantErr: ErrAuthorizationExpired, wantExpired: true},
+		{name: "not yet valid", payload: window("1700000001", "1700003600"), wantErr: ErrAuthorizationNotYetValid},
+		{name: "valid from now", payload: window("1700000000", "1700003600")},
+		{name: "no authorization", payload: PaymentPayload{Payload: map[string]interface{}{"transaction": "AQID"}}},
+		{name: "malformed window", payload: window("soon", "later")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.payload.CheckValidity(now)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if got := tt.payload.IsExpired(now); got != tt.wantExpired {
+				t.Errorf("Expected IsExpired %v, got %v", tt.wantExpired, got)
+			}
+		})
+	}
+
+	// V1 payloads decoded from JSON carry the same window
+	var v1 PaymentPayloadV1
+	if err := json.Unmarshal([]byte(`{"x402Version":1,"payload":{"authorization":{"validAfter":"0","validBefore":"1699999999"}}}`), &v1); err != nil {
+		t.Fatal(err)
+	}
+	if validAfter, validBefore, ok := v1.ValidityWindow(); !ok || validAfter != 0 || validBefore != 1699999999 {
+		t.Errorf("Unexpected window %d-%d (%v)", validAfter, validBefore, ok)
+	}
+	if err := v1.CheckValidity(now); !errors.Is(err, ErrAuthorizationExpired) {
+		t.Errorf("Expected ErrAuthorizationExpired, got %v", err)
+	}
+}
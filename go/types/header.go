@@ -0,0 +1,73 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// EncodePaymentHeader encodes a v2 payment payload as the base64 JSON value of the
+// payment header (PAYMENT-SIGNATURE or X-PAYMENT)
+func EncodePaymentHeader(payload PaymentPayload) (string, error) {
+	if payload.X402Version != 2 {
+		return "", fmt.Errorf("invalid version for v2 payment payload: %d", payload.X402Version)
+	}
+	return encodePaymentHeader(payload)
+}
+
+// DecodePaymentHeader decodes a payment header value into a v2 payment payload,
+// rejecting headers that carry any other x402Version
+func DecodePaymentHeader(header string) (PaymentPayload, error) {
+	var payload PaymentPayload
+	if err := decodePaymentHeader(header, 2, &payload); err != nil {
+		return PaymentPayload{}, err
+	}
+	return payload, nil
+}
+
+// EncodePaymentHeaderV1 encodes a v1 payment payload as the base64 JSON value of the
+// X-PAYMENT header
+func EncodePaymentHeaderV1(payload PaymentPayloadV1) (string, error) {
+	if payload.X402Version != 1 {
+		return "", fmt.Errorf("invalid version for v1 payment payload: %d", payload.X402Version)
+	}
+	return encodePaymentHeader(payload)
+}
+
+// DecodePaymentHeaderV1 decodes an X-PAYMENT header value into a v1 payment payload,
+// rejecting headers that carry any other x402Version
+func DecodePaymentHeaderV1(header string) (PaymentPayloadV1, error) {
+	var payload PaymentPayloadV1
+	if err := decodePaymentHeader(header, 1, &payload); err != nil {
+		return PaymentPayloadV1{}, err
+	}
+	return payload, nil
+}
+
+func encodePaymentHeader(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payment payload: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodePaymentHeader(header string, wantVersion int, out interface{}) error {
+	data, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return fmt.Errorf("invalid base64 payment header: %w", err)
+	}
+
+	version, err := DetectVersion(data)
+	if err != nil {
+		return fmt.Errorf("invalid payment header: %w", err)
+	}
+	if version != wantVersion {
+		return fmt.Errorf("payment header has version %d, expected %d", version, wantVersion)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid v%d payment payload: %w", wantVersion, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestPaymentHeaderRoundTrip(t *testing.T) {
+	payload := PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"signature": "0xsig"},
+		Accepted: PaymentRequirements{
+			Scheme:  "exact",
+			Network: "eip155:8453",
+			Asset:   "0xasset",
+			Amount:  "1000",
+			PayTo:   "0xpayto",
+		},
+		Resource: &ResourceInfo{URL: "https://example.com/resource"},
+	}
+
+	header, err := EncodePaymentHeader(payload)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoded, err := DecodePaymentHeader(header)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded.X402Version != 2 || decoded.Accepted.Amount != "1000" || decoded.Accepted.Network != "eip155:8453" || decoded.Payload["signature"] != "0xsig" {
+		t.Errorf("Payload changed in round trip: %+v", decoded)
+	}
+	if decoded.Resource == nil || decoded.Resource.URL != payload.Resource.URL {
+		t.Errorf("Expected resource to survive, got %+v", decoded.Resource)
+	}
+}
+
+func TestPaymentHeaderV1RoundTrip(t *testing.T) {
+	payload := PaymentPayloadV1{
+		X402Version: 1,
+		Scheme:      "exact",
+		Network:     "base-sepolia",
+		Payload:     map[string]interface{}{"signature": "0xsig"},
+	}
+
+	header, err := EncodePaymentHeaderV1(payload)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoded, err := DecodePaymentHeaderV1(header)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded.Scheme != "exact" || decoded.Network != "base-sepolia" || decoded.Payload["signature"] != "0xsig" {
+		t.Errorf("Payload changed in round trip: %+v", decoded)
+	}
+
+	// A v1 header is not a v2 payload
+	if _, err := DecodePaymentHeader(header); err == nil || !strings.Contains(err.Error(), "version 1, expected 2") {
+		t.Errorf("Expected version mismatch error, got %v", err)
+	}
+}
+
+func TestPaymentHeaderErrors(t *testing.T) {
+	valid, _ := EncodePaymentHeader(PaymentPayload{X402Version: 2})
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr string
+	}{
+		{"truncated base64", valid[:len(valid)-3], "invalid base64 payment header"},
+		{"not base64", "not*base64", "invalid base64 payment header"},
+		{"not json", base64.StdEncoding.EncodeToString([]byte("hello")), "failed to detect version"},
+		{"missing version", base64.StdEncoding.EncodeToString([]byte(`{"payload":{}}`)), "missing x402Version"},
+		{"wrong shape", base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"payload":"x"}`)), "invalid v2 payment payload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePaymentHeader(tt.header)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := EncodePaymentHeader(PaymentPayload{X402Version: 1}); err == nil {
+		t.Errorf("Expected error encoding a v2 payload with version 1")
+	}
+}
@@ -1,22 +1,59 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 )
 
+// ErrInvalidVersion is wrapped by the errors DetectVersion returns
+var ErrInvalidVersion = errors.New("invalid x402Version")
+
 // DetectVersion extracts x402Version from JSON bytes
+//
+// The input must be a single JSON object. Only its top-level "x402Version" field is inspected,
+// matched the way encoding/json matches struct fields (case-insensitively, last occurrence
+// wins) so the result agrees with a later json.Unmarshal into PaymentPayload or
+// PaymentPayloadV1. The field must be a positive integer number: empty input, non-objects,
+// a missing or null field, strings such as "2", booleans, fractions, exponents and values
+// outside the int range are rejected with an error wrapping ErrInvalidVersion. Other fields
+// are not inspected, but the whole input must be valid JSON.
 func DetectVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return 0, fmt.Errorf("failed to detect version: %w: empty input", ErrInvalidVersion)
+	}
+	if trimmed[0] != '{' {
+		return 0, fmt.Errorf("failed to detect version: %w: input is not a JSON object", ErrInvalidVersion)
+	}
+
 	var detector struct {
-		X402Version int `json:"x402Version"`
+		X402Version json.RawMessage `json:"x402Version"`
 	}
-	if err := json.Unmarshal(data, &detector); err != nil {
-		return 0, fmt.Errorf("failed to detect version: %w", err)
+	if err := json.Unmarshal(trimmed, &detector); err != nil {
+		return 0, fmt.Errorf("failed to detect version: %w: %w", ErrInvalidVersion, err)
+	}
+
+	raw := detector.X402Version
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return 0, fmt.Errorf("failed to detect version: %w: missing x402Version", ErrInvalidVersion)
+	case raw[0] == '"':
+		return 0, fmt.Errorf("failed to detect version: %w: x402Version must be a number, got string %s", ErrInvalidVersion, raw)
+	case raw[0] != '-' && (raw[0] < '0' || raw[0] > '9'):
+		return 0, fmt.Errorf("failed to detect version: %w: x402Version must be a number, got %s", ErrInvalidVersion, raw)
+	}
+
+	version, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0, fmt.Errorf("failed to detect version: %w: x402Version must be an integer, got %s", ErrInvalidVersion, raw)
 	}
-	if detector.X402Version < 1 {
-		return 0, fmt.Errorf("invalid version: %d", detector.X402Version)
+	if version < 1 {
+		return 0, fmt.Errorf("failed to detect version: %w: %d", ErrInvalidVersion, version)
 	}
-	return detector.X402Version, nil
+	return version, nil
 }
 
 // ExtractRequirementsInfo gets scheme and network from requirements bytes
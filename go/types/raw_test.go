@@ -0,0 +1,101 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectVersion(t *testing.T) {
+	deep := `{"payload":` + strings.Repeat("[", 20000) + strings.Repeat("]", 20000) + `,"x402Version":2}`
+
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr string
+	}{
+		{name: "v2", data: `{"x402Version":2,"payload":{}}`, want: 2},
+		{name: "v1", data: `{"x402Version":1,"scheme":"exact"}`, want: 1},
+		{name: "surrounding whitespace", data: " \n\t{\"x402Version\":2} \n", want: 2},
+		{name: "case-insensitive key", data: `{"X402VERSION":2}`, want: 2},
+		{name: "last occurrence wins", data: `{"x402Version":1,"x402Version":2}`, want: 2},
+		{name: "empty", data: ``, wantErr: "empty input"},
+		{name: "whitespace only", data: "  \n", wantErr: "empty input"},
+		{name: "not json", data: `hello`, wantErr: "not a JSON object"},
+		{name: "array", data: `[{"x402Version":2}]`, wantErr: "not a JSON object"},
+		{name: "bare number", data: `2`, wantErr: "not a JSON object"},
+		{name: "truncated", data: `{"x402Version":2`, wantErr: "unexpected end of JSON input"},
+		{name: "trailing data", data: `{"x402Version":2}{}`, wantErr: "invalid character"},
+		{name: "deeply nested", data: deep, wantErr: "exceeded max depth"},
+		{name: "missing version", data: `{"payload":{}}`, wantErr: "missing x402Version"},
+		{name: "null version", data: `{"x402Version":null}`, wantErr: "missing x402Version"},
+		{name: "nested version only", data: `{"payload":{"x402Version":2}}`, wantErr: "missing x402Version"},
+		{name: "string version", data: `{"x402Version":"2"}`, wantErr: "must be a number, got string"},
+		{name: "boolean version", data: `{"x402Version":true}`, wantErr: "must be a number"},
+		{name: "object version", data: `{"x402Version":{"v":2}}`, wantErr: "must be a number"},
+		{name: "fraction", data: `{"x402Version":2.5}`, wantErr: "must be an integer"},
+		{name: "exponent", data: `{"x402Version":2e0}`, wantErr: "must be an integer"},
+		{name: "overflow", data: `{"x402Version":99999999999999999999}`, wantErr: "must be an integer"},
+		{name: "zero", data: `{"x402Version":0}`, wantErr: "invalid x402Version: 0"},
+		{name: "negative", data: `{"x402Version":-1}`, wantErr: "invalid x402Version: -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectVersion([]byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil || got != tt.want {
+					t.Errorf("Expected %d, got %d, %v", tt.want, got, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidVersion) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected ErrInvalidVersion containing %q, got %d, %v", tt.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func FuzzDetectVersion(f *testing.F) {
+	for _, seed := range []string{
+		``,
+		`{}`,
+		`null`,
+		`{"x402Version":2,"payload":{},"accepted":{"scheme":"exact"}}`,
+		`{"x402Version":1,"scheme":"exact","network":"base"}`,
+		`{"x402Version":"2"}`,
+		`{"x402Version":2.0}`,
+		`{"x402Version":-0}`,
+		`{"X402Version":1,"x402version":2}`,
+		`{"payload":[[[[{"x402Version":2}]]]]}`,
+		"\xff\xfe{",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		version, err := DetectVersion(data)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidVersion) {
+				t.Fatalf("Expected error wrapping ErrInvalidVersion, got %v", err)
+			}
+			return
+		}
+		if version < 1 {
+			t.Fatalf("Accepted invalid version %d", version)
+		}
+
+		// The detected version must agree with a full decode of the same bytes
+		var decoded struct {
+			X402Version int `json:"x402Version"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Detected version %d for bytes encoding/json rejects: %v", version, err)
+		}
+		if decoded.X402Version != version {
+			t.Fatalf("Detected version %d, but json.Unmarshal sees %d", version, decoded.X402Version)
+		}
+	})
+}
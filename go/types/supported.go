@@ -0,0 +1,59 @@
+package types
+
+import "strings"
+
+// Supports reports whether the facilitator offers scheme on network in x402 version
+// version. A version of 0 matches any version. Kinds advertised with a network pattern
+// (e.g., "eip155:*") match any network in that family.
+func (r SupportedResponse) Supports(network, scheme string, version int) bool {
+	_, ok := r.Find(network, scheme, version)
+	return ok
+}
+
+// Find returns the first kind matching network, scheme and version, as Supports does
+// The kind's Extra carries scheme-specific data such as an SVM fee payer.
+func (r SupportedResponse) Find(network, scheme string, version int) (SupportedKind, bool) {
+	for _, kind := range r.Kinds {
+		if kind.Scheme != scheme || (version != 0 && kind.X402Version != version) {
+			continue
+		}
+		if networkMatches(network, kind.Network) {
+			return kind, true
+		}
+	}
+	return SupportedKind{}, false
+}
+
+// Networks returns the distinct networks of the supported kinds, in the order they are listed
+func (r SupportedResponse) Networks() []string {
+	return distinctKindField(r.Kinds, func(kind SupportedKind) string { return kind.Network })
+}
+
+// Schemes returns the distinct schemes of the supported kinds, in the order they are listed
+func (r SupportedResponse) Schemes() []string {
+	return distinctKindField(r.Kinds, func(kind SupportedKind) string { return kind.Scheme })
+}
+
+func distinctKindField(kinds []SupportedKind, field func(SupportedKind) string) []string {
+	seen := make(map[string]bool, len(kinds))
+	values := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		value := field(kind)
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// networkMatches reports whether network is pattern or belongs to a "family:*" pattern
+func networkMatches(network, pattern string) bool {
+	if network == pattern {
+		return true
+	}
+	if strings.HasSuffix(pattern, ":*") {
+		return strings.HasPrefix(network, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
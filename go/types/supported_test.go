@@ -0,0 +1,78 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testSupportedResponse() SupportedResponse {
+	return SupportedResponse{
+		Kinds: []SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 1, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 2, Scheme: "upto", Network: "eip155:84532"},
+			{X402Version: 2, Scheme: "exact", Network: "solana:*", Extra: map[string]interface{}{"feePayer": "FeePayer111"}},
+		},
+	}
+}
+
+func TestSupportedResponseSupports(t *testing.T) {
+	supported := testSupportedResponse()
+
+	tests := []struct {
+		name    string
+		network string
+		scheme  string
+		version int
+		want    bool
+	}{
+		{name: "exact match", network: "eip155:8453", scheme: "exact", version: 2, want: true},
+		{name: "older version", network: "eip155:8453", scheme: "exact", version: 1, want: true},
+		{name: "any version", network: "eip155:84532", scheme: "upto", version: 0, want: true},
+		{name: "version not offered", network: "eip155:84532", scheme: "upto", version: 1},
+		{name: "scheme not offered on network", network: "eip155:84532", scheme: "exact", version: 2},
+		{name: "unknown network", network: "eip155:1", scheme: "exact", version: 2},
+		{name: "network pattern", network: "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", scheme: "exact", version: 2, want: true},
+		{name: "pattern is per family", network: "solanafork:1", scheme: "exact", version: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := supported.Supports(tt.network, tt.scheme, tt.version); got != tt.want {
+				t.Errorf("Supports(%q, %q, %d) = %v, want %v", tt.network, tt.scheme, tt.version, got, tt.want)
+			}
+		})
+	}
+
+	if (SupportedResponse{}).Supports("eip155:8453", "exact", 2) {
+		t.Error("Expected an empty response to support nothing")
+	}
+}
+
+func TestSupportedResponseFind(t *testing.T) {
+	kind, ok := testSupportedResponse().Find("solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", "exact", 2)
+	if !ok {
+		t.Fatal("Expected a kind for the solana pattern")
+	}
+	if kind.Network != "solana:*" || kind.Extra["feePayer"] != "FeePayer111" {
+		t.Errorf("Unexpected kind: %+v", kind)
+	}
+
+	if _, ok := testSupportedResponse().Find("eip155:1", "exact", 2); ok {
+		t.Error("Expected no kind for an unknown network")
+	}
+}
+
+func TestSupportedResponseNetworksAndSchemes(t *testing.T) {
+	supported := testSupportedResponse()
+
+	if got, want := supported.Networks(), []string{"eip155:8453", "eip155:84532", "solana:*"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Networks() = %v, want %v", got, want)
+	}
+	if got, want := supported.Schemes(), []string{"exact", "upto"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Schemes() = %v, want %v", got, want)
+	}
+	if got := (SupportedResponse{}).Networks(); len(got) != 0 {
+		t.Errorf("Expected no networks, got %v", got)
+	}
+}
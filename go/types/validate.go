@@ -0,0 +1,93 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KnownSchemes lists the payment scheme identifiers accepted by PaymentRequirements.Validate
+var KnownSchemes = []string{"exact", "upto"}
+
+var (
+	decimalIntegerPattern = regexp.MustCompile(`^[0-9]+$`)
+	hexAddressPattern     = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+)
+
+// RequirementsError is one problem found by PaymentRequirements.Validate
+type RequirementsError struct {
+	Field   string // JSON name of the offending field, e.g. "amount"
+	Message string // e.g. "amount must be positive"
+}
+
+// Error implements the error interface
+func (e *RequirementsError) Error() string {
+	return e.Message
+}
+
+// Validate checks that the requirements are well-formed before a payload is built from them
+//
+// Every problem is reported as a *RequirementsError, joined with errors.Join:
+//   - Scheme is one of KnownSchemes
+//   - Network is non-empty
+//   - Amount is a positive decimal integer in the asset's smallest unit
+//   - PayTo is non-empty, and a 0x-prefixed hex address on eip155 networks
+func (r PaymentRequirements) Validate() error {
+	return r.validate(false)
+}
+
+// ValidateAllowZero is Validate, accepting an amount of zero for free-tier requirements that
+// only ask the payer to identify themselves
+func (r PaymentRequirements) ValidateAllowZero() error {
+	return r.validate(true)
+}
+
+// IsZeroAmount reports whether Amount is a decimal zero ("0", "000")
+func (r PaymentRequirements) IsZeroAmount() bool {
+	return decimalIntegerPattern.MatchString(r.Amount) && strings.TrimLeft(r.Amount, "0") == ""
+}
+
+func (r PaymentRequirements) validate(allowZero bool) error {
+	var errs []error
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, &RequirementsError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if r.Scheme == "" {
+		add("scheme", "scheme is required")
+	} else if !isKnownScheme(r.Scheme) {
+		add("scheme", "unknown scheme %q", r.Scheme)
+	}
+
+	if r.Network == "" {
+		add("network", "network is required")
+	}
+
+	switch {
+	case r.Amount == "":
+		add("amount", "amount is required")
+	case !decimalIntegerPattern.MatchString(r.Amount):
+		add("amount", "amount %q is not a decimal integer", r.Amount)
+	case !allowZero && r.IsZeroAmount():
+		add("amount", "amount must be positive")
+	}
+
+	switch {
+	case r.PayTo == "":
+		add("payTo", "payTo is required")
+	case strings.HasPrefix(r.Network, "eip155:") && !hexAddressPattern.MatchString(r.PayTo):
+		add("payTo", "payTo %q is not a hex address", r.PayTo)
+	}
+
+	return errors.Join(errs...)
+}
+
+func isKnownScheme(scheme string) bool {
+	for _, known := range KnownSchemes {
+		if scheme == known {
+			return true
+		}
+	}
+	return false
+}
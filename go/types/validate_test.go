@@ -0,0 +1,111 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPaymentRequirementsValidate(t *testing.T) {
+	valid := PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000",
+		PayTo:   "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(r *PaymentRequirements)
+		wantErr []string
+	}{
+		{name: "valid", mutate: func(r *PaymentRequirements) {}},
+		{name: "upto scheme", mutate: func(r *PaymentRequirements) { r.Scheme = "upto" }},
+		{name: "non-evm payTo", mutate: func(r *PaymentRequirements) {
+			r.Network = "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"
+			r.PayTo = "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin"
+		}},
+		{name: "missing scheme", mutate: func(r *PaymentRequirements) { r.Scheme = "" }, wantErr: []string{"scheme is required"}},
+		{name: "unknown scheme", mutate: func(r *PaymentRequirements) { r.Scheme = "stream" }, wantErr: []string{`unknown scheme "stream"`}},
+		{name: "missing network", mutate: func(r *PaymentRequirements) { r.Network = "" }, wantErr: []string{"network is required"}},
+		{name: "missing amount", mutate: func(r *PaymentRequirements) { r.Amount = "" }, wantErr: []string{"amount is required"}},
+		{name: "decimal amount", mutate: func(r *PaymentRequirements) { r.Amount = "1.5" }, wantErr: []string{"not a decimal integer"}},
+		{name: "negative amount", mutate: func(r *PaymentRequirements) { r.Amount = "-1" }, wantErr: []string{"not a decimal integer"}},
+		{name: "zero amount", mutate: func(r *PaymentRequirements) { r.Amount = "000" }, wantErr: []string{"amount must be positive"}},
+		{name: "missing payTo", mutate: func(r *PaymentRequirements) { r.PayTo = "" }, wantErr: []string{"payTo is required"}},
+		{name: "invalid payTo", mutate: func(r *PaymentRequirements) { r.PayTo = "0x1234" }, wantErr: []string{`payTo "0x1234" is not a hex address`}},
+		{
+			name: "every problem is reported",
+			mutate: func(r *PaymentRequirements) {
+				*r = PaymentRequirements{Scheme: "stream", Network: "eip155:1", Amount: "abc", PayTo: "bob"}
+			},
+			wantErr: []string{"unknown scheme", "not a decimal integer", "not a hex address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.mutate(&r)
+			err := r.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error containing %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentRequirementsValidateAllowZero(t *testing.T) {
+	r := PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:8453",
+		Amount:  "0",
+		PayTo:   "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+	}
+	if !r.IsZeroAmount() {
+		t.Error("Expected amount 0 to be zero")
+	}
+	if err := r.ValidateAllowZero(); err != nil {
+		t.Errorf("Expected a zero amount to be allowed, got %v", err)
+	}
+	if err := r.Validate(); err == nil {
+		t.Error("Expected Validate to reject a zero amount")
+	}
+
+	r.Amount = ""
+	if r.IsZeroAmount() || r.ValidateAllowZero() == nil {
+		t.Error("Expected an empty amount to be neither zero nor valid")
+	}
+}
+
+func TestPaymentRequirementsValidateFields(t *testing.T) {
+	r := PaymentRequirements{Scheme: "stream", Network: "eip155:1", Amount: "abc", PayTo: "bob"}
+	joined, ok := r.Validate().(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Expected joined errors, got %v", r.Validate())
+	}
+
+	var fields []string
+	for _, err := range joined.Unwrap() {
+		var requirementsErr *RequirementsError
+		if !errors.As(err, &requirementsErr) {
+			t.Fatalf("Expected *RequirementsError, got %T", err)
+		}
+		fields = append(fields, requirementsErr.Field)
+	}
+	if want := []string{"scheme", "amount", "payTo"}; strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected fields %v, got %v", want, fields)
+	}
+}
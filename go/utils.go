@@ -1,6 +1,9 @@
 package x402
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // ValidatePaymentPayload performs basic validation on a payment payload
 // Version-aware: handles both v1 and v2 payload structures
@@ -48,6 +51,40 @@ func ValidatePaymentRequirements(r PaymentRequirements) error {
 	return nil
 }
 
+// NegotiateVersion returns the highest x402 version this SDK and the facilitator both
+// support for a network/scheme pair, based on the facilitator's GetSupported response.
+// Supported kinds advertised with a network pattern (e.g., "eip155:*") match any network
+// in that family. Returns a *PaymentError with ErrCodeUnsupportedScheme when the
+// facilitator does not offer the pair in any version this SDK understands.
+func NegotiateVersion(ctx context.Context, client FacilitatorClient, network Network, scheme string) (int, error) {
+	supported, err := client.GetSupported(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get supported kinds: %w", err)
+	}
+
+	version := 0
+	for _, kind := range supported.Kinds {
+		if kind.Scheme != scheme || !network.Match(Network(kind.Network)) {
+			continue
+		}
+		if kind.X402Version < ProtocolVersionV1 || kind.X402Version > ProtocolVersion {
+			continue
+		}
+		if kind.X402Version > version {
+			version = kind.X402Version
+		}
+	}
+
+	if version == 0 {
+		return 0, NewPaymentError(
+			ErrCodeUnsupportedScheme,
+			fmt.Sprintf("facilitator does not support scheme %s on network %s", scheme, network),
+			map[string]interface{}{"network": string(network), "scheme": scheme},
+		)
+	}
+	return version, nil
+}
+
 // findByNetworkAndScheme finds a scheme implementation for a given network/scheme combination
 // This supports pattern matching for networks (e.g., "eip155:*")
 func findByNetworkAndScheme[T any](networkMap map[Network]map[string]T, scheme string, network Network) T {
@@ -1,6 +1,8 @@
 package x402
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
@@ -408,3 +410,50 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestNegotiateVersion(t *testing.T) {
+	client := &mockFacilitatorClient{
+		kinds: []SupportedKind{
+			{X402Version: 1, Scheme: "exact", Network: "base-sepolia"},
+			{X402Version: 1, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 2, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 2, Scheme: "exact", Network: "solana:*"},
+			{X402Version: 3, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 2, Scheme: "upto", Network: "eip155:84532"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		network     Network
+		scheme      string
+		wantVersion int
+		wantErr     bool
+	}{
+		{name: "v1 and v2 offered picks v2", network: "eip155:8453", scheme: "exact", wantVersion: 2},
+		{name: "only v1 offered", network: "base-sepolia", scheme: "exact", wantVersion: 1},
+		{name: "wildcard network", network: "solana:devnet", scheme: "exact", wantVersion: 2},
+		{name: "unknown network", network: "eip155:1", scheme: "exact", wantErr: true},
+		{name: "unknown scheme", network: "eip155:8453", scheme: "upto", wantErr: true},
+		{name: "upto offered", network: "eip155:84532", scheme: "upto", wantVersion: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := NegotiateVersion(context.Background(), client, tt.network, tt.scheme)
+			if tt.wantErr {
+				var paymentErr *PaymentError
+				if !errors.As(err, &paymentErr) || paymentErr.Code != ErrCodeUnsupportedScheme {
+					t.Fatalf("Expected %s payment error, got %v", ErrCodeUnsupportedScheme, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("Expected version %d, got %d", tt.wantVersion, version)
+			}
+		})
+	}
+}